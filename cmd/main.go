@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 
+	"knative.dev/kn-plugin-source-kamelet/internal/command"
 	"knative.dev/kn-plugin-source-kamelet/internal/root"
 )
 
@@ -27,6 +28,6 @@ func main() {
 		if err.Error() != "subcommand is required" {
 			fmt.Fprintln(os.Stderr, err)
 		}
-		os.Exit(1)
+		os.Exit(command.ExitCode(err))
 	}
 }
@@ -0,0 +1,81 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package catalog provides the snapshot of the apache/camel-kamelets catalog bundled with the plugin. The snapshot
+// holds a curated subset of the catalog and is regenerated with internal/catalog/gen.
+package catalog
+
+//go:generate go run ./gen --dir /tmp/camel-kamelets --version v0.3.0 --output zz_generated_snapshot.go --include aws-s3-source,cron-source,kafka-sink,kafka-source,log-sink,timer-source,webhook-source
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	parseOnce sync.Once
+	kamelets  []v1alpha1.Kamelet
+	parseErr  error
+)
+
+// Parse decodes a Kamelet given as YAML or JSON
+func Parse(data []byte) (*v1alpha1.Kamelet, error) {
+	kamelet := &v1alpha1.Kamelet{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096).Decode(kamelet); err != nil {
+		return nil, err
+	}
+	if kamelet.Kind != v1alpha1.KameletKind {
+		return nil, fmt.Errorf("expected kind %s but got %q", v1alpha1.KameletKind, kamelet.Kind)
+	}
+	return kamelet, nil
+}
+
+// Snapshot returns the bundled Kamelets sorted by name
+func Snapshot() ([]v1alpha1.Kamelet, error) {
+	parseOnce.Do(func() {
+		for name, data := range snapshot {
+			kamelet, err := Parse([]byte(data))
+			if err != nil {
+				parseErr = fmt.Errorf("invalid bundled Kamelet %s: %w", name, err)
+				return
+			}
+			kamelets = append(kamelets, *kamelet)
+		}
+		sort.Slice(kamelets, func(i, j int) bool {
+			return kamelets[i].Name < kamelets[j].Name
+		})
+	})
+	return kamelets, parseErr
+}
+
+// Get returns a copy of the bundled Kamelet with given name
+func Get(name string) (*v1alpha1.Kamelet, bool) {
+	items, err := Snapshot()
+	if err != nil {
+		return nil, false
+	}
+	for i := range items {
+		if items[i].Name == name {
+			return items[i].DeepCopy(), true
+		}
+	}
+	return nil, false
+}
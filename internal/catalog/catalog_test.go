@@ -0,0 +1,45 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package catalog
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	kamelets, err := Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, len(kamelets), len(snapshot))
+	for i := 1; i < len(kamelets); i++ {
+		assert.Check(t, kamelets[i-1].Name < kamelets[i].Name)
+	}
+
+	kamelet, ok := Get("timer-source")
+	assert.Check(t, ok)
+	assert.DeepEqual(t, kamelet.Spec.Definition.Required, []string{"message"})
+	assert.Equal(t, kamelet.Labels["camel.apache.org/kamelet.type"], "source")
+
+	_, ok = Get("missing")
+	assert.Check(t, !ok)
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	assert.ErrorContains(t, err, "expected kind Kamelet")
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command gen generates the Kamelet catalog snapshot bundled with the plugin from a local checkout of
+// the apache/camel-kamelets repository, e.g.
+//
+//	git clone --depth 1 --branch v0.3.0 https://github.com/apache/camel-kamelets /tmp/camel-kamelets
+//	go run ./internal/catalog/gen --dir /tmp/camel-kamelets --version v0.3.0
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const kameletSuffix = ".kamelet.yaml"
+
+func main() {
+	dir := flag.String("dir", "", "Directory of the apache/camel-kamelets checkout holding the *.kamelet.yaml files.")
+	version := flag.String("version", "", "Version of the catalog checkout, e.g. v0.3.0.")
+	include := flag.String("include", "", "Comma separated names of the Kamelets to bundle, all Kamelets are bundled by default.")
+	output := flag.String("output", "internal/catalog/zz_generated_snapshot.go", "Path of the generated Go file.")
+	flag.Parse()
+
+	if *dir == "" || *version == "" {
+		fmt.Fprintln(os.Stderr, "--dir and --version are required")
+		os.Exit(1)
+	}
+	if err := generate(*dir, *version, *include, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(dir string, version string, include string, output string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+kameletSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	included := map[string]bool{}
+	for _, name := range strings.Split(include, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			included[name] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by internal/catalog/gen. DO NOT EDIT.\n\n")
+	buf.WriteString("package catalog\n\n")
+	fmt.Fprintf(&buf, "// SnapshotVersion is the version of the apache/camel-kamelets catalog bundled with the plugin\n")
+	fmt.Fprintf(&buf, "const SnapshotVersion = %q\n\n", version)
+	buf.WriteString("// snapshot holds the bundled Kamelet definitions by name\n")
+	buf.WriteString("var snapshot = map[string]string{\n")
+	count := 0
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), kameletSuffix)
+		if len(included) > 0 && !included[name] {
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "%q: %s,\n", name, literal(string(data)))
+		count++
+	}
+	buf.WriteString("}\n")
+	if count == 0 {
+		return fmt.Errorf("no Kamelets found in %s", dir)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(output, source, 0644)
+}
+
+// literal returns a raw string literal unless the value contains backquotes
+func literal(value string) string {
+	if strings.Contains(value, "`") {
+		return strconv.Quote(value)
+	}
+	return "`" + value + "`"
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package catalog
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+)
+
+// DefaultRepository is the GitHub repository of the upstream Kamelet catalog
+const DefaultRepository = "https://github.com/apache/camel-kamelets"
+
+// kameletSuffix is the file name suffix of the Kamelet definitions in the catalog
+const kameletSuffix = ".kamelet.yaml"
+
+// signatureSuffix is appended to the URL of the catalog archive to locate its signature
+const signatureSuffix = ".sig"
+
+// errNotFound reports a download of a missing catalog release
+var errNotFound = errors.New("404 Not Found")
+
+// Verifier checks the signature of the catalog archive downloaded from given source
+type Verifier func(archive []byte, signature []byte, source string) error
+
+// Fetch downloads the source archive of given catalog release from the GitHub repository and returns its Kamelets
+// sorted by name. The Kamelets are read from the root and the kamelets directory of the archive, later releases
+// moved the definitions into the latter. When a verifier is given, the archive must be signed and its signature is
+// read from the archive URL with the .sig suffix.
+func Fetch(ctx context.Context, client *http.Client, repository string, version string, verify Verifier) ([]v1alpha1.Kamelet, error) {
+	archiveURL := fmt.Sprintf("%s/archive/%s.tar.gz", strings.TrimSuffix(repository, "/"), version)
+	archive, err := download(ctx, client, archiveURL)
+	switch {
+	case errors.Is(err, errNotFound):
+		return nil, fmt.Errorf("Kamelet catalog version %s not found in %s", version, repository)
+	case err != nil:
+		return nil, fmt.Errorf("failed to download Kamelet catalog %s: %w", version, err)
+	}
+
+	if verify != nil {
+		signature, err := download(ctx, client, archiveURL+signatureSuffix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download signature of Kamelet catalog %s: %w", version, err)
+		}
+		if err := verify(archive, signature, archiveURL); err != nil {
+			return nil, err
+		}
+	}
+
+	kamelets, err := readArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Kamelet catalog %s: %w", version, err)
+	}
+	return kamelets, nil
+}
+
+// download returns the content of given URL
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.New(resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// readArchive returns the Kamelets of the gzipped tar archive of the catalog repository
+func readArchive(r io.Reader) ([]v1alpha1.Kamelet, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var kamelets []v1alpha1.Kamelet
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !isCatalogFile(header.Name) {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+		kamelet, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kamelet %s: %w", path.Base(header.Name), err)
+		}
+		kamelets = append(kamelets, *kamelet)
+	}
+
+	sort.Slice(kamelets, func(i, j int) bool {
+		return kamelets[i].Name < kamelets[j].Name
+	})
+	return kamelets, nil
+}
+
+// isCatalogFile checks whether the archive entry is a Kamelet definition of the catalog, the entries are prefixed
+// by the top level directory of the archive
+func isCatalogFile(name string) bool {
+	if !strings.HasSuffix(name, kameletSuffix) {
+		return false
+	}
+	segments := strings.Split(name, "/")
+	return len(segments) == 2 || (len(segments) == 3 && segments[1] == "kamelets")
+}
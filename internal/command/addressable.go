@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// verifySinkAddressable checks that the resource referenced as binding sink exists and implements the Addressable
+// duck type by reporting its URL in status.address.url. Kamelet and URI sinks are not checked.
+func (params *KameletPluginParams) verifySinkAddressable(binding *v1alpha1.KameletBinding) error {
+	ref := binding.Spec.Sink.Ref
+	if ref == nil || ref.Kind == v1alpha1.KameletKind {
+		return nil
+	}
+	_, err := params.resolveSinkURL(binding)
+	return err
+}
+
+// resolveSinkURL returns the URL the binding delivers events to, i.e. the status.address.url of the Addressable sink
+// resource or the URI of URI sinks. Kamelet sinks are part of the binding integration and have no address.
+func (params *KameletPluginParams) resolveSinkURL(binding *v1alpha1.KameletBinding) (string, error) {
+	ref := binding.Spec.Sink.Ref
+	if ref == nil {
+		if binding.Spec.Sink.URI == nil {
+			return "", fmt.Errorf("KameletBinding '%s' has no sink", binding.Name)
+		}
+		return *binding.Spec.Sink.URI, nil
+	}
+	if ref.Kind == v1alpha1.KameletKind {
+		return "", fmt.Errorf("sink Kamelet '%s' of KameletBinding '%s' is not addressable", ref.Name, binding.Name)
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = binding.Namespace
+	}
+
+	kubeClient, err := params.NewKubeClient()
+	if err != nil {
+		return "", err
+	}
+	gvr, err := sinkResource(kubeClient, ref)
+	if err != nil {
+		return "", err
+	}
+
+	dynamicClient, err := params.NewDynamicClient()
+	if err != nil {
+		return "", err
+	}
+	sink, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(params.Context, ref.Name, v1.GetOptions{})
+	if err != nil {
+		return "", knerrors.GetError(err)
+	}
+
+	url, _, _ := unstructured.NestedString(sink.Object, "status", "address", "url")
+	if url == "" {
+		return "", fmt.Errorf("sink %s '%s' in namespace '%s' is not addressable, status.address.url is not set", ref.Kind, ref.Name, namespace)
+	}
+	return url, nil
+}
+
+// sinkResource discovers the API resource serving the kind of the sink reference
+func sinkResource(client kubernetes.Interface, ref *corev1.ObjectReference) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("sink API version %q is not served by the cluster: %w", ref.APIVersion, err)
+	}
+	for _, resource := range resources.APIResources {
+		// skip sub resources such as status
+		if resource.Kind == ref.Kind && !strings.Contains(resource.Name, "/") {
+			return gv.WithResource(resource.Name), nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("sink kind %q is not served by API version %q", ref.Kind, ref.APIVersion)
+}
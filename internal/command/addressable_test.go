@@ -0,0 +1,150 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestVerifySinkAddressable(t *testing.T) {
+	p := addressableParams(
+		sinkResourceObject("events", "default", "http://events.default.svc"),
+		sinkResourceObject("pending", "default", ""),
+	)
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Ref.APIVersion = "example.com/v1"
+	binding.Spec.Sink.Ref.Kind = "EventSink"
+
+	binding.Spec.Sink.Ref.Name = "events"
+	assert.NilError(t, p.verifySinkAddressable(binding))
+
+	binding.Spec.Sink.Ref.Name = "pending"
+	assert.Error(t, p.verifySinkAddressable(binding), "sink EventSink 'pending' in namespace 'default' is not addressable, status.address.url is not set")
+
+	binding.Spec.Sink.Ref.Name = "unknown"
+	assert.ErrorContains(t, p.verifySinkAddressable(binding), "not found")
+
+	binding.Spec.Sink.Ref.Kind = "Other"
+	assert.Error(t, p.verifySinkAddressable(binding), "sink kind \"Other\" is not served by API version \"example.com/v1\"")
+}
+
+func TestVerifySinkAddressableSkipsKameletAndURISinks(t *testing.T) {
+	p := addressableParams()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Ref.Kind = "Kamelet"
+	assert.NilError(t, p.verifySinkAddressable(binding))
+
+	uri := "https://example.com/webhook"
+	binding.Spec.Sink.Ref = nil
+	binding.Spec.Sink.URI = &uri
+	assert.NilError(t, p.verifySinkAddressable(binding))
+}
+
+func addressableParams(objects ...runtime.Object) *KameletPluginParams {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []v1.APIResource{
+				{Name: "eventsinks", Kind: "EventSink", Namespaced: true},
+				{Name: "eventsinks/status", Kind: "EventSink", Namespaced: true},
+			},
+		},
+	}
+
+	return &KameletPluginParams{
+		Context: context.TODO(),
+		NewKubeClient: func() (kubernetes.Interface, error) {
+			return clientset, nil
+		},
+		NewDynamicClient: func() (dynamic.Interface, error) {
+			return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...), nil
+		},
+	}
+}
+
+func sinkResourceObject(name string, namespace string, url string) *unstructured.Unstructured {
+	sink := &unstructured.Unstructured{}
+	sink.SetAPIVersion("example.com/v1")
+	sink.SetKind("EventSink")
+	sink.SetName(name)
+	sink.SetNamespace(namespace)
+	if url != "" {
+		_ = unstructured.SetNestedField(sink.Object, url, "status", "address", "url")
+	}
+	return sink
+}
+
+func TestBindOutputSinkURL(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := addressableParams(sinkResourceObject("events", "current", "http://events.current.svc"))
+	p.KnParams = &commands.KnParams{}
+	p.UseKameletBinding = true
+	p.NewKameletClient = func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+		return mockClient, nil
+	}
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-eventsink-events"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "events")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--sink", "example.com/v1:EventSink:events", "-o", "url")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "http://events.current.svc\n")
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-https"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, *binding.Spec.Sink.URI, "https://example.com/webhook")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--uri", "https://example.com/webhook", "-o", "url")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "https://example.com/webhook\n")
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "-o", "url", "--offline")
+	assert.Error(t, err, "output format url can not be combined with --dry-run or --offline, the sink URL is resolved from the cluster")
+	recorder.Validate()
+}
+
+func TestResolveSinkURLKameletSink(t *testing.T) {
+	p := addressableParams()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Ref.Kind = "Kamelet"
+	binding.Spec.Sink.Ref.Name = "log-sink"
+	_, err := p.resolveSinkURL(binding)
+	assert.Error(t, err, "sink Kamelet 'log-sink' of KameletBinding 'b1' is not addressable")
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/dynamic"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// fieldManager identifies the plugin as manager of the fields it sets on the resources
+const fieldManager = "kn-source-kamelet"
+
+// serverSideApplyBinding applies the binding with server-side apply, so the API server merges the fields set by the
+// plugin with the fields managed by other tools such as GitOps controllers
+func serverSideApplyBinding(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*v1alpha1.KameletBinding, error) {
+	var dryRun []string
+	var dryRunSuffix string
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	// the existing binding is only read to report the changes
+	existing, err := client.KameletBindings(binding.Namespace).Get(ctx, binding.Name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	if err := update.verifyUpdate(existing, binding, v1alpha1.KameletBindingKind, out); err != nil {
+		return nil, err
+	}
+
+	manifest, err := bindingManifest(binding, false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := applyConfiguration(manifest)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := client.KameletBindings(binding.Namespace).Patch(ctx, binding.Name, types.ApplyPatchType, data, update.patchOptions(dryRun))
+	var changes []string
+	if err == nil && existing != nil {
+		changes = bindingChanges(existing, applied)
+	}
+	if auditErr := audit.record("apply", binding.Kind, binding.Namespace, binding.Name, changes, err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, applyError(err)
+	}
+	if existing != nil {
+		if err := writeUpdateDiff(existing, applied, false, update.redactor, out); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(out, "KameletBinding '%s' %s in namespace '%s'%s.\n", binding.Name, appliedAction(existing != nil), binding.Namespace, dryRunSuffix)
+	return applied, nil
+}
+
+// serverSideApplyManifest applies the unstructured binding or Pipe manifest with server-side apply
+func serverSideApplyManifest(ctx context.Context, resources dynamic.ResourceInterface, manifest *unstructured.Unstructured, binding *v1alpha1.KameletBinding, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*unstructured.Unstructured, error) {
+	var dryRun []string
+	var dryRunSuffix string
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	kind := manifest.GetKind()
+	var existing *v1alpha1.KameletBinding
+	current, err := resources.Get(ctx, binding.Name, v1.GetOptions{})
+	if err == nil {
+		if existing, err = fromPipe(current); err != nil {
+			return nil, err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return nil, knerrors.GetError(err)
+	}
+	if err := update.verifyUpdate(existing, binding, kind, out); err != nil {
+		return nil, err
+	}
+
+	data, err := applyConfiguration(manifest)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := resources.Patch(ctx, binding.Name, types.ApplyPatchType, data, update.patchOptions(dryRun))
+	var appliedBinding *v1alpha1.KameletBinding
+	var changes []string
+	if err == nil && existing != nil {
+		if appliedBinding, err = fromPipe(applied); err == nil {
+			changes = bindingChanges(existing, appliedBinding)
+		}
+	}
+	if auditErr := audit.record("apply", kind, binding.Namespace, binding.Name, changes, err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, applyError(err)
+	}
+	if existing != nil {
+		if err := writeUpdateDiff(existing, appliedBinding, kind == pipeKind, update.redactor, out); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(out, "%s '%s' %s in namespace '%s'%s.\n", kind, binding.Name, appliedAction(existing != nil), binding.Namespace, dryRunSuffix)
+	return applied, nil
+}
+
+// patchOptions returns the options of server-side apply, on --force the plugin takes over fields managed by others
+func (o updateOptions) patchOptions(dryRun []string) v1.PatchOptions {
+	force := o.Force
+	return v1.PatchOptions{FieldManager: fieldManager, Force: &force, DryRun: dryRun}
+}
+
+// applyConfiguration returns the JSON apply configuration of the resource without status and server generated fields
+func applyConfiguration(obj runtime.Object) ([]byte, error) {
+	manifest, err := sanitize(obj)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(manifest.Object)
+}
+
+// lastAppliedAnnotation holds the configuration of the last create or update, it is shared with kubectl apply so both
+// compute the same three-way merges
+const lastAppliedAnnotation = corev1.LastAppliedConfigAnnotation
+
+// withLastApplied returns a copy of the binding or Pipe annotated with its own configuration as last applied configuration
+func withLastApplied(obj runtime.Object) (runtime.Object, error) {
+	manifest, err := sanitize(obj)
+	if err != nil {
+		return nil, err
+	}
+	removeLastApplied(manifest)
+	data, err := json.Marshal(manifest.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := obj.DeepCopyObject()
+	accessor, err := meta.Accessor(applied)
+	if err != nil {
+		return nil, err
+	}
+	accessor.SetAnnotations(mergeMetadata(accessor.GetAnnotations(), map[string]string{lastAppliedAnnotation: string(data)}, nil))
+	return applied, nil
+}
+
+// removeLastApplied removes the last applied configuration from the manifest
+func removeLastApplied(manifest *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(manifest.Object, "metadata", "annotations", lastAppliedAnnotation)
+	if annotations, _, _ := unstructured.NestedMap(manifest.Object, "metadata", "annotations"); len(annotations) == 0 {
+		unstructured.RemoveNestedField(manifest.Object, "metadata", "annotations")
+	}
+}
+
+// threeWayMergeBinding computes the strategic merge patch from the last applied configuration to the binding and applies
+// it to the existing binding. Fields removed from the binding since the last update are removed while fields set by
+// others, e.g. traits added manually, are kept.
+func threeWayMergeBinding(existing *v1alpha1.KameletBinding, binding *v1alpha1.KameletBinding) (*v1alpha1.KameletBinding, error) {
+	manifest, err := bindingManifest(binding, false)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := withLastApplied(manifest)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := applyConfiguration(modified)
+	if err != nil {
+		return nil, err
+	}
+	currentJSON, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	lookupPatchMeta, err := strategicpatch.NewPatchMetaFromStruct(&v1alpha1.KameletBinding{})
+	if err != nil {
+		return nil, err
+	}
+	original := []byte(existing.Annotations[lastAppliedAnnotation])
+	patch, err := strategicpatch.CreateThreeWayMergePatch(original, modifiedJSON, currentJSON, lookupPatchMeta, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge binding '%s': %w", binding.Name, err)
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(currentJSON, patch, lookupPatchMeta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge binding '%s': %w", binding.Name, err)
+	}
+
+	desired := &v1alpha1.KameletBinding{}
+	if err := json.Unmarshal(mergedJSON, desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// threeWayMergeManifest merges the unstructured Pipe or binding like threeWayMergeBinding, a JSON merge patch is used
+// as strategic merge patches require the Go type of the resource
+func threeWayMergeManifest(existing *unstructured.Unstructured, manifest *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	modified, err := withLastApplied(manifest)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := applyConfiguration(modified)
+	if err != nil {
+		return nil, err
+	}
+	currentJSON, err := existing.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	original := []byte(existing.GetAnnotations()[lastAppliedAnnotation])
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modifiedJSON, currentJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s '%s': %w", manifest.GetKind(), manifest.GetName(), err)
+	}
+	mergedJSON, err := jsonpatch.MergePatch(currentJSON, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s '%s': %w", manifest.GetKind(), manifest.GetName(), err)
+	}
+
+	desired := &unstructured.Unstructured{}
+	if err := desired.UnmarshalJSON(mergedJSON); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// applyError points to --force when the applied fields are managed by another tool
+func applyError(err error) error {
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("%w, use --force to take over the conflicting fields", err)
+	}
+	return knerrors.GetError(err)
+}
+
+func appliedAction(existed bool) string {
+	if existed {
+		return "updated"
+	}
+	return "created"
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWithLastApplied(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Annotations = map[string]string{"owner": "ops"}
+
+	applied, err := withLastApplied(binding)
+	assert.NilError(t, err)
+	annotations := applied.(*camelkapis.KameletBinding).Annotations
+	assert.Equal(t, annotations["owner"], "ops")
+	assert.Check(t, util.ContainsAll(annotations[lastAppliedAnnotation], `"kind":"KameletBinding"`, `"annotations":{"owner":"ops"}`, `"name":"k1"`))
+	assert.Check(t, util.ContainsNone(annotations[lastAppliedAnnotation], "status", "creationTimestamp", lastAppliedAnnotation))
+	// the binding itself is not modified
+	assert.Equal(t, len(binding.Annotations), 1)
+}
+
+func TestThreeWayMergeBinding(t *testing.T) {
+	previous := createKameletBinding("b1", "k1")
+	previous.Labels = map[string]string{"team": "events"}
+	last, err := withLastApplied(previous)
+	assert.NilError(t, err)
+
+	// the binding on the cluster got a trait and replicas added manually
+	existing := last.(*camelkapis.KameletBinding).DeepCopy()
+	existing.ResourceVersion = "42"
+	existing.Annotations["trait.camel.apache.org/logging.level"] = "DEBUG"
+	replicas := int32(3)
+	existing.Spec.Integration = &camelv1.IntegrationSpec{Replicas: &replicas}
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Ref.Kind = "Channel"
+	binding.Spec.Sink.Ref.Name = "events"
+
+	desired, err := threeWayMergeBinding(existing, binding)
+	assert.NilError(t, err)
+	assert.Equal(t, desired.ResourceVersion, "42")
+	assert.Equal(t, desired.Spec.Sink.Ref.Kind, "Channel")
+	assert.Equal(t, desired.Spec.Sink.Ref.Name, "events")
+	// the label removed since the last update is removed while the manually added fields are kept
+	assert.Equal(t, len(desired.Labels), 0)
+	assert.Equal(t, desired.Annotations["trait.camel.apache.org/logging.level"], "DEBUG")
+	assert.Equal(t, *desired.Spec.Integration.Replicas, int32(3))
+	assert.Check(t, util.ContainsAll(desired.Annotations[lastAppliedAnnotation], `"name":"events"`))
+	assert.Check(t, util.ContainsNone(desired.Annotations[lastAppliedAnnotation], "team"))
+
+	// without last applied configuration nothing is removed
+	existing.Annotations = map[string]string{"trait.camel.apache.org/logging.level": "DEBUG"}
+	desired, err = threeWayMergeBinding(existing, binding)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, desired.Labels, map[string]string{"team": "events"})
+	assert.Equal(t, *desired.Spec.Integration.Replicas, int32(3))
+}
+
+func TestThreeWayMergePipe(t *testing.T) {
+	previous := createKameletBinding("b1", "k1")
+	previous.Labels = map[string]string{"team": "events"}
+	pipe, err := toPipe(previous)
+	assert.NilError(t, err)
+	last, err := withLastApplied(pipe)
+	assert.NilError(t, err)
+
+	existing := last.(*unstructured.Unstructured).DeepCopy()
+	existing.SetResourceVersion("42")
+	assert.NilError(t, unstructured.SetNestedField(existing.Object, int64(3), "spec", "replicas"))
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Ref.Name = "other"
+	modified, err := toPipe(binding)
+	assert.NilError(t, err)
+
+	desired, err := threeWayMergeManifest(existing, modified)
+	assert.NilError(t, err)
+	assert.Equal(t, desired.GetResourceVersion(), "42")
+	assert.Equal(t, len(desired.GetLabels()), 0)
+	name, _, _ := unstructured.NestedString(desired.Object, "spec", "sink", "ref", "name")
+	assert.Equal(t, name, "other")
+	replicas, _, _ := unstructured.NestedInt64(desired.Object, "spec", "replicas")
+	assert.Equal(t, replicas, int64(3))
+}
+
+func TestApplyBindingKeepsManualChanges(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	last, err := withLastApplied(createKameletBindingInNamespace("b1", "k1", "current"))
+	assert.NilError(t, err)
+	existing := last.(*camelkapis.KameletBinding)
+	existing.Annotations["trait.camel.apache.org/logging.level"] = "DEBUG"
+
+	recorder.GetBinding(existing, nil)
+	recorder.UpdateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Annotations["trait.camel.apache.org/logging.level"], "DEBUG")
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Channel")
+	}, nil)
+	binding := createKameletBindingInNamespace("b1", "k1", "current")
+	binding.Spec.Sink.Ref = &corev1.ObjectReference{APIVersion: "messaging.knative.dev/v1", Kind: "Channel", Namespace: "current", Name: "events"}
+	output := &bytes.Buffer{}
+	_, err = applyBinding(context.TODO(), mockClient, binding, updateOptions{}, false, nil, output)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output.String(), "+      kind: Channel\n", "KameletBinding 'b1' updated"))
+	assert.Check(t, util.ContainsNone(output.String(), "logging.level", lastAppliedAnnotation))
+	recorder.Validate()
+}
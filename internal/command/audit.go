@@ -0,0 +1,140 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+)
+
+// auditRecord is a single structured entry of the audit log
+type auditRecord struct {
+	Timestamp string   `json:"timestamp"`
+	User      string   `json:"user,omitempty"`
+	As        string   `json:"as,omitempty"`
+	Cluster   string   `json:"cluster,omitempty"`
+	Action    string   `json:"action"`
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Changes   []string `json:"changes,omitempty"`
+	Result    string   `json:"result"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// auditLog appends a JSON record for each mutation performed by the plugin to a file.
+// A nil audit log is valid and records nothing.
+type auditLog struct {
+	path    string
+	user    string
+	as      string
+	cluster string
+	now     func() time.Time
+	lock    sync.Mutex
+}
+
+// auditLog returns the audit log configured by the --audit-log flag or nil when auditing is disabled
+func (params *KameletPluginParams) auditLog() *auditLog {
+	if params.AuditLogFile == "" {
+		return nil
+	}
+	if params.audit == nil {
+		params.audit = &auditLog{
+			path: params.AuditLogFile,
+			as:   params.ImpersonateUser,
+			now:  time.Now,
+		}
+		// user and cluster are informational, so records are written even if the config can not be read
+		if _, kubeContext := params.currentContext(); kubeContext != nil {
+			params.audit.user = kubeContext.AuthInfo
+			params.audit.cluster = kubeContext.Cluster
+		}
+	}
+	return params.audit
+}
+
+// record appends an entry for given mutation and its result
+func (a *auditLog) record(action string, kind string, namespace string, name string, changes []string, result error) error {
+	if a == nil {
+		return nil
+	}
+
+	entry := auditRecord{
+		Timestamp: a.now().UTC().Format(time.RFC3339),
+		User:      a.user,
+		As:        a.as,
+		Cluster:   a.cluster,
+		Action:    action,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Changes:   changes,
+		Result:    "success",
+	}
+	if result != nil {
+		entry.Result = "failure"
+		entry.Error = result.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// bindingChanges summarizes which parts of the binding metadata and spec differ
+func bindingChanges(existing *v1alpha1.KameletBinding, updated *v1alpha1.KameletBinding) []string {
+	var changes []string
+	if !reflect.DeepEqual(existing.Labels, updated.Labels) {
+		changes = append(changes, "metadata.labels")
+	}
+	// the last applied configuration changes with every update
+	ignored := []string{lastAppliedAnnotation}
+	if !reflect.DeepEqual(mergeMetadata(existing.Annotations, nil, ignored), mergeMetadata(updated.Annotations, nil, ignored)) {
+		changes = append(changes, "metadata.annotations")
+	}
+	if !reflect.DeepEqual(existing.Spec.Source, updated.Spec.Source) {
+		changes = append(changes, "spec.source")
+	}
+	if !reflect.DeepEqual(existing.Spec.Sink, updated.Spec.Sink) {
+		changes = append(changes, "spec.sink")
+	}
+	if !reflect.DeepEqual(existing.Spec.Integration, updated.Spec.Integration) {
+		changes = append(changes, "spec.integration")
+	}
+	return changes
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"knative.dev/client/pkg/kn/commands"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAuditLogDisabled(t *testing.T) {
+	p := KameletPluginParams{}
+	audit := p.auditLog()
+	assert.Assert(t, audit == nil)
+	assert.NilError(t, audit.record("create", "KameletBinding", "default", "b1", nil, nil))
+}
+
+func TestAuditLogRecords(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{
+			ClientConfig: clientcmd.NewDefaultClientConfig(clientcmdapi.Config{
+				CurrentContext: "dev",
+				Contexts: map[string]*clientcmdapi.Context{
+					"dev": {AuthInfo: "alice", Cluster: "dev-cluster"},
+				},
+			}, &clientcmd.ConfigOverrides{}),
+		},
+		AuditLogFile:    file,
+		ImpersonateUser: "system:serviceaccount:test:deployer",
+	}
+
+	audit := p.auditLog()
+	audit.now = func() time.Time {
+		return time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	assert.NilError(t, audit.record("update", "KameletBinding", "default", "b1", []string{"spec.sink"}, nil))
+	assert.NilError(t, audit.record("delete", "KameletBinding", "default", "b2", nil, errors.New("forbidden")))
+
+	records := readAuditRecords(t, file)
+	assert.Equal(t, len(records), 2)
+	assert.DeepEqual(t, records[0], auditRecord{
+		Timestamp: "2021-05-01T10:00:00Z",
+		User:      "alice",
+		As:        "system:serviceaccount:test:deployer",
+		Cluster:   "dev-cluster",
+		Action:    "update",
+		Kind:      "KameletBinding",
+		Namespace: "default",
+		Name:      "b1",
+		Changes:   []string{"spec.sink"},
+		Result:    "success",
+	})
+	assert.Equal(t, records[1].Action, "delete")
+	assert.Equal(t, records[1].Result, "failure")
+	assert.Equal(t, records[1].Error, "forbidden")
+}
+
+func TestAuditLogApplyBinding(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	existing := createKameletBinding("k1-to-broker-default", "k1")
+	existing.Spec.Sink.Ref.Namespace = "current"
+	existing.Spec.Source.Ref.Namespace = "current"
+
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, nil)
+	recorder.GetBinding(existing, nil)
+	recorder.UpdateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, nil)
+
+	file := filepath.Join(t.TempDir(), "audit.log")
+	audit := &auditLog{path: file, now: time.Now}
+	options := &kameletapi.BindingOptions{Namespace: "current", Kamelet: "k1", Sink: "broker:default"}
+
+	for _, properties := range []map[string]string{nil, {"message": "Hello"}} {
+		options.SourceProperties = properties
+		binding, err := kameletapi.NewBinding(options)
+		assert.NilError(t, err)
+		_, err = applyBinding(context.TODO(), mockClient, binding, updateOptions{}, false, audit, &bytes.Buffer{})
+		assert.NilError(t, err)
+	}
+
+	records := readAuditRecords(t, file)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].Action, "create")
+	assert.Equal(t, records[0].Name, "k1-to-broker-default")
+	assert.Equal(t, records[0].Result, "success")
+	assert.Equal(t, records[1].Action, "update")
+	assert.DeepEqual(t, records[1].Changes, []string{"spec.source"})
+	recorder.Validate()
+}
+
+func readAuditRecords(t *testing.T, file string) []auditRecord {
+	data, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+
+	var records []auditRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record auditRecord
+		assert.NilError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,405 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands"
+	hprinters "knative.dev/client/pkg/printers"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knflags "knative.dev/client/pkg/kn/flags"
+)
+
+var bindExample = `
+  # Bind Kamelet source to Knative broker
+  kn-source-kamelet bind timer-source --broker default --source-property message=Hello
+
+  # Bind Kamelet source to Knative service using a custom binding name
+  kn-source-kamelet bind timer-source --name timer-binding --service event-display
+
+  # Bind Kamelet source to Knative broker in another namespace
+  kn-source-kamelet bind timer-source --broker default --sink-namespace events
+
+  # Bind Kamelet source to Knative broker, the secret key is stored in a Secret named after the binding
+  kn-source-kamelet bind aws-sqs-source --broker default --source-property queueNameOrArn=events --source-secret-property secretKey=s3cr3t
+
+  # Bind Kamelet source to Knative broker, reading the token from an environment variable and the query from a file
+  kn-source-kamelet bind github-source --broker default --source-secret-property token=@env:GITHUB_TOKEN --source-property query=@file:./query.graphql
+
+  # Bind Kamelet source to Knative broker, failing on missing password properties instead of asking for them
+  kn-source-kamelet bind aws-sqs-source --broker default --source-property queueNameOrArn=events --no-prompt
+
+  # Bind Kamelet source to Knative broker consuming multiple topics, repeated values of an array property are aggregated
+  kn-source-kamelet bind kafka-source --broker default --source-property topics=orders --source-property topics=payments
+
+  # Bind Kamelet source to Knative broker with a structured property value
+  kn-source-kamelet bind kafka-source --broker default --source-property topic=events --source-property-json headers='{"team":"events","tags":["a","b"]}'
+
+  # Bind multiple Kamelet sources to the same Knative broker, creating one binding per source
+  kn-source-kamelet bind --kamelet timer-source --kamelet github-source --broker default
+
+  # Change the sink of an existing binding, without --force the changes are only printed
+  kn-source-kamelet bind timer-source --name timer-source-to-broker-default --channel events --force
+
+  # Fan out the source of an existing binding to another broker
+  kn-source-kamelet bind --from-binding timer-source-to-broker-default --broker events
+
+  # Bind Kamelet source to Knative broker, deserializing the JSON events and passing only matching events
+  kn-source-kamelet bind timer-source --broker default --step json-deserialize-action --step predicate-filter-action --step-property 'predicate-filter-action:expression=@.foo =~ /.*bar.*/'
+
+  # Bind Kamelet source to Kamelet sink
+  kn-source-kamelet bind timer-source --sink kamelet:log-sink --sink-property showHeaders=true
+
+  # Bind Kamelet source to an external HTTP endpoint
+  kn-source-kamelet bind timer-source --uri https://example.com/webhook
+
+  # Bind Kamelet source to Knative broker and wait up to 5 minutes for the binding to become ready
+  kn-source-kamelet bind timer-source --broker default --wait --wait-timeout 300
+
+  # Bind Kamelet source to Knative broker, wait for the binding to become ready and print the broker URL
+  kn-source-kamelet bind timer-source --broker default --wait -o url
+
+  # Bind Kamelet source to Knative broker and print the generated binding name
+  kn-source-kamelet bind timer-source --broker default -o jsonpath='{.metadata.name}'
+
+  # Create a new binding with a generated name such as timer-source-to-broker-default-x7k2p on each invocation
+  kn-source-kamelet bind timer-source --broker default --generate-name
+
+  # Validate the KameletBinding on the API server without persisting it
+  kn-source-kamelet bind timer-source --broker default --dry-run server
+
+  # Walk through choosing the Kamelet source, its properties and the sink
+  kn-source-kamelet bind --interactive
+
+  # Render the KameletBinding manifest without accessing the cluster, verifying the properties against the bundled catalog
+  kn-source-kamelet bind timer-source --broker default --offline -n events --source-property message=hello`
+
+// NewBindCommand implements 'kn-source-kamelet bind' command
+func NewBindCommand(p *KameletPluginParams) *cobra.Command {
+	var flags bindingFlags
+	var name string
+	var generateName bool
+	var offline bool
+	var dryRun string
+	var noPrompt bool
+	var verify verifyOptions
+	update := updateOptions{requireForce: true}
+	var interactive bool
+	var fromBinding string
+	var kamelets []string
+	var steps stepFlags
+	var waitFlags commands.WaitFlags
+	printFlags := genericclioptions.NewPrintFlags("")
+
+	cmd := &cobra.Command{
+		Use:               "bind",
+		Short:             "Bind Kamelet source to Knative broker, channel or service",
+		Aliases:           []string{"b"},
+		Example:           bindExample,
+		ValidArgsFunction: p.completeKameletArg("source"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := knflags.ReconcileBoolFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			if len(args) > 1 || (len(args) == 0 && !interactive && fromBinding == "" && len(kamelets) == 0) {
+				return errors.New("'kn-source-kamelet bind' requires the Kamelet source given as single argument")
+			}
+			if len(kamelets) > 0 {
+				if err := verifyBatchSources(kamelets, len(args) == 1, name, interactive, fromBinding); err != nil {
+					return err
+				}
+			}
+			if fromBinding != "" {
+				switch {
+				case len(args) == 1:
+					return errors.New("--from-binding can not be combined with a Kamelet source argument")
+				case interactive:
+					return errors.New("--from-binding can not be combined with --interactive")
+				case offline:
+					return errors.New("--from-binding reads the source from the cluster, it can not be combined with --offline")
+				}
+			}
+			if interactive && offline {
+				return errors.New("--interactive can not be combined with --offline")
+			}
+			if interactive && flags.readsStdin() {
+				return errors.New("--interactive can not be combined with property values read from @stdin")
+			}
+			if generateName && update.Force {
+				return errors.New("--generate-name can not be combined with --force, bindings with a generated name are always created")
+			}
+			var kamelet string
+			if len(args) == 1 {
+				kamelet = args[0]
+			}
+
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+			if err := p.applyUpdateDefaults(cmd, &update); err != nil {
+				return err
+			}
+			if err := flags.expandPropertyValues(cmd.InOrStdin()); err != nil {
+				return err
+			}
+
+			var namespace string
+			if offline {
+				namespace, err = p.offlineNamespace(cmd)
+			} else {
+				namespace, err = p.GetNamespace(cmd)
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.applyDefaultSink(&flags); err != nil {
+				return err
+			}
+
+			var existing *v1alpha1.KameletBinding
+			if fromBinding != "" {
+				if existing, err = p.getBinding(namespace, fromBinding); err != nil {
+					return err
+				}
+				if kamelet, err = kameletSource(existing); err != nil {
+					return err
+				}
+			}
+			if interactive {
+				client, err := p.NewKameletClient()
+				if err != nil {
+					return err
+				}
+				if kamelet, err = runBindWizard(p.Context, client, namespace, newPrompter(cmd), &flags, kamelet, p.sinkTypes()); err != nil {
+					return err
+				}
+			}
+
+			if offline {
+				dryRun = dryRunClient
+			}
+			stepEndpoints, err := steps.endpoints(namespace)
+			if err != nil {
+				return err
+			}
+			if offline {
+				if err := p.verifyOfflineSteps(stepEndpoints, verify, p.messageOutput(cmd.ErrOrStderr())); err != nil {
+					return err
+				}
+			}
+			sources := kamelets
+			if len(sources) == 0 {
+				sources = []string{kamelet}
+			}
+			bindings := make([]*v1alpha1.KameletBinding, 0, len(sources))
+			secrets := make([]*corev1.Secret, 0, len(sources))
+			for _, source := range sources {
+				// passwords prompted for one source must not end up in the Secret of another binding
+				sourceFlags := flags
+				sourceFlags.SourceSecretProperties = append([]string(nil), flags.SourceSecretProperties...)
+				sourceFlags.SinkSecretProperties = append([]string(nil), flags.SinkSecretProperties...)
+
+				options, err := sourceFlags.toOptions(name, namespace, source, p.sinkTypes())
+				if err != nil {
+					return err
+				}
+				binding, err := kameletapi.NewBinding(options)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					if err := copySource(binding, existing); err != nil {
+						return err
+					}
+				}
+				if generateName {
+					// the API server appends a random suffix to the name prefix
+					binding.GenerateName = binding.Name + "-"
+					binding.Name = ""
+				}
+				if offline {
+					if err := p.verifyOfflineKamelets(binding, verify, p.messageOutput(cmd.ErrOrStderr())); err != nil {
+						return err
+					}
+				}
+				if err := p.promptPasswords(cmd, binding, &sourceFlags, dryRun, noPrompt); err != nil {
+					return err
+				}
+				secret, err := preparePropertySecret(&sourceFlags, binding, dryRun)
+				if err != nil {
+					return err
+				}
+				bindings = append(bindings, binding)
+				secrets = append(secrets, secret)
+			}
+			if err := submitBindings(p, bindings, secrets, stepEndpoints, dryRun, verify, update, printFlags, &waitFlags, 1, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if len(kamelets) > 0 && dryRun != dryRunClient && !printFlags.OutputFlagSpecified() {
+				return printBindSummary(bindings, p.messageOutput(cmd.OutOrStdout()))
+			}
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringVar(&name, "name", "", "Name of the binding, defaults to <source>-to-<kind>-<name>.")
+	cmd.Flags().BoolVar(&generateName, "generate-name", false, "Use the binding name as prefix of a name generated by the API server, so each invocation creates a new binding.")
+	cmd.Flags().StringArrayVar(&kamelets, "kamelet", nil, "Name of a Kamelet source to bind instead of the source argument, repeat the flag to create one binding per source against the same sink. The source properties are set on all bindings and all sources are verified before any binding or Secret is created.")
+	_ = cmd.RegisterFlagCompletionFunc("kamelet", p.completeKameletFlag("source"))
+	cmd.Flags().StringVar(&fromBinding, "from-binding", "", "Copy the Kamelet source and its properties from the existing binding of given name, so only the sink is required. Source properties given by flag override the copied properties.")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Choose the Kamelet source, its required properties and the sink interactively.")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Render the binding manifest without accessing the cluster (no sink validation or namespace resolution, Kamelet properties are verified against the local cache or the bundled Kamelet catalog).")
+	flags.addFlags(cmd.Flags())
+	steps.addFlags(cmd.Flags())
+	_ = cmd.RegisterFlagCompletionFunc("step", p.completeKameletFlag("action"))
+	p.registerSinkCompletion(cmd)
+	p.registerPropertyCompletion(cmd, func(cmd *cobra.Command, args []string) string {
+		if len(args) == 0 {
+			return ""
+		}
+		return args[0]
+	})
+	addWaitFlags(cmd, &waitFlags)
+	addDryRunFlag(cmd.Flags(), &dryRun)
+	verify.addFlags(cmd.Flags())
+	update.addFlags(cmd.Flags())
+	addNoPromptFlag(cmd, &noPrompt)
+	printFlags.AddFlags(cmd)
+	addSinkURLOutput(cmd, printFlags)
+	return cmd
+}
+
+// verifyBatchSources checks that the Kamelet sources given by --kamelet are unique and not combined with flags
+// that only apply to a single binding
+func verifyBatchSources(kamelets []string, hasArgument bool, name string, interactive bool, fromBinding string) error {
+	switch {
+	case hasArgument:
+		return errors.New("--kamelet can not be combined with a Kamelet source argument")
+	case name != "":
+		return errors.New("--kamelet can not be combined with --name, each binding is named after its source")
+	case interactive:
+		return errors.New("--kamelet can not be combined with --interactive")
+	case fromBinding != "":
+		return errors.New("--kamelet can not be combined with --from-binding")
+	}
+	seen := map[string]bool{}
+	for _, kamelet := range kamelets {
+		if seen[kamelet] {
+			return fmt.Errorf("Kamelet source '%s' is given more than once", kamelet)
+		}
+		seen[kamelet] = true
+	}
+	return nil
+}
+
+// printBindSummary prints a table of the bindings created or updated by a single bind invocation
+func printBindSummary(bindings []*v1alpha1.KameletBinding, out io.Writer) error {
+	bindingList := &v1alpha1.KameletBindingList{}
+	for _, binding := range bindings {
+		bindingList.Items = append(bindingList.Items, *binding)
+	}
+	printer, err := commands.NewHumanPrintFlags().ToPrinter(bindSummaryHandlers)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return printer.PrintObj(bindingList, out)
+}
+
+// bindSummaryHandlers handles printing the summary table of `kn-source-kamelet bind --kamelet`
+func bindSummaryHandlers(h hprinters.PrintHandler) {
+	columns := []metav1beta1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Description: "Name of the KameletBinding", Priority: 1},
+		{Name: "Source", Type: "string", Description: "Source of the KameletBinding", Priority: 1},
+		{Name: "Sink", Type: "string", Description: "Sink of the KameletBinding", Priority: 1},
+	}
+	h.TableHandler(columns, func(bindingList *v1alpha1.KameletBindingList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		rows := make([]metav1beta1.TableRow, 0, len(bindingList.Items))
+		for i := range bindingList.Items {
+			binding := &bindingList.Items[i]
+			rows = append(rows, metav1beta1.TableRow{
+				Object: runtime.RawExtension{Object: binding},
+				Cells:  []interface{}{binding.Name, endpointValue(binding.Spec.Source), endpointValue(binding.Spec.Sink)},
+			})
+		}
+		return rows, nil
+	})
+}
+
+// kameletSource returns the name of the Kamelet referenced as source of the binding
+func kameletSource(binding *v1alpha1.KameletBinding) (string, error) {
+	ref := binding.Spec.Source.Ref
+	if ref == nil || ref.Kind != v1alpha1.KameletKind {
+		return "", fmt.Errorf("source of binding '%s' is not a Kamelet", binding.Name)
+	}
+	return ref.Name, nil
+}
+
+// copySource sets the source endpoint of the existing binding on the binding, the source properties of the binding
+// override the copied properties
+func copySource(binding *v1alpha1.KameletBinding, existing *v1alpha1.KameletBinding) error {
+	source := existing.Spec.Source.DeepCopy()
+	properties, err := kameletapi.RawEndpointProperties(source.Properties)
+	if err != nil {
+		return err
+	}
+	given, err := kameletapi.RawEndpointProperties(binding.Spec.Source.Properties)
+	if err != nil {
+		return err
+	}
+	for name, value := range given {
+		properties[name] = value
+	}
+	source.Properties = nil
+	if len(properties) > 0 {
+		data, err := json.Marshal(properties)
+		if err != nil {
+			return err
+		}
+		source.Properties = &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}
+	}
+	binding.Spec.Source = *source
+	return nil
+}
+
+// offlineNamespace returns the namespace given by flag or config file without resolving the current namespace
+// from the cluster config
+func (params *KameletPluginParams) offlineNamespace(cmd *cobra.Command) (string, error) {
+	if namespace := cmd.Flag("namespace").Value.String(); namespace != "" {
+		return namespace, nil
+	}
+	config, err := params.pluginConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.Namespace != "" {
+		return config.Namespace, nil
+	}
+	return "default", nil
+}
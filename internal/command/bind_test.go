@@ -0,0 +1,721 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindSetup(t *testing.T) {
+	p := KameletPluginParams{
+		Context: context.TODO(),
+	}
+
+	bindCmd := NewBindCommand(&p)
+	assert.Equal(t, bindCmd.Use, "bind")
+	assert.Equal(t, bindCmd.Short, "Bind Kamelet source to Knative broker, channel or service")
+	assert.Assert(t, bindCmd.RunE != nil)
+}
+
+func TestBindErrorCaseMissingArgument(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "--broker", "default")
+	assert.Error(t, err, "'kn-source-kamelet bind' requires the Kamelet source given as single argument")
+	recorder.Validate()
+}
+
+func TestBindErrorCaseMissingSink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1")
+	assert.Error(t, err, "missing binding sink, use one of --broker, --channel, --service, --uri or --sink")
+	recorder.Validate()
+}
+
+func TestBindErrorCaseMultipleSinks(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--service", "display")
+	assert.Error(t, err, "only one binding sink is allowed, use one of --broker, --channel, --service, --uri or --sink")
+	recorder.Validate()
+}
+
+func TestBindErrorCaseNotFound(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), errors.New("not found"))
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default")
+	assert.Error(t, err, "not found")
+	recorder.Validate()
+}
+
+func TestBindErrorCaseNoEventSource(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Labels["camel.apache.org/kamelet.type"] = "sink"
+	recorder.Get(kamelet, nil)
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default")
+	assert.Error(t, err, "Kamelet k1 is not an event source")
+	recorder.Validate()
+}
+
+func TestBindErrorCaseMissingRequiredProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default")
+	assert.Error(t, err, "binding is missing required property \"message\" for Kamelet \"k1\"")
+	recorder.Validate()
+}
+
+func TestBindUnknownPropertyWarning(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{"period": {Type: "integer"}}
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "priod=5000")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Warning", "\"priod\"", "not defined by Kamelet \"k1\"", "created"))
+	recorder.Validate()
+}
+
+func TestBindErrorCaseUnknownPropertyStrict(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{"period": {Type: "integer"}}
+	recorder.Get(kamelet, nil)
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "priod=5000", "--source-property", "mesage=Hello", "--strict")
+	assert.Error(t, err, "unknown properties for Kamelet \"k1\": mesage, priod")
+	recorder.Validate()
+}
+
+func TestBindApplyDefaults(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"period"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"period":   {Type: "integer", Default: &camelkapis.JSON{RawMessage: []byte("1000")}},
+		"message":  {Type: "string", Default: &camelkapis.JSON{RawMessage: []byte(`"Hello"`)}},
+		"password": {Type: "string", Format: "password"},
+	}
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hi","password":"secret","period":1000}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "message=Hi", "--source-property", "password=secret", "--apply-defaults")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Effective properties of Kamelet 'k1'", "message=Hi\n", "password=********", "period=1000 (default)", "created"))
+	assert.Check(t, util.ContainsNone(output, "secret"))
+	recorder.Validate()
+}
+
+func TestBindKameletSink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	sink := createKamelet("log-sink")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	sink.Spec.Definition.Required = []string{"loggerName"}
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(sink, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-kamelet-log-sink"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Spec.Sink.Ref.APIVersion, "camel.apache.org/v1alpha1")
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Kamelet")
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "log-sink")
+		assert.Equal(t, binding.Spec.Sink.Ref.Namespace, "current")
+		assert.Equal(t, string(binding.Spec.Sink.Properties.RawMessage), `{"loggerName":"events"}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--sink", "kamelet:log-sink", "--sink-property", "loggerName=events")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "k1-to-kamelet-log-sink", "created"))
+	recorder.Validate()
+}
+
+func TestBindErrorCaseKameletSink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	sink := createKamelet("log-sink")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	sink.Spec.Definition.Required = []string{"loggerName"}
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(sink, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k2"), nil)
+
+	_, err := runBindCmd(mockClient, "k1", "--sink", "kamelet:log-sink")
+	assert.Error(t, err, "binding is missing required property \"loggerName\" for Kamelet \"log-sink\"")
+
+	_, err = runBindCmd(mockClient, "k1", "--sink", "kamelet:k2")
+	assert.Error(t, err, "Kamelet k2 is not an event sink")
+	recorder.Validate()
+}
+
+func TestBindCreate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "k1-to-broker-default")
+		assert.Equal(t, binding.Namespace, "current")
+		assert.Equal(t, binding.Spec.Source.Ref.Name, "k1")
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello"}`)
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Broker")
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "default")
+		assert.Equal(t, binding.Spec.Sink.Ref.Namespace, "current")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "message=Hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "k1-to-broker-default", "created", "current"))
+	recorder.Validate()
+}
+
+func TestBindMultipleSources(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	// all sources are verified before any binding is created
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k2"), nil)
+	for _, name := range []string{"k1", "k2"} {
+		source := name
+		recorder.GetBinding(&camelkapis.KameletBinding{}, notFound(source+"-to-broker-default"))
+		recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+			assert.Equal(t, binding.Name, source+"-to-broker-default")
+			assert.Equal(t, binding.Spec.Source.Ref.Name, source)
+			assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello"}`)
+			assert.Equal(t, binding.Spec.Sink.Ref.Name, "default")
+		}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	}
+
+	output, err := runBindCmd(mockClient, "--kamelet", "k1", "--kamelet", "k2", "--broker", "default", "--source-property", "message=Hello")
+	assert.NilError(t, err)
+	assert.Equal(t, output, `KameletBinding 'k1-to-broker-default' created in namespace 'current'.
+KameletBinding 'k2-to-broker-default' created in namespace 'current'.
+
+NAME                   SOURCE       SINK
+k1-to-broker-default   kamelet:k1   broker:default
+k2-to-broker-default   kamelet:k2   broker:default
+`)
+	recorder.Validate()
+}
+
+func TestBindMultipleSourcesOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "--kamelet", "k1", "--kamelet", "k2", "--sink", "channel:events", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: k1-to-channel-events", "name: k2-to-channel-events", "---"))
+	assert.Check(t, util.ContainsNone(output, "NAME"))
+	recorder.Validate()
+}
+
+func TestBindMultipleSourcesErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1", "--kamelet", "k2", "--broker", "default")
+	assert.Error(t, err, "--kamelet can not be combined with a Kamelet source argument")
+
+	_, err = runBindCmd(mockClient, "--kamelet", "k1", "--kamelet", "k2", "--name", "b1", "--broker", "default")
+	assert.Error(t, err, "--kamelet can not be combined with --name, each binding is named after its source")
+
+	_, err = runBindCmd(mockClient, "--kamelet", "k1", "--from-binding", "b1", "--broker", "default")
+	assert.Error(t, err, "--kamelet can not be combined with --from-binding")
+
+	_, err = runBindCmd(mockClient, "--kamelet", "k1", "--kamelet", "k1", "--broker", "default")
+	assert.Error(t, err, "Kamelet source 'k1' is given more than once")
+
+	// a missing source fails the batch before any binding or Secret is created
+	kubeClient := fake.NewSimpleClientset()
+	p := secretPropertyParams(mockClient, kubeClient)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(nil, notFound("k2"))
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "--kamelet", "k1", "--kamelet", "k2", "--broker", "default", "--source-secret-property", "token=s3cr3t")
+	assert.ErrorContains(t, err, "k2")
+	secrets, err := kubeClient.CoreV1().Secrets("current").List(context.TODO(), v1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(secrets.Items), 0)
+	recorder.Validate()
+}
+
+func TestBindUpdate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(createKameletBinding("my-binding", "k1"), nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "my-binding")
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Service")
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "display")
+	}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, *opts.Force, true)
+	}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--name", "my-binding", "--service", "display", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "my-binding", "updated"))
+	recorder.Validate()
+}
+
+func TestBindWait(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "k1-to-broker-default")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	recorder.GetBinding(creatingBinding("k1-to-broker-default"), nil)
+	recorder.GetBinding(createKameletBinding("k1-to-broker-default", "k1"), nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--wait")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "created", "Waiting", "is ready"))
+	recorder.Validate()
+}
+
+func TestBindGenerateName(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "")
+		assert.Equal(t, binding.GenerateName, "k1-to-broker-default-")
+		// the API server generates the name
+		binding.Name = "k1-to-broker-default-x7k2p"
+	}, nil)
+	recorder.GetBinding(createKameletBinding("k1-to-broker-default-x7k2p", "k1"), nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--generate-name", "--wait")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'k1-to-broker-default-x7k2p' created", "KameletBinding 'k1-to-broker-default-x7k2p' in namespace 'current' is ready"))
+
+	output, err = runBindCmd(mockClient, "k1", "--broker", "default", "--name", "load", "--generate-name", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "generateName: load-"))
+	assert.Check(t, util.ContainsNone(output, "name: load\n"))
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--generate-name", "--force")
+	assert.Error(t, err, "--generate-name can not be combined with --force, bindings with a generated name are always created")
+	recorder.Validate()
+}
+
+func TestBindWaitFlagsConflict(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--wait", "--no-wait")
+	assert.Error(t, err, "only one of --no-wait and --wait may be specified")
+	recorder.Validate()
+}
+
+func TestBindDryRunClient(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding", "name: k1-to-broker-default", "namespace: current"))
+	assert.Check(t, util.ContainsNone(output, "created"))
+	recorder.Validate()
+}
+
+func TestBindDryRunServer(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "k1-to-broker-default")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--dry-run", "server", "--wait")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "created", "(server dry run)"))
+	assert.Check(t, util.ContainsNone(output, "Waiting"))
+	recorder.Validate()
+}
+
+func TestBindDryRunServerOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--dry-run", "server", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding", "name: k1-to-broker-default"))
+	assert.Check(t, util.ContainsNone(output, "created"))
+	recorder.Validate()
+}
+
+func TestBindOutputJSONPath(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "-o", "jsonpath={.metadata.name}")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "k1-to-broker-default")
+	recorder.Validate()
+}
+
+func TestBindQuiet(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := &KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return mockClient, nil
+		},
+		Quiet: true,
+	}
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runBindCmdWithParams(p, "", "k1", "--broker", "default", "--source-property", "unknown=1")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "")
+
+	// requested output is still printed
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err = runBindCmdWithParams(p, "", "k1", "--broker", "default", "-o", "name")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kameletbinding.camel.apache.org/k1-to-broker-default\n")
+	recorder.Validate()
+}
+
+func TestBindDryRunInvalid(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--dry-run", "all")
+	assert.Error(t, err, "unsupported dry-run mode \"all\", expected one of: client|server")
+	recorder.Validate()
+}
+
+func TestBindOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--sink", "channel:events", "--source-property", "message=Hello", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding", "name: k1-to-channel-events", "namespace: test", "kind: Channel", "message: Hello"))
+	assert.Check(t, util.ContainsNone(output, "status:", "creationTimestamp:", "created"))
+	recorder.Validate()
+}
+
+func TestBindOfflineSinkNamespace(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--sink-namespace", "events", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: k1-to-broker-default", "namespace: test", "kind: Broker", "namespace: events"))
+
+	output, err = runBindCmd(mockClient, "k1", "--sink", "broker:events/default", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: k1-to-broker-default", "namespace: events"))
+
+	_, err = runBindCmd(mockClient, "k1", "--uri", "https://example.com/webhook", "--sink-namespace", "events", "--offline")
+	assert.Error(t, err, "sink namespace \"events\" is not supported for URI sinks")
+	recorder.Validate()
+}
+
+func TestBindJSONProperties(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "-n", "test",
+		"--source-property", "message=Hello", "--source-property-json", `filters={"a":1, "b":[2,3]}`)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "filters:\n        a: 1\n        b:\n        - 2\n        - 3\n", "message: Hello"))
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"filters": {Type: "string"},
+	}
+	recorder.Get(kamelet, nil)
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--source-property-json", `filters={"a":1}`)
+	assert.Error(t, err, "invalid object value for property \"filters\" of Kamelet \"k1\": expected type string")
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--source-property-json", `filters={"a":1`)
+	assert.Error(t, err, "invalid JSON value of source property \"filters\": unexpected end of JSON input")
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--sink-property-json", `filters`)
+	assert.Error(t, err, "invalid sink JSON property \"filters\", expected <key>=<json>")
+	recorder.Validate()
+}
+
+func TestBindArrayProperties(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"topics":     {Type: "array", Items: &camelkapis.JSONSchemaProps{Type: "string"}},
+		"partitions": {Type: "array", Items: &camelkapis.JSONSchemaProps{Type: "integer"}},
+		"message":    {Type: "string"},
+	}
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello","partitions":[1,2],"topics":["a","b"]}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "message=Hello",
+		"--source-property", "topics=a", "--source-property", "topics=b", "--source-property", "partitions=1", "--source-property", "partitions=2")
+	assert.NilError(t, err)
+
+	recorder.Get(kamelet, nil)
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--source-property", "message=Hello", "--source-property", "message=Bye")
+	assert.Error(t, err, "invalid array value for property \"message\" of Kamelet \"k1\": expected type string")
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "-n", "test",
+		"--source-property", "topics=a", "--source-property", "topics=b", "--source-property", "message=Hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "topics:\n      - a\n      - b\n", "message: Hello\n"))
+	recorder.Validate()
+}
+
+func TestBindFromBinding(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	existing := createKameletBinding("b1", "k1")
+	existing.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello","period":1000}`)}
+	recorder.GetBinding(existing, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-events"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "k1-to-broker-events")
+		assert.Equal(t, binding.Spec.Source.Ref.Name, "k1")
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Bye","period":1000}`)
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "events")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runBindCmd(mockClient, "--from-binding", "b1", "--broker", "events", "--source-property", "message=Bye")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'k1-to-broker-events' created"))
+
+	uriSource := createKameletBinding("b2", "k1")
+	uriSource.Spec.Source = camelkapis.Endpoint{URI: &[]string{"timer:tick"}[0]}
+	recorder.GetBinding(uriSource, nil)
+	_, err = runBindCmd(mockClient, "--from-binding", "b2", "--broker", "events")
+	assert.Error(t, err, "source of binding 'b2' is not a Kamelet")
+
+	_, err = runBindCmd(mockClient, "k1", "--from-binding", "b1", "--broker", "events")
+	assert.Error(t, err, "--from-binding can not be combined with a Kamelet source argument")
+
+	_, err = runBindCmd(mockClient, "--from-binding", "b1", "--broker", "events", "--offline")
+	assert.Error(t, err, "--from-binding reads the source from the cluster, it can not be combined with --offline")
+	recorder.Validate()
+}
+
+func TestBindOfflineURISink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--uri", "https://example.com/webhook", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: k1-to-https", "uri: https://example.com/webhook"))
+	assert.Check(t, util.ContainsNone(output, "kind: Broker"))
+	recorder.Validate()
+}
+
+func TestBindOfflineLabelsAndAnnotations(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "-l", "team=events", "--label", "app=timer", "--annotation", "cost-center=4711")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "labels:", "team: events", "app: timer", "annotations:", "cost-center: \"4711\""))
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--label", "team=events!")
+	assert.ErrorContains(t, err, "metadata.labels: Invalid value: \"events!\"")
+	recorder.Validate()
+}
+
+func TestBindUpdateRequiresForce(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	// the changes are printed and the existing binding is left untouched
+	existing := createKameletBindingInNamespace("my-binding", "k1", "current")
+	existing.Labels = map[string]string{"app": "timer"}
+	for _, serverSide := range []string{"--server-side=true", "--server-side=false"} {
+		recorder.Get(createKamelet("k1"), nil)
+		recorder.GetBinding(existing, nil)
+		output, err := runBindCmd(mockClient, "k1", "--name", "my-binding", "--broker", "default", "--label", "team=events", serverSide)
+		assert.ErrorContains(t, err, "KameletBinding 'my-binding' already exists in namespace 'current', use --force to update it")
+		assert.Equal(t, ExitCode(err), ExitCodeConflict)
+		assert.Check(t, util.ContainsAll(output, "--- my-binding (cluster)\n", "+    team: events\n"))
+		assert.Check(t, util.ContainsNone(output, "-    app: timer"))
+	}
+
+	// binding again with unchanged settings is no update
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, *opts.Force, false)
+	}, nil)
+	_, err := runBindCmd(mockClient, "k1", "--name", "my-binding", "--broker", "default")
+	assert.NilError(t, err)
+	recorder.Validate()
+}
+
+func TestBindOfflineReplicas(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--replicas", "0")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "integration:", "replicas: 0"))
+
+	output, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsNone(output, "replicas:"))
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--replicas", "-1")
+	assert.ErrorContains(t, err, "invalid argument \"-1\" for \"--replicas\" flag: must not be negative")
+	recorder.Validate()
+}
+
+func TestBindOfflinePropertiesFiles(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	sourceFile := writePropertiesFile(t, "message=Hello\nperiod=1000\n")
+	sinkFile := writePropertiesFile(t, "type=org.apache.camel.event\n")
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline",
+		"--source-properties-file", sourceFile, "--source-property", "message=Bye", "--sink-properties-file", sinkFile)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "message: Bye", "period: \"1000\"", "type: org.apache.camel.event"))
+	recorder.Validate()
+}
+
+func TestBindOfflineSecretProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--source-property-secret", "password=credentials/password")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "password: '{{secret:credentials/password}}'"))
+	recorder.Validate()
+}
+
+func TestBindOfflineDefaultNamespace(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "-o", "json")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "\"kind\": \"KameletBinding\"", "\"namespace\": \"default\""))
+	recorder.Validate()
+}
+
+func TestBindOfflineRuntimeLogLevel(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--runtime-log-level", "debug", "--runtime-logger", "org.apache.camel=trace")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "logging:", "level: DEBUG", `quarkus.log.category."org.apache.camel".level=TRACE`))
+	recorder.Validate()
+}
+
+func runBindCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindCommand(&p), p.KnParams)
+
+	args := []string{"bind"}
+	args = append(args, options...)
+	bindCmd.SetArgs(args)
+	err := bindCmd.Execute()
+
+	return output.String(), err
+}
@@ -0,0 +1,1102 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// NewBindingCommand implements 'kn-source-kamelet binding' command
+func NewBindingCommand(p *KameletPluginParams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "binding",
+		Short:   "Manage KameletBindings of Kamelet sources",
+		Aliases: []string{"bindings"},
+	}
+	cmd.AddCommand(newBindingCreateCommand(p))
+	cmd.AddCommand(newBindingApplyCommand(p))
+	cmd.AddCommand(newBindingListCommand(p))
+	cmd.AddCommand(newBindingDescribeCommand(p))
+	cmd.AddCommand(newBindingDiffCommand(p))
+	cmd.AddCommand(newBindingValidateCommand(p))
+	cmd.AddCommand(newBindingLintCommand(p))
+	cmd.AddCommand(newBindingExportCommand(p))
+	cmd.AddCommand(newBindingUpdateCommand(p))
+	cmd.AddCommand(newBindingDeleteCommand(p))
+	cmd.AddCommand(newBindingMigrateCommand(p))
+	return cmd
+}
+
+// bindingFlags holds the flags configuring the source and the sink of a binding
+type bindingFlags struct {
+	Broker                   string
+	Channel                  string
+	Service                  string
+	Sink                     string
+	SinkNamespace            string
+	URI                      string
+	SourceProperties         []string
+	SourcePropertiesJSON     []string
+	SourcePropertiesFile     string
+	SourcePropertySecrets    []string
+	SourceSecretProperties   []string
+	SourcePropertyConfigMaps []string
+	SinkProperties           []string
+	SinkPropertiesJSON       []string
+	SinkPropertiesFile       string
+	SinkPropertySecrets      []string
+	SinkSecretProperties     []string
+	SinkPropertyConfigMaps   []string
+	CEOverrides              []string
+	Labels                   []string
+	Annotations              []string
+	Traits                   []string
+	Replicas                 optionalInt32
+	RuntimeLogLevel          string
+	RuntimeLoggers           []string
+}
+
+// addFlags adds the binding flags to given flag set
+func (f *bindingFlags) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Broker, "broker", "", "Uses a broker as binding sink.")
+	flags.StringVar(&f.Channel, "channel", "", "Uses a channel as binding sink.")
+	flags.StringVar(&f.Service, "service", "", "Uses a Knative service as binding sink.")
+	flags.StringVar(&f.URI, "uri", "", "Uses a Camel endpoint URI as binding sink, e.g. https://example.com/webhook or kafka:topic.")
+	flags.StringVar(&f.Sink, "sink", "", "Sink expression to define the binding sink in the form of <type>:[<namespace>/]<name>, e.g. broker:default, broker:events/default, kamelet:log-sink or kafkatopic:my-topic. Any other resource is given in the form of <apiVersion>:<kind>:[<namespace>/]<name>.")
+	flags.StringVar(&f.SinkNamespace, "sink-namespace", "", "Namespace of the binding sink, defaults to the namespace of the binding. Requires a cluster setup that allows sources to deliver events across namespaces.")
+	flags.StringArrayVar(&f.SourceProperties, "source-property", nil, "Add a source property in the form of \"<key>=<value>\", repeat the flag to give multiple values of an array property. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input.")
+	flags.StringArrayVar(&f.SourcePropertiesJSON, "source-property-json", nil, "Add a source property with a structured value in the form of \"<key>=<json>\", e.g. filters='{\"a\":1,\"b\":[2,3]}', for object or array properties of the Kamelet.")
+	flags.StringVar(&f.SourcePropertiesFile, "source-properties-file", "", "Read source properties from a .properties or .env file, values given with --source-property take precedence.")
+	flags.StringArrayVar(&f.SourcePropertySecrets, "source-property-secret", nil, "Resolve a source property from a Secret at runtime in the form of \"<key>=<secret>/<secret-key>\".")
+	flags.StringArrayVar(&f.SourceSecretProperties, "source-secret-property", nil, "Add a sensitive source property in the form of \"<key>=<value>\", the value is stored in a Secret named after the binding and resolved at runtime. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input.")
+	flags.StringArrayVar(&f.SourcePropertyConfigMaps, "source-property-configmap", nil, "Resolve a source property from a ConfigMap at runtime in the form of \"<key>=<configmap>/<configmap-key>\".")
+	flags.StringArrayVar(&f.SinkProperties, "sink-property", nil, "Add a sink property in the form of \"<key>=<value>\", repeat the flag to give multiple values of an array property. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input.")
+	flags.StringArrayVar(&f.SinkPropertiesJSON, "sink-property-json", nil, "Add a sink property with a structured value in the form of \"<key>=<json>\", for object or array properties of the Kamelet.")
+	flags.StringVar(&f.SinkPropertiesFile, "sink-properties-file", "", "Read sink properties from a .properties or .env file, values given with --sink-property take precedence.")
+	flags.StringArrayVar(&f.SinkPropertySecrets, "sink-property-secret", nil, "Resolve a sink property from a Secret at runtime in the form of \"<key>=<secret>/<secret-key>\".")
+	flags.StringArrayVar(&f.SinkSecretProperties, "sink-secret-property", nil, "Add a sensitive sink property in the form of \"<key>=<value>\", the value is stored in a Secret named after the binding and resolved at runtime. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input.")
+	flags.StringArrayVar(&f.SinkPropertyConfigMaps, "sink-property-configmap", nil, "Resolve a sink property from a ConfigMap at runtime in the form of \"<key>=<configmap>/<configmap-key>\".")
+	flags.StringArrayVar(&f.CEOverrides, "ce-override", nil, "Override a CloudEvents attribute of the events sent to the Knative sink in the form of \"<attribute>=<value>\", e.g. type=org.example.tick.")
+	flags.StringArrayVarP(&f.Labels, "label", "l", nil, "Add a label to the binding in the form of \"<key>=<value>\".")
+	flags.StringArrayVar(&f.Annotations, "annotation", nil, "Add an annotation to the binding in the form of \"<key>=<value>\".")
+	flags.StringArrayVar(&f.Traits, "trait", nil, "Configure a Camel K trait of the binding integration in the form of \"<trait>.<property>=<value>\", e.g. jvm.options=-Xmx256m.")
+	flags.Var(&f.Replicas, "replicas", "Number of replicas of the binding integration.")
+	flags.StringVar(&f.RuntimeLogLevel, "runtime-log-level", "", fmt.Sprintf("Root log level of the binding integration runtime. One of: %s.", strings.Join(kameletapi.RuntimeLogLevels, "|")))
+	flags.StringArrayVar(&f.RuntimeLoggers, "runtime-logger", nil, "Override the log level of a single runtime logger in the form of \"<logger>=<level>\", e.g. org.apache.camel=debug.")
+}
+
+// optionalInt32 is a flag value distinguishing an explicit zero from a flag not given
+type optionalInt32 struct {
+	value *int32
+}
+
+func (o *optionalInt32) Set(s string) error {
+	value, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	if value < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	v := int32(value)
+	o.value = &v
+	return nil
+}
+
+func (o *optionalInt32) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*o.value))
+}
+
+func (o *optionalInt32) Type() string {
+	return "int32"
+}
+
+// sinkExpression returns the sink given by exactly one of the sink flags in the form of <type>:<name>
+func (f *bindingFlags) sinkExpression() (string, error) {
+	var sinks []string
+	if f.Broker != "" {
+		sinks = append(sinks, "broker:"+f.Broker)
+	}
+	if f.Channel != "" {
+		sinks = append(sinks, "channel:"+f.Channel)
+	}
+	if f.Service != "" {
+		sinks = append(sinks, "service:"+f.Service)
+	}
+	if f.URI != "" {
+		sinks = append(sinks, kameletapi.URISinkType+":"+f.URI)
+	}
+	if f.Sink != "" {
+		sinks = append(sinks, f.Sink)
+	}
+
+	switch len(sinks) {
+	case 0:
+		return "", errors.New("missing binding sink, use one of --broker, --channel, --service, --uri or --sink")
+	case 1:
+		return sinks[0], nil
+	default:
+		return "", errors.New("only one binding sink is allowed, use one of --broker, --channel, --service, --uri or --sink")
+	}
+}
+
+// toOptions converts the flags to binding options for given Kamelet source, the sink expression is resolved with given
+// sink types
+func (f *bindingFlags) toOptions(name string, namespace string, kamelet string, sinkTypes map[string]v1.TypeMeta) (*kameletapi.BindingOptions, error) {
+	sink, err := f.sinkExpression()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.CEOverrides) > 0 {
+		sinkEndpoint, err := kameletapi.DecodeSink(sink, sinkTypes)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyCEOverrideSink(sinkEndpoint.Ref); err != nil {
+			return nil, err
+		}
+	}
+
+	sourceProperties, err := f.sourceProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	sinkProperties, err := f.sinkProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceJSONProperties, err := f.sourceJSONProperties()
+	if err != nil {
+		return nil, err
+	}
+	sinkJSONProperties, err := f.sinkJSONProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	loggers, err := f.runtimeLoggers()
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := f.labels()
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := f.annotations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kameletapi.BindingOptions{
+		Name:                 name,
+		Namespace:            namespace,
+		Kamelet:              kamelet,
+		Sink:                 sink,
+		SinkTypes:            sinkTypes,
+		SinkNamespace:        f.SinkNamespace,
+		SourceProperties:     sourceProperties,
+		SinkProperties:       sinkProperties,
+		SourceJSONProperties: sourceJSONProperties,
+		SinkJSONProperties:   sinkJSONProperties,
+		RuntimeLogLevel:      f.RuntimeLogLevel,
+		RuntimeLoggers:       loggers,
+		Labels:               labels,
+		Annotations:          annotations,
+		Replicas:             f.Replicas.value,
+	}, nil
+}
+
+// annotationValues returns the "<key>=<value>" pairs of the annotation flags followed by the trait annotations
+func (f *bindingFlags) annotationValues() ([]string, error) {
+	traits, err := traitAnnotations(f.Traits)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]string{}, f.Annotations...), traits...), nil
+}
+
+// traitAnnotationPrefix is the prefix of the annotations configuring the traits of the binding integration
+const traitAnnotationPrefix = "trait.camel.apache.org/"
+
+// traitAnnotations converts "<trait>.<property>=<value>" pairs to trait annotations, a "<trait>.<property>-" pair
+// removes the annotation
+func traitAnnotations(traits []string) ([]string, error) {
+	values := make([]string, 0, len(traits))
+	for _, trait := range traits {
+		key := trait
+		if index := strings.Index(trait, "="); index >= 0 {
+			key = trait[:index]
+		} else if strings.HasSuffix(trait, "-") {
+			key = strings.TrimSuffix(trait, "-")
+		} else {
+			return nil, fmt.Errorf("invalid trait %q, expected <trait>.<property>=<value>", trait)
+		}
+
+		names := strings.SplitN(key, ".", 2)
+		if len(names) != 2 || names[0] == "" || names[1] == "" {
+			return nil, fmt.Errorf("invalid trait %q, expected <trait>.<property>=<value>", trait)
+		}
+		values = append(values, traitAnnotationPrefix+trait)
+	}
+	return values, nil
+}
+
+// labels returns the labels given as "<key>=<value>" pairs, verifying keys and values are valid label syntax
+func (f *bindingFlags) labels() (map[string]string, error) {
+	labels, err := util.MapFromArray(f.Labels, "=")
+	if err != nil {
+		return nil, err
+	}
+	if errs := metav1validation.ValidateLabels(labels, field.NewPath("metadata", "labels")); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return labels, nil
+}
+
+// annotations returns the annotations given as "<key>=<value>" pairs including the trait annotations
+func (f *bindingFlags) annotations() (map[string]string, error) {
+	values, err := f.annotationValues()
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := util.MapFromArray(values, "=")
+	if err != nil {
+		return nil, err
+	}
+	if errs := apivalidation.ValidateAnnotations(annotations, field.NewPath("metadata", "annotations")); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return annotations, nil
+}
+
+// sourceProperties returns the source properties read from the properties file overridden by the property flags
+// and the property references, properties given multiple times are returned as arrays by sourceJSONProperties
+func (f *bindingFlags) sourceProperties() (map[string]string, error) {
+	values, err := f.sourcePropertyValues()
+	if err != nil {
+		return nil, err
+	}
+	return mergedProperties(f.SourcePropertiesFile, withoutKeys(values, repeatedKeys(f.SourceProperties)))
+}
+
+// sinkProperties returns the sink properties read from the properties file overridden by the property flags
+// and the property references, properties given multiple times are returned as arrays by sinkJSONProperties
+func (f *bindingFlags) sinkProperties() (map[string]string, error) {
+	values, err := f.sinkPropertyValues()
+	if err != nil {
+		return nil, err
+	}
+	return mergedProperties(f.SinkPropertiesFile, withoutKeys(values, repeatedKeys(f.SinkProperties)))
+}
+
+// sourcePropertyValues returns the "<key>=<value>" pairs of the source property flags followed by the property references
+func (f *bindingFlags) sourcePropertyValues() ([]string, error) {
+	return propertyValues(f.SourceProperties, f.SourcePropertySecrets, f.SourcePropertyConfigMaps)
+}
+
+// sinkPropertyValues returns the "<key>=<value>" pairs of the sink property flags followed by the property references
+// and the CloudEvents overrides
+func (f *bindingFlags) sinkPropertyValues() ([]string, error) {
+	values, err := propertyValues(f.SinkProperties, f.SinkPropertySecrets, f.SinkPropertyConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := ceOverrideValues(f.CEOverrides)
+	if err != nil {
+		return nil, err
+	}
+	return append(values, overrides...), nil
+}
+
+// ceOverridePrefix is the prefix of the Knative endpoint properties overriding CloudEvents attributes
+const ceOverridePrefix = "ce.override.ce-"
+
+// ceOverrideValues converts "<attribute>=<value>" pairs to the sink properties overriding the CloudEvents attributes,
+// the attribute may be given with or without the "ce-" prefix. A "<attribute>-" pair removes the override.
+func ceOverrideValues(overrides []string) ([]string, error) {
+	values := make([]string, 0, len(overrides))
+	for _, override := range overrides {
+		parts := strings.SplitN(override, "=", 2)
+		attribute := strings.TrimPrefix(parts[0], "ce-")
+		if len(parts) == 1 && strings.HasSuffix(attribute, "-") && len(attribute) > 1 {
+			values = append(values, ceOverridePrefix+attribute)
+			continue
+		}
+		if len(parts) != 2 || attribute == "" {
+			return nil, fmt.Errorf("invalid CloudEvents override %q, expected <attribute>=<value>", override)
+		}
+		values = append(values, fmt.Sprintf("%s%s=%s", ceOverridePrefix, attribute, parts[1]))
+	}
+	return values, nil
+}
+
+// verifyCEOverrideSink checks that the sink is a Knative resource receiving CloudEvents
+func verifyCEOverrideSink(sink *corev1.ObjectReference) error {
+	if sink != nil {
+		switch sink.Kind {
+		case kameletapi.SinkTypes["broker"].Kind, kameletapi.SinkTypes["channel"].Kind, kameletapi.SinkTypes["service"].Kind:
+			return nil
+		}
+	}
+	return errors.New("--ce-override requires a Knative broker, channel or service as binding sink")
+}
+
+// propertyValues appends the Secret and ConfigMap references to the "<key>=<value>" pairs
+func propertyValues(values []string, secrets []string, configMaps []string) ([]string, error) {
+	secretValues, err := propertyReferences("secret", secrets)
+	if err != nil {
+		return nil, err
+	}
+	configMapValues, err := propertyReferences("configmap", configMaps)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]string{}, values...)
+	result = append(result, secretValues...)
+	return append(result, configMapValues...), nil
+}
+
+// propertyReferences converts "<key>=<name>/<name-key>" pairs to properties resolved by the integration runtime
+// from the resource of given kind using the Camel K placeholder syntax {{<kind>:<name>/<name-key>}}
+func propertyReferences(kind string, references []string) ([]string, error) {
+	values := make([]string, 0, len(references))
+	for _, reference := range references {
+		parts := strings.SplitN(reference, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid %s reference %q, expected <key>=<%s>/<%s-key>", kind, reference, kind, kind)
+		}
+		ref := strings.SplitN(parts[1], "/", 2)
+		if len(ref) != 2 || ref[0] == "" || ref[1] == "" {
+			return nil, fmt.Errorf("invalid %s reference %q, expected <key>=<%s>/<%s-key>", kind, reference, kind, kind)
+		}
+		values = append(values, fmt.Sprintf("%s={{%s:%s/%s}}", parts[0], kind, ref[0], ref[1]))
+	}
+	return values, nil
+}
+
+// sourceJSONProperties returns the source properties given multiple times aggregated into arrays overridden by the
+// structured property flags
+func (f *bindingFlags) sourceJSONProperties() (map[string]json.RawMessage, error) {
+	return structuredProperties("source", f.SourceProperties, f.SourcePropertiesJSON)
+}
+
+// sinkJSONProperties returns the sink properties given multiple times aggregated into arrays overridden by the
+// structured property flags
+func (f *bindingFlags) sinkJSONProperties() (map[string]json.RawMessage, error) {
+	return structuredProperties("sink", f.SinkProperties, f.SinkPropertiesJSON)
+}
+
+// structuredProperties aggregates the values of the "<key>=<value>" pairs given multiple times for the same key into
+// JSON arrays, e.g. topics=a and topics=b into ["a","b"], and adds the "<key>=<json>" pairs
+func structuredProperties(endpoint string, values []string, jsonValues []string) (map[string]json.RawMessage, error) {
+	properties, err := jsonProperties(endpoint, jsonValues)
+	if err != nil {
+		return nil, err
+	}
+
+	repeated := repeatedKeys(values)
+	items := map[string][]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) == 2 && repeated[parts[0]] {
+			items[parts[0]] = append(items[parts[0]], parts[1])
+		}
+	}
+	for key := range repeated {
+		if _, ok := properties[key]; ok {
+			continue
+		}
+		data, err := json.Marshal(items[key])
+		if err != nil {
+			return nil, err
+		}
+		if properties == nil {
+			properties = map[string]json.RawMessage{}
+		}
+		properties[key] = data
+	}
+	return properties, nil
+}
+
+// repeatedKeys returns the keys given multiple times by the "<key>=<value>" pairs
+func repeatedKeys(values []string) map[string]bool {
+	counts := map[string]int{}
+	for _, value := range values {
+		if parts := strings.SplitN(value, "=", 2); len(parts) == 2 {
+			counts[parts[0]]++
+		}
+	}
+	repeated := map[string]bool{}
+	for key, count := range counts {
+		if count > 1 {
+			repeated[key] = true
+		}
+	}
+	return repeated
+}
+
+// withoutKeys removes the "<key>=<value>" pairs of given keys
+func withoutKeys(values []string, keys map[string]bool) []string {
+	if len(keys) == 0 {
+		return values
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !keys[strings.SplitN(value, "=", 2)[0]] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// jsonProperties parses the "<key>=<json>" pairs of the structured property flags and verifies the values are valid JSON
+func jsonProperties(endpoint string, values []string) (map[string]json.RawMessage, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	properties := make(map[string]json.RawMessage, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid %s JSON property %q, expected <key>=<json>", endpoint, value)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(parts[1]), &decoded); err != nil {
+			return nil, fmt.Errorf("invalid JSON value of %s property %q: %v", endpoint, parts[0], err)
+		}
+		properties[parts[0]] = json.RawMessage(parts[1])
+	}
+	return properties, nil
+}
+
+// mergedProperties reads the properties file if any and overrides its values with the given "<key>=<value>" pairs
+func mergedProperties(file string, values []string) (map[string]string, error) {
+	properties := map[string]string{}
+	if file != "" {
+		read, err := readPropertiesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		properties = read
+	}
+
+	given, err := util.MapFromArray(values, "=")
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range given {
+		properties[key] = value
+	}
+	return properties, nil
+}
+
+// runtimeLoggers verifies the runtime log levels and returns the logger overrides
+func (f *bindingFlags) runtimeLoggers() (map[string]string, error) {
+	if f.RuntimeLogLevel != "" {
+		if err := kameletapi.VerifyRuntimeLogLevel(f.RuntimeLogLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	loggers, err := util.MapFromArray(f.RuntimeLoggers, "=")
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range loggers {
+		if err := kameletapi.VerifyRuntimeLogLevel(level); err != nil {
+			return nil, err
+		}
+	}
+	return loggers, nil
+}
+
+// verifySource checks that the Kamelet referenced as binding source is an event source and verifies its properties
+func verifySource(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, options verifyOptions, out io.Writer) error {
+	return verifyKameletEndpoint(ctx, client, binding, &binding.Spec.Source, "source", options, out)
+}
+
+// verifySink checks that a Kamelet referenced as binding sink is an event sink and verifies its properties
+func verifySink(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, options verifyOptions, out io.Writer) error {
+	return verifyKameletEndpoint(ctx, client, binding, &binding.Spec.Sink, "sink", options, out)
+}
+
+// verifyKameletEndpoint checks that the Kamelet referenced by the endpoint is of given type and that all its required
+// properties are given. Properties unknown to the Kamelet are reported as warning, or as error in strict mode.
+// When defaults are applied the missing properties are set on the endpoint.
+func verifyKameletEndpoint(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, endpoint *v1alpha1.Endpoint, kameletType string, options verifyOptions, out io.Writer) error {
+	ref := endpoint.Ref
+	if ref == nil || ref.Kind != v1alpha1.KameletKind {
+		return nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = binding.Namespace
+	}
+	kamelet, err := client.Kamelets(namespace).Get(ctx, ref.Name, v1.GetOptions{})
+	if err != nil {
+		return knerrors.GetError(err)
+	}
+	return verifyKameletProperties(kamelet, endpoint, kameletType, options, out)
+}
+
+// verifyOfflineKamelets verifies the Kamelet endpoints of the binding against the Kamelets of the local cache or the
+// bundled catalog
+// without accessing the cluster, endpoints referencing Kamelets that are not cached are not verified
+func (params *KameletPluginParams) verifyOfflineKamelets(binding *v1alpha1.KameletBinding, options verifyOptions, out io.Writer) error {
+	for kameletType, endpoint := range map[string]*v1alpha1.Endpoint{"source": &binding.Spec.Source, "sink": &binding.Spec.Sink} {
+		ref := endpoint.Ref
+		if ref == nil || ref.Kind != v1alpha1.KameletKind {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = binding.Namespace
+		}
+		if kamelet, ok := params.offlineKamelet(namespace, ref.Name); ok {
+			if err := verifyKameletProperties(kamelet, endpoint, kameletType, options, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verifyKameletProperties checks that the Kamelet is of given type and verifies the endpoint properties against
+// the Kamelet definition
+func verifyKameletProperties(kamelet *v1alpha1.Kamelet, endpoint *v1alpha1.Endpoint, kameletType string, options verifyOptions, out io.Writer) error {
+	if kameletapi.TypeOf(kamelet) != kameletType {
+		if kameletType == "action" {
+			return withExitCode(ExitCodeValidation, fmt.Errorf("Kamelet %s is not an action", kamelet.Name))
+		}
+		return withExitCode(ExitCodeValidation, fmt.Errorf("Kamelet %s is not an event %s", kamelet.Name, kameletType))
+	}
+
+	var defaulted []string
+	if options.ApplyDefaults {
+		var err error
+		if endpoint.Properties, defaulted, err = kameletapi.ApplyPropertyDefaults(kamelet, endpoint.Properties); err != nil {
+			return err
+		}
+	}
+
+	properties, err := kameletapi.DecodeEndpointProperties(endpoint.Properties)
+	if err != nil {
+		return withExitCode(ExitCodeValidation, err)
+	}
+	if err := kameletapi.ValidateProperties(kamelet, properties); err != nil {
+		return withExitCode(ExitCodeValidation, err)
+	}
+	if err := kameletapi.ValidateStructuredProperties(kamelet, endpoint.Properties); err != nil {
+		return withExitCode(ExitCodeValidation, err)
+	}
+	if endpoint.Properties, err = kameletapi.EncodeTypedProperties(kamelet, endpoint.Properties); err != nil {
+		return withExitCode(ExitCodeValidation, err)
+	}
+	if err := verifyUnknownProperties(kamelet, properties, options.Strict, out); err != nil {
+		return withExitCode(ExitCodeValidation, err)
+	}
+
+	if options.ApplyDefaults {
+		printEffectiveProperties(kamelet, properties, defaulted, out)
+	}
+	return nil
+}
+
+// applyBinding creates the binding or updates the spec of the existing binding with the same name
+func applyBinding(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*v1alpha1.KameletBinding, error) {
+	var dryRun []string
+	var dryRunSuffix string
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	// without overwrite the binding is created right away so the API server rejects existing bindings with AlreadyExists,
+	// bindings with a generated name are always created
+	create := update.NoOverwrite || binding.Name == ""
+	if update.ServerSide && !create {
+		return serverSideApplyBinding(ctx, client, binding, update, serverDryRun, audit, out)
+	}
+	var existing *v1alpha1.KameletBinding
+	var err error
+	if !create {
+		existing, err = client.KameletBindings(binding.Namespace).Get(ctx, binding.Name, v1.GetOptions{})
+	}
+	if create || apierrors.IsNotFound(err) {
+		applied, err := withLastApplied(binding)
+		if err != nil {
+			return nil, err
+		}
+		created, err := client.KameletBindings(binding.Namespace).Create(ctx, applied.(*v1alpha1.KameletBinding), v1.CreateOptions{DryRun: dryRun, FieldManager: fieldManager})
+		name := createdName(binding, created, err)
+		if auditErr := audit.record("create", binding.Kind, binding.Namespace, name, nil, err); auditErr != nil {
+			return nil, auditErr
+		}
+		if err != nil {
+			return nil, knerrors.GetError(err)
+		}
+		fmt.Fprintf(out, "KameletBinding '%s' created in namespace '%s'%s.\n", name, binding.Namespace, dryRunSuffix)
+		return created, nil
+	} else if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	if err := update.verifyUpdate(existing, binding, v1alpha1.KameletBindingKind, out); err != nil {
+		return nil, err
+	}
+
+	desired, err := update.apply(existing, binding)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeUpdateDiff(existing, desired, false, update.redactor, out); err != nil {
+		return nil, err
+	}
+	changes := bindingChanges(existing, desired)
+	if unchanged(existing, desired, changes) {
+		fmt.Fprintf(out, "KameletBinding '%s' unchanged in namespace '%s'%s.\n", binding.Name, binding.Namespace, dryRunSuffix)
+		return existing, nil
+	}
+	updated, err := client.KameletBindings(binding.Namespace).Update(ctx, desired, v1.UpdateOptions{DryRun: dryRun, FieldManager: fieldManager})
+	if auditErr := audit.record(update.action(), binding.Kind, binding.Namespace, binding.Name, changes, err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	fmt.Fprintf(out, "KameletBinding '%s' %sd in namespace '%s'%s.\n", binding.Name, update.action(), binding.Namespace, dryRunSuffix)
+	return updated, nil
+}
+
+// unchanged checks whether updating the existing binding is a no-op, i.e. neither the binding nor its last applied
+// configuration change
+func unchanged(existing *v1alpha1.KameletBinding, desired *v1alpha1.KameletBinding, changes []string) bool {
+	return len(changes) == 0 && existing.Annotations[lastAppliedAnnotation] == desired.Annotations[lastAppliedAnnotation]
+}
+
+// createdName returns the name of the created binding, the API server assigns the name of bindings created with
+// generateName so the prefix is returned when creating them failed
+func createdName(binding *v1alpha1.KameletBinding, created runtime.Object, err error) string {
+	if binding.Name != "" {
+		return binding.Name
+	}
+	if err == nil {
+		if accessor, err := meta.Accessor(created); err == nil {
+			return accessor.GetName()
+		}
+	}
+	return binding.GenerateName
+}
+
+// submitBindings prints the bindings on client dry-run, otherwise verifies their Kamelet source and sink and creates or updates
+// them on the cluster as KameletBindings or Pipes, waiting for the bindings to become ready if requested. The bindings
+// are verified and applied by at most concurrency workers, the errors of all failed bindings are reported. The Secrets
+// holding the secret properties of the bindings, given at the index of their binding, are applied right before their
+// binding once all bindings are verified.
+func submitBindings(p *KameletPluginParams, bindings []*v1alpha1.KameletBinding, secrets []*corev1.Secret, steps []v1alpha1.Endpoint, dryRun string, options verifyOptions, update updateOptions, printFlags *genericclioptions.PrintFlags, waitFlags *commands.WaitFlags, concurrency int, out io.Writer) error {
+	printURL := printFlags.OutputFlagSpecified() && strings.ToLower(*printFlags.OutputFormat) == sinkURLOutput
+	if printURL && dryRun != "" {
+		return errors.New("output format url can not be combined with --dry-run or --offline, the sink URL is resolved from the cluster")
+	}
+	if dryRun == dryRunClient {
+		// the cluster is not accessed on client dry-run, so Pipes are only rendered when explicitly requested
+		r := p.newOfflineRedactor(update.ShowSecrets)
+		manifests := make([]runtime.Object, 0, len(bindings))
+		for _, binding := range bindings {
+			var manifest *unstructured.Unstructured
+			var err error
+			if p.UsePipe {
+				manifest, err = toPipe(binding)
+			} else {
+				manifest, err = sanitize(binding)
+			}
+			if err != nil {
+				return err
+			}
+			if err := setSteps(manifest, steps); err != nil {
+				return err
+			}
+			if err := r.redactManifest(manifest); err != nil {
+				return err
+			}
+			manifests = append(manifests, manifest)
+		}
+		return printBindingManifests(printFlags, out, manifests...)
+	}
+
+	client, err := p.NewKameletClient()
+	if err != nil {
+		return err
+	}
+
+	pipes, err := p.usePipes()
+	if err != nil {
+		return err
+	}
+	serverDryRun := dryRun == dryRunServer
+	update.redactor = p.newRedactor(update.ShowSecrets)
+	// the applied resources are printed instead of any messages when an output format is given
+	printResult := printFlags.OutputFlagSpecified()
+	var messages io.Writer = &syncWriter{out: p.messageOutput(out)}
+	if printResult {
+		messages = ioutil.Discard
+	}
+
+	if len(steps) > 0 {
+		// all bindings share the same steps, so they are verified once
+		if err := verifySteps(p.Context, client, bindings[0], steps, options, messages); err != nil {
+			return err
+		}
+	}
+
+	var dynamicClient dynamic.Interface
+	if pipes || len(steps) > 0 {
+		if dynamicClient, err = p.NewDynamicClient(); err != nil {
+			return err
+		}
+	}
+
+	// all bindings are verified before any of them is applied, so a bad source of a batch leaves no bindings behind
+	errs, err := runConcurrently(concurrency, len(bindings), func(i int) error {
+		if err := verifySource(p.Context, client, bindings[i], options, messages); err != nil {
+			return err
+		}
+		if err := verifySink(p.Context, client, bindings[i], options, messages); err != nil {
+			return err
+		}
+		if options.VerifyAddressable {
+			return p.verifySinkAddressable(bindings[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := aggregateErrors(errs); err != nil {
+		return err
+	}
+
+	audit := p.auditLog()
+	applied := make([]runtime.Object, len(bindings))
+	errs, err = runConcurrently(concurrency, len(bindings), func(i int) error {
+		binding := bindings[i]
+		if i < len(secrets) && secrets[i] != nil {
+			if err := p.applyPropertySecret(secrets[i], serverDryRun, messages); err != nil {
+				return err
+			}
+		}
+		var result runtime.Object
+		// the existing binding is read again when the update fails with a conflict
+		err := p.retryOnConflict(func() (err error) {
+			if pipes {
+				result, err = applyPipe(p.Context, dynamicClient, binding, steps, update, serverDryRun, audit, messages)
+			} else if len(steps) > 0 {
+				result, err = applyBindingWithSteps(p.Context, dynamicClient, binding, steps, update, serverDryRun, audit, messages)
+			} else {
+				result, err = applyBinding(p.Context, client, binding, update, serverDryRun, audit, messages)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		binding.Name = createdName(binding, result, nil)
+		applied[i] = result
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := aggregateErrors(errs); err != nil {
+		return err
+	}
+
+	if waitFlags.Wait && !serverDryRun {
+		for _, binding := range bindings {
+			timeout := time.Duration(waitFlags.TimeoutInSeconds) * time.Second
+			if pipes {
+				err = waitForPipeReady(p.Context, dynamicClient, binding.Namespace, binding.Name, timeout, messages)
+			} else {
+				err = waitForBindingReady(p.Context, client, binding.Namespace, binding.Name, timeout, messages)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if printURL {
+		for _, binding := range bindings {
+			url, err := p.resolveSinkURL(binding)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, url)
+		}
+		return nil
+	}
+	if printResult {
+		for i, result := range applied {
+			if applied[i], err = update.redactor.redactObject(result); err != nil {
+				return err
+			}
+		}
+		return printBindingManifests(printFlags, out, applied...)
+	}
+	return nil
+}
+
+// sinkURLOutput is the output format printing the resolved URL of the binding sink instead of the binding
+const sinkURLOutput = "url"
+
+// addSinkURLOutput documents the url output format of commands creating bindings
+func addSinkURLOutput(cmd *cobra.Command, printFlags *genericclioptions.PrintFlags) {
+	cmd.Flag("output").Usage = fmt.Sprintf("Output format. One of: %s. Format url prints the resolved address of the binding sink.", strings.Join(append(printFlags.AllowedFormats(), sinkURLOutput), "|"))
+}
+
+// mergeMetadata adds and removes the entries of the labels or annotations, the result is nil when no entry is left
+func mergeMetadata(metadata map[string]string, toAdd map[string]string, toRemove []string) map[string]string {
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		return metadata
+	}
+
+	merged := map[string]string{}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	for key, value := range toAdd {
+		merged[key] = value
+	}
+	for _, key := range toRemove {
+		delete(merged, key)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// dry-run modes supported by the --dry-run flag
+const (
+	dryRunClient = "client"
+	dryRunServer = "server"
+)
+
+// addDryRunFlag adds the --dry-run flag to given flag set
+func addDryRunFlag(flags *pflag.FlagSet, dryRun *string) {
+	flags.StringVar(dryRun, "dry-run", "", "Only render (client) or validate on the API server (server) the binding without persisting it. One of: client|server.")
+}
+
+// verifyOptions controls how the properties of Kamelet sources and sinks are verified against the Kamelet definition
+type verifyOptions struct {
+	Strict            bool
+	ApplyDefaults     bool
+	VerifyAddressable bool
+}
+
+// addFlags adds the --strict, --apply-defaults and --verify-addressable flags to given flag set
+func (o *verifyOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Strict, "strict", false, "Fail instead of warn when a property is not defined by the Kamelet.")
+	flags.BoolVar(&o.ApplyDefaults, "apply-defaults", false, "Set all properties not given to the default value defined by the Kamelet and print the effective properties.")
+	flags.BoolVar(&o.VerifyAddressable, "verify-addressable", false, "Check that the sink resource exists and is addressable, i.e. reports status.address.url.")
+}
+
+// updateOptions defines how existing bindings are updated
+type updateOptions struct {
+	Force       bool
+	NoOverwrite bool
+	ServerSide  bool
+	ShowSecrets bool
+
+	// requireForce refuses to update existing bindings without --force, commands creating bindings never silently
+	// overwrite an existing binding
+	requireForce bool
+	// redactor masks the sensitive properties in the printed changes and results
+	redactor *redactor
+}
+
+// addFlags adds the --force, --no-overwrite, --server-side and --show-secrets flags to given flag set
+func (o *updateOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Force, "force", false, "Update an existing binding, without --force the changes to an existing binding are printed and the command fails. With server-side apply the fields managed by other tools are taken over, with --server-side=false the existing binding is replaced with the given one instead of updated with a three-way merge.")
+	flags.BoolVar(&o.NoOverwrite, "no-overwrite", false, "Fail with AlreadyExists when the binding exists instead of updating it. Defaults to the noOverwrite setting of the config file.")
+	flags.BoolVar(&o.ServerSide, "server-side", true, "Create or update the binding with server-side apply, keeping the fields managed by other tools. Use --server-side=false to fall back to a three-way merge with the last applied configuration. Defaults to the serverSide setting of the config file.")
+	addShowSecretsFlag(flags, &o.ShowSecrets)
+}
+
+// apply returns the existing binding updated with given binding. The update is a three-way merge of the last applied
+// configuration, the given binding and the existing binding, so fields added to the binding by others are kept. On
+// --force the spec, labels and annotations of the existing binding are replaced instead.
+func (o updateOptions) apply(existing *v1alpha1.KameletBinding, binding *v1alpha1.KameletBinding) (*v1alpha1.KameletBinding, error) {
+	if !o.Force {
+		return threeWayMergeBinding(existing, binding)
+	}
+	applied, err := withLastApplied(binding)
+	if err != nil {
+		return nil, err
+	}
+	desired := existing.DeepCopy()
+	desired.Spec = binding.Spec
+	desired.Labels = binding.Labels
+	desired.Annotations = applied.(*v1alpha1.KameletBinding).Annotations
+	return desired, nil
+}
+
+// verifyUpdate prints the changes given binding makes to the existing binding and fails with a conflict when
+// updating existing bindings requires --force. Bindings that would not change are applied as usual.
+func (o updateOptions) verifyUpdate(existing *v1alpha1.KameletBinding, binding *v1alpha1.KameletBinding, kind string, out io.Writer) error {
+	if !o.requireForce || o.Force || existing == nil {
+		return nil
+	}
+	desired, err := threeWayMergeBinding(existing, binding)
+	if err != nil {
+		return err
+	}
+	if len(bindingChanges(existing, desired)) == 0 {
+		return nil
+	}
+	if err := writeUpdateDiff(existing, desired, kind == pipeKind, o.redactor, out); err != nil {
+		return err
+	}
+	return withExitCode(ExitCodeConflict, fmt.Errorf("%s '%s' already exists in namespace '%s', use --force to update it", kind, binding.Name, binding.Namespace))
+}
+
+// action names the update for messages and the audit log
+func (o updateOptions) action() string {
+	if o.Force {
+		return "replace"
+	}
+	return "update"
+}
+
+// verifyDryRun checks that the dry-run mode is supported, an empty mode disables dry-run
+func verifyDryRun(dryRun string) error {
+	switch dryRun {
+	case "", dryRunClient, dryRunServer:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dry-run mode %q, expected one of: %s|%s", dryRun, dryRunClient, dryRunServer)
+	}
+}
+
+// printBindingManifests prints the bindings with given print flags, defaults to YAML output
+func printBindingManifests(printFlags *genericclioptions.PrintFlags, out io.Writer, bindings ...runtime.Object) error {
+	if !printFlags.OutputFlagSpecified() {
+		*printFlags.OutputFormat = "yaml"
+	}
+	printer, err := printFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	for _, binding := range bindings {
+		// the client drops the type meta when decoding the resources returned by the cluster
+		if kameletBinding, ok := binding.(*v1alpha1.KameletBinding); ok && kameletBinding.Kind == "" {
+			kameletBinding.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.KameletBindingKind))
+		}
+		if err := printer.PrintObj(binding, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyUnknownProperties reports the properties not defined by the Kamelet, typically caused by a typo in the
+// property key. Kamelets without property definitions accept any property.
+func verifyUnknownProperties(kamelet *v1alpha1.Kamelet, properties map[string]string, strict bool, out io.Writer) error {
+	if kamelet.Spec.Definition == nil || len(kamelet.Spec.Definition.Properties) == 0 {
+		return nil
+	}
+
+	var unknown []string
+	for name := range properties {
+		if _, ok := kamelet.Spec.Definition.Properties[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	if strict {
+		return fmt.Errorf("unknown properties for Kamelet %q: %s", kamelet.Name, strings.Join(unknown, ", "))
+	}
+	for _, name := range unknown {
+		fmt.Fprintf(out, "Warning: property %q is not defined by Kamelet %q.\n", name, kamelet.Name)
+	}
+	return nil
+}
+
+// printEffectiveProperties prints the properties the binding source runs with, values of password
+// properties are masked and defaulted values are marked
+func printEffectiveProperties(kamelet *v1alpha1.Kamelet, properties map[string]string, defaulted []string, out io.Writer) {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "Effective properties of Kamelet '%s':\n", kamelet.Name)
+	for _, name := range names {
+		value := properties[name]
+		if kamelet.Spec.Definition != nil && isPasswordProperty(kamelet.Spec.Definition.Properties[name]) && !kameletapi.IsPropertyReference(value) {
+			value = "********"
+		}
+		if containsString(defaulted, name) {
+			value += " (default)"
+		}
+		fmt.Fprintf(out, "  %s=%s\n", name, value)
+	}
+}
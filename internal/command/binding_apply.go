@@ -0,0 +1,234 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingApplyExample = `
+  # Create or update the bindings described in the manifests of given directory
+  kn-source-kamelet binding apply -f bindings/
+
+  # Also delete the bindings that have been applied before but are no longer part of the manifests
+  kn-source-kamelet binding apply -f bindings/ --prune
+
+  # Show the bindings that would be deleted without changing anything on the cluster
+  kn-source-kamelet binding apply -f bindings/ --prune --dry-run server
+
+  # Apply the bindings with at most 20 requests in parallel
+  kn-source-kamelet binding apply -f bindings/ --concurrency 20`
+
+// newBindingApplyCommand implements 'kn-source-kamelet binding apply' command
+func newBindingApplyCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var prune bool
+	var concurrency int
+	var waitFlags commands.WaitFlags
+	var dryRun string
+	var verify verifyOptions
+	var update updateOptions
+	printFlags := genericclioptions.NewPrintFlags("")
+
+	cmd := &cobra.Command{
+		Use:   "apply -f FILENAME",
+		Short: "Reconcile the bindings of given manifests with the cluster",
+		Long: `Reconcile the bindings of given manifests with the cluster.
+
+Bindings missing on the cluster are created and bindings differing from their manifest are updated. With --prune the
+bindings of the namespaces of the manifests that have been created or updated by kn-source-kamelet before, i.e.
+are applied by the '` + fieldManager + `' field manager or carry the '` + lastAppliedAnnotation + `'
+annotation, but are no longer part of the manifests are deleted.`,
+		Example: bindingApplyExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(filenames) == 0 {
+				return errors.New("missing manifests, use --filename to specify them")
+			}
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+			if err := p.applyUpdateDefaults(cmd, &update); err != nil {
+				return err
+			}
+			// existing bindings are always reconciled
+			update.NoOverwrite = false
+
+			bindings, err := readBindingManifests(filenames, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(bindings) == 0 {
+				return errors.New("no KameletBinding found in given manifests")
+			}
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+			for _, binding := range bindings {
+				if binding.Namespace == "" || cmd.Flags().Changed("namespace") {
+					binding.Namespace = namespace
+				}
+				// manifests exported from a cluster must not carry over the identity of the original resource
+				binding.UID = ""
+				binding.ResourceVersion = ""
+			}
+
+			out := cmd.OutOrStdout()
+			if err := submitBindings(p, bindings, nil, nil, dryRun, verify, update, printFlags, &waitFlags, concurrency, out); err != nil {
+				return err
+			}
+			if !prune {
+				return nil
+			}
+			if dryRun == dryRunClient || printFlags.OutputFlagSpecified() {
+				// stdout is reserved for the printed manifests
+				out = cmd.ErrOrStderr()
+			}
+			return p.pruneBindings(bindings, dryRun, concurrency, p.messageOutput(out))
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file or directory with the KameletBindings or Pipes to apply, use - to read from stdin.")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete the bindings applied before that are no longer part of the manifests.")
+	cmd.Flags().BoolVar(&update.Force, "force", false, "Take over the fields of existing bindings managed by other tools, with --server-side=false replace existing bindings with the given ones.")
+	cmd.Flags().BoolVar(&update.ServerSide, "server-side", true, "Create or update the bindings with server-side apply. Use --server-side=false to fall back to a three-way merge with the last applied configuration. Defaults to the serverSide setting of the config file.")
+	addWaitFlags(cmd, &waitFlags)
+	addDryRunFlag(cmd.Flags(), &dryRun)
+	addConcurrencyFlag(cmd.Flags(), &concurrency)
+	verify.addFlags(cmd.Flags())
+	printFlags.AddFlags(cmd)
+	return cmd
+}
+
+// pruneBindings deletes the bindings of the namespaces of the applied bindings that have been applied before but are
+// not part of the applied bindings, on client dry-run the bindings are only reported. The bindings are deleted by at
+// most concurrency workers, the errors of all failed deletions are reported.
+func (params *KameletPluginParams) pruneBindings(applied []*v1alpha1.KameletBinding, dryRun string, concurrency int, out io.Writer) error {
+	keep := map[string]bool{}
+	for _, binding := range applied {
+		keep[binding.Namespace+"/"+binding.Name] = true
+	}
+	namespaces := make([]string, 0, len(applied))
+	for _, binding := range applied {
+		if !containsString(namespaces, binding.Namespace) {
+			namespaces = append(namespaces, binding.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	pipes, err := params.usePipes()
+	if err != nil {
+		return err
+	}
+	kind := v1alpha1.KameletBindingKind
+	if pipes {
+		kind = pipeKind
+	}
+
+	var deleteOptions v1.DeleteOptions
+	var dryRunSuffix string
+	audit := params.auditLog()
+	if dryRun == dryRunServer {
+		deleteOptions.DryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	var targets []v1.ObjectMeta
+	for _, namespace := range namespaces {
+		bindings, err := params.listBindings(namespace)
+		if err != nil {
+			return err
+		}
+		for _, binding := range bindings {
+			if !appliedBefore(binding) || keep[namespace+"/"+binding.Name] {
+				continue
+			}
+			if dryRun == dryRunClient {
+				fmt.Fprintf(out, "%s '%s' would be pruned in namespace '%s'.\n", kind, binding.Name, namespace)
+				continue
+			}
+			targets = append(targets, v1.ObjectMeta{Name: binding.Name, Namespace: namespace})
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	errs, err := runConcurrently(concurrency, len(targets), func(i int) error {
+		err := params.deleteBinding(targets[i].Namespace, targets[i].Name, pipes, deleteOptions)
+		if auditErr := audit.record("delete", kind, targets[i].Namespace, targets[i].Name, nil, err); auditErr != nil {
+			return auditErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to prune %s '%s' in namespace '%s': %w", kind, targets[i].Name, targets[i].Namespace, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, target := range targets {
+		if errs[i] == nil {
+			fmt.Fprintf(out, "%s '%s' pruned in namespace '%s'%s.\n", kind, target.Name, target.Namespace, dryRunSuffix)
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// deleteBinding deletes the KameletBinding or Pipe of given name
+func (params *KameletPluginParams) deleteBinding(namespace string, name string, pipes bool, options v1.DeleteOptions) error {
+	if pipes {
+		client, err := params.NewDynamicClient()
+		if err != nil {
+			return err
+		}
+		return knerrors.GetError(client.Resource(pipeResource).Namespace(namespace).Delete(params.Context, name, options))
+	}
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return err
+	}
+	return knerrors.GetError(client.KameletBindings(namespace).Delete(params.Context, name, options))
+}
+
+// appliedBefore checks whether the binding has been applied by the plugin, either with server-side apply of its field
+// manager or with the three-way merge that keeps the last applied configuration
+func appliedBefore(binding v1alpha1.KameletBinding) bool {
+	if _, ok := binding.Annotations[lastAppliedAnnotation]; ok {
+		return true
+	}
+	for _, entry := range binding.ManagedFields {
+		if entry.Manager == fieldManager && entry.Operation == v1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
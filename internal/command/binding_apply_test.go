@@ -0,0 +1,207 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+	"sigs.k8s.io/yaml"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingApply(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	dir := t.TempDir()
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b1", "k1", "current"))
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b2", "k2", "current"))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k2"), nil)
+	recorder.GetBinding(createKameletBindingInNamespace("b1", "k1", "current"), nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+	}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, opts.FieldManager, fieldManager)
+	}, nil)
+	recorder.GetBinding(nil, notFound("b2"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b2")
+		assert.Equal(t, binding.Annotations[lastAppliedAnnotation], "")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindingApplyCmd(mockClient, "-f", dir, "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "KameletBinding 'b1' updated in namespace 'current'.\nKameletBinding 'b2' created in namespace 'current'.\n")
+	recorder.Validate()
+}
+
+func TestBindingApplyThreeWayMerge(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	dir := t.TempDir()
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b1", "k1", "current"))
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b2", "k2", "current"))
+
+	last, err := withLastApplied(createKameletBindingInNamespace("b1", "k1", "current"))
+	assert.NilError(t, err)
+	existing := last.(*camelkapis.KameletBinding)
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k2"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.GetBinding(nil, notFound("b2"))
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b2")
+		assert.Check(t, binding.Annotations[lastAppliedAnnotation] != "")
+	}, nil)
+
+	output, err := runBindingApplyCmd(mockClient, "-f", dir, "--concurrency", "1", "--server-side=false")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "KameletBinding 'b1' unchanged in namespace 'current'.\nKameletBinding 'b2' created in namespace 'current'.\n")
+	recorder.Validate()
+}
+
+func TestBindingApplyErrorsCollected(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	dir := t.TempDir()
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b1", "k1", "current"))
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b2", "k2", "current"))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k2"), nil)
+	recorder.GetBinding(nil, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, errors.New("admission webhook denied the request"))
+	recorder.GetBinding(nil, notFound("b2"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b2")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	// the failure of b1 does not stop applying b2
+	output, err := runBindingApplyCmd(mockClient, "-f", dir, "--concurrency", "1")
+	assert.ErrorContains(t, err, "admission webhook denied the request")
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'b2' created in namespace 'current'."))
+	assert.Check(t, util.ContainsNone(output, "'b1' created"))
+	recorder.Validate()
+}
+
+func TestBindingApplyPrune(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	dir := t.TempDir()
+	writeBindingManifest(t, dir, createKameletBindingInNamespace("b1", "k1", "current"))
+
+	applied := func(name string) camelkapis.KameletBinding {
+		last, err := withLastApplied(createKameletBindingInNamespace(name, "k1", "current"))
+		assert.NilError(t, err)
+		return *last.(*camelkapis.KameletBinding)
+	}
+	serverSideApplied := func(name string) camelkapis.KameletBinding {
+		binding := createKameletBindingInNamespace(name, "k1", "current")
+		binding.ManagedFields = []v1.ManagedFieldsEntry{{Manager: fieldManager, Operation: v1.ManagedFieldsOperationApply}}
+		return *binding
+	}
+	existing := serverSideApplied("b1")
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{
+		existing, applied("b2"), *createKameletBindingInNamespace("b3", "k1", "current"), serverSideApplied("b4"),
+	}}
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	recorder.ListBindings(bindingList, nil)
+	recorder.DeleteBinding("b2", nil)
+	recorder.DeleteBinding("b4", nil)
+
+	output, err := runBindingApplyCmd(mockClient, "-f", dir, "--prune", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "KameletBinding 'b1' updated in namespace 'current'.\n"+
+		"KameletBinding 'b2' pruned in namespace 'current'.\nKameletBinding 'b4' pruned in namespace 'current'.\n")
+
+	recorder.ListBindings(bindingList, nil)
+	output, err = runBindingApplyCmd(mockClient, "-f", dir, "--prune", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding\n", "  name: b1\n",
+		"KameletBinding 'b2' would be pruned in namespace 'current'.\n", "KameletBinding 'b4' would be pruned in namespace 'current'.\n"))
+	assert.Check(t, util.ContainsNone(output, "b3"))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	recorder.ListBindings(bindingList, nil)
+	recorder.DeleteBinding("b2", notFound("b2"))
+	recorder.DeleteBinding("b4", nil)
+	_, err = runBindingApplyCmd(mockClient, "-f", dir, "--prune", "--concurrency", "1")
+	assert.ErrorContains(t, err, "failed to prune KameletBinding 'b2' in namespace 'current': ")
+
+	recorder.Validate()
+}
+
+func TestBindingApplyErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingApplyCmd(mockClient)
+	assert.Error(t, err, "missing manifests, use --filename to specify them")
+
+	_, err = runBindingApplyCmd(mockClient, "-f", t.TempDir(), "--prune")
+	assert.Error(t, err, "no KameletBinding found in given manifests")
+	recorder.Validate()
+}
+
+func writeBindingManifest(t *testing.T, dir string, binding *camelkapis.KameletBinding) {
+	manifest, err := exportManifest(binding, false)
+	assert.NilError(t, err)
+	data, err := yaml.Marshal(manifest.Object)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, binding.Name+".yaml"), data, 0600))
+}
+
+func runBindingApplyCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs(append([]string{"binding", "apply"}, options...))
+	// prune messages are written to stderr on client dry-run
+	bindingCmd.SetErr(output)
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
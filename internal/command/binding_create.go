@@ -0,0 +1,217 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knflags "knative.dev/client/pkg/kn/flags"
+)
+
+var bindingCreateExample = `
+  # Create a binding of Kamelet source to Knative broker
+  kn-source-kamelet binding create NAME --kamelet timer-source --broker default --source-property message=Hello
+
+  # Print the binding manifest without creating it
+  kn-source-kamelet binding create NAME --kamelet timer-source --broker default --dry-run client -o yaml
+
+  # Create the bindings described in given manifest file
+  kn-source-kamelet binding create -f binding.yaml
+
+  # Update an existing binding with the one of given manifest file, without --force the changes are only printed
+  kn-source-kamelet binding create -f binding.yaml --force
+
+  # Replace an existing binding including its labels and annotations with the one of given manifest file
+  kn-source-kamelet binding create -f binding.yaml --force --server-side=false
+
+  # Create the binding of a shared template setting its {{ .team }} and {{ .sink }} placeholders
+  kn-source-kamelet binding create --from-template binding-template.yaml --set team=payments --set sink=payments-broker
+
+  # Create the binding read from stdin using the given name and namespace
+  cat binding.yaml | kn-source-kamelet binding create NAME -n events -f -
+
+  # Create a binding and wait for it to become ready
+  kn-source-kamelet binding create NAME --kamelet timer-source --service event-display --wait`
+
+// newBindingCreateCommand implements 'kn-source-kamelet binding create' command
+func newBindingCreateCommand(p *KameletPluginParams) *cobra.Command {
+	var flags bindingFlags
+	var kamelet string
+	var waitFlags commands.WaitFlags
+	var dryRun string
+	var noPrompt bool
+	var verify verifyOptions
+	update := updateOptions{requireForce: true}
+	var filenames []string
+	var templateFile string
+	var templateValues []string
+	printFlags := genericclioptions.NewPrintFlags("")
+
+	cmd := &cobra.Command{
+		Use:     "create NAME|-f FILENAME",
+		Short:   "Create a KameletBinding of Kamelet source to Knative broker, channel or service",
+		Example: bindingCreateExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := knflags.ReconcileBoolFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+			if err := p.applyUpdateDefaults(cmd, &update); err != nil {
+				return err
+			}
+
+			if len(templateValues) > 0 && templateFile == "" {
+				return errors.New("--set requires a template given with --from-template")
+			}
+			if templateFile != "" {
+				if len(filenames) > 0 {
+					return errors.New("--from-template can not be combined with --filename")
+				}
+				read := func() ([]*v1alpha1.KameletBinding, error) {
+					return renderBindingTemplate(templateFile, templateValues, cmd.InOrStdin())
+				}
+				return createBindingsFromManifests(cmd, p, read, args, dryRun, verify, update, printFlags, &waitFlags)
+			}
+			if len(filenames) > 0 {
+				read := func() ([]*v1alpha1.KameletBinding, error) {
+					return readBindingManifests(filenames, cmd.InOrStdin())
+				}
+				return createBindingsFromManifests(cmd, p, read, args, dryRun, verify, update, printFlags, &waitFlags)
+			}
+
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet binding create' requires the binding name given as single argument")
+			}
+			if kamelet == "" {
+				return errors.New("missing Kamelet source, use --kamelet to specify it")
+			}
+
+			if err := flags.expandPropertyValues(cmd.InOrStdin()); err != nil {
+				return err
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+			if err := p.applyDefaultSink(&flags); err != nil {
+				return err
+			}
+
+			options, err := flags.toOptions(args[0], namespace, kamelet, p.sinkTypes())
+			if err != nil {
+				return err
+			}
+			binding, err := kameletapi.NewBinding(options)
+			if err != nil {
+				return err
+			}
+			if err := p.promptPasswords(cmd, binding, &flags, dryRun, noPrompt); err != nil {
+				return err
+			}
+			secret, err := preparePropertySecret(&flags, binding, dryRun)
+			if err != nil {
+				return err
+			}
+			return submitBindings(p, []*v1alpha1.KameletBinding{binding}, []*corev1.Secret{secret}, nil, dryRun, verify, update, printFlags, &waitFlags, 1, cmd.OutOrStdout())
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringVar(&kamelet, "kamelet", "", "Name of the Kamelet source to bind.")
+	_ = cmd.RegisterFlagCompletionFunc("kamelet", p.completeKameletFlag("source"))
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file or directory with the KameletBindings to create, use - to read from stdin.")
+	cmd.Flags().StringVar(&templateFile, "from-template", "", "Manifest template with the KameletBindings to create, placeholders such as {{ .sink }} are set with --set. Use - to read from stdin.")
+	cmd.Flags().StringArrayVar(&templateValues, "set", nil, "Value of a template placeholder given as key=value, may be given multiple times.")
+	flags.addFlags(cmd.Flags())
+	p.registerSinkCompletion(cmd)
+	p.registerPropertyCompletion(cmd, func(cmd *cobra.Command, args []string) string {
+		return kamelet
+	})
+	addWaitFlags(cmd, &waitFlags)
+	addDryRunFlag(cmd.Flags(), &dryRun)
+	verify.addFlags(cmd.Flags())
+	update.addFlags(cmd.Flags())
+	addNoPromptFlag(cmd, &noPrompt)
+	printFlags.AddFlags(cmd)
+	addSinkURLOutput(cmd, printFlags)
+	return cmd
+}
+
+// manifestConflictingFlags lists the flags defining a binding that can not be combined with --filename or --from-template
+var manifestConflictingFlags = []string{
+	"kamelet", "broker", "channel", "service", "uri", "sink",
+	"source-property", "source-properties-file", "source-property-secret", "source-property-configmap",
+	"sink-property", "sink-properties-file", "sink-property-secret", "sink-property-configmap",
+	"ce-override", "label", "annotation", "trait", "replicas", "runtime-log-level", "runtime-logger",
+}
+
+// createBindingsFromManifests creates the KameletBindings read from manifests or a rendered template. The binding name
+// given as argument and the namespace flag override the values of the manifest.
+func createBindingsFromManifests(cmd *cobra.Command, p *KameletPluginParams, read func() ([]*v1alpha1.KameletBinding, error), args []string, dryRun string, verify verifyOptions, update updateOptions, printFlags *genericclioptions.PrintFlags, waitFlags *commands.WaitFlags) error {
+	source := "filename"
+	if cmd.Flags().Changed("from-template") {
+		source = "from-template"
+	}
+	for _, flag := range manifestConflictingFlags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s can not be combined with --%s", flag, source)
+		}
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("'kn-source-kamelet binding create' accepts at most one binding name when used with --%s", source)
+	}
+
+	bindings, err := read()
+	if err != nil {
+		return err
+	}
+	if len(bindings) == 0 {
+		return errors.New("no KameletBinding found in given manifests")
+	}
+	if len(args) == 1 && len(bindings) > 1 {
+		return fmt.Errorf("binding name %q requires a single KameletBinding in given manifests, found %d", args[0], len(bindings))
+	}
+
+	namespace, err := p.GetNamespace(cmd)
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		if len(args) == 1 {
+			binding.Name = args[0]
+		}
+		if binding.Namespace == "" || cmd.Flags().Changed("namespace") {
+			binding.Namespace = namespace
+		}
+		// manifests exported from a cluster must not carry over the identity of the original resource
+		binding.UID = ""
+		binding.ResourceVersion = ""
+	}
+
+	return submitBindings(p, bindings, nil, nil, dryRun, verify, update, printFlags, waitFlags, 1, cmd.OutOrStdout())
+}
@@ -0,0 +1,373 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingCreateErrorCaseMissingArgument(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingCreateCmd(mockClient, "--kamelet", "k1", "--broker", "default")
+	assert.Error(t, err, "'kn-source-kamelet binding create' requires the binding name given as single argument")
+	recorder.Validate()
+}
+
+func TestBindingCreateErrorCaseMissingKamelet(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingCreateCmd(mockClient, "b1", "--broker", "default")
+	assert.Error(t, err, "missing Kamelet source, use --kamelet to specify it")
+	recorder.Validate()
+}
+
+func TestBindingCreate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+		assert.Equal(t, binding.Spec.Source.Ref.Name, "k1")
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Channel")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--channel", "events")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "created"))
+	assert.Check(t, util.ContainsNone(output, "Waiting"))
+	recorder.Validate()
+}
+
+func TestBindingCreateUpdatesExisting(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	existing := createKameletBindingInNamespace("b1", "k1", "current")
+	existing.Labels = map[string]string{"owner": "ops"}
+
+	// without --force the changes are printed and nothing is updated
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	output, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default", "--label", "team=events", "--server-side=false")
+	assert.ErrorContains(t, err, "KameletBinding 'b1' already exists in namespace 'current', use --force to update it")
+	assert.Equal(t, ExitCode(err), ExitCodeConflict)
+	assert.Check(t, util.ContainsAll(output, "+    team: events"))
+	assert.Check(t, util.ContainsNone(output, "-    owner: ops", "KameletBinding 'b1' updated"))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.UpdateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.DeepEqual(t, binding.Labels, map[string]string{"team": "events"})
+	}, nil)
+	output, err = runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default", "--label", "team=events", "--force", "--server-side=false")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "-    owner: ops", "+    team: events", "KameletBinding 'b1' replaced in namespace 'current'."))
+	recorder.Validate()
+}
+
+func TestBindingCreateNoOverwrite(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+	}, apierrors.NewAlreadyExists(schema.GroupResource{Group: "camel.apache.org", Resource: "kameletbindings"}, "b1"))
+
+	_, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default", "--no-overwrite")
+	assert.Check(t, apierrors.IsAlreadyExists(err))
+	assert.Error(t, err, `kameletbindings.camel.apache.org "b1" already exists`)
+
+	_, err = runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default", "--no-overwrite", "--force")
+	assert.Error(t, err, "--force can not be combined with --no-overwrite")
+	recorder.Validate()
+}
+
+func TestBindingCreateServerSide(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	existing := createKameletBindingInNamespace("b1", "k1", "current")
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Kind, "KameletBinding")
+		assert.Equal(t, binding.Name, "b1")
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Channel")
+		assert.Check(t, binding.CreationTimestamp.IsZero())
+		assert.Check(t, binding.Annotations[lastAppliedAnnotation] == "")
+	}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, opts.FieldManager, "kn-source-kamelet")
+		assert.Equal(t, *opts.Force, true)
+	}, nil)
+	output, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--channel", "events", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "-      kind: Broker\n", "+      kind: Channel\n", "KameletBinding 'b1' updated in namespace 'current'."))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(nil, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, *opts.Force, true)
+	}, nil)
+	output, err = runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--channel", "events", "--force")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "KameletBinding 'b1' created in namespace 'current'.\n")
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, *opts.Force, false)
+	}, apierrors.NewConflict(schema.GroupResource{Group: "camel.apache.org", Resource: "kameletbindings"}, "b1", errors.New("conflict with \"argocd\"")))
+	_, err = runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default")
+	assert.Check(t, apierrors.IsConflict(err))
+	assert.ErrorContains(t, err, "use --force to take over the conflicting fields")
+	recorder.Validate()
+}
+
+func TestBindingCreateDryRunClient(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--service", "display", "--dry-run", "client", "-o", "json")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "\"kind\": \"KameletBinding\"", "\"name\": \"b1\"", "\"kind\": \"Service\""))
+	recorder.Validate()
+}
+
+func TestBindingCreateWaitError(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	failed := createKameletBinding("b1", "k1")
+	failed.Status.Phase = camelkapis.KameletBindingPhaseError
+	failed.Status.Conditions[0].Status = corev1.ConditionFalse
+	failed.Status.Conditions[0].Reason = "IntegrationError"
+	recorder.GetBinding(failed, nil)
+
+	_, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--broker", "default", "--wait")
+	assert.Error(t, err, "KameletBinding 'b1' in namespace 'current' failed: IntegrationError")
+	recorder.Validate()
+}
+
+var sourceBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b1
+  resourceVersion: "42"
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+    properties:
+      message: Hello
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: default
+`
+
+func TestBindingCreateFromFile(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	file := filepath.Join(t.TempDir(), "binding.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(sourceBindingManifest), 0600))
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "b1")
+		assert.Equal(t, binding.Namespace, "current")
+		assert.Equal(t, binding.ResourceVersion, "")
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello"}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindingCreateCmd(mockClient, "-f", file)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "created", "current"))
+	recorder.Validate()
+}
+
+func TestBindingCreateFromStdinOverrides(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindingCreateCmdWithInput(mockClient, sourceBindingManifest, "my-binding", "-f", "-", "-n", "events", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: my-binding", "namespace: events", "message: Hello"))
+	assert.Check(t, util.ContainsNone(output, "resourceVersion"))
+	recorder.Validate()
+}
+
+func TestBindingCreateFromFileMissingProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message", "period"}
+	recorder.Get(kamelet, nil)
+
+	_, err := runBindingCreateCmdWithInput(mockClient, sourceBindingManifest, "-f", "-")
+	assert.Error(t, err, "binding is missing required property \"period\" for Kamelet \"k1\"")
+	recorder.Validate()
+}
+
+func TestBindingCreateFromFileErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingCreateCmdWithInput(mockClient, sourceBindingManifest, "-f", "-", "--broker", "default")
+	assert.Error(t, err, "--broker can not be combined with --filename")
+
+	_, err = runBindingCreateCmdWithInput(mockClient, bindingManifests, "b1", "-f", "-")
+	assert.Error(t, err, "binding name \"b1\" requires a single KameletBinding in given manifests, found 2")
+
+	_, err = runBindingCreateCmdWithInput(mockClient, "---\n", "-f", "-")
+	assert.Error(t, err, "no KameletBinding found in given manifests")
+
+	_, err = runBindingCreateCmdWithInput(mockClient, "kind: ConfigMap", "-f", "-")
+	assert.Error(t, err, "unsupported kind \"ConfigMap\" in manifest stdin, only KameletBindings and Pipes are supported")
+	recorder.Validate()
+}
+
+func runBindingCreateCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	return runBindingCreateCmdWithInput(c, "", options...)
+}
+
+func runBindingCreateCmdWithInput(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+
+	args := []string{"binding", "create"}
+	args = append(args, options...)
+	bindingCmd.SetArgs(args)
+	bindingCmd.SetIn(strings.NewReader(input))
+	err := bindingCmd.Execute()
+
+	return output.String(), err
+}
+
+var bindingTemplate = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: {{ .team }}-timer
+  labels:
+    team: {{ .team }}
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+    properties:
+      message: "{{ .message }}"
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: {{ .broker }}
+`
+
+func TestBindingCreateFromTemplate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	file := filepath.Join(t.TempDir(), "binding-template.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(bindingTemplate), 0600))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(nil, notFound("payments-timer"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "payments-timer")
+		assert.Equal(t, binding.Namespace, "current")
+		assert.Equal(t, binding.Labels["team"], "payments")
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello payments"}`)
+		assert.Equal(t, binding.Spec.Sink.Ref.Name, "payments-broker")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runBindingCreateCmd(mockClient, "--from-template", file, "--set", "team=payments", "--set", "broker=payments-broker", "--set", "message=Hello payments")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'payments-timer' created"))
+
+	output, err = runBindingCreateCmdWithInput(mockClient, bindingTemplate, "--from-template", "-", "--set", "team=orders", "--set", "broker=default", "--set", "message=Hi", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: orders-timer", "team: orders", "message: Hi"))
+	recorder.Validate()
+}
+
+func TestBindingCreateTemplateErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingCreateCmdWithInput(mockClient, bindingTemplate, "--from-template", "-", "--set", "team=orders")
+	assert.ErrorContains(t, err, "failed to render template stdin, set all placeholders with --set")
+	assert.ErrorContains(t, err, `map has no entry for key "message"`)
+
+	_, err = runBindingCreateCmdWithInput(mockClient, "name: {{ .team", "--from-template", "-")
+	assert.ErrorContains(t, err, "invalid template stdin")
+
+	_, err = runBindingCreateCmd(mockClient, "--set", "team=orders")
+	assert.Error(t, err, "--set requires a template given with --from-template")
+
+	_, err = runBindingCreateCmdWithInput(mockClient, bindingTemplate, "--from-template", "-", "-f", "binding.yaml")
+	assert.Error(t, err, "--from-template can not be combined with --filename")
+
+	_, err = runBindingCreateCmdWithInput(mockClient, bindingTemplate, "--from-template", "-", "--broker", "default")
+	assert.Error(t, err, "--broker can not be combined with --from-template")
+	recorder.Validate()
+}
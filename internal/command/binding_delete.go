@@ -0,0 +1,211 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingDeleteExample = `
+  # Delete given KameletBindings
+  kn-source-kamelet binding delete NAME...
+
+  # Delete all KameletBindings described in given manifest file
+  kn-source-kamelet binding delete -f binding.yaml
+
+  # Delete all KameletBindings described in the manifests of given directory
+  kn-source-kamelet binding delete -f bindings/
+
+  # Delete all KameletBindings labeled app=payments after confirming the deletion
+  kn-source-kamelet binding delete -l app=payments
+
+  # Delete all KameletBindings in the current namespace without asking for confirmation
+  kn-source-kamelet binding delete --all --yes`
+
+// newBindingDeleteCommand implements 'kn-source-kamelet binding delete' command
+func newBindingDeleteCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var concurrency int
+	var selector string
+	var all, yes bool
+
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete KameletBindings",
+		Aliases: []string{"rm"},
+		Example: bindingDeleteExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			bySelector := selector != "" || all
+			if len(args) == 0 && len(filenames) == 0 && !bySelector {
+				return errors.New("'kn-source-kamelet binding delete' requires the binding names given as arguments, manifests given with --filename, a label selector given with --selector or --all")
+			}
+			if len(args) > 0 && len(filenames) > 0 {
+				return errors.New("binding names given as arguments can not be combined with --filename")
+			}
+			if selector != "" && all {
+				return errors.New("--selector can not be combined with --all")
+			}
+			if bySelector && (len(args) > 0 || len(filenames) > 0) {
+				return errors.New("--selector and --all can not be combined with binding names or --filename")
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			var targets []v1.ObjectMeta
+			for _, name := range args {
+				targets = append(targets, v1.ObjectMeta{Name: name, Namespace: namespace})
+			}
+
+			if len(filenames) > 0 {
+				bindings, err := readBindingManifests(filenames, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				for _, binding := range bindings {
+					target := v1.ObjectMeta{Name: binding.Name, Namespace: binding.Namespace}
+					if target.Namespace == "" || cmd.Flags().Changed("namespace") {
+						target.Namespace = namespace
+					}
+					targets = append(targets, target)
+				}
+				if len(targets) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+					return nil
+				}
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+			pipes, err := p.usePipes()
+			if err != nil {
+				return err
+			}
+			kind := v1alpha1.KameletBindingKind
+			var dynamicClient dynamic.Interface
+			if pipes {
+				kind = pipeKind
+				if dynamicClient, err = p.NewDynamicClient(); err != nil {
+					return err
+				}
+			}
+
+			if bySelector {
+				listOptions, err := selectorListOptions(selector)
+				if err != nil {
+					return err
+				}
+				if pipes {
+					pipeList, err := dynamicClient.Resource(pipeResource).Namespace(namespace).List(p.Context, listOptions)
+					if err != nil {
+						return knerrors.GetError(err)
+					}
+					for _, pipe := range pipeList.Items {
+						targets = append(targets, v1.ObjectMeta{Name: pipe.GetName(), Namespace: namespace})
+					}
+				} else {
+					bindingList, err := client.KameletBindings(namespace).List(p.Context, listOptions)
+					if err != nil {
+						return knerrors.GetError(err)
+					}
+					for _, binding := range bindingList.Items {
+						targets = append(targets, v1.ObjectMeta{Name: binding.Name, Namespace: namespace})
+					}
+				}
+				if len(targets) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+					return nil
+				}
+				if !yes {
+					confirmed, err := confirmDeletion(cmd, kind, targets, namespace)
+					if err != nil || !confirmed {
+						return err
+					}
+				}
+			}
+
+			audit := p.auditLog()
+			errs, err := runConcurrently(concurrency, len(targets), func(i int) error {
+				var err error
+				if pipes {
+					err = dynamicClient.Resource(pipeResource).Namespace(targets[i].Namespace).Delete(p.Context, targets[i].Name, v1.DeleteOptions{})
+				} else {
+					err = client.KameletBindings(targets[i].Namespace).Delete(p.Context, targets[i].Name, v1.DeleteOptions{})
+				}
+				if auditErr := audit.record("delete", kind, targets[i].Namespace, targets[i].Name, nil, err); auditErr != nil {
+					return auditErr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to delete %s '%s' in namespace '%s': %w", kind, targets[i].Name, targets[i].Namespace, knerrors.GetError(err))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			out := p.messageOutput(cmd.OutOrStdout())
+			for i, target := range targets {
+				if errs[i] == nil {
+					fmt.Fprintf(out, "%s '%s' deleted in namespace '%s'.\n", kind, target.Name, target.Namespace)
+				}
+			}
+
+			return aggregateErrors(errs)
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file or directory with the KameletBindings or Pipes to delete, use - to read from stdin.")
+	addSelectorFlag(cmd.Flags(), &selector)
+	cmd.Flags().BoolVar(&all, "all", false, "Delete all KameletBindings in the namespace.")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt when deleting bindings by --selector or --all.")
+	addConcurrencyFlag(cmd.Flags(), &concurrency)
+	return cmd
+}
+
+// confirmDeletion lists the bindings selected for deletion and asks the user to confirm, fails when the input is not
+// a terminal so that scripts have to opt in with --yes
+func confirmDeletion(cmd *cobra.Command, kind string, targets []v1.ObjectMeta, namespace string) (bool, error) {
+	if !isTerminalInput(cmd) {
+		return false, fmt.Errorf("deleting %d %ss requires confirmation, use --yes to skip it", len(targets), kind)
+	}
+	out := cmd.OutOrStdout()
+	for _, target := range targets {
+		fmt.Fprintf(out, "  %s\n", target.Name)
+	}
+	confirmed, err := newPrompter(cmd).confirm(fmt.Sprintf("Delete %d %ss in namespace '%s'?", len(targets), kind, namespace))
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Fprintf(out, "Deletion aborted.\n")
+	}
+	return confirmed, nil
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var bindingManifests = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b1
+spec:
+  source:
+    ref:
+      kind: Kamelet
+      name: k1
+---
+apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b2
+  namespace: other
+`
+
+func TestBindingDeleteSetup(t *testing.T) {
+	p := KameletPluginParams{
+		Context: context.TODO(),
+	}
+
+	deleteCmd := newBindingDeleteCommand(&p)
+	assert.Equal(t, deleteCmd.Use, "delete")
+	assert.Equal(t, deleteCmd.Short, "Delete KameletBindings")
+	assert.Assert(t, deleteCmd.RunE != nil)
+}
+
+func TestBindingDeleteErrorCaseMissingArgument(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingDeleteCmd(mockClient)
+	assert.Error(t, err, "'kn-source-kamelet binding delete' requires the binding names given as arguments, manifests given with --filename, a label selector given with --selector or --all")
+	recorder.Validate()
+}
+
+func TestBindingDeleteByName(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.DeleteBinding("b1", nil)
+	recorder.DeleteBinding("b2", nil)
+
+	output, err := runBindingDeleteCmd(mockClient, "b1", "b2", "--concurrency", "1")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "KameletBinding", "b1", "deleted", "current"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "KameletBinding", "b2", "deleted", "current"))
+	recorder.Validate()
+}
+
+func TestBindingDeleteErrorCollected(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.DeleteBinding("b1", errors.New("not found"))
+	recorder.DeleteBinding("b2", nil)
+
+	output, err := runBindingDeleteCmd(mockClient, "b1", "b2", "--concurrency", "1")
+	assert.Error(t, err, "failed to delete KameletBinding 'b1' in namespace 'current': not found")
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b2", "deleted"))
+	assert.Check(t, util.ContainsNone(output, "'b1' deleted"))
+	recorder.Validate()
+}
+
+func TestBindingDeleteFromFile(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	file := filepath.Join(t.TempDir(), "bindings.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(bindingManifests), 0600))
+
+	recorder.DeleteBinding("b1", nil)
+	recorder.DeleteBinding("b2", nil)
+
+	output, err := runBindingDeleteCmd(mockClient, "-f", file, "--concurrency", "1")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "b1", "deleted", "current"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "b2", "deleted", "other"))
+	recorder.Validate()
+}
+
+func TestBindingDeleteFromFileNamespaceOverride(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	file := filepath.Join(t.TempDir(), "bindings.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(bindingManifests), 0600))
+
+	recorder.DeleteBinding("b1", nil)
+	recorder.DeleteBinding("b2", nil)
+
+	output, err := runBindingDeleteCmd(mockClient, "-f", file, "-n", "test", "--concurrency", "1")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "b1", "deleted", "test"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "b2", "deleted", "test"))
+	recorder.Validate()
+}
+
+func TestBindingDeleteBySelector(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1"), *createKameletBinding("b2", "k2")}}, nil)
+	recorder.DeleteBinding("b1", nil)
+	recorder.DeleteBinding("b2", nil)
+
+	output, err := runBindingDeleteCmd(mockClient, "-l", "app=payments", "--yes", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "KameletBinding 'b1' deleted in namespace 'current'.\nKameletBinding 'b2' deleted in namespace 'current'.\n")
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	output, err = runBindingDeleteCmd(mockClient, "--all", "-y")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "No resources found.\n")
+
+	// scripts have to opt in to deleting without confirmation
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1")}}, nil)
+	_, err = runBindingDeleteCmd(mockClient, "--all")
+	assert.Error(t, err, "deleting 1 KameletBindings requires confirmation, use --yes to skip it")
+	recorder.Validate()
+}
+
+func TestBindingDeleteConfirm(t *testing.T) {
+	defer func(isTerminal func(*cobra.Command) bool) {
+		isTerminalInput = isTerminal
+	}(isTerminalInput)
+	isTerminalInput = func(*cobra.Command) bool {
+		return true
+	}
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1"), *createKameletBinding("b2", "k2")}}
+	recorder.ListBindings(bindingList, nil)
+	output, err := runBindingDeleteCmdWithInput(mockClient, "n\n", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "  b1\n  b2\nDelete 2 KameletBindings in namespace 'current'? [y/N]: Deletion aborted.\n")
+
+	recorder.ListBindings(bindingList, nil)
+	recorder.DeleteBinding("b1", nil)
+	recorder.DeleteBinding("b2", nil)
+	output, err = runBindingDeleteCmdWithInput(mockClient, "yes\n", "--all", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "[y/N]: KameletBinding 'b1' deleted", "KameletBinding 'b2' deleted"))
+	recorder.Validate()
+}
+
+func TestBindingDeleteBySelectorErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingDeleteCmd(mockClient, "-l", "app=payments", "--all")
+	assert.Error(t, err, "--selector can not be combined with --all")
+
+	_, err = runBindingDeleteCmd(mockClient, "b1", "--all")
+	assert.Error(t, err, "--selector and --all can not be combined with binding names or --filename")
+
+	_, err = runBindingDeleteCmd(mockClient, "-l", "app in (payments")
+	assert.ErrorContains(t, err, "invalid label selector \"app in (payments\"")
+	recorder.Validate()
+}
+
+func runBindingDeleteCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	return runBindingDeleteCmdWithInput(c, "", options...)
+}
+
+func runBindingDeleteCmdWithInput(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+
+	args := []string{"binding", "delete"}
+	args = append(args, options...)
+	bindingCmd.SetArgs(args)
+	bindingCmd.SetIn(strings.NewReader(input))
+	err := bindingCmd.Execute()
+
+	return output.String(), err
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/printers"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	"knative.dev/pkg/apis"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingDescribeExample = `
+  # Describe given binding including its conditions and the state of its integration
+  kn-source-kamelet binding describe NAME
+
+  # Describe given binding including the messages of all conditions
+  kn-source-kamelet binding describe NAME --verbose
+
+  # Print given binding in YAML output format
+  kn-source-kamelet binding describe NAME -o yaml
+
+  # Print given binding including the values of sensitive properties such as passwords
+  kn-source-kamelet binding describe NAME --show-secrets
+
+  # Describe given binding again on each of its changes until interrupted
+  kn-source-kamelet binding describe NAME --watch`
+
+// integrationResource is the Camel K integration running the binding
+var integrationResource = schema.GroupVersionResource{Group: "camel.apache.org", Version: "v1", Resource: "integrations"}
+
+// integrationLabel selects the pods of a Camel K integration
+const integrationLabel = "camel.apache.org/integration"
+
+// newBindingDescribeCommand implements 'kn-source-kamelet binding describe' command
+func newBindingDescribeCommand(p *KameletPluginParams) *cobra.Command {
+	printFlags := genericclioptions.NewPrintFlags("")
+	var verbose bool
+	var showSecrets bool
+	var watchChanges bool
+
+	cmd := &cobra.Command{
+		Use:     "describe NAME",
+		Short:   "Show details of a binding",
+		Example: bindingDescribeExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet binding describe' requires the binding name given as single argument")
+			}
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			describe := func(binding *v1alpha1.KameletBinding) error {
+				binding, err := p.newRedactor(showSecrets).redactBinding(binding)
+				if err != nil {
+					return err
+				}
+				if printFlags.OutputFlagSpecified() {
+					return printBindingManifests(printFlags, out, binding)
+				}
+
+				colors := p.colorsEnabled(out)
+				dw := printers.NewPrefixWriter(out)
+				commands.WriteMetadata(dw, &binding.ObjectMeta, verbose)
+				writeBindingEndpoint(dw, "Source", binding.Spec.Source)
+				writeBindingEndpoint(dw, "Sink", binding.Spec.Sink)
+				dw.WriteAttribute("Phase", colorStatus(string(binding.Status.Phase), colors))
+				dw.WriteLine()
+				p.writeIntegration(dw, binding, colors)
+				commands.WriteConditions(dw, bindingConditions(binding.Status.Conditions), true)
+				if verbose {
+					writeConditionDetails(dw, binding.Status.Conditions, colors)
+				}
+				return dw.Flush()
+			}
+
+			if watchChanges {
+				return p.watchBinding(namespace, args[0], describe, out)
+			}
+
+			binding, err := p.getBinding(namespace, args[0])
+			if err != nil {
+				return err
+			}
+			return describe(binding)
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "More output, i.e. all labels and annotations and the transition times of the conditions.")
+	cmd.Flags().BoolVarP(&watchChanges, "watch", "w", false, "After describing the binding, describe it again on each of its changes until interrupted.")
+	addShowSecretsFlag(cmd.Flags(), &showSecrets)
+	printFlags.AddFlags(cmd)
+	return cmd
+}
+
+// getBinding returns the binding of given name, Pipes are converted to KameletBindings
+func (params *KameletPluginParams) getBinding(namespace string, name string) (*v1alpha1.KameletBinding, error) {
+	pipes, err := params.usePipes()
+	if err != nil {
+		return nil, err
+	}
+	if pipes {
+		client, err := params.NewDynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		binding, err := getPipe(params.Context, client, namespace, name)
+		if err != nil {
+			return nil, knerrors.GetError(err)
+		}
+		return binding, nil
+	}
+
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := client.KameletBindings(namespace).Get(params.Context, name, v1.GetOptions{})
+	if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	return binding, nil
+}
+
+// watchBinding describes the binding and then describes it again on each of its changes until the context is
+// canceled, the changes are observed by the same informer as the changes of 'binding list --watch'
+func (params *KameletPluginParams) watchBinding(namespace string, name string, describe func(binding *v1alpha1.KameletBinding) error, out io.Writer) error {
+	pipes, err := params.usePipes()
+	if err != nil {
+		return err
+	}
+
+	selector := v1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()}
+	kind, resource := v1alpha1.KameletBindingKind, v1alpha1.SchemeGroupVersion.WithResource("kameletbindings").GroupResource()
+	var lw *cache.ListWatch
+	var objType runtime.Object
+	if pipes {
+		client, err := params.NewDynamicClient()
+		if err != nil {
+			return err
+		}
+		resources := client.Resource(pipeResource).Namespace(namespace)
+		lw = selectorListWatch(selector, func(opts v1.ListOptions) (runtime.Object, error) {
+			return resources.List(params.Context, opts)
+		}, func(opts v1.ListOptions) (watch.Interface, error) {
+			return resources.Watch(params.Context, opts)
+		})
+		kind, resource, objType = pipeKind, pipeResource.GroupResource(), &unstructured.Unstructured{}
+	} else {
+		client, err := params.NewKameletClient()
+		if err != nil {
+			return err
+		}
+		bindings := client.KameletBindings(namespace)
+		lw = selectorListWatch(selector, func(opts v1.ListOptions) (runtime.Object, error) {
+			return bindings.List(params.Context, opts)
+		}, func(opts v1.ListOptions) (watch.Interface, error) {
+			return bindings.Watch(params.Context, opts)
+		})
+		objType = &v1alpha1.KameletBinding{}
+	}
+
+	initial, err := lw.List(v1.ListOptions{})
+	if err != nil {
+		return knerrors.GetError(err)
+	}
+	items, err := meta.ExtractList(initial)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return knerrors.GetError(apierrors.NewNotFound(resource, name))
+	}
+	binding, err := bindingObject(items[0])
+	if err != nil {
+		return err
+	}
+	if err := describe(binding); err != nil {
+		return err
+	}
+
+	return watchEvents(params.Context, lw, initial, objType, func(eventType watch.EventType, obj runtime.Object) error {
+		fmt.Fprintln(out)
+		if eventType == watch.Deleted {
+			fmt.Fprintf(out, "%s '%s' deleted in namespace '%s'.\n", kind, name, namespace)
+			return nil
+		}
+		binding, err := bindingObject(obj)
+		if err != nil {
+			return err
+		}
+		return describe(binding)
+	})
+}
+
+// bindingObject returns a copy of given KameletBinding or Pipe as KameletBinding, watched objects are shared with the
+// informer cache and must not be modified
+func bindingObject(obj runtime.Object) (*v1alpha1.KameletBinding, error) {
+	switch binding := obj.(type) {
+	case *v1alpha1.KameletBinding:
+		return binding.DeepCopy(), nil
+	case *unstructured.Unstructured:
+		return fromPipe(binding)
+	}
+	return nil, fmt.Errorf("unsupported binding type %T", obj)
+}
+
+// writeBindingEndpoint writes the endpoint and its properties
+func writeBindingEndpoint(dw printers.PrefixWriter, label string, endpoint v1alpha1.Endpoint) {
+	section := dw.WriteAttribute(label, endpointValue(endpoint))
+	properties, err := kameletapi.DecodeEndpointProperties(endpoint.Properties)
+	if err != nil || len(properties) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		section.WriteAttribute(key, properties[key])
+	}
+}
+
+// writeIntegration writes the phase of the integration running the binding and the readiness of its pods, the
+// integration is looked up best effort as it is only created once the binding has been reconciled
+func (params *KameletPluginParams) writeIntegration(dw printers.PrefixWriter, binding *v1alpha1.KameletBinding, colors bool) {
+	if params.NewDynamicClient == nil {
+		return
+	}
+	client, err := params.NewDynamicClient()
+	if err != nil {
+		return
+	}
+	integration, err := client.Resource(integrationResource).Namespace(binding.Namespace).Get(params.Context, binding.Name, v1.GetOptions{})
+	if err != nil {
+		return
+	}
+
+	section := dw.WriteAttribute("Integration", integration.GetName())
+	phase, _, _ := unstructured.NestedString(integration.Object, "status", "phase")
+	section.WriteAttribute("Phase", colorStatus(phase, colors))
+	if ready, total, ok := params.integrationPods(binding.Namespace, binding.Name); ok {
+		section.WriteAttribute("Pods", fmt.Sprintf("%d/%d ready", ready, total))
+	}
+	dw.WriteLine()
+}
+
+// integrationPods returns the number of ready and total pods of the integration
+func (params *KameletPluginParams) integrationPods(namespace string, name string) (int, int, bool) {
+	if params.NewKubeClient == nil {
+		return 0, 0, false
+	}
+	client, err := params.NewKubeClient()
+	if err != nil || client == nil {
+		return 0, 0, false
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(params.Context, v1.ListOptions{LabelSelector: integrationLabel + "=" + name})
+	if err != nil {
+		return 0, 0, false
+	}
+	var ready int
+	for _, pod := range pods.Items {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return ready, len(pods.Items), true
+}
+
+// bindingConditions converts the binding conditions for printing them like the conditions of Knative resources
+func bindingConditions(conditions []v1alpha1.KameletBindingCondition) apis.Conditions {
+	converted := make(apis.Conditions, 0, len(conditions))
+	for _, condition := range conditions {
+		converted = append(converted, apis.Condition{
+			Type:               apis.ConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: apis.VolatileTime{Inner: condition.LastTransitionTime},
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return converted
+}
+
+// writeConditionDetails writes the transition time and the full message of each condition
+func writeConditionDetails(dw printers.PrefixWriter, conditions []v1alpha1.KameletBindingCondition, colors bool) {
+	if len(conditions) == 0 {
+		return
+	}
+	section := dw.WriteAttribute("Condition Details", "")
+	for _, condition := range conditions {
+		details := section.WriteAttribute(string(condition.Type), colorStatus(string(condition.Status), colors))
+		if !condition.LastTransitionTime.IsZero() {
+			details.WriteAttribute("Last Transition", condition.LastTransitionTime.UTC().Format("2006-01-02T15:04:05Z"))
+		}
+		if condition.Reason != "" {
+			details.WriteAttribute("Reason", condition.Reason)
+		}
+		if message := strings.TrimSpace(condition.Message); message != "" {
+			details.WriteAttribute("Message", message)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingDescribe(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: []byte(`{"message":"hello"}`)}
+	binding.Status.Conditions = []camelkapis.KameletBindingCondition{
+		{Type: camelkapis.KameletBindingConditionReady, Status: corev1.ConditionFalse, Reason: "IntegrationNotReady",
+			Message: "0/1 pods ready", LastTransitionTime: v1.NewTime(time.Date(2021, 5, 4, 10, 0, 0, 0, time.UTC))},
+	}
+	binding.Status.Phase = camelkapis.KameletBindingPhaseError
+
+	integration := &unstructured.Unstructured{}
+	integration.SetAPIVersion("camel.apache.org/v1")
+	integration.SetKind("Integration")
+	integration.SetName("b1")
+	integration.SetNamespace("default")
+	_ = unstructured.SetNestedField(integration.Object, "Running", "status", "phase")
+
+	pods := fake.NewSimpleClientset(
+		integrationPod("b1-1", corev1.ConditionTrue),
+		integrationPod("b1-2", corev1.ConditionFalse),
+	)
+	p := &KameletPluginParams{
+		KnParams:          &commands.KnParams{},
+		Context:           context.TODO(),
+		UseKameletBinding: true,
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return mockClient, nil
+		},
+		NewKubeClient: func() (kubernetes.Interface, error) {
+			return pods, nil
+		},
+		NewDynamicClient: func() (dynamic.Interface, error) {
+			return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), integration), nil
+		},
+	}
+
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Name:", "b1", "Source:", "kamelet:k1", "message:", "hello", "Sink:", "broker:default",
+		"Phase:", "Error", "Integration:", "Running", "Pods:", "1/2 ready",
+		"Conditions:", "OK TYPE", "Ready", "IntegrationNotReady (0/1 pods ready)"))
+	assert.Check(t, util.ContainsNone(output, "Condition Details"))
+
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default", "--verbose")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Condition Details:", "Last Transition:", "2021-05-04T10:00:00Z", "Message:", "0/1 pods ready"))
+
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding", "name: b1"))
+
+	recorder.GetBinding(nil, notFound("b2"))
+	_, err = runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b2", "-n", "default")
+	assert.ErrorContains(t, err, "not found")
+
+	recorder.Validate()
+}
+
+func TestBindingDescribeWatch(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Status.Phase = camelkapis.KameletBindingPhaseCreating
+	binding.Status.Conditions = []camelkapis.KameletBindingCondition{{Type: camelkapis.KameletBindingConditionReady, Status: corev1.ConditionFalse, Reason: "IntegrationNotReady"}}
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding}}, nil)
+
+	ready := createKameletBinding("b1", "k1")
+	ready.ResourceVersion = "2"
+	ready.Status.Phase = camelkapis.KameletBindingPhaseReady
+	ready.Status.Conditions = []camelkapis.KameletBindingCondition{{Type: camelkapis.KameletBindingConditionReady, Status: corev1.ConditionTrue}}
+	watcher := watch.NewFakeWithChanSize(2, false)
+	watcher.Modify(ready)
+	watcher.Delete(ready)
+	recorder.WatchBindings(watcher, nil)
+
+	p := bindingListParams(mockClient)
+	p.UseKameletBinding = true
+	output, err := runWatchCmd(p, NewBindingCommand(p), "deleted", "binding", "describe", "b1", "--watch", "--show-secrets")
+	assert.NilError(t, err)
+	descriptions := strings.Split(output, "Name:")
+	assert.Equal(t, len(descriptions), 3)
+	assert.Check(t, util.ContainsAll(descriptions[1], "b1", "Phase:", "Creating", "!! Ready", "IntegrationNotReady"))
+	assert.Check(t, util.ContainsAll(descriptions[2], "b1", "Phase:", "Ready", "++ Ready", "KameletBinding 'b1' deleted"))
+	recorder.Validate()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	_, err = runWatchCmd(p, NewBindingCommand(p), "deleted", "binding", "describe", "b2", "--watch")
+	assert.ErrorContains(t, err, "not found")
+	recorder.Validate()
+}
+
+func integrationPod(name string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{integrationLabel: "b1"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+	}
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/client/pkg/kn/commands"
+	"sigs.k8s.io/yaml"
+)
+
+var bindingDiffExample = `
+  # Show the changes applying given manifest would make to the binding on the cluster
+  kn-source-kamelet binding diff NAME -f binding.yaml
+
+  # Review the changes of a manifest read from stdin
+  cat binding.yaml | kn-source-kamelet binding diff NAME -n events -f -`
+
+// newBindingDiffCommand implements 'kn-source-kamelet binding diff' command
+func newBindingDiffCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var update updateOptions
+
+	cmd := &cobra.Command{
+		Use:   "diff NAME -f FILENAME",
+		Short: "Show the differences between a binding on the cluster and a manifest",
+		Long: `Show the differences between a binding on the cluster and a manifest in unified diff format.
+
+The manifest is merged with the binding on the cluster the same way 'kn-source-kamelet binding create' updates
+existing bindings, i.e. with a three-way merge of the last applied configuration, the manifest and the binding on
+the cluster or, with --force, by replacing the spec, labels and annotations, so the diff shows exactly the changes
+applying the manifest would make.`,
+		Example: bindingDiffExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet binding diff' requires the binding name given as single argument")
+			}
+			if len(filenames) == 0 {
+				return errors.New("missing manifest, use --filename to specify it")
+			}
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			bindings, err := readBindingManifests(filenames, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(bindings) != 1 {
+				return fmt.Errorf("'kn-source-kamelet binding diff' requires a single KameletBinding in given manifests, found %d", len(bindings))
+			}
+			binding := bindings[0]
+			binding.Name = args[0]
+			binding.Namespace = namespace
+
+			pipes, err := p.usePipes()
+			if err != nil {
+				return err
+			}
+			existing, err := p.getBinding(namespace, binding.Name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+
+			desired := binding
+			if existing != nil {
+				if desired, err = update.apply(existing, binding); err != nil {
+					return err
+				}
+			}
+			if existing, desired, err = p.newRedactor(update.ShowSecrets).redactDiff(existing, desired); err != nil {
+				return err
+			}
+			from, err := bindingManifest(existing, pipes)
+			if err != nil {
+				return err
+			}
+			to, err := bindingManifest(desired, pipes)
+			if err != nil {
+				return err
+			}
+			diff, err := manifestDiff(from, to, binding.Name+" (cluster)", binding.Name+" (manifest)")
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if diff == "" {
+				fmt.Fprintf(out, "Binding '%s' in namespace '%s' is up to date.\n", binding.Name, namespace)
+				return nil
+			}
+			_, err = io.WriteString(out, diff)
+			return err
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file with the KameletBinding to compare, use - to read from stdin.")
+	update.addFlags(cmd.Flags())
+	cmd.Flag("force").Usage = "Show the changes of replacing the spec, labels and annotations of the binding on the cluster with the manifest instead of a three-way merge."
+	return cmd
+}
+
+// writeUpdateDiff writes the changes made by updating the existing binding on the cluster, sensitive properties are
+// masked by given redactor
+func writeUpdateDiff(existing *v1alpha1.KameletBinding, desired *v1alpha1.KameletBinding, pipe bool, r *redactor, out io.Writer) error {
+	existing, desired, err := r.redactDiff(existing, desired)
+	if err != nil {
+		return err
+	}
+	from, err := bindingManifest(existing, pipe)
+	if err != nil {
+		return err
+	}
+	to, err := bindingManifest(desired, pipe)
+	if err != nil {
+		return err
+	}
+	diff, err := manifestDiff(from, to, existing.Name+" (cluster)", desired.Name+" (updated)")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, diff)
+	return err
+}
+
+// bindingManifest returns the binding with its type meta set or the binding converted to a Pipe, nil when there
+// is no binding
+func bindingManifest(binding *v1alpha1.KameletBinding, pipe bool) (runtime.Object, error) {
+	if binding == nil {
+		return nil, nil
+	}
+	if pipe {
+		return toPipe(binding)
+	}
+	manifest := binding.DeepCopy()
+	// the client drops the type meta when decoding the resources returned by the cluster
+	manifest.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.KameletBindingKind))
+	return manifest, nil
+}
+
+// manifestDiff renders the differences between the YAML manifests of both resources without status and server
+// generated fields, a nil resource is rendered as an empty manifest
+func manifestDiff(from runtime.Object, to runtime.Object, fromName string, toName string) (string, error) {
+	fromYAML, err := manifestYAML(from)
+	if err != nil {
+		return "", err
+	}
+	toYAML, err := manifestYAML(to)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(fromYAML, toYAML, fromName, toName), nil
+}
+
+func manifestYAML(obj runtime.Object) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	manifest, err := sanitize(obj)
+	if err != nil {
+		return "", err
+	}
+	// the last applied configuration repeats the changes
+	removeLastApplied(manifest)
+	data, err := yaml.Marshal(manifest.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
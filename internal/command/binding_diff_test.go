@@ -0,0 +1,155 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, unifiedDiff("a\nb\n", "a\nb\n", "from", "to"), "")
+
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	to := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	assert.Equal(t, unifiedDiff(from, to, "from", "to"), `--- from
++++ to
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`)
+
+	assert.Equal(t, unifiedDiff("", "a\n", "from", "to"), "--- from\n+++ to\n@@ -0,0 +1,1 @@\n+a\n")
+}
+
+var diffBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b1
+  labels:
+    team: events
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+      namespace: default
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: other
+      namespace: default
+`
+
+func TestBindingDiff(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	output, err := runBindingDiffCmd(mockClient, diffBindingManifest, "b1", "-n", "default", "-f", "-")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "--- b1 (cluster)\n", "+++ b1 (manifest)\n",
+		"+  labels:\n+    team: events\n", "-      name: default\n+      name: other\n"))
+	assert.Check(t, util.ContainsNone(output, "status", "creationTimestamp"))
+
+	existing := createKameletBinding("b1", "k1")
+	existing.Labels = map[string]string{"team": "events"}
+	existing.Spec.Sink.Ref.Name = "other"
+	recorder.GetBinding(existing, nil)
+	output, err = runBindingDiffCmd(mockClient, diffBindingManifest, "b1", "-n", "default", "-f", "-")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Binding 'b1' in namespace 'default' is up to date.\n")
+
+	recorder.GetBinding(nil, notFound("b2"))
+	output, err = runBindingDiffCmd(mockClient, diffBindingManifest, "b2", "-n", "default", "-f", "-")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "@@ -0,0 +1,", "+kind: KameletBinding\n", "+  name: b2\n"))
+	assert.Check(t, util.ContainsNone(output, "\n-"))
+
+	recorder.Validate()
+}
+
+func TestBindingDiffErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingDiffCmd(mockClient, diffBindingManifest, "-f", "-")
+	assert.Error(t, err, "'kn-source-kamelet binding diff' requires the binding name given as single argument")
+
+	_, err = runBindingDiffCmd(mockClient, diffBindingManifest, "b1")
+	assert.Error(t, err, "missing manifest, use --filename to specify it")
+
+	_, err = runBindingDiffCmd(mockClient, diffBindingManifest+"---\n"+diffBindingManifest, "b1", "-f", "-")
+	assert.Error(t, err, "'kn-source-kamelet binding diff' requires a single KameletBinding in given manifests, found 2")
+	recorder.Validate()
+}
+
+func TestBindingCreateShowsDiffOfExistingBinding(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	existing := createKameletBindingInNamespace("b1", "k1", "current")
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(existing, nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Channel")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindingCreateCmd(mockClient, "b1", "--kamelet", "k1", "--channel", "events", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "--- b1 (cluster)\n+++ b1 (updated)\n",
+		"-      kind: Broker\n", "+      kind: Channel\n", "KameletBinding 'b1' updated in namespace 'current'."))
+	recorder.Validate()
+}
+
+func runBindingDiffCmd(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs(append([]string{"binding", "diff"}, options...))
+	bindingCmd.SetIn(strings.NewReader(input))
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
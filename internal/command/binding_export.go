@@ -0,0 +1,261 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/client/pkg/kn/commands"
+	"sigs.k8s.io/yaml"
+)
+
+var bindingExportExample = `
+  # Print given binding as YAML manifest without status and server generated fields
+  kn-source-kamelet binding export NAME
+
+  # Write all bindings of the namespace to directory 'bindings', one file per binding
+  kn-source-kamelet binding export --all --dir bindings
+
+  # Write given bindings and a kustomization.yaml listing them to directory 'base'
+  kn-source-kamelet binding export NAME1 NAME2 --format kustomize --dir base
+
+  # Write a Helm chart with values for the source properties and the sink of given binding to directory 'chart'
+  kn-source-kamelet binding export NAME --format helm --dir chart`
+
+// exportFormats lists the formats supported by 'kn-source-kamelet binding export'
+var exportFormats = []string{"yaml", "kustomize", "helm"}
+
+// exportFile is a file generated by 'kn-source-kamelet binding export'
+type exportFile struct {
+	name    string
+	content []byte
+}
+
+// newBindingExportCommand implements 'kn-source-kamelet binding export' command
+func newBindingExportCommand(p *KameletPluginParams) *cobra.Command {
+	var format string
+	var dir string
+	var all bool
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "export NAME...|--all",
+		Short: "Export bindings as manifests ready to be committed into git",
+		Long: `Export bindings as manifests ready to be committed into git.
+
+The status and server generated fields are removed from the exported bindings. The kustomize format adds a
+kustomization.yaml listing the bindings as resources, the namespace of the bindings is set by the kustomization
+instead of the binding manifests. The helm format wraps the bindings into a minimal Helm chart, the source
+properties and the sink of each binding are given by the chart values.`,
+		Example: bindingExportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return errors.New("'kn-source-kamelet binding export' exports either given bindings or all bindings with --all")
+			}
+			if !all && len(args) == 0 {
+				return errors.New("'kn-source-kamelet binding export' requires the name of at least one binding or --all")
+			}
+			if !containsString(exportFormats, format) {
+				return fmt.Errorf("invalid export format %q, expected one of: yaml|kustomize|helm", format)
+			}
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+			pipes, err := p.usePipes()
+			if err != nil {
+				return err
+			}
+
+			var bindings []*v1alpha1.KameletBinding
+			if all {
+				bindingList, err := p.listBindings(namespace)
+				if err != nil {
+					return err
+				}
+				if len(bindingList) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+					return nil
+				}
+				// sorted by name so that repeated exports only differ in the changed bindings
+				sort.Slice(bindingList, func(i, j int) bool {
+					return bindingList[i].Name < bindingList[j].Name
+				})
+				for i := range bindingList {
+					bindings = append(bindings, &bindingList[i])
+				}
+			}
+			// failed bindings are reported after the others have been exported
+			var failed []error
+			if len(args) > 0 {
+				named := make([]*v1alpha1.KameletBinding, len(args))
+				errs, err := runConcurrently(concurrency, len(args), func(i int) (err error) {
+					named[i], err = p.getBinding(namespace, args[i])
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				for i := range named {
+					if errs[i] == nil {
+						bindings = append(bindings, named[i])
+					}
+				}
+				failed = nonNilErrors(errs)
+			}
+
+			manifests := make([]*unstructured.Unstructured, len(bindings))
+			errs, err := runConcurrently(concurrency, len(bindings), func(i int) (err error) {
+				manifests[i], err = exportManifest(bindings[i], pipes)
+				if err != nil {
+					return fmt.Errorf("failed to export binding '%s': %w", bindings[i].Name, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			exported := make([]*unstructured.Unstructured, 0, len(manifests))
+			for i := range manifests {
+				if errs[i] == nil {
+					exported = append(exported, manifests[i])
+				}
+			}
+			failed = append(failed, nonNilErrors(errs)...)
+			if len(exported) == 0 {
+				return aggregateErrors(failed)
+			}
+
+			files, err := exportBindings(exported, namespace, format)
+			if err != nil {
+				return err
+			}
+			failed = append(failed, writeExport(cmd.OutOrStdout(), files, dir, format != "yaml", concurrency))
+			return aggregateErrors(failed)
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringVar(&format, "format", "yaml", "Format of the export, one of: yaml|kustomize|helm.")
+	cmd.Flags().BoolVar(&all, "all", false, "Export all bindings of the namespace.")
+	cmd.Flags().StringVar(&dir, "dir", "", "Write the exported files to given directory instead of stdout.")
+	addConcurrencyFlag(cmd.Flags(), &concurrency)
+	return cmd
+}
+
+// exportBindings renders the files of given format from the exported manifests, i.e. one manifest per binding plus
+// the kustomization.yaml listing them for the kustomize format or the chart templates and values for the helm format
+func exportBindings(manifests []*unstructured.Unstructured, namespace string, format string) ([]exportFile, error) {
+	if format != "yaml" {
+		for _, manifest := range manifests {
+			// the namespace is given by the kustomization or the Helm release
+			unstructured.RemoveNestedField(manifest.Object, "metadata", "namespace")
+		}
+	}
+	if format == "helm" {
+		return helmChart(manifests, namespace)
+	}
+
+	files := make([]exportFile, 0, len(manifests)+1)
+	resources := make([]string, 0, len(manifests))
+	for _, manifest := range manifests {
+		content, err := yaml.Marshal(manifest.Object)
+		if err != nil {
+			return nil, err
+		}
+		name := manifest.GetName() + ".yaml"
+		files = append(files, exportFile{name: name, content: content})
+		resources = append(resources, name)
+	}
+
+	if format == "kustomize" {
+		content, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind":       "Kustomization",
+			"namespace":  namespace,
+			"resources":  resources,
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, exportFile{name: "kustomization.yaml", content: content})
+	}
+	return files, nil
+}
+
+// exportManifest returns the manifest of the binding without status, server generated fields and the last applied
+// configuration
+func exportManifest(binding *v1alpha1.KameletBinding, pipe bool) (*unstructured.Unstructured, error) {
+	obj, err := bindingManifest(binding, pipe)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := sanitize(obj)
+	if err != nil {
+		return nil, err
+	}
+	removeLastApplied(manifest)
+	return manifest, nil
+}
+
+// writeExport writes the files to given directory or prints them as YAML stream, the name of each file is printed as
+// comment when the format generates more than the binding manifests. Files are written by at most concurrency workers,
+// the errors of all failed files are reported.
+func writeExport(out io.Writer, files []exportFile, dir string, withSource bool, concurrency int) error {
+	if dir != "" {
+		errs, err := runConcurrently(concurrency, len(files), func(i int) error {
+			path := filepath.Join(dir, files[i].name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create export directory: %w", err)
+			}
+			if err := ioutil.WriteFile(path, files[i].content, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i, file := range files {
+			if errs[i] == nil {
+				fmt.Fprintf(out, "Wrote %s.\n", filepath.Join(dir, file.name))
+			}
+		}
+		return aggregateErrors(errs)
+	}
+
+	for i, file := range files {
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		if withSource {
+			fmt.Fprintf(out, "# Source: %s\n", file.name)
+		}
+		if _, err := out.Write(file.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
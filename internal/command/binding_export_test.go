@@ -0,0 +1,222 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingExport(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	output, err := runBindingExportCmd(mockClient, "b1", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding\n", "  name: b1\n", "  namespace: default\n", "name: k1\n"))
+	assert.Check(t, util.ContainsNone(output, "status", "creationTimestamp", "# Source", "---"))
+
+	recorder.GetBinding(nil, notFound("b2"))
+	_, err = runBindingExportCmd(mockClient, "b2", "-n", "default")
+	assert.ErrorContains(t, err, "\"b2\" not found")
+
+	recorder.Validate()
+}
+
+func TestBindingExportAll(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{
+		*createKameletBinding("b2", "k2"),
+		*createKameletBinding("b1", "k1"),
+	}}
+	recorder.ListBindings(bindingList, nil)
+	output, err := runBindingExportCmd(mockClient, "--all", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "  name: b1\n", "---\n", "  name: b2\n"))
+	assert.Check(t, strings.Index(output, "name: b1") < strings.Index(output, "name: b2"))
+	assert.Check(t, util.ContainsNone(output, "status", "creationTimestamp", "# Source"))
+
+	dir := t.TempDir()
+	recorder.ListBindings(bindingList, nil)
+	output, err = runBindingExportCmd(mockClient, "--all", "-n", "default", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Wrote "+filepath.Join(dir, "b1.yaml")+".\nWrote "+filepath.Join(dir, "b2.yaml")+".\n")
+	manifest, err := ioutil.ReadFile(filepath.Join(dir, "b2.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(manifest), "kind: KameletBinding\n", "  name: b2\n"))
+	assert.Check(t, util.ContainsNone(string(manifest), "---", "b1"))
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	output, err = runBindingExportCmd(mockClient, "--all", "-n", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "No resources found.\n")
+
+	recorder.Validate()
+}
+
+func TestBindingExportErrorCollected(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	// the missing binding does not stop exporting the others
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.GetBinding(nil, notFound("b2"))
+	recorder.GetBinding(createKameletBinding("b3", "k1"), nil)
+	dir := t.TempDir()
+	output, err := runBindingExportCmd(mockClient, "b1", "b2", "b3", "-n", "default", "--dir", dir, "--concurrency", "1")
+	assert.ErrorContains(t, err, "\"b2\" not found")
+	assert.Check(t, strings.HasPrefix(output, "Wrote "+filepath.Join(dir, "b1.yaml")+".\nWrote "+filepath.Join(dir, "b3.yaml")+".\n"))
+	assert.Check(t, util.ContainsNone(output, "b2.yaml"))
+
+	recorder.GetBinding(nil, notFound("b1"))
+	recorder.GetBinding(nil, notFound("b2"))
+	_, err = runBindingExportCmd(mockClient, "b1", "b2", "-n", "default", "--concurrency", "1")
+	assert.ErrorContains(t, err, "\"b1\" not found")
+	assert.ErrorContains(t, err, "\"b2\" not found")
+	recorder.Validate()
+}
+
+func TestBindingExportKustomize(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.GetBinding(createKameletBinding("b2", "k2"), nil)
+	output, err := runBindingExportCmd(mockClient, "b1", "b2", "-n", "default", "--format", "kustomize", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "# Source: b1.yaml\n", "---\n# Source: b2.yaml\n", "---\n# Source: kustomization.yaml\n"))
+	assert.Check(t, util.ContainsAll(output, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: default
+resources:
+- b1.yaml
+- b2.yaml
+`))
+	assert.Check(t, util.ContainsNone(output, "  namespace: default\n  name: b1"))
+
+	dir := t.TempDir()
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	output, err = runBindingExportCmd(mockClient, "b1", "-n", "default", "--format", "kustomize", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Wrote "+filepath.Join(dir, "b1.yaml"), "Wrote "+filepath.Join(dir, "kustomization.yaml")))
+
+	manifest, err := ioutil.ReadFile(filepath.Join(dir, "b1.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(manifest), "kind: KameletBinding\n", "  name: b1\n"))
+	assert.Check(t, util.ContainsNone(string(manifest), "# Source", "status:"))
+	kustomization, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(kustomization), "kind: Kustomization\n", "- b1.yaml\n"))
+
+	recorder.Validate()
+}
+
+func TestBindingExportHelm(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: []byte(`{"message":"hello"}`)}
+	recorder.GetBinding(binding, nil)
+	dir := t.TempDir()
+	output, err := runBindingExportCmd(mockClient, "b1", "-n", "default", "--format", "helm", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Wrote "+filepath.Join(dir, "Chart.yaml"),
+		"Wrote "+filepath.Join(dir, "templates", "b1.yaml"), "Wrote "+filepath.Join(dir, "values.yaml")))
+
+	chart, err := ioutil.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(chart), "apiVersion: v2\n", "name: b1\n", "version: 0.1.0\n"))
+
+	template, err := ioutil.ReadFile(filepath.Join(dir, "templates", "b1.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(template), "kind: KameletBinding\n", `  sink:
+    {{- toYaml (index .Values "b1").sink | nindent 4 }}
+`, `    {{- with (index .Values "b1").source.properties }}
+    properties:
+      {{- toYaml . | nindent 6 }}
+    {{- end }}
+`))
+	assert.Check(t, util.ContainsNone(string(template), "hello", "Broker", "HELM_"))
+
+	values, err := ioutil.ReadFile(filepath.Join(dir, "values.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(values), `b1:
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: default
+      namespace: default
+  source:
+    properties:
+      message: hello
+`)
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.GetBinding(createKameletBinding("b2", "k2"), nil)
+	output, err = runBindingExportCmd(mockClient, "b1", "b2", "-n", "default", "--format", "helm", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "# Source: Chart.yaml\n", "name: default\n", "# Source: templates/b1.yaml\n",
+		"# Source: templates/b2.yaml\n", "# Source: values.yaml\n", "b2:\n  sink:\n", "  source:\n    properties: {}\n"))
+
+	recorder.Validate()
+}
+
+func TestBindingExportErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingExportCmd(mockClient)
+	assert.Error(t, err, "'kn-source-kamelet binding export' requires the name of at least one binding or --all")
+
+	_, err = runBindingExportCmd(mockClient, "b1", "--all")
+	assert.Error(t, err, "'kn-source-kamelet binding export' exports either given bindings or all bindings with --all")
+
+	_, err = runBindingExportCmd(mockClient, "b1", "--format", "json")
+	assert.Error(t, err, "invalid export format \"json\", expected one of: yaml|kustomize|helm")
+	recorder.Validate()
+}
+
+func runBindingExportCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs(append([]string{"binding", "export"}, options...))
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
@@ -0,0 +1,392 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"knative.dev/client/pkg/kn/commands"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	"sigs.k8s.io/yaml"
+)
+
+var bindingLintExample = `
+  # Lint the bindings of given directory, e.g. as merge check of a GitOps repository
+  kn-source-kamelet binding lint -f bindings/
+
+  # Lint all bindings of the current namespace
+  kn-source-kamelet binding lint
+
+  # Fail on bindings without error handler and require a team label
+  kn-source-kamelet binding lint -f bindings/ --rule missing-error-handler=error --require-label team`
+
+// severityOff disables a lint rule
+const severityOff = "off"
+
+// lintRule is a check of 'binding lint' with the severity of its findings unless configured otherwise
+type lintRule struct {
+	name        string
+	severity    string
+	description string
+}
+
+// lintRules are the rules of 'binding lint'
+var lintRules = []lintRule{
+	{"deprecated-kamelet", severityWarning, "the source or sink Kamelet is deprecated"},
+	{"plaintext-credentials", severityError, "a credential property is given as plain value instead of a property placeholder"},
+	{"missing-error-handler", severityWarning, "the manifest configures no spec.errorHandler"},
+	{"missing-labels", severityWarning, "the binding has no labels or misses a required label"},
+}
+
+// credentialPropertyPattern matches the names of properties holding credentials when the Kamelet does not mark them
+var credentialPropertyPattern = regexp.MustCompile(`(?i)(password|secret|token|accesskey|apikey)`)
+
+// lintResult holds the lint findings of a binding, the source is empty for bindings read from the cluster
+type lintResult struct {
+	Source    string    `json:"source,omitempty"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Passed    bool      `json:"passed"`
+	Findings  []finding `json:"findings,omitempty"`
+}
+
+// lintOptions holds the rule severities and required labels of a lint run
+type lintOptions struct {
+	severities     map[string]string
+	requiredLabels []string
+	offline        bool
+}
+
+// newBindingLintCommand implements 'kn-source-kamelet binding lint' command
+func newBindingLintCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var offline bool
+	var rules []string
+	var requiredLabels []string
+	var output string
+
+	var ruleHelp []string
+	for _, rule := range lintRules {
+		ruleHelp = append(ruleHelp, fmt.Sprintf("  %-22s %-8s %s", rule.name, rule.severity, rule.description))
+	}
+
+	cmd := &cobra.Command{
+		Use:   "lint [NAME...|-f FILENAME]",
+		Short: "Check bindings against best practice rules",
+		Long: `Check bindings against best practice rules.
+
+Lints the bindings of given manifests, the bindings of given names or all bindings of the namespace. The severity of
+each rule can be set to error, warning or off by the lint section of the config file or the --rule flag. The command
+fails when any binding has a finding of severity error. The error handler is only known for manifests as it is not
+part of the KameletBinding API of the plugin, bindings read from the cluster are not checked for it.
+
+Rules (name, default severity, description):
+` + strings.Join(ruleHelp, "\n"),
+		Example: bindingLintExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(filenames) > 0 && len(args) > 0 {
+				return errors.New("'kn-source-kamelet binding lint' lints either given manifests or given bindings")
+			}
+			if offline && len(filenames) == 0 {
+				return errors.New("--offline requires manifests given by --filename")
+			}
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("invalid output format %q, expected one of: json|yaml", output)
+			}
+			options, err := p.lintOptions(rules, requiredLabels)
+			if err != nil {
+				return err
+			}
+			options.offline = offline
+
+			var namespace string
+			if offline {
+				namespace, err = p.offlineNamespace(cmd)
+			} else {
+				namespace, err = p.GetNamespace(cmd)
+			}
+			if err != nil {
+				return err
+			}
+
+			var documents []bindingDocument
+			switch {
+			case len(filenames) > 0:
+				if documents, err = readBindingDocuments(filenames, cmd.InOrStdin()); err != nil {
+					return err
+				}
+				if len(documents) == 0 {
+					return errors.New("no KameletBinding found in given manifests")
+				}
+			case len(args) > 0:
+				for _, name := range args {
+					binding, err := p.getBinding(namespace, name)
+					if err != nil {
+						return err
+					}
+					documents = append(documents, bindingDocument{binding: binding})
+				}
+			default:
+				bindings, err := p.listBindings(namespace)
+				if err != nil {
+					return err
+				}
+				if len(bindings) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+					return nil
+				}
+				sort.Slice(bindings, func(i, j int) bool {
+					return bindings[i].Name < bindings[j].Name
+				})
+				for i := range bindings {
+					documents = append(documents, bindingDocument{binding: &bindings[i]})
+				}
+			}
+
+			results := make([]lintResult, 0, len(documents))
+			failed := 0
+			for _, document := range documents {
+				result, err := p.lintBinding(document, namespace, options)
+				if err != nil {
+					return err
+				}
+				if !result.Passed {
+					failed++
+				}
+				results = append(results, result)
+			}
+
+			if err := writeLintResults(cmd.OutOrStdout(), results, output); err != nil {
+				return err
+			}
+			if failed > 0 {
+				// the findings are the relevant output, not the usage
+				cmd.SilenceUsage = true
+				return withExitCode(ExitCodeValidation, fmt.Errorf("%d of %d KameletBindings failed the lint rules", failed, len(results)))
+			}
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file or directory with the KameletBindings to lint, use - to read from stdin.")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Look up the Kamelets of the manifests in the local cache or the bundled Kamelet catalog without accessing the cluster.")
+	cmd.Flags().StringArrayVar(&rules, "rule", nil, "Severity of a lint rule in the form of <rule>=<error|warning|off>, overrides the config file. Can be given multiple times.")
+	cmd.Flags().StringSliceVar(&requiredLabels, "require-label", nil, "Label every binding has to carry, adds to the required labels of the config file. Can be given multiple times.")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format of the lint results. One of: json|yaml.")
+	return cmd
+}
+
+// lintOptions merges the default rule severities with the lint section of the config file and the rules given by flag
+func (params *KameletPluginParams) lintOptions(rules []string, requiredLabels []string) (lintOptions, error) {
+	config, err := params.pluginConfig()
+	if err != nil {
+		return lintOptions{}, err
+	}
+	options := lintOptions{severities: map[string]string{}}
+	for _, rule := range lintRules {
+		options.severities[rule.name] = rule.severity
+	}
+
+	names := make([]string, 0, len(config.Lint.Rules))
+	for name := range config.Lint.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := options.setSeverity(name, config.Lint.Rules[name]); err != nil {
+			return lintOptions{}, err
+		}
+	}
+	for _, rule := range rules {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			return lintOptions{}, fmt.Errorf("invalid rule %q, expected <rule>=<error|warning|off>", rule)
+		}
+		if err := options.setSeverity(parts[0], parts[1]); err != nil {
+			return lintOptions{}, err
+		}
+	}
+
+	options.requiredLabels = append(options.requiredLabels, config.Lint.RequiredLabels...)
+	for _, label := range requiredLabels {
+		if !containsString(options.requiredLabels, label) {
+			options.requiredLabels = append(options.requiredLabels, label)
+		}
+	}
+	return options, nil
+}
+
+// setSeverity sets the severity of a known lint rule
+func (options lintOptions) setSeverity(name string, severity string) error {
+	if _, ok := options.severities[name]; !ok {
+		names := make([]string, 0, len(lintRules))
+		for _, rule := range lintRules {
+			names = append(names, rule.name)
+		}
+		return fmt.Errorf("unknown lint rule %q, expected one of: %s", name, strings.Join(names, "|"))
+	}
+	if severity != severityError && severity != severityWarning && severity != severityOff {
+		return fmt.Errorf("invalid severity %q of lint rule %q, expected one of: error|warning|off", severity, name)
+	}
+	options.severities[name] = severity
+	return nil
+}
+
+// lintBinding checks the binding of the document against the enabled lint rules
+func (params *KameletPluginParams) lintBinding(document bindingDocument, namespace string, options lintOptions) (lintResult, error) {
+	binding := document.binding
+	if binding.Namespace == "" {
+		binding.Namespace = namespace
+	}
+	result := lintResult{Source: document.source, Name: binding.Name, Namespace: binding.Namespace, Passed: true}
+	report := func(rule string, path *field.Path, message string) {
+		severity := options.severities[rule]
+		if severity == severityOff {
+			return
+		}
+		if severity == severityError {
+			result.Passed = false
+		}
+		result.Findings = append(result.Findings, finding{Severity: severity, Field: path.String(), Message: message, Rule: rule})
+	}
+
+	labelsPath := field.NewPath("metadata", "labels")
+	if len(binding.Labels) == 0 && len(options.requiredLabels) == 0 {
+		report("missing-labels", labelsPath, "binding has no labels")
+	}
+	for _, label := range options.requiredLabels {
+		if _, ok := binding.Labels[label]; !ok {
+			report("missing-labels", labelsPath.Key(label), "missing required label")
+		}
+	}
+
+	// bindings read from the cluster lose the error handler on decoding
+	if document.source != "" && !document.errorHandler {
+		report("missing-error-handler", field.NewPath("spec", "errorHandler"), "no error handler configured, failing events are only logged")
+	}
+
+	for _, endpoint := range []struct {
+		kameletType string
+		endpoint    v1alpha1.Endpoint
+	}{{"source", binding.Spec.Source}, {"sink", binding.Spec.Sink}} {
+		path := field.NewPath("spec", endpoint.kameletType)
+		kamelet, err := params.lintKamelet(endpoint.endpoint, binding.Namespace, options.offline)
+		if err != nil {
+			return result, err
+		}
+		if kamelet != nil && strings.EqualFold(kamelet.Annotations[supportLevelAnnotation], "deprecated") {
+			report("deprecated-kamelet", path.Child("ref", "name"), fmt.Sprintf("Kamelet %q is deprecated", kamelet.Name))
+		}
+
+		properties, err := kameletapi.DecodeEndpointProperties(endpoint.endpoint.Properties)
+		if err != nil {
+			// malformed properties are reported by 'binding validate'
+			continue
+		}
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if kameletapi.IsPropertyReference(properties[name]) || !isCredentialProperty(kamelet, name) {
+				continue
+			}
+			report("plaintext-credentials", path.Child("properties").Key(name),
+				"credential given as plain value, use a property placeholder resolved from a secret instead")
+		}
+	}
+	return result, nil
+}
+
+// lintKamelet returns the Kamelet referenced by the endpoint or nil when the endpoint does not reference a Kamelet or
+// the Kamelet is unknown, unknown Kamelets are reported by 'binding validate'
+func (params *KameletPluginParams) lintKamelet(endpoint v1alpha1.Endpoint, namespace string, offline bool) (*v1alpha1.Kamelet, error) {
+	ref := endpoint.Ref
+	if ref == nil || ref.Kind != v1alpha1.KameletKind || ref.Name == "" {
+		return nil, nil
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	if offline {
+		kamelet, _ := params.offlineKamelet(namespace, ref.Name)
+		return kamelet, nil
+	}
+	kamelet, err := params.getKamelet(namespace, ref.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return kamelet, err
+}
+
+// isCredentialProperty checks if the property is marked as password by the Kamelet or, if the Kamelet does not define
+// the property, its name suggests a credential
+func isCredentialProperty(kamelet *v1alpha1.Kamelet, name string) bool {
+	if kamelet != nil && kamelet.Spec.Definition != nil {
+		if schema, ok := kamelet.Spec.Definition.Properties[name]; ok {
+			return isPasswordProperty(schema)
+		}
+	}
+	return credentialPropertyPattern.MatchString(name)
+}
+
+// writeLintResults prints the results in given format, by default as one line per binding followed by its findings
+func writeLintResults(out io.Writer, results []lintResult, output string) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	for _, result := range results {
+		status := "passed"
+		if !result.Passed {
+			status = "failed"
+		}
+		if result.Source != "" {
+			fmt.Fprintf(out, "%s: KameletBinding '%s' %s.\n", result.Source, result.Name, status)
+		} else {
+			fmt.Fprintf(out, "KameletBinding '%s' in namespace '%s' %s.\n", result.Name, result.Namespace, status)
+		}
+		for _, f := range result.Findings {
+			fmt.Fprintf(out, "  %s: %s: %s [%s]\n", f.Severity, f.Field, f.Message, f.Rule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var lintBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b1
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+    properties:
+      accessKey: "{{aws.accessKey}}"
+      password: secret
+      token: abc
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: default
+`
+
+var lintedBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b2
+  labels:
+    team: events
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: default
+  errorHandler:
+    log: {}
+`
+
+func lintedKamelet() *camelkapis.Kamelet {
+	kamelet := createKamelet("k1")
+	kamelet.Annotations = map[string]string{supportLevelAnnotation: "Deprecated"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"password": {Type: "string", Format: "password"},
+		"token":    {Type: "string"},
+	}
+	return kamelet
+}
+
+func TestBindingLint(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(lintedKamelet(), nil)
+	output, err := runBindingLintCmd(mockClient, lintBindingManifest, "-f", "-")
+	assert.Error(t, err, "1 of 1 KameletBindings failed the lint rules")
+	assert.Equal(t, output, `stdin: KameletBinding 'b1' failed.
+  warning: metadata.labels: binding has no labels [missing-labels]
+  warning: spec.errorHandler: no error handler configured, failing events are only logged [missing-error-handler]
+  warning: spec.source.ref.name: Kamelet "k1" is deprecated [deprecated-kamelet]
+  error: spec.source.properties[password]: credential given as plain value, use a property placeholder resolved from a secret instead [plaintext-credentials]
+`)
+
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runBindingLintCmd(mockClient, lintedBindingManifest, "-f", "-")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "stdin: KameletBinding 'b2' passed.\n")
+
+	recorder.Get(nil, notFound("k1"))
+	output, err = runBindingLintCmd(mockClient, lintedBindingManifest, "-f", "-", "--require-label", "owner,team")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "stdin: KameletBinding 'b2' passed.\n  warning: metadata.labels[owner]: missing required label [missing-labels]\n")
+
+	recorder.Validate()
+}
+
+func TestBindingLintRules(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(lintedKamelet(), nil)
+	output, err := runBindingLintCmd(mockClient, lintBindingManifest, "-f", "-", "-o", "json",
+		"--rule", "plaintext-credentials=warning", "--rule", "deprecated-kamelet=off", "--rule", "missing-labels=error")
+	assert.Error(t, err, "1 of 1 KameletBindings failed the lint rules")
+
+	var results []lintResult
+	assert.NilError(t, json.Unmarshal([]byte(output), &results))
+	assert.Equal(t, len(results), 1)
+	assert.Check(t, !results[0].Passed)
+	assert.DeepEqual(t, results[0].Findings, []finding{
+		{Severity: severityError, Field: "metadata.labels", Message: "binding has no labels", Rule: "missing-labels"},
+		{Severity: severityWarning, Field: "spec.errorHandler", Message: "no error handler configured, failing events are only logged", Rule: "missing-error-handler"},
+		{Severity: severityWarning, Field: "spec.source.properties[password]", Message: "credential given as plain value, use a property placeholder resolved from a secret instead", Rule: "plaintext-credentials"},
+	})
+
+	// properties unknown to the Kamelet are matched by name
+	output, err = runBindingLintCmd(mockClient, strings.Replace(lintBindingManifest, "k1", "timer-source", 1), "-f", "-", "--offline",
+		"--rule", "missing-labels=off", "--rule", "missing-error-handler=off")
+	assert.Error(t, err, "1 of 1 KameletBindings failed the lint rules")
+	assert.Equal(t, output, `stdin: KameletBinding 'b1' failed.
+  error: spec.source.properties[password]: credential given as plain value, use a property placeholder resolved from a secret instead [plaintext-credentials]
+  error: spec.source.properties[token]: credential given as plain value, use a property placeholder resolved from a secret instead [plaintext-credentials]
+`)
+
+	recorder.Validate()
+}
+
+func TestBindingLintLive(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	labeled := createKameletBinding("b2", "k1")
+	labeled.Labels = map[string]string{"team": "events"}
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*labeled, *createKameletBinding("b1", "k1")}}, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	output, err := runBindingLintCmd(mockClient, "", "-n", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, output, `KameletBinding 'b1' in namespace 'default' passed.
+  warning: metadata.labels: binding has no labels [missing-labels]
+KameletBinding 'b2' in namespace 'default' passed.
+`)
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runBindingLintCmd(mockClient, "", "b1", "-n", "default", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "- findings:\n", "    rule: missing-labels\n", "  name: b1\n", "  passed: true\n"))
+	assert.Check(t, util.ContainsNone(output, "source:"))
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	output, err = runBindingLintCmd(mockClient, "")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "No resources found.\n")
+
+	recorder.Validate()
+}
+
+func TestBindingLintConfig(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	config := &PluginConfig{Lint: LintConfig{
+		Rules:          map[string]string{"missing-error-handler": "error"},
+		RequiredLabels: []string{"owner"},
+	}}
+	recorder.Get(createKamelet("k1"), nil)
+	output, err := runBindingLintCmdWithConfig(mockClient, config, strings.Replace(lintedBindingManifest, "  errorHandler:\n    log: {}\n", "", 1), "-f", "-")
+	assert.Error(t, err, "1 of 1 KameletBindings failed the lint rules")
+	assert.Equal(t, output, `stdin: KameletBinding 'b2' failed.
+  warning: metadata.labels[owner]: missing required label [missing-labels]
+  error: spec.errorHandler: no error handler configured, failing events are only logged [missing-error-handler]
+`)
+
+	// flags take precedence over the config file
+	recorder.Get(createKamelet("k1"), nil)
+	_, err = runBindingLintCmdWithConfig(mockClient, config, strings.Replace(lintedBindingManifest, "  errorHandler:\n    log: {}\n", "", 1), "-f", "-",
+		"--rule", "missing-error-handler=warning")
+	assert.NilError(t, err)
+
+	config.Lint.Rules["missing-handler"] = "error"
+	_, err = runBindingLintCmdWithConfig(mockClient, config, lintedBindingManifest, "-f", "-")
+	assert.Error(t, err, "unknown lint rule \"missing-handler\", expected one of: deprecated-kamelet|plaintext-credentials|missing-error-handler|missing-labels")
+
+	recorder.Validate()
+}
+
+func TestBindingLintErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingLintCmd(mockClient, "", "b1", "-f", "-")
+	assert.Error(t, err, "'kn-source-kamelet binding lint' lints either given manifests or given bindings")
+
+	_, err = runBindingLintCmd(mockClient, "", "--offline")
+	assert.Error(t, err, "--offline requires manifests given by --filename")
+
+	_, err = runBindingLintCmd(mockClient, lintBindingManifest, "-f", "-", "--rule", "missing-labels")
+	assert.Error(t, err, "invalid rule \"missing-labels\", expected <rule>=<error|warning|off>")
+
+	_, err = runBindingLintCmd(mockClient, lintBindingManifest, "-f", "-", "--rule", "missing-labels=fatal")
+	assert.Error(t, err, "invalid severity \"fatal\" of lint rule \"missing-labels\", expected one of: error|warning|off")
+
+	_, err = runBindingLintCmd(mockClient, lintBindingManifest, "-f", "-", "-o", "wide")
+	assert.Error(t, err, "invalid output format \"wide\", expected one of: json|yaml")
+
+	_, err = runBindingLintCmd(mockClient, "kind: ConfigMap", "-f", "-")
+	assert.Error(t, err, "no KameletBinding found in given manifests")
+	recorder.Validate()
+}
+
+func runBindingLintCmd(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	return runBindingLintCmdWithConfig(c, &PluginConfig{}, input, options...)
+}
+
+func runBindingLintCmdWithConfig(c *kamelettesting.MockKameletClient, config *PluginConfig, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+		Config: config,
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs(append([]string{"binding", "lint"}, options...))
+	bindingCmd.SetIn(strings.NewReader(input))
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/kn/commands/flags"
+	hprinters "knative.dev/client/pkg/printers"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingListExample = `
+  # List KameletBindings in the current namespace
+  kn-source-kamelet binding list
+
+  # List KameletBindings in all namespaces
+  kn-source-kamelet binding list --all-namespaces
+
+  # List KameletBindings with given label
+  kn-source-kamelet binding list -l team=integration
+
+  # List KameletBindings sorted by phase
+  kn-source-kamelet binding list --sort-by phase
+
+  # List KameletBindings with their creation timestamp instead of their age
+  kn-source-kamelet binding list --show-timestamps
+
+  # List KameletBindings and watch their phase and readiness change
+  kn-source-kamelet binding list --watch
+
+  # List KameletBindings in YAML output format
+  kn-source-kamelet binding list -o yaml`
+
+// newBindingListCommand implements 'kn-source-kamelet binding list' command
+func newBindingListCommand(p *KameletPluginParams) *cobra.Command {
+	bindingListFlags := flags.NewListPrintFlags(BindingListHandlers)
+	var selector, sortBy string
+	var watchChanges, showTimestamps bool
+	var showSecrets bool
+	var chunkSize int64
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List KameletBindings",
+		Aliases: []string{"ls"},
+		Example: bindingListExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			listOptions, err := selectorListOptions(selector)
+			if err != nil {
+				return err
+			}
+			sortPath, err := parseSortBy(sortBy)
+			if err != nil {
+				return err
+			}
+
+			if showTimestamps {
+				bindingListFlags.PrinterHandler = bindingListTimestampHandlers
+			}
+
+			pipes, err := p.usePipes()
+			if err != nil {
+				return err
+			}
+			if pipes {
+				return listPipes(p, namespace, listOptions, chunkSize, sortPath, watchChanges, showTimestamps, p.newRedactor(showSecrets), bindingListFlags, cmd.OutOrStdout())
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+
+			result, err := listInChunks(listOptions, chunkSize, func(opts v1.ListOptions) (runtime.Object, error) {
+				return client.KameletBindings(namespace).List(p.Context, opts)
+			})
+			if err != nil {
+				return knerrors.GetError(err)
+			}
+			bindingList := result.(*camelkv1alpha1.KameletBindingList)
+			setBindingListKind(bindingList)
+			if err := sortList(bindingList, sortPath); err != nil {
+				return err
+			}
+
+			// empty namespace indicates all-namespaces flag is specified
+			if namespace == "" {
+				bindingListFlags.EnsureWithNamespace()
+			}
+
+			if watchChanges {
+				bindings := client.KameletBindings(namespace)
+				lw := selectorListWatch(listOptions, func(opts v1.ListOptions) (runtime.Object, error) {
+					return bindings.List(p.Context, opts)
+				}, func(opts v1.ListOptions) (watch.Interface, error) {
+					return bindings.Watch(p.Context, opts)
+				})
+				return watchBindings(p.Context, lw, bindingList, &camelkv1alpha1.KameletBinding{}, showTimestamps, bindingListFlags, cmd.OutOrStdout())
+			}
+
+			if len(bindingList.Items) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+				return nil
+			}
+			if bindingListFlags.GenericPrintFlags.OutputFlagSpecified() {
+				r := p.newRedactor(showSecrets)
+				for i := range bindingList.Items {
+					redacted, err := r.redactBinding(&bindingList.Items[i])
+					if err != nil {
+						return err
+					}
+					bindingList.Items[i] = *redacted
+				}
+			}
+			return p.printColoredTable(cmd.OutOrStdout(), func(out io.Writer) error {
+				return printList(bindingListFlags, bindingList, out)
+			})
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), true)
+	addSelectorFlag(cmd.Flags(), &selector)
+	addSortByFlag(cmd.Flags(), &sortBy)
+	addWatchFlag(cmd.Flags(), &watchChanges)
+	addShowTimestampsFlag(cmd.Flags(), &showTimestamps)
+	addChunkSizeFlag(cmd.Flags(), &chunkSize)
+	addListPrintFlags(cmd, bindingListFlags)
+	addShowSecretsFlag(cmd.Flags(), &showSecrets)
+	return cmd
+}
+
+// listPipes prints the Pipes in given namespace, tables show the Pipes in the same way as KameletBindings
+func listPipes(p *KameletPluginParams, namespace string, listOptions v1.ListOptions, chunkSize int64, sortPath *jsonpath.JSONPath, watchChanges bool, showTimestamps bool, r *redactor, listFlags *flags.ListPrintFlags, out io.Writer) error {
+	client, err := p.NewDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := listInChunks(listOptions, chunkSize, func(opts v1.ListOptions) (runtime.Object, error) {
+		return client.Resource(pipeResource).Namespace(namespace).List(p.Context, opts)
+	})
+	if err != nil {
+		return knerrors.GetError(err)
+	}
+	pipeList := result.(*unstructured.UnstructuredList)
+	if err := sortList(pipeList, sortPath); err != nil {
+		return err
+	}
+
+	// empty namespace indicates all-namespaces flag is specified
+	if namespace == "" {
+		listFlags.EnsureWithNamespace()
+	}
+
+	if watchChanges {
+		pipes := client.Resource(pipeResource).Namespace(namespace)
+		lw := selectorListWatch(listOptions, func(opts v1.ListOptions) (runtime.Object, error) {
+			return pipes.List(p.Context, opts)
+		}, func(opts v1.ListOptions) (watch.Interface, error) {
+			return pipes.Watch(p.Context, opts)
+		})
+		return watchBindings(p.Context, lw, pipeList, &unstructured.Unstructured{}, showTimestamps, listFlags, out)
+	}
+
+	if len(pipeList.Items) == 0 {
+		fmt.Fprintf(out, "No resources found.\n")
+		return nil
+	}
+
+	if listFlags.GenericPrintFlags.OutputFlagSpecified() {
+		for i := range pipeList.Items {
+			if err := r.redactManifest(&pipeList.Items[i]); err != nil {
+				return err
+			}
+		}
+		pipeList.SetAPIVersion(pipeAPIVersion)
+		pipeList.SetKind(pipeKind + "List")
+		return printList(listFlags, pipeList, out)
+	}
+	bindingList, err := fromPipeList(pipeList)
+	if err != nil {
+		return err
+	}
+	return p.printColoredTable(out, func(out io.Writer) error {
+		return printList(listFlags, bindingList, out)
+	})
+}
+
+// watchBindings prints the listed KameletBindings or Pipes as added and then streams their changes,
+// tables show Pipes in the same way as KameletBindings
+func watchBindings(ctx context.Context, lw *cache.ListWatch, bindingList runtime.Object, objType runtime.Object, showTimestamps bool, listFlags *flags.ListPrintFlags, out io.Writer) error {
+	columns, printRows := bindingColumnDefinitions(), printBindingObject
+	if showTimestamps {
+		columns, printRows = timestampColumns(columns), timestampRowPrinter(columns, printRows)
+	}
+	printer, err := newEventPrinter(listFlags, columns, printRows, out)
+	if err != nil {
+		return err
+	}
+
+	if err := printer.printHeader(); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(bindingList)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := printer.printEvent(watch.Added, item); err != nil {
+			return err
+		}
+	}
+
+	return watchEvents(ctx, lw, bindingList, objType, func(eventType watch.EventType, obj runtime.Object) error {
+		if binding, ok := obj.(*camelkv1alpha1.KameletBinding); ok {
+			binding.SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletBindingKind))
+		}
+		return printer.printEvent(eventType, obj)
+	})
+}
+
+// printBindingObject populates the table rows of a KameletBinding or a Pipe
+func printBindingObject(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	binding, err := bindingObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	return printBinding(binding, options)
+}
+
+// setBindingListKind sets the type meta of the list and its items, which the client drops when decoding lists
+func setBindingListKind(bindingList *camelkv1alpha1.KameletBindingList) {
+	bindingList.SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletBindingKind + "List"))
+	for i := range bindingList.Items {
+		bindingList.Items[i].SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletBindingKind))
+	}
+}
+
+// BindingListHandlers handles printing human readable table for `kn-source-kamelet binding list` command's output
+func BindingListHandlers(h hprinters.PrintHandler) {
+	columns := bindingColumnDefinitions()
+	h.TableHandler(columns, printBinding)
+	h.TableHandler(columns, printBindingList)
+}
+
+// bindingListTimestampHandlers handles printing the table of `kn-source-kamelet binding list --show-timestamps`,
+// which shows the creation timestamp instead of the age
+func bindingListTimestampHandlers(h hprinters.PrintHandler) {
+	columns := bindingColumnDefinitions()
+	h.TableHandler(timestampColumns(columns), func(binding *camelkv1alpha1.KameletBinding, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printBinding(binding, options))
+	})
+	h.TableHandler(timestampColumns(columns), func(bindingList *camelkv1alpha1.KameletBindingList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printBindingList(bindingList, options))
+	})
+}
+
+// bindingColumnDefinitions returns the columns of the KameletBinding table
+func bindingColumnDefinitions() []metav1beta1.TableColumnDefinition {
+	return []metav1beta1.TableColumnDefinition{
+		{Name: "Namespace", Type: "string", Description: "Namespace of the KameletBinding", Priority: 0},
+		{Name: "Name", Type: "string", Description: "Name of the KameletBinding", Priority: 1},
+		{Name: "Source", Type: "string", Description: "Source of the KameletBinding", Priority: 1},
+		{Name: "Sink", Type: "string", Description: "Sink of the KameletBinding", Priority: 1},
+		{Name: "Phase", Type: "string", Description: "Phase of the KameletBinding", Priority: 1},
+		{Name: "Ready", Type: "string", Description: "Ready state of the KameletBinding", Priority: 1},
+		{Name: "Age", Type: "string", Description: "Age of the KameletBinding", Priority: 1},
+	}
+}
+
+// printBindingList populates the KameletBinding list table rows
+func printBindingList(bindingList *camelkv1alpha1.KameletBindingList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	rows := make([]metav1beta1.TableRow, 0, len(bindingList.Items))
+
+	for i := range bindingList.Items {
+		r, err := printBinding(&bindingList.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+// printBinding populates the KameletBinding table rows
+func printBinding(binding *camelkv1alpha1.KameletBinding, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	row := metav1beta1.TableRow{
+		Object: runtime.RawExtension{Object: binding},
+	}
+
+	if options.AllNamespaces {
+		row.Cells = append(row.Cells, binding.Namespace)
+	}
+
+	row.Cells = append(row.Cells,
+		binding.Name,
+		endpointValue(binding.Spec.Source),
+		endpointValue(binding.Spec.Sink),
+		binding.Status.Phase,
+		bindingReadyCondition(binding.Status.Conditions),
+		commands.TranslateTimestampSince(binding.CreationTimestamp))
+	return []metav1beta1.TableRow{row}, nil
+}
+
+// endpointValue returns the endpoint in the form of <type>:<name> or its URI
+func endpointValue(endpoint camelkv1alpha1.Endpoint) string {
+	if endpoint.Ref != nil {
+		return fmt.Sprintf("%s:%s", strings.ToLower(endpoint.Ref.Kind), endpoint.Ref.Name)
+	}
+	if endpoint.URI != nil {
+		return *endpoint.URI
+	}
+	return "<unknown>"
+}
+
+// bindingReadyCondition returns status of the binding's Ready type condition
+func bindingReadyCondition(conditions []camelkv1alpha1.KameletBindingCondition) string {
+	for _, condition := range conditions {
+		if condition.Type == camelkv1alpha1.KameletBindingConditionReady {
+			return string(condition.Status)
+		}
+	}
+	return "<unknown>"
+}
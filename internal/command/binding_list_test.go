@@ -0,0 +1,209 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingListSetup(t *testing.T) {
+	p := KameletPluginParams{
+		Context: context.TODO(),
+	}
+
+	listCmd := newBindingListCommand(&p)
+	assert.Equal(t, listCmd.Use, "list")
+	assert.Equal(t, listCmd.Short, "List KameletBindings")
+	assert.Assert(t, listCmd.RunE != nil)
+}
+
+func TestBindingListOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding1 := createKameletBinding("b1", "k1")
+	binding2 := createKameletBinding("b2", "k2")
+	binding2.Spec.Sink.Ref.Kind = "Service"
+	binding2.Spec.Sink.Ref.Name = "display"
+	binding2.Status.Phase = camelkapis.KameletBindingPhaseError
+	binding2.Status.Conditions[0].Status = "False"
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding1, *binding2}}
+	recorder.ListBindings(bindingList, nil)
+
+	output, err := runBindingListCmd(mockClient)
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "SOURCE", "SINK", "PHASE", "READY", "AGE"))
+	assert.Check(t, util.ContainsNone(outputLines[0], "NAMESPACE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "b1", "kamelet:k1", "broker:default", "Ready", "True"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "b2", "kamelet:k2", "service:display", "Error", "False"))
+
+	recorder.Validate()
+}
+
+func TestBindingListEmpty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	output, err := runBindingListCmd(mockClient)
+	assert.NilError(t, err)
+
+	assert.Assert(t, util.ContainsAll(output, "No", "resources", "found"))
+
+	recorder.Validate()
+}
+
+func TestBindingListAllNamespace(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding1 := createKameletBindingInNamespace("b1", "k1", "default1")
+	binding2 := createKameletBindingInNamespace("b2", "k2", "default2")
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding1, *binding2}}
+	recorder.ListBindings(bindingList, nil)
+
+	output, err := runBindingListCmd(mockClient, "--all-namespaces")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAMESPACE", "NAME", "SOURCE", "SINK"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "default1", "b1"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "default2", "b2"))
+
+	recorder.Validate()
+}
+
+func TestBindingListYAMLOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.TypeMeta = v1.TypeMeta{}
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding}}, nil)
+
+	output, err := runBindingListCmd(mockClient, "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBindingList", "kind: KameletBinding\n", "name: b1"))
+
+	recorder.Validate()
+}
+
+func TestBindingListNoHeaders(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1")}}, nil)
+
+	output, err := runBindingListCmd(mockClient, "--no-headers", "--all-namespaces")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsNone(output, "NAMESPACE", "NAME", "SOURCE"))
+	assert.Check(t, util.ContainsAll(output, "default", "b1", "kamelet:k1"))
+
+	recorder.Validate()
+}
+
+func TestBindingListTimestamps(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.CreationTimestamp = v1.NewTime(time.Date(2021, 5, 4, 10, 0, 0, 0, time.UTC))
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding}}, nil)
+	output, err := runBindingListCmd(mockClient, "--show-timestamps", "--all-namespaces")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAMESPACE", "NAME", "READY", "CREATED"))
+	assert.Check(t, util.ContainsNone(outputLines[0], "AGE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "default", "b1", "True", "2021-05-04T10:00:00Z"))
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding}}, nil)
+	p := bindingListParams(mockClient)
+	output, err = runWatchCmd(p, NewBindingCommand(p), "2021-05-04T10:00:00Z", "binding", "list", "--show-timestamps", "--watch")
+	assert.NilError(t, err)
+
+	outputLines = strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "EVENT", "NAME", "CREATED"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "ADDED", "b1", "True", "2021-05-04T10:00:00Z"))
+
+	recorder.Validate()
+}
+
+func TestBindingListWatch(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Status.Phase = camelkapis.KameletBindingPhaseCreating
+	binding.Status.Conditions = []camelkapis.KameletBindingCondition{{Type: camelkapis.KameletBindingConditionReady, Status: "False"}}
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*binding}}, nil)
+
+	ready := createKameletBinding("b1", "k1")
+	ready.Status.Phase = camelkapis.KameletBindingPhaseReady
+	ready.Status.Conditions = []camelkapis.KameletBindingCondition{{Type: camelkapis.KameletBindingConditionReady, Status: "True"}}
+	watcher := watch.NewFakeWithChanSize(1, false)
+	watcher.Modify(ready)
+	recorder.WatchBindings(watcher, nil)
+
+	p := bindingListParams(mockClient)
+	output, err := runWatchCmd(p, NewBindingCommand(p), "MODIFIED", "binding", "list", "--watch")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "EVENT", "NAME", "SOURCE", "SINK", "PHASE", "READY"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "ADDED", "b1", "Creating", "False"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "MODIFIED", "b1", "Ready", "True"))
+
+	recorder.Validate()
+}
+
+func runBindingListCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := bindingListParams(c)
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(p), p.KnParams)
+
+	args := []string{"binding", "list"}
+	args = append(args, options...)
+	bindingCmd.SetArgs(args)
+	err := bindingCmd.Execute()
+
+	return output.String(), err
+}
+
+func bindingListParams(c *kamelettesting.MockKameletClient) *KameletPluginParams {
+	return &KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingMigrateExample = `
+  # Migrate all KameletBindings in the current namespace to Pipes
+  kn-source-kamelet binding migrate
+
+  # Preview the Pipes migrated from given KameletBindings
+  kn-source-kamelet binding migrate NAME... --dry-run client
+
+  # Migrate all KameletBindings and delete them once the Pipe is created
+  kn-source-kamelet binding migrate -n events --delete-old`
+
+// newBindingMigrateCommand implements 'kn-source-kamelet binding migrate' command
+func newBindingMigrateCommand(p *KameletPluginParams) *cobra.Command {
+	var dryRun string
+	var deleteOld bool
+	printFlags := genericclioptions.NewPrintFlags("")
+
+	cmd := &cobra.Command{
+		Use:   "migrate [NAME...]",
+		Short: "Migrate KameletBindings to Camel K v1 Pipes",
+		Long: `Migrate KameletBindings to Camel K v1 Pipes.
+
+Converts the given KameletBindings, or all KameletBindings of the namespace, to Pipes with the same name,
+labels, annotations, source and sink. Existing Pipes are not overwritten.`,
+		Example: bindingMigrateExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+
+			var bindings []v1alpha1.KameletBinding
+			if len(args) == 0 {
+				bindingList, err := client.KameletBindings(namespace).List(p.Context, v1.ListOptions{})
+				if err != nil {
+					return knerrors.GetError(err)
+				}
+				bindings = bindingList.Items
+			}
+			for _, name := range args {
+				binding, err := client.KameletBindings(namespace).Get(p.Context, name, v1.GetOptions{})
+				if err != nil {
+					return knerrors.GetError(err)
+				}
+				bindings = append(bindings, *binding)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(bindings) == 0 {
+				fmt.Fprintf(out, "No resources found.\n")
+				return nil
+			}
+
+			if dryRun == dryRunClient {
+				pipes := make([]runtime.Object, 0, len(bindings))
+				for i := range bindings {
+					pipe, err := toPipe(&bindings[i])
+					if err != nil {
+						return err
+					}
+					pipes = append(pipes, pipe)
+				}
+				return printBindingManifests(printFlags, out, pipes...)
+			}
+			out = p.messageOutput(out)
+
+			dynamicClient, err := p.NewDynamicClient()
+			if err != nil {
+				return err
+			}
+
+			var createOptions v1.CreateOptions
+			var dryRunSuffix string
+			audit := p.auditLog()
+			if dryRun == dryRunServer {
+				createOptions.DryRun = []string{v1.DryRunAll}
+				dryRunSuffix = " (server dry run)"
+				audit = nil
+			}
+
+			for i := range bindings {
+				binding := &bindings[i]
+				pipe, err := toPipe(binding)
+				if err != nil {
+					return err
+				}
+
+				_, err = dynamicClient.Resource(pipeResource).Namespace(binding.Namespace).Create(p.Context, pipe, createOptions)
+				if apierrors.IsAlreadyExists(err) {
+					fmt.Fprintf(out, "Pipe '%s' already exists in namespace '%s', skipping.\n", binding.Name, binding.Namespace)
+					continue
+				}
+				if auditErr := audit.record("create", pipeKind, binding.Namespace, binding.Name, nil, err); auditErr != nil {
+					return auditErr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to migrate KameletBinding '%s' in namespace '%s': %w", binding.Name, binding.Namespace, knerrors.GetError(err))
+				}
+				fmt.Fprintf(out, "KameletBinding '%s' migrated to Pipe in namespace '%s'%s.\n", binding.Name, binding.Namespace, dryRunSuffix)
+
+				if deleteOld && dryRun == "" {
+					err := client.KameletBindings(binding.Namespace).Delete(p.Context, binding.Name, v1.DeleteOptions{})
+					if auditErr := audit.record("delete", v1alpha1.KameletBindingKind, binding.Namespace, binding.Name, nil, err); auditErr != nil {
+						return auditErr
+					}
+					if err != nil {
+						return fmt.Errorf("failed to delete KameletBinding '%s' in namespace '%s': %w", binding.Name, binding.Namespace, knerrors.GetError(err))
+					}
+					fmt.Fprintf(out, "KameletBinding '%s' deleted in namespace '%s'.\n", binding.Name, binding.Namespace)
+				}
+			}
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().BoolVar(&deleteOld, "delete-old", false, "Delete each KameletBinding once it is migrated to a Pipe.")
+	addDryRunFlag(cmd.Flags(), &dryRun)
+	printFlags.AddFlags(cmd)
+	return cmd
+}
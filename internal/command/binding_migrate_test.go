@@ -0,0 +1,90 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingMigrate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	b1 := migrateBinding("b1")
+	b1.Annotations = map[string]string{"trait.camel.apache.org/jvm.options": "-Xmx256m"}
+	b2 := migrateBinding("b2")
+	existing, err := toPipe(b2)
+	assert.NilError(t, err)
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*b1, *b2}}, nil)
+	recorder.DeleteBinding("b1", nil)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), existing)
+	p := pipeParams(mockClient, dynamicClient)
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "migrate", "--delete-old")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'b1' migrated to Pipe", "KameletBinding 'b1' deleted", "Pipe 'b2' already exists"))
+
+	pipe, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pipe.GetAnnotations(), map[string]string{"trait.camel.apache.org/jvm.options": "-Xmx256m"})
+	sink, _, _ := unstructured.NestedString(pipe.Object, "spec", "sink", "ref", "name")
+	assert.Equal(t, sink, "default")
+	recorder.Validate()
+}
+
+func TestBindingMigrateDryRunClient(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.GetBinding(migrateBinding("b1"), nil)
+
+	p := pipeParams(mockClient, nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "migrate", "b1", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "apiVersion: camel.apache.org/v1", "kind: Pipe", "name: b1"))
+	assert.Check(t, util.ContainsNone(output, "status:", "migrated"))
+	recorder.Validate()
+}
+
+func TestBindingMigrateNoBindings(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+
+	p := pipeParams(mockClient, nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "migrate")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "No resources found"))
+	recorder.Validate()
+}
+
+func migrateBinding(name string) *camelkapis.KameletBinding {
+	binding := createKameletBinding(name, "k1")
+	binding.Namespace = "current"
+	return binding
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"text/template"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"knative.dev/client/pkg/util"
+)
+
+// renderBindingTemplate renders the binding manifest template with the given key=value pairs and reads the resulting
+// KameletBindings, "-" reads the template from stdin. Placeholders are Go template actions such as {{ .sink }}, all
+// placeholders have to be given a value.
+func renderBindingTemplate(path string, values []string, stdin io.Reader) ([]*v1alpha1.KameletBinding, error) {
+	data, source, err := readTemplate(path, stdin)
+	if err != nil {
+		return nil, err
+	}
+	given, err := util.MapFromArray(values, "=")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(source).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", source, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, given); err != nil {
+		return nil, fmt.Errorf("failed to render template %s, set all placeholders with --set: %w", source, err)
+	}
+	return decodeBindings(&rendered, source)
+}
+
+func readTemplate(path string, stdin io.Reader) ([]byte, string, error) {
+	if path == "-" {
+		data, err := ioutil.ReadAll(stdin)
+		return data, "stdin", err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return data, path, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRuntimeLogLevelFlags(t *testing.T) {
+	flags := bindingFlags{Broker: "default", RuntimeLogLevel: "DEBUG", RuntimeLoggers: []string{"org.apache.camel=info"}}
+	options, err := flags.toOptions("", "default", "k1", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, options.RuntimeLogLevel, "DEBUG")
+	assert.DeepEqual(t, options.RuntimeLoggers, map[string]string{"org.apache.camel": "info"})
+
+	flags = bindingFlags{Broker: "default", RuntimeLogLevel: "verbose"}
+	_, err = flags.toOptions("", "default", "k1", nil)
+	assert.Error(t, err, "unsupported runtime log level \"verbose\", expected one of: trace|debug|info|warn|error")
+
+	flags = bindingFlags{Broker: "default", RuntimeLoggers: []string{"org.apache.camel=loud"}}
+	_, err = flags.toOptions("", "default", "k1", nil)
+	assert.Error(t, err, "unsupported runtime log level \"loud\", expected one of: trace|debug|info|warn|error")
+}
+
+func TestPropertyReferences(t *testing.T) {
+	values, err := propertyReferences("secret", []string{"password=credentials/password", "token=api/token"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []string{"password={{secret:credentials/password}}", "token={{secret:api/token}}"})
+
+	_, err = propertyReferences("secret", []string{"password"})
+	assert.Error(t, err, "invalid secret reference \"password\", expected <key>=<secret>/<secret-key>")
+
+	_, err = propertyReferences("secret", []string{"password=credentials"})
+	assert.Error(t, err, "invalid secret reference \"password=credentials\", expected <key>=<secret>/<secret-key>")
+}
+
+func TestSecretPropertyFlags(t *testing.T) {
+	flags := bindingFlags{
+		Broker:                "default",
+		SourceProperties:      []string{"message=Hello"},
+		SourcePropertySecrets: []string{"password=credentials/password"},
+		SinkPropertySecrets:   []string{"token=sink-credentials/token"},
+	}
+	options, err := flags.toOptions("", "default", "k1", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.SourceProperties, map[string]string{"message": "Hello", "password": "{{secret:credentials/password}}"})
+	assert.DeepEqual(t, options.SinkProperties, map[string]string{"token": "{{secret:sink-credentials/token}}"})
+}
+
+func TestCEOverrideFlags(t *testing.T) {
+	flags := bindingFlags{
+		Broker:         "default",
+		SinkProperties: []string{"foo=bar"},
+		CEOverrides:    []string{"type=org.example.tick", "ce-source=timer", "myextension=value"},
+	}
+	options, err := flags.toOptions("", "default", "k1", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.SinkProperties, map[string]string{
+		"foo":                        "bar",
+		"ce.override.ce-type":        "org.example.tick",
+		"ce.override.ce-source":      "timer",
+		"ce.override.ce-myextension": "value",
+	})
+
+	flags = bindingFlags{Broker: "default", CEOverrides: []string{"type"}}
+	_, err = flags.toOptions("", "default", "k1", nil)
+	assert.Error(t, err, "invalid CloudEvents override \"type\", expected <attribute>=<value>")
+
+	flags = bindingFlags{Sink: "kamelet:log-sink", CEOverrides: []string{"type=org.example.tick"}}
+	_, err = flags.toOptions("", "default", "k1", nil)
+	assert.Error(t, err, "--ce-override requires a Knative broker, channel or service as binding sink")
+}
+
+func TestTraitFlags(t *testing.T) {
+	flags := bindingFlags{
+		Broker:      "default",
+		Annotations: []string{"cost-center=4711"},
+		Traits:      []string{"jvm.options=-Xmx256m", "health.enabled=true"},
+	}
+	options, err := flags.toOptions("", "default", "k1", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.Annotations, map[string]string{
+		"cost-center":                           "4711",
+		"trait.camel.apache.org/jvm.options":    "-Xmx256m",
+		"trait.camel.apache.org/health.enabled": "true",
+	})
+
+	for _, trait := range []string{"jvm=true", "jvm.options", ".options=foo"} {
+		flags = bindingFlags{Broker: "default", Traits: []string{trait}}
+		_, err = flags.toOptions("", "default", "k1", nil)
+		assert.Error(t, err, fmt.Sprintf("invalid trait %q, expected <trait>.<property>=<value>", trait))
+	}
+}
+
+func TestConfigMapPropertyFlags(t *testing.T) {
+	flags := bindingFlags{
+		Broker:                   "default",
+		SourcePropertyConfigMaps: []string{"period=timer-config/period"},
+		SinkPropertyConfigMaps:   []string{"type=sink-config/event-type"},
+	}
+	options, err := flags.toOptions("", "default", "k1", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options.SourceProperties, map[string]string{"period": "{{configmap:timer-config/period}}"})
+	assert.DeepEqual(t, options.SinkProperties, map[string]string{"type": "{{configmap:sink-config/event-type}}"})
+
+	flags = bindingFlags{Broker: "default", SourcePropertyConfigMaps: []string{"period=timer-config"}}
+	_, err = flags.toOptions("", "default", "k1", nil)
+	assert.Error(t, err, "invalid configmap reference \"period=timer-config\", expected <key>=<configmap>/<configmap-key>")
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var bindingUpdateExample = `
+  # Add or override a single source property of the binding
+  kn-source-kamelet binding update NAME --source-property message=Hello
+
+  # Remove a source property of the binding
+  kn-source-kamelet binding update NAME --source-property message-
+
+  # Rotate a sensitive source property reading the new value from stdin
+  printenv GITHUB_TOKEN | kn-source-kamelet binding update NAME --source-secret-property token=@stdin
+
+  # Switch the binding sink to another broker
+  kn-source-kamelet binding update NAME --broker events`
+
+// newBindingUpdateCommand implements 'kn-source-kamelet binding update' command
+func newBindingUpdateCommand(p *KameletPluginParams) *cobra.Command {
+	var updateFlags bindingFlags
+	var verify verifyOptions
+
+	cmd := &cobra.Command{
+		Use:     "update NAME",
+		Short:   "Update a KameletBinding",
+		Example: bindingUpdateExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet binding update' requires the binding name given as single argument")
+			}
+			name := args[0]
+			if err := updateFlags.expandPropertyValues(cmd.InOrStdin()); err != nil {
+				return err
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+			pipes, err := p.usePipes()
+			if err != nil {
+				return err
+			}
+			kind := v1alpha1.KameletBindingKind
+			var dynamicClient dynamic.Interface
+			if pipes {
+				kind = pipeKind
+				if dynamicClient, err = p.NewDynamicClient(); err != nil {
+					return err
+				}
+			}
+
+			// the binding is read again when the update fails with a conflict
+			return p.retryOnConflict(func() error {
+				var existing *v1alpha1.KameletBinding
+				var existingPipe *unstructured.Unstructured
+				if pipes {
+					if existingPipe, err = dynamicClient.Resource(pipeResource).Namespace(namespace).Get(p.Context, name, v1.GetOptions{}); err != nil {
+						return knerrors.GetError(err)
+					}
+					if existing, err = fromPipe(existingPipe); err != nil {
+						return err
+					}
+				} else if existing, err = client.KameletBindings(namespace).Get(p.Context, name, v1.GetOptions{}); err != nil {
+					return knerrors.GetError(err)
+				}
+
+				binding := existing.DeepCopy()
+				if err := updateFlags.applyTo(binding, p.sinkTypes()); err != nil {
+					return err
+				}
+				secret, err := updateFlags.propertySecret(binding)
+				if err != nil {
+					return err
+				}
+
+				out := p.messageOutput(cmd.OutOrStdout())
+				if err := verifySource(p.Context, client, binding, verify, out); err != nil {
+					return err
+				}
+				if err := verifySink(p.Context, client, binding, verify, out); err != nil {
+					return err
+				}
+				if verify.VerifyAddressable {
+					if err := p.verifySinkAddressable(binding); err != nil {
+						return err
+					}
+				}
+
+				if secret != nil {
+					if err := p.applyPropertySecret(secret, false, out); err != nil {
+						return err
+					}
+				}
+
+				changes := bindingChanges(existing, binding)
+				if len(changes) == 0 {
+					fmt.Fprintf(out, "%s '%s' in namespace '%s' is unchanged.\n", kind, name, namespace)
+					return nil
+				}
+
+				if pipes {
+					var pipe *unstructured.Unstructured
+					if pipe, err = updatePipe(existingPipe, binding); err != nil {
+						return err
+					}
+					_, err = dynamicClient.Resource(pipeResource).Namespace(namespace).Update(p.Context, pipe, v1.UpdateOptions{})
+				} else {
+					_, err = client.KameletBindings(namespace).Update(p.Context, binding, v1.UpdateOptions{})
+				}
+				if auditErr := p.auditLog().record("update", kind, namespace, name, changes, err); auditErr != nil {
+					return auditErr
+				}
+				if err != nil {
+					return knerrors.GetError(err)
+				}
+				fmt.Fprintf(out, "%s '%s' updated in namespace '%s'.\n", kind, name, namespace)
+				return nil
+			})
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	updateFlags.addFlags(cmd.Flags())
+	p.registerSinkCompletion(cmd)
+	verify.addFlags(cmd.Flags())
+	cmd.Flag("source-property").Usage = "Add or override a source property in the form of \"<key>=<value>\", use \"<key>-\" to remove it. Repeat the flag to give multiple values of an array property. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input."
+	cmd.Flag("sink-property").Usage = "Add or override a sink property in the form of \"<key>=<value>\", use \"<key>-\" to remove it. Repeat the flag to give multiple values of an array property. Values of the form @env:<variable>, @file:<path> or @stdin are read from the environment, a file or the input."
+	cmd.Flag("label").Usage = "Add or override a label in the form of \"<key>=<value>\", use \"<key>-\" to remove it."
+	cmd.Flag("annotation").Usage = "Add or override an annotation in the form of \"<key>=<value>\", use \"<key>-\" to remove it."
+	cmd.Flag("trait").Usage = "Add or override a Camel K trait configuration in the form of \"<trait>.<property>=<value>\", use \"<trait>.<property>-\" to remove it."
+	return cmd
+}
+
+// applyTo applies only the given flags to the binding and preserves all other settings
+func (f *bindingFlags) applyTo(binding *v1alpha1.KameletBinding, sinkTypes map[string]v1.TypeMeta) error {
+	if f.Broker != "" || f.Channel != "" || f.Service != "" || f.URI != "" || f.Sink != "" {
+		sink, err := f.sinkExpression()
+		if err != nil {
+			return err
+		}
+		sinkEndpoint, err := kameletapi.DecodeSink(sink, sinkTypes)
+		if err != nil {
+			return err
+		}
+		if err := kameletapi.SetSinkNamespace(sinkEndpoint, f.SinkNamespace, binding.Namespace); err != nil {
+			return err
+		}
+		binding.Spec.Sink.Ref = sinkEndpoint.Ref
+		binding.Spec.Sink.URI = sinkEndpoint.URI
+	} else if f.SinkNamespace != "" {
+		// moves the current sink to given namespace
+		if binding.Spec.Sink.Ref == nil {
+			return fmt.Errorf("sink namespace %q is not supported for URI sinks", f.SinkNamespace)
+		}
+		binding.Spec.Sink.Ref.Namespace = f.SinkNamespace
+	}
+
+	sourceValues, err := f.sourcePropertyValues()
+	if err != nil {
+		return err
+	}
+	if binding.Spec.Source.Properties, err = updateEndpointProperties(binding.Spec.Source.Properties, f.SourcePropertiesFile, sourceValues); err != nil {
+		return err
+	}
+	sourceJSONProperties, err := f.sourceJSONProperties()
+	if err != nil {
+		return err
+	}
+	if binding.Spec.Source.Properties, err = kameletapi.SetRawProperties(binding.Spec.Source.Properties, sourceJSONProperties); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(&binding.Labels, f.Labels); err != nil {
+		return err
+	}
+	annotations, err := f.annotationValues()
+	if err != nil {
+		return err
+	}
+	if err := updateMetadata(&binding.Annotations, annotations); err != nil {
+		return err
+	}
+
+	if len(f.CEOverrides) > 0 {
+		if err := verifyCEOverrideSink(binding.Spec.Sink.Ref); err != nil {
+			return err
+		}
+	}
+	sinkValues, err := f.sinkPropertyValues()
+	if err != nil {
+		return err
+	}
+	if binding.Spec.Sink.Properties, err = updateEndpointProperties(binding.Spec.Sink.Properties, f.SinkPropertiesFile, sinkValues); err != nil {
+		return err
+	}
+	sinkJSONProperties, err := f.sinkJSONProperties()
+	if err != nil {
+		return err
+	}
+	if binding.Spec.Sink.Properties, err = kameletapi.SetRawProperties(binding.Spec.Sink.Properties, sinkJSONProperties); err != nil {
+		return err
+	}
+
+	if f.Replicas.value != nil {
+		if binding.Spec.Integration == nil {
+			binding.Spec.Integration = &camelv1.IntegrationSpec{}
+		}
+		binding.Spec.Integration.Replicas = f.Replicas.value
+	}
+
+	if f.RuntimeLogLevel != "" || len(f.RuntimeLoggers) > 0 {
+		loggers, err := f.runtimeLoggers()
+		if err != nil {
+			return err
+		}
+		logging, err := kameletapi.RuntimeLogging(f.RuntimeLogLevel, loggers)
+		if err != nil {
+			return err
+		}
+		binding.Spec.Integration = mergeRuntimeLogging(binding.Spec.Integration, logging)
+	}
+	return nil
+}
+
+// updateEndpointProperties sets the values of the properties file and then applies the "<key>=<value>" pairs,
+// a "<key>-" pair removes the property. Returns the unchanged properties if neither is given.
+func updateEndpointProperties(existing *v1alpha1.EndpointProperties, file string, values []string) (*v1alpha1.EndpointProperties, error) {
+	if file == "" && len(values) == 0 {
+		return existing, nil
+	}
+
+	toSet, toRemove, err := util.OrderedMapAndRemovalListFromArray(values, "=")
+	if err != nil {
+		return nil, err
+	}
+	properties, err := kameletapi.RawEndpointProperties(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		read, err := readPropertiesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range read {
+			properties[key] = value
+		}
+	}
+	it := toSet.Iterator()
+	for key, value, ok := it.NextString(); ok; key, value, ok = it.NextString() {
+		properties[key] = value
+	}
+	for _, key := range toRemove {
+		delete(properties, key)
+	}
+
+	if len(properties) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}, nil
+}
+
+// updateMetadata applies the "<key>=<value>" pairs to the labels or annotations, a "<key>-" pair removes the entry
+func updateMetadata(metadata *map[string]string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	toSet, toRemove, err := util.OrderedMapAndRemovalListFromArray(values, "=")
+	if err != nil {
+		return err
+	}
+	toAdd := map[string]string{}
+	it := toSet.Iterator()
+	for key, value, ok := it.NextString(); ok; key, value, ok = it.NextString() {
+		toAdd[key] = value
+	}
+	*metadata = mergeMetadata(*metadata, toAdd, toRemove)
+	return nil
+}
+
+// mergeRuntimeLogging merges the logging trait and logger overrides into the existing integration spec
+func mergeRuntimeLogging(integration *camelv1.IntegrationSpec, logging *camelv1.IntegrationSpec) *camelv1.IntegrationSpec {
+	if integration == nil {
+		return logging
+	}
+
+	for name, trait := range logging.Traits {
+		if integration.Traits == nil {
+			integration.Traits = map[string]camelv1.TraitSpec{}
+		}
+		integration.Traits[name] = trait
+	}
+
+	for _, override := range logging.Configuration {
+		prefix := override.Value[:strings.Index(override.Value, ".level=")+len(".level=")]
+		replaced := false
+		for i, configuration := range integration.Configuration {
+			if configuration.Type == override.Type && strings.HasPrefix(configuration.Value, prefix) {
+				integration.Configuration[i] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			integration.Configuration = append(integration.Configuration, override)
+		}
+	}
+	return integration
+}
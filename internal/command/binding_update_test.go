@@ -0,0 +1,324 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingUpdateErrorCaseMissingArgument(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingUpdateCmd(mockClient, "--broker", "default")
+	assert.Error(t, err, "'kn-source-kamelet binding update' requires the binding name given as single argument")
+	recorder.Validate()
+}
+
+func TestBindingUpdateErrorCaseNotFound(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("b1"))
+
+	_, err := runBindingUpdateCmd(mockClient, "b1", "--broker", "default")
+	assert.ErrorContains(t, err, "not found")
+	recorder.Validate()
+}
+
+func TestBindingUpdateSourceProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello","period":1000}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Source.Properties.RawMessage), `{"message":"Bye","period":1000}`)
+		assert.Equal(t, updated.Spec.Sink.Ref.Kind, "Broker")
+		assert.Equal(t, updated.Spec.Sink.Ref.Name, "default")
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--source-property", "message=Bye")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateJSONProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"filters":["a"],"message":"Hello"}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Source.Properties.RawMessage), `{"filters":{"type":["a","b"]},"message":"Hello"}`)
+	}, nil)
+
+	_, err := runBindingUpdateCmd(mockClient, "b1", "--source-property-json", `filters={"type": ["a", "b"]}`)
+	assert.NilError(t, err)
+	recorder.Validate()
+}
+
+func TestBindingUpdateArrayProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello","topics":["a"]}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Source.Properties.RawMessage), `{"message":"Bye","topics":["a","b"]}`)
+	}, nil)
+
+	_, err := runBindingUpdateCmd(mockClient, "b1", "--source-property", "topics=a", "--source-property", "topics=b", "--source-property", "message=Bye")
+	assert.NilError(t, err)
+	recorder.Validate()
+}
+
+func TestBindingUpdatePropertiesFile(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello"}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Source.Properties.RawMessage), `{"message":"Hello","period":"2000"}`)
+		assert.Equal(t, string(updated.Spec.Sink.Properties.RawMessage), `{"type":"custom"}`)
+	}, nil)
+
+	_, err := runBindingUpdateCmd(mockClient, "b1",
+		"--source-properties-file", writePropertiesFile(t, "period=1000\n"), "--source-property", "period=2000",
+		"--sink-properties-file", writePropertiesFile(t, "type=custom\n"))
+	assert.NilError(t, err)
+	recorder.Validate()
+}
+
+func TestBindingUpdateRemoveRequiredProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello"}`)}
+	recorder.GetBinding(binding, nil)
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+
+	_, err := runBindingUpdateCmd(mockClient, "b1", "--source-property", "message-")
+	assert.Error(t, err, "binding is missing required property \"message\" for Kamelet \"k1\"")
+	recorder.Validate()
+}
+
+func TestBindingUpdateSink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"message":"Hello"}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Source.Properties.RawMessage), `{"message":"Hello"}`)
+		assert.Equal(t, updated.Spec.Sink.Ref.Kind, "Broker")
+		assert.Equal(t, updated.Spec.Sink.Ref.Name, "events")
+		assert.Equal(t, updated.Spec.Sink.Ref.Namespace, "default")
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--broker", "events", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated", "default"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateSinkNamespace(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, updated.Spec.Sink.Ref.Name, "default")
+		assert.Equal(t, updated.Spec.Sink.Ref.Namespace, "events")
+	}, nil)
+
+	_, err := runBindingUpdateCmd(mockClient, "b1", "--sink-namespace", "events", "-n", "default")
+	assert.NilError(t, err)
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	_, err = runBindingUpdateCmd(mockClient, "b1", "--sink", "broker:events/default", "--sink-namespace", "other", "-n", "default")
+	assert.Error(t, err, "sink namespace \"other\" conflicts with namespace \"events\" of the sink expression")
+	recorder.Validate()
+}
+
+func TestBindingUpdateURISink(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Assert(t, updated.Spec.Sink.Ref == nil)
+		assert.Equal(t, *updated.Spec.Sink.URI, "kafka:events")
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--uri", "kafka:events")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateCEOverride(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Sink.Properties = &camelkapis.EndpointProperties{RawMessage: camelv1.RawMessage(`{"ce.override.ce-source":"timer"}`)}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, string(updated.Spec.Sink.Properties.RawMessage), `{"ce.override.ce-type":"org.example.tick"}`)
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--ce-override", "type=org.example.tick", "--ce-override", "source-")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateLabelsAndAnnotations(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Labels = map[string]string{"app": "timer", "team": "events"}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.DeepEqual(t, updated.Labels, map[string]string{"app": "timer", "tier": "backend"})
+		assert.DeepEqual(t, updated.Annotations, map[string]string{"cost-center": "4711"})
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--label", "team-", "--label", "tier=backend", "--annotation", "cost-center=4711")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateTraits(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Annotations = map[string]string{"trait.camel.apache.org/health.enabled": "true"}
+	recorder.GetBinding(binding, nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.DeepEqual(t, updated.Annotations, map[string]string{"trait.camel.apache.org/jvm.options": "-Xmx256m"})
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--trait", "jvm.options=-Xmx256m", "--trait", "health.enabled-")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateReplicas(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, updated *camelkapis.KameletBinding) {
+		assert.Equal(t, *updated.Spec.Integration.Replicas, int32(3))
+	}, nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--replicas", "3")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "updated"))
+	recorder.Validate()
+}
+
+func TestBindingUpdateUnchanged(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+
+	output, err := runBindingUpdateCmd(mockClient, "b1", "--broker", "default", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding", "b1", "unchanged"))
+	recorder.Validate()
+}
+
+func TestMergeRuntimeLogging(t *testing.T) {
+	integration := &camelv1.IntegrationSpec{
+		Replicas: new(int32),
+		Configuration: []camelv1.ConfigurationSpec{
+			{Type: "property", Value: "camel.main.name=test"},
+			{Type: "property", Value: `quarkus.log.category."org.apache.camel".level=INFO`},
+		},
+	}
+	logging, err := kameletapi.RuntimeLogging("debug", map[string]string{"org.apache.camel": "trace", "io.quarkus": "warn"})
+	assert.NilError(t, err)
+
+	merged := mergeRuntimeLogging(integration, logging)
+	assert.Assert(t, merged.Replicas != nil)
+	assert.Equal(t, string(merged.Traits["logging"].Configuration.RawMessage), `{"level":"DEBUG"}`)
+	assert.DeepEqual(t, merged.Configuration, []camelv1.ConfigurationSpec{
+		{Type: "property", Value: "camel.main.name=test"},
+		{Type: "property", Value: `quarkus.log.category."org.apache.camel".level=TRACE`},
+		{Type: "property", Value: `quarkus.log.category."io.quarkus".level=WARN`},
+	})
+}
+
+func runBindingUpdateCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+
+	args := []string{"binding", "update"}
+	args = append(args, options...)
+	bindingCmd.SetArgs(args)
+	err := bindingCmd.Execute()
+
+	return output.String(), err
+}
@@ -0,0 +1,442 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"knative.dev/client/pkg/kn/commands"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	"sigs.k8s.io/yaml"
+)
+
+var bindingValidateExample = `
+  # Validate the binding of given manifest against the Kamelets of the cluster
+  kn-source-kamelet binding validate -f binding.yaml
+
+  # Validate all bindings of given directory against the cached or bundled Kamelets without accessing the cluster
+  kn-source-kamelet binding validate -f bindings/ --offline
+
+  # Print the validation results in JSON format, e.g. for CI pipelines
+  kn-source-kamelet binding validate -f bindings/ -o json`
+
+// severities of the findings reported for binding manifests
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// finding is a problem reported for a binding manifest
+type finding struct {
+	Severity string `json:"severity"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+	Rule     string `json:"rule,omitempty"`
+}
+
+// validationResult holds the findings of a binding manifest
+type validationResult struct {
+	Source    string    `json:"source"`
+	Name      string    `json:"name,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Valid     bool      `json:"valid"`
+	Findings  []finding `json:"findings,omitempty"`
+}
+
+// bindingDocument is a KameletBinding read from a manifest, err is set when the document is not a valid binding.
+// errorHandler is set when the manifest configures spec.errorHandler of newer Camel K versions which is not part of the
+// KameletBinding API known to the plugin.
+type bindingDocument struct {
+	source       string
+	binding      *v1alpha1.KameletBinding
+	errorHandler bool
+	err          error
+}
+
+// newBindingValidateCommand implements 'kn-source-kamelet binding validate' command
+func newBindingValidateCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var offline bool
+	var strict bool
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "validate -f FILENAME",
+		Short: "Validate binding manifests",
+		Long: `Validate binding manifests without creating the bindings.
+
+The manifests are checked for unknown fields, a valid name, labels and annotations, the source and the sink are
+checked for a reference or URI and a supported sink kind. The referenced Kamelets must exist and be of the right type,
+required properties must be given and property values must match the type of the Kamelet property definition.
+The command fails when any binding is invalid.`,
+		Example: bindingValidateExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(filenames) == 0 {
+				return errors.New("missing manifests, use --filename to specify them")
+			}
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("invalid output format %q, expected one of: json|yaml", output)
+			}
+			var namespace string
+			var err error
+			if offline {
+				namespace, err = p.offlineNamespace(cmd)
+			} else {
+				namespace, err = p.GetNamespace(cmd)
+			}
+			if err != nil {
+				return err
+			}
+
+			documents, err := readBindingDocuments(filenames, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(documents) == 0 {
+				return errors.New("no KameletBinding found in given manifests")
+			}
+
+			results := make([]validationResult, 0, len(documents))
+			invalid := 0
+			for _, document := range documents {
+				result, err := p.validateBindingDocument(document, namespace, offline, strict)
+				if err != nil {
+					return err
+				}
+				if !result.Valid {
+					invalid++
+				}
+				results = append(results, result)
+			}
+
+			if err := writeValidationResults(cmd.OutOrStdout(), results, output); err != nil {
+				return err
+			}
+			if invalid > 0 {
+				// the findings are the relevant output, not the usage
+				cmd.SilenceUsage = true
+				return withExitCode(ExitCodeValidation, fmt.Errorf("%d of %d KameletBindings are invalid", invalid, len(results)))
+			}
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file or directory with the KameletBindings to validate, use - to read from stdin.")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Validate against the Kamelets of the local cache or the bundled Kamelet catalog without accessing the cluster.")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Report properties not defined by the Kamelet as error instead of warning.")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format of the validation results. One of: json|yaml.")
+	return cmd
+}
+
+// readBindingDocuments reads all KameletBinding documents from given files or directories, "-" reads from stdin.
+// Documents are decoded strictly so that unknown fields are reported, documents of other kinds are skipped.
+func readBindingDocuments(paths []string, stdin io.Reader) ([]bindingDocument, error) {
+	var documents []bindingDocument
+	for _, path := range paths {
+		if path == "-" {
+			read, err := decodeBindingDocuments(stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, read...)
+			continue
+		}
+
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			read, err := decodeBindingDocuments(f, file)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, read...)
+		}
+	}
+	return documents, nil
+}
+
+// decodeBindingDocuments decodes the KameletBinding documents of a YAML or JSON stream
+func decodeBindingDocuments(reader io.Reader, source string) ([]bindingDocument, error) {
+	var documents []bindingDocument
+	yamlReader := utilyaml.NewYAMLReader(bufio.NewReader(reader))
+	for {
+		data, err := yamlReader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		data, err = utilyaml.ToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+			continue
+		}
+
+		var typeMeta struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				ErrorHandler json.RawMessage `json:"errorHandler"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(data, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		if typeMeta.Kind != v1alpha1.KameletBindingKind {
+			continue
+		}
+
+		binding := &v1alpha1.KameletBinding{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		errorHandler := typeMeta.Spec.ErrorHandler
+		document := bindingDocument{source: source, binding: binding, errorHandler: len(errorHandler) > 0 && string(errorHandler) != "null"}
+		if document.err = decoder.Decode(binding); document.err != nil {
+			// the lenient decoding still provides the name of the binding for the report
+			_ = json.Unmarshal(data, binding)
+		}
+		documents = append(documents, document)
+	}
+}
+
+// validateBindingDocument validates the binding of the document and the properties of its Kamelet endpoints
+func (params *KameletPluginParams) validateBindingDocument(document bindingDocument, namespace string, offline bool, strict bool) (validationResult, error) {
+	binding := document.binding
+	if binding.Namespace == "" {
+		binding.Namespace = namespace
+	}
+	result := validationResult{Source: document.source, Name: binding.Name, Namespace: binding.Namespace}
+
+	var decodeErrors []finding
+	if document.err != nil {
+		decodeErrors = append(decodeErrors, finding{Severity: severityError, Message: document.err.Error()})
+	}
+	errs := validateBinding(binding, params.sinkTypes())
+
+	var warnings []finding
+	for _, endpoint := range []struct {
+		kameletType string
+		endpoint    *v1alpha1.Endpoint
+	}{{"source", &binding.Spec.Source}, {"sink", &binding.Spec.Sink}} {
+		ref := endpoint.endpoint.Ref
+		if ref == nil || ref.Kind != v1alpha1.KameletKind || ref.Name == "" {
+			continue
+		}
+		path := field.NewPath("spec", endpoint.kameletType)
+		kameletNamespace := ref.Namespace
+		if kameletNamespace == "" {
+			kameletNamespace = binding.Namespace
+		}
+
+		var kamelet *v1alpha1.Kamelet
+		if offline {
+			var ok bool
+			if kamelet, ok = params.offlineKamelet(kameletNamespace, ref.Name); !ok {
+				warnings = append(warnings, finding{Severity: severityWarning, Field: path.Child("ref", "name").String(),
+					Message: fmt.Sprintf("Kamelet %q is neither cached nor part of the bundled catalog, its properties are not verified", ref.Name)})
+				continue
+			}
+		} else {
+			var err error
+			if kamelet, err = params.getKamelet(kameletNamespace, ref.Name); apierrors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(path.Child("ref", "name"), ref.Name))
+				continue
+			} else if err != nil {
+				return result, err
+			}
+		}
+
+		kameletErrs, unknown := validateKameletEndpoint(kamelet, endpoint.endpoint, endpoint.kameletType, path)
+		errs = append(errs, kameletErrs...)
+		for _, name := range unknown {
+			unknownPath := path.Child("properties").Key(name)
+			if strict {
+				errs = append(errs, field.NotSupported(unknownPath, name, nil))
+				continue
+			}
+			warnings = append(warnings, finding{Severity: severityWarning, Field: unknownPath.String(),
+				Message: fmt.Sprintf("property is not defined by Kamelet %q", kamelet.Name)})
+		}
+	}
+
+	result.Findings = decodeErrors
+	for _, err := range errs {
+		result.Findings = append(result.Findings, finding{Severity: severityError, Field: err.Field, Message: err.ErrorBody()})
+	}
+	result.Findings = append(result.Findings, warnings...)
+	result.Valid = len(decodeErrors) == 0 && len(errs) == 0
+	return result, nil
+}
+
+// validateBinding checks the metadata of the binding and that source and sink are given by a reference or URI, sink
+// references must be of a supported kind
+func validateBinding(binding *v1alpha1.KameletBinding, sinkTypes map[string]v1.TypeMeta) field.ErrorList {
+	var errs field.ErrorList
+	namePath := field.NewPath("metadata", "name")
+	if binding.Name == "" {
+		errs = append(errs, field.Required(namePath, ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(binding.Name) {
+			errs = append(errs, field.Invalid(namePath, binding.Name, msg))
+		}
+	}
+	errs = append(errs, metav1validation.ValidateLabels(binding.Labels, field.NewPath("metadata", "labels"))...)
+	errs = append(errs, apivalidation.ValidateAnnotations(binding.Annotations, field.NewPath("metadata", "annotations"))...)
+
+	errs = append(errs, validateEndpoint(binding.Spec.Source, field.NewPath("spec", "source"))...)
+	sinkPath := field.NewPath("spec", "sink")
+	errs = append(errs, validateEndpoint(binding.Spec.Sink, sinkPath)...)
+	if ref := binding.Spec.Sink.Ref; ref != nil && ref.Kind != "" {
+		kinds := make([]string, 0, len(sinkTypes))
+		for _, sinkType := range sinkTypes {
+			kinds = append(kinds, sinkType.Kind)
+		}
+		sort.Strings(kinds)
+		if !containsString(kinds, ref.Kind) {
+			errs = append(errs, field.NotSupported(sinkPath.Child("ref", "kind"), ref.Kind, kinds))
+		}
+	}
+	return errs
+}
+
+// validateEndpoint checks that the endpoint is given by either a reference or URI and that its properties are a
+// JSON object
+func validateEndpoint(endpoint v1alpha1.Endpoint, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch {
+	case endpoint.Ref == nil && endpoint.URI == nil:
+		errs = append(errs, field.Required(path, "a ref or uri is required"))
+	case endpoint.Ref != nil && endpoint.URI != nil:
+		errs = append(errs, field.Forbidden(path.Child("uri"), "may not be combined with ref"))
+	case endpoint.Ref != nil:
+		if endpoint.Ref.Kind == "" {
+			errs = append(errs, field.Required(path.Child("ref", "kind"), ""))
+		}
+		if endpoint.Ref.Name == "" {
+			errs = append(errs, field.Required(path.Child("ref", "name"), ""))
+		}
+	}
+	if _, err := kameletapi.DecodeEndpointProperties(endpoint.Properties); err != nil {
+		errs = append(errs, field.Invalid(path.Child("properties"), "", err.Error()))
+	}
+	return errs
+}
+
+// validateKameletEndpoint checks the Kamelet type, the required properties and the property values of the endpoint
+// against the Kamelet definition, returns the errors and the sorted names of the properties unknown to the Kamelet
+func validateKameletEndpoint(kamelet *v1alpha1.Kamelet, endpoint *v1alpha1.Endpoint, kameletType string, path *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	if kameletapi.TypeOf(kamelet) != kameletType {
+		errs = append(errs, field.Invalid(path.Child("ref", "name"), kamelet.Name, fmt.Sprintf("Kamelet is not an event %s", kameletType)))
+	}
+	definition := kamelet.Spec.Definition
+	properties, err := kameletapi.DecodeEndpointProperties(endpoint.Properties)
+	if definition == nil || err != nil {
+		return errs, nil
+	}
+
+	propertiesPath := path.Child("properties")
+	for _, name := range definition.Required {
+		if _, ok := properties[name]; !ok {
+			errs = append(errs, field.Required(propertiesPath.Key(name), fmt.Sprintf("required by Kamelet %q", kamelet.Name)))
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var unknown []string
+	for _, name := range names {
+		schema, ok := definition.Properties[name]
+		if !ok {
+			if len(definition.Properties) > 0 {
+				unknown = append(unknown, name)
+			}
+			continue
+		}
+		if value := properties[name]; !kameletapi.IsPropertyReference(value) {
+			if err := kameletapi.ValidateValue(schema, value); err != nil {
+				errs = append(errs, field.Invalid(propertiesPath.Key(name), value, err.Error()))
+			}
+		}
+	}
+	return errs, unknown
+}
+
+// writeValidationResults prints the results in given format, by default as one line per binding followed by its findings
+func writeValidationResults(out io.Writer, results []validationResult, output string) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	for _, result := range results {
+		if result.Valid {
+			fmt.Fprintf(out, "%s: KameletBinding '%s' is valid.\n", result.Source, result.Name)
+		} else {
+			fmt.Fprintf(out, "%s: KameletBinding '%s' is invalid.\n", result.Source, result.Name)
+		}
+		for _, f := range result.Findings {
+			if f.Field != "" {
+				fmt.Fprintf(out, "  %s: %s: %s\n", f.Severity, f.Field, f.Message)
+			} else {
+				fmt.Fprintf(out, "  %s: %s\n", f.Severity, f.Message)
+			}
+		}
+	}
+	return nil
+}
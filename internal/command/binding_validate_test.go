@@ -0,0 +1,198 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var validBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: b1
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+    properties:
+      message: Hello
+      period: 1000
+  sink:
+    ref:
+      apiVersion: eventing.knative.dev/v1
+      kind: Broker
+      name: default
+`
+
+var invalidBindingManifest = `apiVersion: camel.apache.org/v1alpha1
+kind: KameletBinding
+metadata:
+  name: B2
+  labels:
+    team: events!
+spec:
+  source:
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: k1
+    properties:
+      period: often
+      priod: 1000
+  sink:
+    ref:
+      apiVersion: v1
+      kind: ConfigMap
+      name: events
+  sinc: typo
+`
+
+func validatedKamelet() *camelkapis.Kamelet {
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"message": {Type: "string"},
+		"period":  {Type: "integer"},
+	}
+	return kamelet
+}
+
+func TestBindingValidate(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(validatedKamelet(), nil)
+	output, err := runBindingValidateCmd(mockClient, validBindingManifest, "-f", "-")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "stdin: KameletBinding 'b1' is valid.\n")
+
+	recorder.Get(validatedKamelet(), nil)
+	output, err = runBindingValidateCmd(mockClient, invalidBindingManifest, "-f", "-")
+	assert.Error(t, err, "1 of 1 KameletBindings are invalid")
+	assert.Check(t, util.ContainsAll(output, "stdin: KameletBinding 'B2' is invalid.\n",
+		"  error: json: unknown field \"sinc\"\n",
+		"  error: metadata.name: Invalid value: \"B2\": a DNS-1123 subdomain must consist of lower case",
+		"  error: metadata.labels: Invalid value: \"events!\"",
+		"  error: spec.sink.ref.kind: Unsupported value: \"ConfigMap\": supported values: \"Broker\", ",
+		"  error: spec.source.properties[message]: Required value: required by Kamelet \"k1\"\n",
+		"  error: spec.source.properties[period]: Invalid value: \"often\": expected type integer\n",
+		"  warning: spec.source.properties[priod]: property is not defined by Kamelet \"k1\"\n"))
+
+	recorder.Get(nil, notFound("k1"))
+	output, err = runBindingValidateCmd(mockClient, validBindingManifest, "-f", "-")
+	assert.Error(t, err, "1 of 1 KameletBindings are invalid")
+	assert.Check(t, util.ContainsAll(output, "  error: spec.source.ref.name: Not found: \"k1\"\n"))
+
+	recorder.Validate()
+}
+
+func TestBindingValidateOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(validatedKamelet(), nil)
+	recorder.Get(validatedKamelet(), nil)
+	output, err := runBindingValidateCmd(mockClient, validBindingManifest+"---\n"+invalidBindingManifest, "-f", "-", "-o", "json", "--strict")
+	assert.Error(t, err, "1 of 2 KameletBindings are invalid")
+
+	var results []validationResult
+	assert.NilError(t, json.Unmarshal([]byte(output), &results))
+	assert.Equal(t, len(results), 2)
+	assert.Check(t, results[0].Valid)
+	assert.Equal(t, results[0].Namespace, "current")
+	assert.Check(t, !results[1].Valid)
+	assert.DeepEqual(t, results[1].Findings[len(results[1].Findings)-1], finding{
+		Severity: severityError,
+		Field:    "spec.source.properties[priod]",
+		Message:  "Unsupported value: \"priod\"",
+	})
+
+	output, err = runBindingValidateCmd(mockClient, strings.Replace(validBindingManifest, "k1", "timer-source", 1), "-f", "-", "--offline", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "- name: b1\n  namespace: default\n  source: stdin\n  valid: true\n")
+
+	output, err = runBindingValidateCmd(mockClient, validBindingManifest, "-f", "-", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'b1' is valid.\n",
+		"  warning: spec.source.ref.name: Kamelet \"k1\" is neither cached nor part of the bundled catalog"))
+
+	recorder.Validate()
+}
+
+func TestBindingValidateOfflineRoundTrip(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	// large integers are encoded as JSON numbers by bind and must validate as integers again
+	manifest, err := runBindCmd(mockClient, "timer-source", "--broker", "default", "--offline", "-n", "test",
+		"--source-property", "message=Hello", "--source-property", "period=9007199254740993")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(manifest, "period: 9007199254740993"))
+
+	output, err := runBindingValidateCmd(mockClient, manifest, "-f", "-", "--offline")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "stdin: KameletBinding 'timer-source-to-broker-default' is valid.\n")
+
+	output, err = runBindingValidateCmd(mockClient, strings.Replace(manifest, "9007199254740993", "1000000", 1), "-f", "-", "--offline")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "stdin: KameletBinding 'timer-source-to-broker-default' is valid.\n")
+	recorder.Validate()
+}
+
+func TestBindingValidateErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindingValidateCmd(mockClient, "")
+	assert.Error(t, err, "missing manifests, use --filename to specify them")
+
+	_, err = runBindingValidateCmd(mockClient, validBindingManifest, "-f", "-", "-o", "wide")
+	assert.Error(t, err, "invalid output format \"wide\", expected one of: json|yaml")
+
+	_, err = runBindingValidateCmd(mockClient, "kind: ConfigMap", "-f", "-")
+	assert.Error(t, err, "no KameletBinding found in given manifests")
+	recorder.Validate()
+}
+
+func runBindingValidateCmd(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs(append([]string{"binding", "validate"}, options...))
+	bindingCmd.SetIn(strings.NewReader(input))
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
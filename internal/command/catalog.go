@@ -0,0 +1,257 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands/flags"
+	hprinters "knative.dev/client/pkg/printers"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	"knative.dev/kn-plugin-source-kamelet/internal/catalog"
+)
+
+var catalogListExample = `
+  # List the Kamelet sources of the upstream catalog release matching the bundled catalog
+  kn-source-kamelet catalog list
+
+  # List Kamelets of all types of given catalog release
+  kn-source-kamelet catalog list --type all --catalog-version v0.2.1
+
+  # Describe a Kamelet of the upstream catalog that is not installed in the cluster yet
+  kn-source-kamelet catalog describe aws-s3-source
+
+  # Print the Kamelet definition of the upstream catalog as YAML
+  kn-source-kamelet catalog describe aws-s3-source -o yaml`
+
+// catalogVersionPattern restricts the catalog versions to release tag names as they are part of URLs and file names
+var catalogVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// NewCatalogCommand implements 'kn-source-kamelet catalog' command
+func NewCatalogCommand(p *KameletPluginParams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Browse the upstream Kamelet catalog and upgrade the installed Kamelets",
+		Long: `Browse the upstream Kamelet catalog and upgrade the installed Kamelets.
+
+The Kamelets are read from the releases of the apache/camel-kamelets GitHub repository, so Kamelets can be discovered
+before they are installed in the cluster. Downloaded catalog releases are kept in the local cache.`,
+		Example: catalogListExample,
+	}
+	cmd.AddCommand(newCatalogListCommand(p))
+	cmd.AddCommand(newCatalogDescribeCommand(p))
+	cmd.AddCommand(newCatalogUpgradeCommand(p))
+	return cmd
+}
+
+func newCatalogListCommand(p *KameletPluginParams) *cobra.Command {
+	listFlags := flags.NewListPrintFlags(CatalogListHandlers)
+	var version string
+	var filters kameletFilters
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the Kamelets of the upstream catalog",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := filters.verify(); err != nil {
+				return err
+			}
+			kamelets, err := p.catalogKamelets(version, nil)
+			if err != nil {
+				return err
+			}
+
+			kameletList := &v1alpha1.KameletList{Items: filters.filter(kamelets)}
+			setKameletListKind(kameletList)
+			if len(kameletList.Items) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+				return nil
+			}
+			return printList(listFlags, kameletList, cmd.OutOrStdout())
+		},
+	}
+	addCatalogVersionFlag(cmd.Flags(), &version)
+	filters.addFlags(cmd.Flags())
+	addListPrintFlags(cmd, listFlags)
+	return cmd
+}
+
+func newCatalogDescribeCommand(p *KameletPluginParams) *cobra.Command {
+	printFlags := genericclioptions.NewPrintFlags("")
+	var version string
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show details of a Kamelet of the upstream catalog",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet catalog describe' requires the Kamelet name given as single argument")
+			}
+			kamelets, err := p.catalogKamelets(version, nil)
+			if err != nil {
+				return err
+			}
+
+			var kamelet *v1alpha1.Kamelet
+			for i := range kamelets {
+				if kamelets[i].Name == args[0] {
+					kamelet = &kamelets[i]
+				}
+			}
+			if kamelet == nil {
+				return fmt.Errorf("Kamelet %s not found in catalog %s", args[0], version)
+			}
+			kamelet.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.KameletKind))
+
+			out := cmd.OutOrStdout()
+			if printFlags.OutputFlagSpecified() {
+				printer, err := printFlags.ToPrinter()
+				if err != nil {
+					return err
+				}
+				return printer.PrintObj(kamelet, out)
+			}
+
+			dw := hprinters.NewPrefixWriter(out)
+			dw.WriteAttribute("Name", kamelet.Name)
+			dw.WriteAttribute("Type", kameletapi.TypeOf(kamelet))
+			if definition := kamelet.Spec.Definition; definition != nil {
+				dw.WriteAttribute("Title", definition.Title)
+				dw.WriteAttribute("Description", strings.TrimSpace(definition.Description))
+			}
+			dw.WriteAttribute("Provider", kamelet.Annotations[providerAnnotation])
+			dw.WriteAttribute("Support Level", kamelet.Annotations[supportLevelAnnotation])
+			dw.WriteAttribute("Catalog Version", version)
+			dw.WriteLine()
+			writeKameletProperties(dw, kamelet, verbose)
+			return dw.Flush()
+		},
+	}
+	addCatalogVersionFlag(cmd.Flags(), &version)
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show the full property descriptions instead of their titles.")
+	printFlags.AddFlags(cmd)
+	return cmd
+}
+
+// addCatalogVersionFlag adds the flag selecting the release of the upstream catalog
+func addCatalogVersionFlag(flags *pflag.FlagSet, version *string) {
+	flags.StringVar(version, "catalog-version", catalog.SnapshotVersion, "Release of the apache/camel-kamelets catalog to read the Kamelets from.")
+}
+
+// catalogRepository returns the repository the upstream catalog is downloaded from, which the config file may
+// point to a mirror
+func (params *KameletPluginParams) catalogRepository() (string, error) {
+	config, err := params.pluginConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.CatalogRepository != "" {
+		return config.CatalogRepository, nil
+	}
+	return catalog.DefaultRepository, nil
+}
+
+// catalogKamelets returns the Kamelets of given catalog release, releases are immutable so downloaded releases are
+// cached without expiry unless refreshed. When a verifier is given, the release is always downloaded to verify the
+// signature of its archive.
+func (params *KameletPluginParams) catalogKamelets(version string, verifier *signatureVerifier) ([]v1alpha1.Kamelet, error) {
+	if !catalogVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid catalog version %q", version)
+	}
+
+	cacheFile, cacheErr := params.cacheFile("catalog-" + version + ".json")
+	if cacheErr == nil && !params.Refresh && verifier == nil {
+		if data, err := ioutil.ReadFile(cacheFile); err == nil {
+			var kamelets []v1alpha1.Kamelet
+			if json.Unmarshal(data, &kamelets) == nil {
+				return kamelets, nil
+			}
+		}
+	}
+
+	repository, err := params.catalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	var verify catalog.Verifier
+	if verifier != nil {
+		verify = verifier.verify
+	}
+	kamelets, err := catalog.Fetch(params.Context, &http.Client{Timeout: params.RequestTimeout}, repository, version, verify)
+	if err != nil {
+		return nil, err
+	}
+	if cacheErr == nil {
+		if data, err := json.Marshal(kamelets); err == nil {
+			_ = ioutil.WriteFile(cacheFile, data, 0600)
+		}
+	}
+	return kamelets, nil
+}
+
+// CatalogListHandlers handles printing human readable table for `kn-source-kamelet catalog list` command's output
+func CatalogListHandlers(h hprinters.PrintHandler) {
+	columns := []metav1beta1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Description: "Name of the Kamelet", Priority: 1},
+		{Name: "Type", Type: "string", Description: "Type of the Kamelet, e.g. source, sink or action", Priority: 1},
+		{Name: "Support Level", Type: "string", Description: "Maturity of the Kamelet", Priority: 1},
+		{Name: "Description", Type: "string", Description: "Summary of the Kamelet definition", Priority: 1},
+	}
+	h.TableHandler(columns, printCatalogKamelet)
+	h.TableHandler(columns, printCatalogKameletList)
+}
+
+// printCatalogKameletList populates the catalog Kamelet list table rows
+func printCatalogKameletList(kameletList *v1alpha1.KameletList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	rows := make([]metav1beta1.TableRow, 0, len(kameletList.Items))
+	for i := range kameletList.Items {
+		r, err := printCatalogKamelet(&kameletList.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+// printCatalogKamelet populates the catalog Kamelet table rows
+func printCatalogKamelet(kamelet *v1alpha1.Kamelet, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	row := metav1beta1.TableRow{
+		Object: runtime.RawExtension{Object: kamelet},
+	}
+	row.Cells = append(row.Cells,
+		kamelet.Name,
+		kameletapi.TypeOf(kamelet),
+		kamelet.Annotations[supportLevelAnnotation],
+		kameletSummary(kamelet))
+	return []metav1beta1.TableRow{row}, nil
+}
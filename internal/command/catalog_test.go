@@ -0,0 +1,180 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const catalogKamelet = `apiVersion: camel.apache.org/v1alpha1
+kind: Kamelet
+metadata:
+  name: %s
+  annotations:
+    camel.apache.org/kamelet.support.level: "Preview"
+  labels:
+    camel.apache.org/kamelet.type: "%s"
+spec:
+  definition:
+    title: "%s"
+    description: "Test %s"
+    required:
+      - topic
+    properties:
+      topic:
+        title: Topic Names
+        type: string
+`
+
+func TestCatalogList(t *testing.T) {
+	p, requests := catalogParams(t, kamelettesting.NewMockKameletClient(t))
+
+	output, err := runPipeCmd(p, NewCatalogCommand(p), "catalog", "list", "--catalog-version", "v0.4.0")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "NAME", "SUPPORT LEVEL", "kafka-source", "source", "Preview", "Test Kafka Source"))
+	assert.Check(t, util.ContainsNone(output, "kafka-sink", "template-source"))
+
+	// the downloaded release is served from the cache
+	output, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "list", "--catalog-version", "v0.4.0", "--type", "all", "-o", "name")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kamelet.camel.apache.org/kafka-sink\nkamelet.camel.apache.org/kafka-source\n")
+	assert.Equal(t, *requests, 1)
+
+	p.Refresh = true
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "list", "--catalog-version", "v0.4.0")
+	assert.NilError(t, err)
+	assert.Equal(t, *requests, 2)
+}
+
+func TestCatalogDescribe(t *testing.T) {
+	p, _ := catalogParams(t, kamelettesting.NewMockKameletClient(t))
+
+	output, err := runPipeCmd(p, NewCatalogCommand(p), "catalog", "describe", "kafka-sink", "--catalog-version", "v0.4.0")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Name:", "kafka-sink", "Type:", "sink", "Catalog Version:", "v0.4.0", "Required Properties", "topic", "Topic Names"))
+
+	output, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "describe", "kafka-sink", "--catalog-version", "v0.4.0", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "apiVersion: camel.apache.org/v1alpha1", "kind: Kamelet", "name: kafka-sink"))
+
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "describe", "timer-source", "--catalog-version", "v0.4.0")
+	assert.Error(t, err, "Kamelet timer-source not found in catalog v0.4.0")
+}
+
+func TestCatalogErrors(t *testing.T) {
+	p, _ := catalogParams(t, kamelettesting.NewMockKameletClient(t))
+
+	_, err := runPipeCmd(p, NewCatalogCommand(p), "catalog", "list", "--catalog-version", "v9.9.9")
+	assert.ErrorContains(t, err, "Kamelet catalog version v9.9.9 not found")
+
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "list", "--catalog-version", "../v0.4.0")
+	assert.Error(t, err, "invalid catalog version \"../v0.4.0\"")
+}
+
+func TestCatalogUpgrade(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p, _ := catalogParams(t, mockClient)
+
+	release, err := p.catalogKamelets("v0.4.0", nil)
+	assert.NilError(t, err)
+	unchanged := release[0].DeepCopy()
+	unchanged.Namespace = "current"
+	changed := release[1].DeepCopy()
+	changed.Namespace = "current"
+	changed.Spec.Definition.Required = nil
+	removed := createKameletInNamespace("old-source", "current")
+	removed.Annotations = map[string]string{catalogVersionAnnotation: "0.3.0"}
+	installed := &camelkapis.KameletList{Items: []camelkapis.Kamelet{*unchanged, *changed, *removed, *createKameletInNamespace("custom-source", "current")}}
+
+	recorder.List(installed, nil)
+	output, err := runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.4.0", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'kafka-source' would be updated.\n"+
+		"Kamelet 'old-source' is not part of catalog v0.4.0, delete it with 'kn-source-kamelet kamelet delete old-source'.\n"+
+		"Catalog v0.4.0: 0 added, 1 changed, 1 removed, 1 unchanged.\n")
+
+	installed.Items = installed.Items[1:]
+	recorder.List(installed, nil)
+	recorder.Get(nil, notFound("kafka-sink"))
+	recorder.Create(func(t *testing.T, kamelet *camelkapis.Kamelet) {
+		assert.Equal(t, kamelet.Name, "kafka-sink")
+		assert.Equal(t, kamelet.Namespace, "current")
+	}, nil)
+	recorder.Get(changed, nil)
+	recorder.Update(func(t *testing.T, kamelet *camelkapis.Kamelet) {
+		assert.Equal(t, kamelet.Name, "kafka-source")
+		assert.DeepEqual(t, kamelet.Spec.Definition.Required, []string{"topic"})
+	}, nil)
+	output, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.4.0")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Kamelet 'kafka-sink' created in namespace 'current'.", "Kamelet 'kafka-source' updated in namespace 'current'.",
+		"Catalog v0.4.0: 1 added, 1 changed, 1 removed, 0 unchanged."))
+
+	recorder.Validate()
+}
+
+// catalogParams serves a catalog release v0.4.0 holding Kafka Kamelets and counts the downloads
+func catalogParams(t *testing.T, c *kamelettesting.MockKameletClient) (*KameletPluginParams, *int) {
+	archive := catalogArchive(t, map[string]string{
+		"camel-kamelets-0.4.0/kafka-source.kamelet.yaml":              fmt.Sprintf(catalogKamelet, "kafka-source", "source", "Kafka Source", "Kafka Source"),
+		"camel-kamelets-0.4.0/kamelets/kafka-sink.kamelet.yaml":       fmt.Sprintf(catalogKamelet, "kafka-sink", "sink", "Kafka Sink", "Kafka Sink"),
+		"camel-kamelets-0.4.0/templates/template-source.kamelet.yaml": "invalid",
+		"camel-kamelets-0.4.0/README.md":                              "# Kamelets",
+	})
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apache/camel-kamelets/archive/v0.4.0.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		_, _ = w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+
+	p := cacheParams(t, c)
+	p.Config = &PluginConfig{CatalogRepository: server.URL + "/apache/camel-kamelets"}
+	return p, &requests
+}
+
+func catalogArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	for name, content := range files {
+		assert.NilError(t, archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := archive.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, archive.Close())
+	assert.NilError(t, gz.Close())
+	return buf.Bytes()
+}
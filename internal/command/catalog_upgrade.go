@@ -0,0 +1,207 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var catalogUpgradeExample = `
+  # Show the Kamelets added, changed and removed by given catalog release without applying them
+  kn-source-kamelet catalog upgrade --catalog-version v0.4.0 --dry-run client
+
+  # Upgrade the Kamelets of the current namespace to given catalog release
+  kn-source-kamelet catalog upgrade --catalog-version v0.4.0
+
+  # Upgrade the Kamelets after verifying the signature of the release archive created with 'cosign sign-blob --key cosign.key'
+  kn-source-kamelet catalog upgrade --catalog-version v0.4.0 --verify-signature --key cosign.pub`
+
+// catalogVersionAnnotation holds the version of the catalog the Kamelet has been released with
+const catalogVersionAnnotation = "camel.apache.org/catalog.version"
+
+// catalogDiff holds the differences between the installed Kamelets and a catalog release
+type catalogDiff struct {
+	added     []*v1alpha1.Kamelet
+	changed   []*v1alpha1.Kamelet
+	removed   []string
+	unchanged int
+}
+
+// newCatalogUpgradeCommand implements 'kn-source-kamelet catalog upgrade' command
+func newCatalogUpgradeCommand(p *KameletPluginParams) *cobra.Command {
+	var version string
+	var dryRun string
+	var signature signatureFlags
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the installed Kamelets to a release of the upstream catalog",
+		Long: `Upgrade the installed Kamelets to a release of the upstream catalog.
+
+Kamelets of the release that are not installed are added and installed Kamelets with a different definition are
+updated. Installed catalog Kamelets, i.e. Kamelets annotated with '` + catalogVersionAnnotation + `', that are no
+longer part of the release are reported but not deleted.`,
+		Example: catalogUpgradeExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			verifier, err := signature.verifier()
+			if err != nil {
+				return err
+			}
+			kamelets, err := p.catalogKamelets(version, verifier)
+			if err != nil {
+				return err
+			}
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+			installed, err := client.Kamelets(namespace).List(p.Context, v1.ListOptions{})
+			if err != nil {
+				return knerrors.GetError(err)
+			}
+
+			diff, err := diffCatalog(installed.Items, kamelets)
+			if err != nil {
+				return err
+			}
+
+			// the changes reported on client dry-run are the requested output
+			out := cmd.OutOrStdout()
+			messages := p.messageOutput(out)
+			if dryRun == dryRunClient {
+				for _, kamelet := range diff.added {
+					fmt.Fprintf(out, "Kamelet '%s' would be added.\n", kamelet.Name)
+				}
+				for _, kamelet := range diff.changed {
+					fmt.Fprintf(out, "Kamelet '%s' would be updated.\n", kamelet.Name)
+				}
+			} else {
+				audit := p.auditLog()
+				for _, kamelet := range append(diff.added, diff.changed...) {
+					kamelet.Namespace = namespace
+					if err := applyKamelet(p.Context, client, kamelet, dryRun == dryRunServer, audit, messages); err != nil {
+						return err
+					}
+				}
+			}
+			for _, name := range diff.removed {
+				fmt.Fprintf(messages, "Kamelet '%s' is not part of catalog %s, delete it with 'kn-source-kamelet kamelet delete %s'.\n", name, version, name)
+			}
+			fmt.Fprintf(messages, "Catalog %s: %d added, %d changed, %d removed, %d unchanged.\n",
+				version, len(diff.added), len(diff.changed), len(diff.removed), diff.unchanged)
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	addCatalogVersionFlag(cmd.Flags(), &version)
+	cmd.Flags().StringVar(&dryRun, "dry-run", "", "Only report (client) or validate on the API server (server) the changes without applying them. One of: client|server.")
+	signature.addFlags(cmd.Flags(), "Verify the signature of the catalog release before applying its Kamelets, the signature is read from the URL of the release archive with the .sig suffix.")
+	return cmd
+}
+
+// diffCatalog compares the installed Kamelets with the Kamelets of a catalog release, Kamelets are considered changed
+// when their labels, annotations or spec differ
+func diffCatalog(installed []v1alpha1.Kamelet, release []v1alpha1.Kamelet) (*catalogDiff, error) {
+	byName := map[string]*v1alpha1.Kamelet{}
+	for i := range installed {
+		byName[installed[i].Name] = &installed[i]
+	}
+
+	diff := &catalogDiff{}
+	inRelease := map[string]bool{}
+	for i := range release {
+		kamelet := release[i].DeepCopy()
+		inRelease[kamelet.Name] = true
+		existing, ok := byName[kamelet.Name]
+		if !ok {
+			diff.added = append(diff.added, kamelet)
+			continue
+		}
+		changed, err := kameletChanged(existing, kamelet)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			diff.changed = append(diff.changed, kamelet)
+		} else {
+			diff.unchanged++
+		}
+	}
+
+	for i := range installed {
+		if _, ok := installed[i].Annotations[catalogVersionAnnotation]; ok && !inRelease[installed[i].Name] {
+			diff.removed = append(diff.removed, installed[i].Name)
+		}
+	}
+	return diff, nil
+}
+
+// kameletChanged checks whether applying the released Kamelet changes the installed Kamelet. The specs are compared
+// as JSON as the raw values of the property schemas differ in formatting between YAML manifests and the cluster.
+func kameletChanged(installed *v1alpha1.Kamelet, released *v1alpha1.Kamelet) (bool, error) {
+	for key, value := range released.Labels {
+		if installed.Labels[key] != value {
+			return true, nil
+		}
+	}
+	for key, value := range released.Annotations {
+		if installed.Annotations[key] != value {
+			return true, nil
+		}
+	}
+
+	installedSpec, err := normalizeJSON(installed.Spec)
+	if err != nil {
+		return false, err
+	}
+	releasedSpec, err := normalizeJSON(released.Spec)
+	if err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(installedSpec, releasedSpec), nil
+}
+
+// normalizeJSON returns the generic JSON representation of the value
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// defaultChunkSize is the number of resources list commands request per API call unless given otherwise
+const defaultChunkSize = 500
+
+// addChunkSizeFlag adds the flag setting the number of resources requested per API call of list commands
+func addChunkSizeFlag(flags *pflag.FlagSet, chunkSize *int64) {
+	flags.Int64Var(chunkSize, "chunk-size", defaultChunkSize, "Return large lists in chunks of given size rather than all at once, 0 disables chunking.")
+}
+
+// listInChunks lists the resources in chunks of given size by following the continue token of the responses and
+// returns the first chunk holding the items of all chunks. When the continue token expires before all chunks are
+// read, the resources are listed again in a single call.
+func listInChunks(opts v1.ListOptions, chunkSize int64, list func(opts v1.ListOptions) (runtime.Object, error)) (runtime.Object, error) {
+	if chunkSize < 0 {
+		return nil, fmt.Errorf("--chunk-size must not be negative")
+	}
+	opts.Limit = chunkSize
+	result, err := list(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []runtime.Object
+	chunk := result
+	for {
+		chunkItems, err := meta.ExtractList(chunk)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, chunkItems...)
+
+		listMeta, err := meta.ListAccessor(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if listMeta.GetContinue() == "" {
+			break
+		}
+		opts.Continue = listMeta.GetContinue()
+		chunk, err = list(opts)
+		if apierrors.IsResourceExpired(err) {
+			opts.Limit, opts.Continue = 0, ""
+			return list(opts)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	listMeta, err := meta.ListAccessor(result)
+	if err != nil {
+		return nil, err
+	}
+	listMeta.SetContinue("")
+	return result, meta.SetList(result, items)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestListInChunks(t *testing.T) {
+	chunks := map[string]*camelkapis.KameletList{
+		"": {ListMeta: v1.ListMeta{ResourceVersion: "1", Continue: "c1"},
+			Items: []camelkapis.Kamelet{*createKamelet("k1"), *createKamelet("k2")}},
+		"c1": {ListMeta: v1.ListMeta{ResourceVersion: "1", Continue: "c2"},
+			Items: []camelkapis.Kamelet{*createKamelet("k3"), *createKamelet("k4")}},
+		"c2": {ListMeta: v1.ListMeta{ResourceVersion: "1"},
+			Items: []camelkapis.Kamelet{*createKamelet("k5")}},
+	}
+	var requests []v1.ListOptions
+	list := func(opts v1.ListOptions) (runtime.Object, error) {
+		requests = append(requests, opts)
+		if opts.Limit == 0 {
+			return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("all")}}, nil
+		}
+		if chunk, ok := chunks[opts.Continue]; ok {
+			return chunk.DeepCopy(), nil
+		}
+		return nil, apierrors.NewResourceExpired("continue token expired")
+	}
+
+	result, err := listInChunks(v1.ListOptions{LabelSelector: "team=events"}, 2, list)
+	assert.NilError(t, err)
+	kameletList := result.(*camelkapis.KameletList)
+	assert.Equal(t, kameletList.ResourceVersion, "1")
+	assert.Equal(t, kameletList.Continue, "")
+	var names []string
+	for _, kamelet := range kameletList.Items {
+		names = append(names, kamelet.Name)
+	}
+	assert.DeepEqual(t, names, []string{"k1", "k2", "k3", "k4", "k5"})
+	assert.DeepEqual(t, requests, []v1.ListOptions{
+		{LabelSelector: "team=events", Limit: 2},
+		{LabelSelector: "team=events", Limit: 2, Continue: "c1"},
+		{LabelSelector: "team=events", Limit: 2, Continue: "c2"},
+	})
+
+	// expired continue tokens fall back to listing all resources at once
+	chunks["c1"].Continue = "expired"
+	requests = nil
+	result, err = listInChunks(v1.ListOptions{}, 2, list)
+	assert.NilError(t, err)
+	assert.Equal(t, len(result.(*camelkapis.KameletList).Items), 1)
+	assert.Equal(t, len(requests), 4)
+	assert.DeepEqual(t, requests[3], v1.ListOptions{})
+
+	_, err = listInChunks(v1.ListOptions{}, -1, list)
+	assert.Error(t, err, "--chunk-size must not be negative")
+}
+
+func TestBindingListChunks(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{ListMeta: v1.ListMeta{Continue: "c1"},
+		Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1")}}, nil)
+	recorder.ListBindings(&camelkapis.KameletBindingList{
+		Items: []camelkapis.KameletBinding{*createKameletBinding("b2", "k2")}}, nil)
+	output, err := runBindingListCmd(mockClient, "--chunk-size", "1", "-o", "name")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kameletbinding.camel.apache.org/b1\nkameletbinding.camel.apache.org/b2\n")
+
+	recorder.Validate()
+}
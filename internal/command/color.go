@@ -0,0 +1,155 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences of the colors of status values
+const (
+	colorGreen  = "\x1b[32m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// coloredColumns are the table columns whose values are colored
+var coloredColumns = []string{"PHASE", "READY"}
+
+// isTerminalOutput checks if given output is a terminal, colors are only written to terminals
+var isTerminalOutput = func(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// AddNoColorFlag adds the flag disabling colored output to given flag set
+func (params *KameletPluginParams) AddNoColorFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(&params.NoColor, "no-color", false, "Do not color the phase and ready state of resources. Colors are also disabled by the NO_COLOR environment variable and when the output is not a terminal.")
+}
+
+// colorsEnabled checks if status values written to given output are colored
+func (params *KameletPluginParams) colorsEnabled(out io.Writer) bool {
+	return !params.NoColor && os.Getenv("NO_COLOR") == "" && isTerminalOutput(out)
+}
+
+// statusColor returns the color of a phase or a condition status, green for ready, red for failed and yellow for
+// pending or unknown states
+func statusColor(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "ready", "running":
+		return colorGreen
+	case "false", "error":
+		return colorRed
+	case "":
+		return ""
+	}
+	return colorYellow
+}
+
+// colorStatus colors the phase or condition status when colors are enabled
+func colorStatus(value string, enabled bool) string {
+	color := statusColor(value)
+	if !enabled || color == "" {
+		return value
+	}
+	return color + value + colorReset
+}
+
+// printColoredTable colors the values of the phase and ready columns of the table written by print. The values are
+// colored after the table layout is done, as the escape sequences would otherwise count for the column widths.
+func (params *KameletPluginParams) printColoredTable(out io.Writer, print func(out io.Writer) error) error {
+	if !params.colorsEnabled(out) {
+		return print(out)
+	}
+	var buf bytes.Buffer
+	if err := print(&buf); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, colorTable(buf.String()))
+	return err
+}
+
+// colorTable colors the cells of the colored columns, the columns are located by the table header so tables without
+// header are printed unchanged
+func colorTable(table string) string {
+	lines := strings.SplitAfter(table, "\n")
+	starts := columnStarts(strings.TrimSuffix(lines[0], "\n"))
+
+	var colored strings.Builder
+	colored.WriteString(lines[0])
+	for _, line := range lines[1:] {
+		colored.WriteString(colorLine(line, starts))
+	}
+	return colored.String()
+}
+
+// columnStarts returns the start positions of the columns in the header line by column name
+func columnStarts(header string) map[string]int {
+	starts := map[string]int{}
+	runes := []rune(header)
+	for i, r := range runes {
+		if !unicode.IsSpace(r) && (i == 0 || unicode.IsSpace(runes[i-1])) {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			starts[string(runes[i:end])] = i
+		}
+	}
+	return starts
+}
+
+// colorLine colors the cells of the colored columns in the table line
+func colorLine(line string, starts map[string]int) string {
+	runes := []rune(line)
+	type span struct{ start, end int }
+	var spans []span
+	for _, column := range coloredColumns {
+		start, ok := starts[column]
+		if !ok || start >= len(runes) || unicode.IsSpace(runes[start]) {
+			continue
+		}
+		end := start
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+		spans = append(spans, span{start, end})
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var colored strings.Builder
+	last := 0
+	for _, s := range spans {
+		colored.WriteString(string(runes[last:s.start]))
+		colored.WriteString(colorStatus(string(runes[s.start:s.end]), true))
+		last = s.end
+	}
+	colored.WriteString(string(runes[last:]))
+	return colored.String()
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestColorTable(t *testing.T) {
+	table := "NAME   PHASE      READY     AGE\n" +
+		"b1     Ready      True      1m\n" +
+		"b2     Creating   Unknown   1m\n" +
+		"b3                False     1m\n"
+	assert.Equal(t, colorTable(table), "NAME   PHASE      READY     AGE\n"+
+		"b1     \x1b[32mReady\x1b[0m      \x1b[32mTrue\x1b[0m      1m\n"+
+		"b2     \x1b[33mCreating\x1b[0m   \x1b[33mUnknown\x1b[0m   1m\n"+
+		"b3                \x1b[31mFalse\x1b[0m     1m\n")
+
+	assert.Equal(t, colorStatus("Error", true), "\x1b[31mError\x1b[0m")
+	assert.Equal(t, colorStatus("Error", false), "Error")
+
+	// tables without the colored columns are unchanged
+	assert.Equal(t, colorTable("NAME   AGE\nb1     1m\n"), "NAME   AGE\nb1     1m\n")
+}
+
+func TestBindingListColors(t *testing.T) {
+	defer terminalOutput()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	failed := createKameletBinding("b2", "k2")
+	failed.Status.Phase = camelkapis.KameletBindingPhaseError
+	failed.Status.Conditions[0].Status = "False"
+	bindingList := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k1"), *failed}}
+
+	recorder.ListBindings(bindingList, nil)
+	output, err := runColoredBindingListCmd(mockClient, false)
+	assert.NilError(t, err)
+	lines := strings.Split(output, "\n")
+	assert.Check(t, strings.Contains(lines[1], "\x1b[32mReady\x1b[0m"))
+	assert.Check(t, strings.Contains(lines[1], "\x1b[32mTrue\x1b[0m"))
+	assert.Check(t, strings.Contains(lines[2], "\x1b[31mError\x1b[0m"))
+	assert.Check(t, strings.Contains(lines[2], "\x1b[31mFalse\x1b[0m"))
+
+	recorder.ListBindings(bindingList, nil)
+	output, err = runColoredBindingListCmd(mockClient, true)
+	assert.NilError(t, err)
+	assert.Check(t, !strings.Contains(output, "\x1b["))
+
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	recorder.ListBindings(bindingList, nil)
+	output, err = runColoredBindingListCmd(mockClient, false)
+	assert.NilError(t, err)
+	assert.Check(t, !strings.Contains(output, "\x1b["))
+
+	recorder.Validate()
+}
+
+// terminalOutput treats all outputs as terminal and returns a function restoring the terminal check
+func terminalOutput() func() {
+	isTerminal := isTerminalOutput
+	isTerminalOutput = func(out io.Writer) bool {
+		return true
+	}
+	return func() {
+		isTerminalOutput = isTerminal
+	}
+}
+
+func runColoredBindingListCmd(c *kamelettesting.MockKameletClient, noColor bool) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+		NoColor: noColor,
+	}
+
+	bindingCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindingCommand(&p), p.KnParams)
+	bindingCmd.SetArgs([]string{"binding", "list"})
+	err := bindingCmd.Execute()
+	return output.String(), err
+}
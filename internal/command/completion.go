@@ -0,0 +1,285 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+)
+
+// namespaceCacheTTL defines how long completed namespaces are reused before the cluster is queried again
+const namespaceCacheTTL = 5 * time.Minute
+
+// sinkFlagResources maps the sink flags to the Knative resources completed as their values
+var sinkFlagResources = map[string]schema.GroupVersionResource{
+	"broker":  {Group: "eventing.knative.dev", Version: "v1", Resource: "brokers"},
+	"channel": {Group: "messaging.knative.dev", Version: "v1", Resource: "channels"},
+	"service": {Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+}
+
+// namespaceCacheEntry holds the namespaces cached for a single kubeconfig context
+type namespaceCacheEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Namespaces []string  `json:"namespaces"`
+}
+
+// RegisterNamespaceCompletion registers dynamic completion of the --namespace flag on given command and all its sub commands
+func RegisterNamespaceCompletion(cmd *cobra.Command, p *KameletPluginParams) {
+	if cmd.Flags().Lookup("namespace") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return filterPrefix(p.completeNamespaces(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+	for _, sub := range cmd.Commands() {
+		RegisterNamespaceCompletion(sub, p)
+	}
+}
+
+// completeNamespaces returns the namespaces accessible by the user, served from the cache while it is fresh.
+// When listing namespaces is not permitted the current namespace is returned.
+func (params *KameletPluginParams) completeNamespaces() []string {
+	cacheKey := params.cacheKey()
+	cacheFile, err := params.cacheFile("namespaces.json")
+	if err != nil {
+		cacheFile = ""
+	}
+
+	cache := map[string]namespaceCacheEntry{}
+	if cacheFile != "" {
+		if data, err := ioutil.ReadFile(cacheFile); err == nil {
+			_ = json.Unmarshal(data, &cache)
+		}
+	}
+	if entry, ok := cache[cacheKey]; ok && time.Since(entry.Timestamp) < namespaceCacheTTL {
+		return entry.Namespaces
+	}
+
+	namespaces, err := params.listNamespaces()
+	if err != nil {
+		if current, err := params.CurrentNamespace(); err == nil && current != "" {
+			return []string{current}
+		}
+		return nil
+	}
+
+	if cacheFile != "" {
+		cache[cacheKey] = namespaceCacheEntry{Timestamp: time.Now(), Namespaces: namespaces}
+		if data, err := json.Marshal(cache); err == nil {
+			_ = ioutil.WriteFile(cacheFile, data, 0600)
+		}
+	}
+	return namespaces
+}
+
+func (params *KameletPluginParams) listNamespaces() ([]string, error) {
+	client, err := params.NewKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceList, err := client.CoreV1().Namespaces().List(params.Context, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// cacheFile returns the path of given file in the plugin cache directory, creating the directory if needed
+func (params *KameletPluginParams) cacheFile(name string) (string, error) {
+	dir := params.CacheDir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(userCacheDir, "kn-source-kamelet")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// completeKameletArg returns the completion of a single Kamelet name argument of given type
+func (params *KameletPluginParams) completeKameletArg(kameletType string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return params.completeKamelets(cmd, kameletType, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeKameletFlag returns the completion of a flag given a Kamelet name of given type
+func (params *KameletPluginParams) completeKameletFlag(kameletType string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return params.completeKamelets(cmd, kameletType, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeKamelets returns the names of the Kamelets of given type in the namespace of the command,
+// described by their title, served from the Kamelet cache while it is fresh. Completion is best effort,
+// so any failure results in no completions.
+func (params *KameletPluginParams) completeKamelets(cmd *cobra.Command, kameletType string, toComplete string) []string {
+	namespace, err := params.GetNamespace(cmd)
+	if err != nil {
+		return nil
+	}
+	kamelets, err := params.listKamelets(namespace)
+	if err != nil {
+		return nil
+	}
+
+	var completions []string
+	for i := range kamelets {
+		kamelet := &kamelets[i]
+		if kameletapi.TypeOf(kamelet) != kameletType || !strings.HasPrefix(kamelet.Name, toComplete) {
+			continue
+		}
+		completion := kamelet.Name
+		if kamelet.Spec.Definition != nil && kamelet.Spec.Definition.Title != "" {
+			completion += "\t" + kamelet.Spec.Definition.Title
+		}
+		completions = append(completions, completion)
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// registerPropertyCompletion registers completion of the property keys of the Kamelet source selected by
+// given function and of Kamelet sinks given with the --sink flag
+func (params *KameletPluginParams) registerPropertyCompletion(cmd *cobra.Command, source func(cmd *cobra.Command, args []string) string) {
+	_ = cmd.RegisterFlagCompletionFunc("source-property", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return params.completePropertyKeys(cmd, "source-property", source(cmd, args), toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("sink-property", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		sink := cmd.Flag("sink").Value.String()
+		if !strings.HasPrefix(sink, "kamelet:") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return params.completePropertyKeys(cmd, "sink-property", strings.TrimPrefix(sink, "kamelet:"), toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// completePropertyKeys returns the property keys defined by the Kamelet in the form of <key>= described by their title,
+// required properties are marked as such and keys already given with the flag are omitted
+func (params *KameletPluginParams) completePropertyKeys(cmd *cobra.Command, flagName string, kameletName string, toComplete string) []string {
+	if kameletName == "" || strings.Contains(toComplete, "=") {
+		return nil
+	}
+	namespace, err := params.GetNamespace(cmd)
+	if err != nil {
+		return nil
+	}
+	kamelet, err := params.getKamelet(namespace, kameletName)
+	if err != nil || kamelet.Spec.Definition == nil {
+		return nil
+	}
+
+	given := map[string]bool{}
+	if values, err := cmd.Flags().GetStringArray(flagName); err == nil {
+		for _, value := range values {
+			given[strings.SplitN(value, "=", 2)[0]] = true
+		}
+	}
+	required := map[string]bool{}
+	for _, name := range kamelet.Spec.Definition.Required {
+		required[name] = true
+	}
+
+	var completions []string
+	for name, property := range kamelet.Spec.Definition.Properties {
+		if given[name] || !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		description := property.Title
+		if required[name] {
+			description = strings.TrimSpace("(required) " + description)
+		}
+		completion := name + "="
+		if description != "" {
+			completion += "\t" + description
+		}
+		completions = append(completions, completion)
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// registerSinkCompletion registers completion of the --broker, --channel and --service flags with the names of
+// the Brokers, Channels and Knative Services in the namespace of the command
+func (params *KameletPluginParams) registerSinkCompletion(cmd *cobra.Command) {
+	for flagName, gvr := range sinkFlagResources {
+		gvr := gvr
+		_ = cmd.RegisterFlagCompletionFunc(flagName, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return params.completeResourceNames(cmd, gvr, toComplete), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+}
+
+// completeResourceNames returns the sorted names of the resources in the namespace of the command,
+// completion is best effort, so any failure such as a missing CRD results in no completions.
+func (params *KameletPluginParams) completeResourceNames(cmd *cobra.Command, gvr schema.GroupVersionResource, toComplete string) []string {
+	namespace, err := params.GetNamespace(cmd)
+	if err != nil {
+		return nil
+	}
+	client, err := params.NewDynamicClient()
+	if err != nil {
+		return nil
+	}
+	list, err := client.Resource(gvr).Namespace(namespace).List(params.Context, v1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, item := range list.Items {
+		if strings.HasPrefix(item.GetName(), toComplete) {
+			names = append(names, item.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterPrefix returns all values starting with given prefix
+func filterPrefix(values []string, prefix string) []string {
+	var result []string
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
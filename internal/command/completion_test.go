@@ -0,0 +1,251 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCompleteNamespaces(t *testing.T) {
+	p := completionParams(t, fake.NewSimpleClientset(namespace("test"), namespace("default"), namespace("kube-system")))
+
+	assert.DeepEqual(t, p.completeNamespaces(), []string{"default", "kube-system", "test"})
+
+	// namespaces are served from the cache once listed
+	p.NewKubeClient = func() (kubernetes.Interface, error) {
+		return nil, errors.New("should not be called")
+	}
+	assert.DeepEqual(t, p.completeNamespaces(), []string{"default", "kube-system", "test"})
+}
+
+func TestCompleteNamespacesFallback(t *testing.T) {
+	p := completionParams(t, nil)
+	p.NewKubeClient = func() (kubernetes.Interface, error) {
+		return nil, errors.New("forbidden")
+	}
+	p.KubeCfgPath = filepath.Join(p.CacheDir, "kubeconfig")
+	assert.NilError(t, ioutil.WriteFile(p.KubeCfgPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: c1
+  cluster:
+    server: https://localhost:6443
+contexts:
+- name: ctx1
+  context:
+    cluster: c1
+    namespace: current
+current-context: ctx1
+`), 0600))
+
+	assert.DeepEqual(t, p.completeNamespaces(), []string{"current"})
+}
+
+func TestRegisterNamespaceCompletion(t *testing.T) {
+	p := completionParams(t, fake.NewSimpleClientset(namespace("test"), namespace("default")))
+
+	rootCmd := &cobra.Command{Use: "kn-source-kamelet"}
+	rootCmd.AddCommand(NewBindCommand(p), NewBindingCommand(p))
+	RegisterNamespaceCompletion(rootCmd, p)
+
+	for _, args := range [][]string{{"bind", "k1", "-n", "t"}, {"binding", "delete", "b1", "--namespace", "t"}} {
+		output := &bytes.Buffer{}
+		rootCmd.SetOut(output)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		assert.NilError(t, rootCmd.Execute())
+		assert.Check(t, util.ContainsAll(output.String(), "test", ":4"))
+		assert.Check(t, util.ContainsNone(output.String(), "default"))
+	}
+}
+
+func TestKameletCompletion(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	p := completionParams(t, nil)
+	p.NewKameletClient = func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+		return mockClient, nil
+	}
+
+	rootCmd := &cobra.Command{Use: "kn-source-kamelet"}
+	rootCmd.AddCommand(NewBindCommand(p), NewBindingCommand(p), NewDescribeTypeCommand(p))
+
+	sink := createKamelet("timer-sink")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	// Kamelets are listed once and served from the cache afterwards
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("timer-source"), *createKamelet("kafka-source"), *sink}}, nil)
+
+	for _, args := range [][]string{{"bind", "ti"}, {"binding", "create", "b1", "--kamelet", "ti"}, {"describe-type", "ti"}} {
+		output := &bytes.Buffer{}
+		rootCmd.SetOut(output)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		assert.NilError(t, rootCmd.Execute())
+		assert.Check(t, util.ContainsAll(output.String(), "timer-source\tKamelet timer-source", ":4"))
+		assert.Check(t, util.ContainsNone(output.String(), "kafka-source", "timer-sink"))
+	}
+
+	// only a single Kamelet is completed
+	output := &bytes.Buffer{}
+	rootCmd.SetOut(output)
+	rootCmd.SetArgs([]string{cobra.ShellCompRequestCmd, "bind", "timer-source", ""})
+	assert.NilError(t, rootCmd.Execute())
+	assert.Check(t, util.ContainsNone(output.String(), "timer-source"))
+
+	recorder.Validate()
+}
+
+func TestPropertyKeyCompletion(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	p := completionParams(t, nil)
+	p.NewKameletClient = func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+		return mockClient, nil
+	}
+
+	// flag values are kept between executions, so each completion request runs on new commands
+	complete := func(args ...string) string {
+		rootCmd := &cobra.Command{Use: "kn-source-kamelet"}
+		rootCmd.AddCommand(NewBindCommand(p), NewBindingCommand(p))
+		output := &bytes.Buffer{}
+		rootCmd.SetOut(output)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		assert.NilError(t, rootCmd.Execute())
+		return output.String()
+	}
+
+	kamelet := createKamelet("timer-source")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"message": {Type: "string", Title: "Message"},
+		"period":  {Type: "integer", Title: "Period"},
+	}
+	sink := createKamelet("log-sink")
+	sink.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"loggerName": {Type: "string", Title: "Logger Name"},
+	}
+
+	for _, args := range [][]string{{"bind", "timer-source", "--source-property", ""}, {"binding", "create", "b1", "--kamelet", "timer-source", "--source-property", ""}} {
+		recorder.Get(kamelet, nil)
+		assert.Check(t, util.ContainsAll(complete(args...), "message=\t(required) Message", "period=\tPeriod", ":6"))
+	}
+
+	// keys already given are omitted
+	recorder.Get(kamelet, nil)
+	output := complete("bind", "timer-source", "--source-property", "message=hello", "--source-property", "")
+	assert.Check(t, util.ContainsAll(output, "period="))
+	assert.Check(t, util.ContainsNone(output, "message="))
+
+	// sink properties are completed for Kamelet sinks only
+	recorder.Get(sink, nil)
+	assert.Check(t, util.ContainsAll(complete("bind", "timer-source", "--sink", "kamelet:log-sink", "--sink-property", "log"), "loggerName=\tLogger Name"))
+	assert.Check(t, util.ContainsNone(complete("bind", "timer-source", "--broker", "default", "--sink-property", ""), "="))
+
+	recorder.Validate()
+}
+
+func TestSinkCompletion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, gvk := range []schema.GroupVersionKind{
+		{Group: "eventing.knative.dev", Version: "v1", Kind: "Broker"},
+		{Group: "serving.knative.dev", Version: "v1", Kind: "Service"},
+	} {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+	sink := func(apiVersion string, kind string, namespace string, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+
+	p := completionParams(t, nil)
+	p.NewDynamicClient = func() (dynamic.Interface, error) {
+		return dynamicfake.NewSimpleDynamicClient(scheme,
+			sink("eventing.knative.dev/v1", "Broker", "test", "default"),
+			sink("eventing.knative.dev/v1", "Broker", "test", "dev"),
+			sink("eventing.knative.dev/v1", "Broker", "other", "prod"),
+			sink("serving.knative.dev/v1", "Service", "test", "event-display")), nil
+	}
+
+	complete := func(args ...string) string {
+		rootCmd := &cobra.Command{Use: "kn-source-kamelet"}
+		rootCmd.AddCommand(NewBindCommand(p), NewBindingCommand(p))
+		output := &bytes.Buffer{}
+		rootCmd.SetOut(output)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		assert.NilError(t, rootCmd.Execute())
+		return output.String()
+	}
+
+	output := complete("bind", "timer-source", "-n", "test", "--broker", "")
+	assert.Check(t, util.ContainsAll(output, "default", "dev", ":4"))
+	assert.Check(t, util.ContainsNone(output, "prod", "event-display"))
+
+	output = complete("binding", "create", "b1", "-n", "test", "--broker", "de")
+	assert.Check(t, util.ContainsAll(output, "default", "dev"))
+
+	output = complete("binding", "update", "b1", "-n", "test", "--service", "")
+	assert.Check(t, util.ContainsAll(output, "event-display"))
+	assert.Check(t, util.ContainsNone(output, "default"))
+
+	// channels are not served by the cluster
+	output = complete("bind", "timer-source", "-n", "test", "--channel", "")
+	assert.Check(t, util.ContainsAll(output, ":4"))
+	assert.Check(t, util.ContainsNone(output, "default", "event-display"))
+}
+
+func completionParams(t *testing.T, clientset kubernetes.Interface) *KameletPluginParams {
+	return &KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		CacheDir: t.TempDir(),
+		NewKubeClient: func() (kubernetes.Interface, error) {
+			return clientset, nil
+		},
+	}
+}
+
+func namespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: name}}
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+)
+
+// configFileEnv overrides the location of the plugin config file
+const configFileEnv = "KN_SOURCE_KAMELET_CONFIG"
+
+// defaultOutputAnnotation marks the commands using the default output format of the config file
+const defaultOutputAnnotation = "kn-source-kamelet/default-output"
+
+// PluginConfig holds the defaults read from the plugin config file, e.g.
+//
+//	namespace: my-namespace
+//	sink: broker:default
+//	output: wide
+//	sinkTypes:
+//	  kafkachannel:
+//	    apiVersion: messaging.knative.dev/v1beta1
+//	    kind: KafkaChannel
+//	noOverwrite: true
+//	serverSide: false
+//	lint:
+//	  rules:
+//	    missing-error-handler: error
+//	  requiredLabels:
+//	  - team
+type PluginConfig struct {
+	// Namespace is used when no namespace is given by flag instead of the namespace of the kubeconfig context
+	Namespace string `json:"namespace,omitempty"`
+	// Sink is the sink expression of new bindings when no sink flag is given
+	Sink string `json:"sink,omitempty"`
+	// Output is the output format of list commands when no output flag is given
+	Output string `json:"output,omitempty"`
+	// SinkTypes adds sink types usable in sink expressions in the form of <type>:<name>
+	SinkTypes map[string]v1.TypeMeta `json:"sinkTypes,omitempty"`
+	// NoOverwrite makes create and bind fail on existing bindings unless --no-overwrite=false is given
+	NoOverwrite bool `json:"noOverwrite,omitempty"`
+	// ServerSide set to false makes create and bind fall back to the three-way merge unless --server-side is given,
+	// server-side apply is used when unset
+	ServerSide *bool `json:"serverSide,omitempty"`
+	// CatalogRepository is a mirror of the apache/camel-kamelets GitHub repository the catalog commands read from
+	CatalogRepository string `json:"catalogRepository,omitempty"`
+	// Lint configures the rules of 'binding lint'
+	Lint LintConfig `json:"lint,omitempty"`
+}
+
+// LintConfig holds the lint settings of the plugin config file
+type LintConfig struct {
+	// Rules overrides the severity of lint rules, one of error, warning or off
+	Rules map[string]string `json:"rules,omitempty"`
+	// RequiredLabels are the labels every binding has to carry
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+}
+
+// defaultConfigFile returns the config file given by environment or the default location in the kn config directory
+func defaultConfigFile() (string, bool) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path, true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, ".config", "kn", "plugins", "source-kamelet.yaml"), false
+}
+
+// pluginConfig returns the plugin config, loading the config file on first use. A missing config file at the default
+// location results in an empty config while a config file given explicitly has to exist.
+func (params *KameletPluginParams) pluginConfig() (*PluginConfig, error) {
+	if params.Config != nil {
+		return params.Config, nil
+	}
+
+	path, explicit := params.ConfigFile, params.ConfigFile != ""
+	if !explicit {
+		path, explicit = defaultConfigFile()
+	}
+
+	config := &PluginConfig{}
+	if path != "" {
+		file, err := os.Open(path)
+		switch {
+		case os.IsNotExist(err) && !explicit:
+		case err != nil:
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		default:
+			defer file.Close()
+			if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(config); err != nil {
+				return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		}
+	}
+
+	for name, sinkType := range config.SinkTypes {
+		if sinkType.APIVersion == "" || sinkType.Kind == "" {
+			return nil, fmt.Errorf("invalid sink type %q in config file %s, apiVersion and kind are required", name, path)
+		}
+	}
+	params.Config = config
+	return config, nil
+}
+
+// sinkTypes returns the built-in sink types extended by the sink types of the config file
+func (params *KameletPluginParams) sinkTypes() map[string]v1.TypeMeta {
+	if params.Config == nil || len(params.Config.SinkTypes) == 0 {
+		return kameletapi.SinkTypes
+	}
+	sinkTypes := make(map[string]v1.TypeMeta, len(kameletapi.SinkTypes)+len(params.Config.SinkTypes))
+	for name, sinkType := range kameletapi.SinkTypes {
+		sinkTypes[name] = sinkType
+	}
+	for name, sinkType := range params.Config.SinkTypes {
+		sinkTypes[name] = sinkType
+	}
+	return sinkTypes
+}
+
+// ApplyConfig loads the plugin config and applies its default output format to given command
+func (params *KameletPluginParams) ApplyConfig(cmd *cobra.Command) error {
+	config, err := params.pluginConfig()
+	if err != nil {
+		return err
+	}
+
+	output := cmd.Flags().Lookup("output")
+	if config.Output != "" && output != nil && !output.Changed && cmd.Annotations[defaultOutputAnnotation] == "true" {
+		return cmd.Flags().Set("output", config.Output)
+	}
+	return nil
+}
+
+// GetNamespace returns the namespace given by flag, the namespace of the config file or the namespace of the
+// kubeconfig context. An empty namespace represents all namespaces.
+func (params *KameletPluginParams) GetNamespace(cmd *cobra.Command) (string, error) {
+	config, err := params.pluginConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.Namespace != "" && cmd.Flag("namespace").Value.String() == "" {
+		if all := cmd.Flags().Lookup("all-namespaces"); all == nil || all.Value.String() != "true" {
+			return config.Namespace, nil
+		}
+	}
+	return params.KnParams.GetNamespace(cmd)
+}
+
+// applyDefaultSink sets the sink of the config file on the binding flags unless a sink is given by flag
+func (params *KameletPluginParams) applyDefaultSink(flags *bindingFlags) error {
+	config, err := params.pluginConfig()
+	if err != nil {
+		return err
+	}
+	if config.Sink != "" && flags.Broker == "" && flags.Channel == "" && flags.Service == "" && flags.URI == "" && flags.Sink == "" {
+		flags.Sink = config.Sink
+	}
+	return nil
+}
+
+// applyUpdateDefaults enables the no-overwrite mode of the config file unless --no-overwrite or --force are given and
+// verifies that both flags are not combined. The config file enables or disables server-side apply unless --server-side
+// is given.
+func (params *KameletPluginParams) applyUpdateDefaults(cmd *cobra.Command, update *updateOptions) error {
+	if update.Force && update.NoOverwrite {
+		return errors.New("--force can not be combined with --no-overwrite")
+	}
+	config, err := params.pluginConfig()
+	if err != nil {
+		return err
+	}
+	if config.NoOverwrite && !update.Force && !cmd.Flags().Changed("no-overwrite") {
+		update.NoOverwrite = true
+	}
+	if config.ServerSide != nil && !cmd.Flags().Changed("server-side") {
+		update.ServerSide = *config.ServerSide
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	p := configParams(t, mockClient, `
+namespace: configured
+sink: channel:events
+output: name
+sinkTypes:
+  kafkachannel:
+    apiVersion: messaging.knative.dev/v1beta1
+    kind: KafkaChannel
+`)
+	output, err := runConfigCmd(p, NewBindCommand(p), "bind", "k1", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "namespace: configured", "kind: Channel", "name: events"))
+
+	output, err = runConfigCmd(p, NewBindCommand(p), "bind", "k1", "--offline", "-n", "test", "--sink", "kafkachannel:orders")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "namespace: test", "kind: KafkaChannel", "apiVersion: messaging.knative.dev/v1beta1", "name: orders"))
+
+	// sink types of the config file are kept on the params and do not leak into the built-in sink types
+	_, ok := kameletapi.SinkTypes["kafkachannel"]
+	assert.Assert(t, !ok)
+	_, err = runBindCmd(mockClient, "k1", "--offline", "--sink", "kafkachannel:orders")
+	assert.ErrorContains(t, err, "unsupported sink type \"kafkachannel\"")
+
+	mockClient.Recorder().List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1")}}, nil)
+	output, err = runConfigCmd(p, NewListTypesCommand(p), "list-types")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kamelet.camel.apache.org/k1\n")
+
+	// output given by flag takes precedence
+	mockClient.Recorder().List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1")}}, nil)
+	output, err = runConfigCmd(p, NewListTypesCommand(p), "list-types", "-o", "jsonpath={.items[0].metadata.name}")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "k1")
+
+	mockClient.Recorder().Validate()
+}
+
+func TestConfigNamespace(t *testing.T) {
+	p := configParams(t, kamelettesting.NewMockKameletClient(t), "namespace: configured\n")
+
+	cmd := &cobra.Command{}
+	commands.AddNamespaceFlags(cmd.Flags(), true)
+	namespace, err := p.GetNamespace(cmd)
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "configured")
+
+	assert.NilError(t, cmd.Flags().Set("namespace", "test"))
+	namespace, err = p.GetNamespace(cmd)
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "test")
+
+	assert.NilError(t, cmd.Flags().Set("all-namespaces", "true"))
+	namespace, err = p.GetNamespace(cmd)
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "")
+}
+
+func TestConfigErrors(t *testing.T) {
+	p := &KameletPluginParams{ConfigFile: filepath.Join(t.TempDir(), "missing.yaml")}
+	_, err := p.pluginConfig()
+	assert.ErrorContains(t, err, "failed to read config file")
+
+	p = configParams(t, kamelettesting.NewMockKameletClient(t), "sinkTypes:\n  kafkachannel:\n    kind: KafkaChannel\n")
+	_, err = p.pluginConfig()
+	assert.ErrorContains(t, err, "invalid sink type \"kafkachannel\"")
+
+	p = configParams(t, kamelettesting.NewMockKameletClient(t), "namespace: [")
+	_, err = p.pluginConfig()
+	assert.ErrorContains(t, err, "invalid config file")
+}
+
+func TestConfigNoOverwrite(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := configParams(t, mockClient, "noOverwrite: true")
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, nil)
+	_, err := runConfigCmd(p, NewBindingCommand(p), "binding", "create", "b1", "--kamelet", "k1", "--broker", "default")
+	assert.NilError(t, err)
+
+	// the config default is overridden by flag
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(createKameletBindingInNamespace("b1", "k1", "current"), nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runConfigCmd(p, NewBindingCommand(p), "binding", "create", "b1", "--kamelet", "k1", "--broker", "default", "--no-overwrite=false")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'b1' updated"))
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(createKameletBindingInNamespace("b1", "k1", "current"), nil)
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {
+		assert.Equal(t, *opts.Force, true)
+	}, nil)
+	output, err = runConfigCmd(p, NewBindingCommand(p), "binding", "create", "b1", "--kamelet", "k1", "--channel", "events", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'b1' updated"))
+	recorder.Validate()
+}
+
+func TestConfigServerSide(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := configParams(t, mockClient, "serverSide: false")
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(nil, notFound("b1"))
+	recorder.CreateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, nil)
+	_, err := runConfigCmd(p, NewBindingCommand(p), "binding", "create", "b1", "--kamelet", "k1", "--broker", "default")
+	assert.NilError(t, err)
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(nil, notFound("b1"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	_, err = runConfigCmd(p, NewBindingCommand(p), "binding", "create", "b1", "--kamelet", "k1", "--broker", "default", "--server-side")
+	assert.NilError(t, err)
+	recorder.Validate()
+}
+
+func configParams(t *testing.T, c *kamelettesting.MockKameletClient, config string) *KameletPluginParams {
+	configFile := filepath.Join(t.TempDir(), "source-kamelet.yaml")
+	assert.NilError(t, ioutil.WriteFile(configFile, []byte(config), 0600))
+
+	return &KameletPluginParams{
+		KnParams:   &commands.KnParams{},
+		Context:    context.TODO(),
+		ConfigFile: configFile,
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+}
+
+// runConfigCmd runs the command applying the plugin config like the root command does
+func runConfigCmd(p *KameletPluginParams, cmd *cobra.Command, args ...string) (string, error) {
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return p.ApplyConfig(cmd)
+	}
+	return runPipeCmd(p, cmd, args...)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"knative.dev/client/pkg/kn/commands/flags"
+	"knative.dev/client/pkg/printers"
+	"knative.dev/client/pkg/util"
+)
+
+// customColumnsPrefix introduces the custom columns output format, e.g. -o custom-columns=NAME:.metadata.name
+const customColumnsPrefix = "custom-columns="
+
+// customColumn is a column header with the JSONPath expression printing its value
+type customColumn struct {
+	header string
+	path   *jsonpath.JSONPath
+}
+
+// addListPrintFlags adds the list print flags to the command, the output formats include custom columns
+// and the additional formats handled by the command itself. The output format defaults to the one of the config file.
+func addListPrintFlags(cmd *cobra.Command, listFlags *flags.ListPrintFlags, formats ...string) {
+	listFlags.AddFlags(cmd)
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[defaultOutputAnnotation] = "true"
+	formats = append(listFlags.AllowedFormats(), formats...)
+	cmd.Flag("output").Usage = fmt.Sprintf("Output format. One of: %s|custom-columns=<header>:<json-path-expression>,...", strings.Join(formats, "|"))
+	cmd.Flag("no-headers").Usage = "When using the default or custom-columns output format, don't print headers (default: print headers)."
+}
+
+// printList prints the list in the output format given by the list print flags
+func printList(listFlags *flags.ListPrintFlags, obj runtime.Object, out io.Writer) error {
+	if output := listFlags.GenericPrintFlags.OutputFormat; output != nil && strings.HasPrefix(*output, customColumnsPrefix) {
+		columns, err := parseCustomColumns(strings.TrimPrefix(*output, customColumnsPrefix))
+		if err != nil {
+			return err
+		}
+		return printCustomColumns(columns, obj, listFlags.HumanReadableFlags.NoHeaders, out)
+	}
+	return listFlags.Print(obj, out)
+}
+
+// parseCustomColumns parses the comma separated column specs in the form of <header>:<json-path-expression>
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format specified but no custom columns given")
+	}
+
+	var columns []customColumn
+	for _, columnSpec := range strings.Split(spec, ",") {
+		parts := strings.SplitN(columnSpec, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expected <header>:<json-path-expression>", columnSpec)
+		}
+
+		expression := parts[1]
+		if !strings.HasPrefix(expression, "{") {
+			expression = "{" + expression + "}"
+		}
+		path := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := path.Parse(expression); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath expression %q of custom column %q: %w", parts[1], parts[0], err)
+		}
+		columns = append(columns, customColumn{header: parts[0], path: path})
+	}
+	return columns, nil
+}
+
+// printCustomColumns prints a table row with the column values of each list item, missing values are printed as <none>
+func printCustomColumns(columns []customColumn, obj runtime.Object, noHeaders bool, out io.Writer) error {
+	list, ok := obj.(*unstructured.UnstructuredList)
+	if !ok {
+		var err error
+		if list, err = util.ToUnstructuredList(obj); err != nil {
+			return err
+		}
+	}
+
+	dw := printers.NewPrefixWriter(out)
+	if !noHeaders {
+		headers := make([]string, 0, len(columns))
+		for _, column := range columns {
+			headers = append(headers, column.header)
+		}
+		dw.WriteColsLn(headers...)
+	}
+
+	for _, item := range list.Items {
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			results, err := column.path.FindResults(item.Object)
+			if err != nil {
+				return err
+			}
+			var found []string
+			for _, result := range results {
+				for _, value := range result {
+					found = append(found, fmt.Sprintf("%v", value.Interface()))
+				}
+			}
+			if len(found) == 0 {
+				values = append(values, "<none>")
+			} else {
+				values = append(values, strings.Join(found, ","))
+			}
+		}
+		dw.WriteColsLn(values...)
+	}
+	return dw.Flush()
+}
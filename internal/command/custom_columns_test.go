@@ -0,0 +1,85 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestListTypesCustomColumns(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet1 := createKamelet("k1")
+	kamelet1.Annotations = map[string]string{providerAnnotation: "Acme"}
+	kamelet2 := createKamelet("k2")
+	kameletList := func() *camelkapis.KameletList {
+		return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet1, *kamelet2}}
+	}
+
+	recorder.List(kameletList(), nil)
+	output, err := runListTypesCmd(mockClient, "-o", `custom-columns=NAME:.metadata.name,PROVIDER:.metadata.annotations.camel\.apache\.org/provider,PHASE:{.status.phase}`)
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "PROVIDER", "PHASE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "Acme", "Ready"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "k2", "<none>", "Ready"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "-o", "custom-columns=NAME:.metadata.name", "--no-headers")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "k1\nk2\n")
+
+	recorder.Validate()
+}
+
+func TestBindingListPipesCustomColumns(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Namespace = "current"
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe))
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "list", "-o", "custom-columns=NAME:.metadata.name,KIND:.kind,SOURCE:.spec.source.ref.name")
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "KIND", "SOURCE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "b1", "Pipe", "k1"))
+}
+
+func TestParseCustomColumnsErrors(t *testing.T) {
+	_, err := parseCustomColumns("")
+	assert.Error(t, err, "custom-columns format specified but no custom columns given")
+
+	_, err = parseCustomColumns("NAME")
+	assert.Error(t, err, "invalid custom column \"NAME\", expected <header>:<json-path-expression>")
+
+	_, err = parseCustomColumns("NAME:.metadata.name,PHASE:")
+	assert.Error(t, err, "invalid custom column \"PHASE:\", expected <header>:<json-path-expression>")
+
+	_, err = parseCustomColumns("NAME:{.metadata.name")
+	assert.ErrorContains(t, err, "invalid JSONPath expression \"{.metadata.name\" of custom column \"NAME\"")
+}
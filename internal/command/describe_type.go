@@ -19,12 +19,15 @@ package command
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"knative.dev/client/pkg/printers"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
 	"knative.dev/pkg/apis"
 
 	knerrors "knative.dev/client/pkg/errors"
@@ -37,18 +40,30 @@ var describeExample = `
   # Describe given Kamelets
   kn-source-kamelet describe-type NAME
 
+  # Describe given Kamelets including the full property descriptions
+  kn-source-kamelet describe NAME --verbose
+
   # Describe given Kamelets in YAML output format
-  kn-source-kamelet describe-type NAME -o yaml`
+  kn-source-kamelet describe-type NAME -o yaml
+
+  # Print given Kamelet as YAML without status and server generated fields
+  kn-source-kamelet describe-type NAME -o yaml --clean
+
+  # Describe given Kamelet from the local cache filled by list-types or the bundled Kamelet catalog
+  kn-source-kamelet describe-type NAME --offline`
 
 // NewDescribeTypeCommand implements 'kn-source-kamelet describe-type' command
 func NewDescribeTypeCommand(p *KameletPluginParams) *cobra.Command {
 	printFlags := genericclioptions.NewPrintFlags("")
+	var clean bool
+	var offline bool
 
 	cmd := &cobra.Command{
-		Use:     "describe-type",
-		Short:   "Show details of given Kamelet source type",
-		Aliases: []string{"dt"},
-		Example: describeExample,
+		Use:               "describe-type",
+		Short:             "Show details of given Kamelet source type",
+		Aliases:           []string{"dt", "describe"},
+		Example:           describeExample,
+		ValidArgsFunction: p.completeKameletArg("source"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if len(args) != 1 {
 				return errors.New("'kn-source-kamelet describe-type' requires the Kamelet name given as single argument")
@@ -60,14 +75,24 @@ func NewDescribeTypeCommand(p *KameletPluginParams) *cobra.Command {
 				return err
 			}
 
-			client, err := p.NewKameletClient()
-			if err != nil {
-				return err
-			}
-
-			kamelet, err := client.Kamelets(namespace).Get(p.Context, name, v1.GetOptions{})
-			if err != nil {
-				return knerrors.GetError(err)
+			var kamelet *v1alpha1.Kamelet
+			if offline {
+				if p.Refresh {
+					return errors.New("--offline can not be combined with --refresh")
+				}
+				cached, ok := p.offlineKamelet(namespace, name)
+				if !ok {
+					return fmt.Errorf("Kamelet %s is neither cached for namespace %s nor part of the bundled catalog, run 'kn-source-kamelet list-types' to refresh the cache", name, namespace)
+				}
+				kamelet = cached
+			} else {
+				client, err := p.NewKameletClient()
+				if err != nil {
+					return err
+				}
+				if kamelet, err = client.Kamelets(namespace).Get(p.Context, name, v1.GetOptions{}); err != nil {
+					return knerrors.GetError(err)
+				}
 			}
 
 			out := cmd.OutOrStdout()
@@ -85,7 +110,13 @@ func NewDescribeTypeCommand(p *KameletPluginParams) *cobra.Command {
 				if err != nil {
 					return err
 				}
-				return printer.PrintObj(kamelet, out)
+				var obj runtime.Object = kamelet
+				if clean {
+					if obj, err = sanitize(kamelet); err != nil {
+						return err
+					}
+				}
+				return printer.PrintObj(obj, out)
 			}
 
 			dw := printers.NewPrefixWriter(out)
@@ -95,12 +126,19 @@ func NewDescribeTypeCommand(p *KameletPluginParams) *cobra.Command {
 				return err
 			}
 
-			writeKamelet(dw, kamelet, printDetails)
+			writeKamelet(dw, kamelet, printDetails, p.colorsEnabled(out))
 			dw.WriteLine()
 			if err := dw.Flush(); err != nil {
 				return err
 			}
 
+			if writeKameletProperties(dw, kamelet, printDetails) {
+				dw.WriteLine()
+				if err := dw.Flush(); err != nil {
+					return err
+				}
+			}
+
 			// Condition info
 			commands.WriteConditions(dw, asApiConditions(kamelet.Status.Conditions), printDetails)
 			if err := dw.Flush(); err != nil {
@@ -113,24 +151,90 @@ func NewDescribeTypeCommand(p *KameletPluginParams) *cobra.Command {
 	flags := cmd.Flags()
 	commands.AddNamespaceFlags(flags, false)
 	flags.BoolP("verbose", "v", false, "More output.")
+	flags.BoolVar(&offline, "offline", false, "Describe the Kamelet from the local cache or the bundled Kamelet catalog without accessing the cluster.")
+	addCleanFlag(flags, &clean)
 	printFlags.AddFlags(cmd)
 	cmd.Flag("output").Usage = fmt.Sprintf("Output format. One of: %s.", strings.Join(append(printFlags.AllowedFormats(), "url"), "|"))
 	return cmd
 }
 
-func writeKamelet(dw printers.PrefixWriter, kamelet *v1alpha1.Kamelet, printDetails bool) {
+func writeKamelet(dw printers.PrefixWriter, kamelet *v1alpha1.Kamelet, printDetails bool, colors bool) {
 	commands.WriteMetadata(dw, &kamelet.ObjectMeta, printDetails)
-	if kamelet.Spec.Definition.Title != "" {
-		dw.WriteAttribute("Description", fmt.Sprintf("%s - %s", kamelet.Spec.Definition.Title, kamelet.Spec.Definition.Description))
-	} else {
-		dw.WriteAttribute("Description", kamelet.Spec.Definition.Description)
+	if definition := kamelet.Spec.Definition; definition != nil {
+		if definition.Title != "" {
+			dw.WriteAttribute("Description", fmt.Sprintf("%s - %s", definition.Title, definition.Description))
+		} else {
+			dw.WriteAttribute("Description", definition.Description)
+		}
 	}
 
-	dw.WriteAttribute("Phase", string(kamelet.Status.Phase))
+	if provider := kamelet.Annotations[providerAnnotation]; provider != "" {
+		dw.WriteAttribute("Provider", provider)
+	}
+
+	dw.WriteAttribute("Phase", colorStatus(string(kamelet.Status.Phase), colors))
+}
+
+// writeKameletProperties writes the required and optional properties of the Kamelet definition,
+// returns false when the Kamelet does not define any properties.
+func writeKameletProperties(dw printers.PrefixWriter, kamelet *v1alpha1.Kamelet, printDetails bool) bool {
+	if kamelet.Spec.Definition == nil || len(kamelet.Spec.Definition.Properties) == 0 {
+		return false
+	}
+
+	required := map[string]bool{}
+	for _, name := range kamelet.Spec.Definition.Required {
+		required[name] = true
+	}
+
+	var requiredNames, optionalNames []string
+	for name := range kamelet.Spec.Definition.Properties {
+		if required[name] {
+			requiredNames = append(requiredNames, name)
+		} else {
+			optionalNames = append(optionalNames, name)
+		}
+	}
+	sort.Strings(requiredNames)
+	sort.Strings(optionalNames)
+
+	writeSection := func(label string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		section := dw.WriteAttribute(label, "")
+		section.WriteColsLn("NAME", "TYPE", "DEFAULT", "EXAMPLE", "DESCRIPTION")
+		for _, name := range names {
+			property := kamelet.Spec.Definition.Properties[name]
+			description := property.Title
+			if description == "" || printDetails {
+				description = property.Description
+			}
+			section.WriteColsLn(name, propertyType(property), kameletapi.JSONValue(property.Default), kameletapi.JSONValue(property.Example), description)
+		}
+	}
+
+	writeSection("Required Properties", requiredNames)
+	writeSection("Optional Properties", optionalNames)
+	return true
 }
 
+// propertyType returns the schema type of the property including its format if any
+func propertyType(property v1alpha1.JSONSchemaProps) string {
+	if property.Format != "" {
+		return fmt.Sprintf("%s (%s)", property.Type, property.Format)
+	}
+	return property.Type
+}
+
+// providerAnnotation holds the name of the Kamelet provider
+const providerAnnotation = "camel.apache.org/provider"
+
+// supportLevelAnnotation holds the maturity of the Kamelet, e.g. stable, preview or experimental
+const supportLevelAnnotation = "camel.apache.org/kamelet.support.level"
+
 func isEventSourceType(kamelet *v1alpha1.Kamelet) bool {
-	return kamelet.Labels["camel.apache.org/kamelet.type"] == "source"
+	return kameletapi.TypeOf(kamelet) == "source"
 }
 
 func asApiConditions(conditions []v1alpha1.KameletCondition) apis.Conditions {
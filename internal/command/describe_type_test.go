@@ -22,10 +22,11 @@ import (
 	"strings"
 	"testing"
 
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
 	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
 	"knative.dev/client/pkg/kn/commands"
 	"knative.dev/client/pkg/util"
-	"knative.dev/kn-plugin-source-kamelet/internal/client"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
 
 	"gotest.tools/v3/assert"
 )
@@ -41,7 +42,7 @@ func TestDescribeTypeSetup(t *testing.T) {
 	assert.Assert(t, describeCmd.RunE != nil)
 }
 func TestDescribeTypeErrorCase(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	_, err := runDescribeTypeCmd(mockClient)
@@ -50,7 +51,7 @@ func TestDescribeTypeErrorCase(t *testing.T) {
 }
 
 func TestDescribeTypeErrorCaseNotFound(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet := createKamelet("k1")
@@ -62,7 +63,7 @@ func TestDescribeTypeErrorCaseNotFound(t *testing.T) {
 }
 
 func TestDescribeTypeErrorCaseNoEventSource(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet := createKamelet("k1")
@@ -77,7 +78,7 @@ func TestDescribeTypeErrorCaseNoEventSource(t *testing.T) {
 }
 
 func TestDescribeTypeOutput(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet := createKamelet("k1")
@@ -102,8 +103,73 @@ func TestDescribeTypeOutput(t *testing.T) {
 	recorder.Validate()
 }
 
+func TestDescribeTypeProperties(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Annotations = map[string]string{"camel.apache.org/provider": "Apache Software Foundation"}
+	kamelet.Spec.Definition.Required = []string{"message"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"message": {
+			Type:        "string",
+			Title:       "Message",
+			Description: "The message to generate",
+			Example:     &camelkapis.JSON{RawMessage: []byte(`"Hello"`)},
+		},
+		"period": {
+			Type:        "integer",
+			Title:       "Period",
+			Description: "The time interval between two events",
+			Default:     &camelkapis.JSON{RawMessage: []byte(`1000`)},
+		},
+	}
+	recorder.Get(kamelet, nil)
+
+	output, err := runDescribeTypeCmd(mockClient, "k1")
+	assert.NilError(t, err)
+
+	assert.Check(t, util.ContainsAll(output, "Provider:", "Apache Software Foundation"))
+
+	outputLines := strings.Split(output, "\n")
+	index := 0
+	for i, line := range outputLines {
+		if strings.HasPrefix(line, "Required Properties:") {
+			index = i
+		}
+	}
+	assert.Assert(t, index > 0, output)
+	assert.Check(t, util.ContainsAll(outputLines[index+1], "NAME", "TYPE", "DEFAULT", "EXAMPLE", "DESCRIPTION"))
+	assert.Check(t, util.ContainsAll(outputLines[index+2], "message", "string", "Hello", "Message"))
+	assert.Check(t, util.ContainsAll(outputLines[index+3], "Optional Properties:"))
+	assert.Check(t, util.ContainsAll(outputLines[index+5], "period", "integer", "1000", "Period"))
+
+	recorder.Validate()
+}
+
+func TestDescribeTypeAlias(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return mockClient, nil
+		},
+	}
+	describeCmd, _, output := commands.CreateSourcesTestKnCommand(NewDescribeTypeCommand(&p), p.KnParams)
+	describeCmd.SetArgs([]string{"describe", "k1", "--verbose"})
+	assert.NilError(t, describeCmd.Execute())
+	assert.Check(t, util.ContainsAll(output.String(), "Name:", "k1"))
+
+	recorder.Validate()
+}
+
 func TestDescribeTypeURL(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet := createKamelet("k1")
@@ -118,7 +184,37 @@ func TestDescribeTypeURL(t *testing.T) {
 	recorder.Validate()
 }
 
-func runDescribeTypeCmd(c *client.MockKameletClient, options ...string) (string, error) {
+func TestDescribeTypeJSONPath(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+
+	output, err := runDescribeTypeCmd(mockClient, "k1", "-o", "jsonpath={.spec.definition.title}")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet k1")
+	recorder.Validate()
+}
+
+func TestDescribeTypeYAMLClean(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.UID = "3d5f3c2a-1b0e-4c58-9f4e-6c1e0d3d9a11"
+	kamelet.ResourceVersion = "1234"
+	kamelet.Generation = 2
+	recorder.Get(kamelet, nil)
+
+	output, err := runDescribeTypeCmd(mockClient, "k1", "-o", "yaml", "--clean")
+	assert.NilError(t, err)
+
+	assert.Check(t, util.ContainsAll(output, "kind: Kamelet", "name: k1", "camel.apache.org/kamelet.type: source", "Sample Kamelet source"))
+	assert.Check(t, util.ContainsNone(output, "status:", "uid:", "resourceVersion:", "creationTimestamp:", "generation:", "selfLink:"))
+	recorder.Validate()
+}
+
+func runDescribeTypeCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
 	p := KameletPluginParams{
 		KnParams: &commands.KnParams{},
 		Context:  context.TODO(),
@@ -0,0 +1,96 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Exit codes of the plugin, so that scripts can branch on the failure without parsing the error message
+const (
+	// ExitCodeError is the exit code of all failures without a more specific exit code
+	ExitCodeError = 1
+	// ExitCodeValidation is the exit code of invalid bindings, properties and manifests
+	ExitCodeValidation = 2
+	// ExitCodeNotFound is the exit code when a resource such as a Kamelet or a binding does not exist
+	ExitCodeNotFound = 3
+	// ExitCodeTimeout is the exit code when a binding does not become ready in time or a request times out
+	ExitCodeTimeout = 4
+	// ExitCodeConflict is the exit code when a resource already exists or was modified concurrently
+	ExitCodeConflict = 5
+)
+
+// exitCodeError assigns the exit code to the error
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode assigns the exit code to the error, nil errors are returned unchanged
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// ExitCode returns the exit code of the error returned by a command, the exit code of API errors is derived from
+// their status reason
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	// errors of bulk operations keep their exit code when all of them agree
+	var aggregate utilerrors.Aggregate
+	if errors.As(err, &aggregate) && len(aggregate.Errors()) > 0 {
+		code := ExitCode(aggregate.Errors()[0])
+		for _, err := range aggregate.Errors()[1:] {
+			if ExitCode(err) != code {
+				return ExitCodeError
+			}
+		}
+		return code
+	}
+	switch {
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+		return ExitCodeValidation
+	case apierrors.IsNotFound(err):
+		return ExitCodeNotFound
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || errors.Is(err, context.DeadlineExceeded):
+		return ExitCodeTimeout
+	case apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err):
+		return ExitCodeConflict
+	}
+	return ExitCodeError
+}
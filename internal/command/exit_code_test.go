@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	resource := schema.GroupResource{Group: "camel.apache.org", Resource: "kameletbindings"}
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("failed"), ExitCodeError},
+		{withExitCode(ExitCodeValidation, errors.New("invalid")), ExitCodeValidation},
+		{fmt.Errorf("bind failed: %w", withExitCode(ExitCodeTimeout, errors.New("timeout"))), ExitCodeTimeout},
+		{apierrors.NewNotFound(resource, "b1"), ExitCodeNotFound},
+		{fmt.Errorf("failed to delete: %w", apierrors.NewNotFound(resource, "b1")), ExitCodeNotFound},
+		{apierrors.NewConflict(resource, "b1", errors.New("modified")), ExitCodeConflict},
+		{apierrors.NewAlreadyExists(resource, "b1"), ExitCodeConflict},
+		{apierrors.NewBadRequest("invalid spec"), ExitCodeValidation},
+		{apierrors.NewTimeoutError("slow", 1), ExitCodeTimeout},
+		{context.DeadlineExceeded, ExitCodeTimeout},
+		{apierrors.NewInternalError(errors.New("boom")), ExitCodeError},
+		{utilerrors.NewAggregate([]error{apierrors.NewNotFound(resource, "b1"), fmt.Errorf("failed: %w", apierrors.NewNotFound(resource, "b2"))}), ExitCodeNotFound},
+		{utilerrors.NewAggregate([]error{apierrors.NewNotFound(resource, "b1"), apierrors.NewConflict(resource, "b2", errors.New("modified"))}), ExitCodeError},
+	} {
+		assert.Equal(t, ExitCode(tc.err), tc.code, tc.err)
+	}
+	assert.NilError(t, withExitCode(ExitCodeValidation, nil))
+}
+
+func TestBindExitCode(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(nil, notFound("k1"))
+	_, err := runBindCmd(mockClient, "k1", "--sink", "broker:default")
+	assert.Equal(t, ExitCode(err), ExitCodeNotFound)
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{"period": {Type: "integer"}}
+	recorder.Get(kamelet, nil)
+	_, err = runBindCmd(mockClient, "k1", "--sink", "broker:default", "--source-property", "period=often")
+	assert.Check(t, util.ContainsAll(err.Error(), "period"))
+	assert.Equal(t, ExitCode(err), ExitCodeValidation)
+
+	recorder.Validate()
+}
+
+func TestDeleteExitCode(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.DeleteBinding("missing", notFound("missing"))
+	_, err := runBindingDeleteCmd(mockClient, "missing")
+	assert.Error(t, err, "failed to delete KameletBinding 'missing' in namespace 'current': kameletbindings.camel.apache.org \"missing\" not found")
+	assert.Equal(t, ExitCode(err), ExitCodeNotFound)
+
+	recorder.DeleteBinding("b1", notFound("b1"))
+	recorder.DeleteBinding("b2", notFound("b2"))
+	_, err = runBindingDeleteCmd(mockClient, "b1", "b2", "--concurrency", "1")
+	assert.Equal(t, ExitCode(err), ExitCodeNotFound)
+
+	recorder.DeleteBinding("b1", notFound("b1"))
+	recorder.DeleteBinding("b2", errors.New("forbidden"))
+	_, err = runBindingDeleteCmd(mockClient, "b1", "b2", "--concurrency", "1")
+	assert.Equal(t, ExitCode(err), ExitCodeError)
+
+	p := cacheParams(t, mockClient)
+	p.UseKameletBinding = true
+	recorder.ListBindings(&camelkapis.KameletBindingList{}, nil)
+	recorder.Delete("k1", notFound("k1"))
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k1", "-n", "default")
+	assert.Equal(t, ExitCode(err), ExitCodeNotFound)
+
+	recorder.Validate()
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// placeholders replaced by the Helm template directives after rendering the binding manifest as YAML
+const (
+	helmPropertiesPlaceholder = "HELM_SOURCE_PROPERTIES"
+	helmSinkPlaceholder       = "HELM_SINK"
+)
+
+// helmChart wraps the binding manifests into a minimal Helm chart. The source properties and the sink of each binding
+// are moved to the chart values keyed by the binding name, the chart is named after the binding when a single binding
+// is exported and after the namespace otherwise.
+func helmChart(manifests []*unstructured.Unstructured, namespace string) ([]exportFile, error) {
+	name := namespace
+	if len(manifests) == 1 {
+		name = manifests[0].GetName()
+	}
+	chart, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        name,
+		"description": "Kamelet bindings exported by kn-source-kamelet",
+		"type":        "application",
+		"version":     "0.1.0",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []exportFile{{name: "Chart.yaml", content: chart}}
+	values := map[string]interface{}{}
+	for _, manifest := range manifests {
+		properties, _, _ := unstructured.NestedMap(manifest.Object, "spec", "source", "properties")
+		if properties == nil {
+			properties = map[string]interface{}{}
+		}
+		sink, _, _ := unstructured.NestedMap(manifest.Object, "spec", "sink")
+		values[manifest.GetName()] = map[string]interface{}{
+			"source": map[string]interface{}{"properties": properties},
+			"sink":   sink,
+		}
+
+		template, err := helmTemplate(manifest)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, exportFile{name: path.Join("templates", manifest.GetName()+".yaml"), content: template})
+	}
+
+	content, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, exportFile{name: "values.yaml", content: content}), nil
+}
+
+// helmTemplate renders the binding manifest with the source properties and the sink read from the chart values
+func helmTemplate(manifest *unstructured.Unstructured) ([]byte, error) {
+	template := manifest.DeepCopy()
+	if err := unstructured.SetNestedField(template.Object, helmPropertiesPlaceholder, "spec", "source", "properties"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(template.Object, helmSinkPlaceholder, "spec", "sink"); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(template.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	values := fmt.Sprintf("(index .Values %q)", manifest.GetName())
+	rendered := strings.Replace(string(data), "    properties: "+helmPropertiesPlaceholder+"\n",
+		"    {{- with "+values+".source.properties }}\n    properties:\n      {{- toYaml . | nindent 6 }}\n    {{- end }}\n", 1)
+	rendered = strings.Replace(rendered, "  sink: "+helmSinkPlaceholder+"\n",
+		"  sink:\n    {{- toYaml "+values+".sink | nindent 4 }}\n", 1)
+	return []byte(rendered), nil
+}
@@ -18,14 +18,35 @@ package command
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
 
 	camelkv1alpha1 "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Shared test helpers
 
+// TestMain isolates the tests from the config file and the cache directory of the user
+func TestMain(m *testing.M) {
+	home, err := ioutil.TempDir("", "kn-source-kamelet-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("XDG_CACHE_HOME", home+"/.cache")
+	os.Unsetenv(configFileEnv)
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
 func createKamelet(kameletName string) *camelkv1alpha1.Kamelet {
 	return createKameletInNamespace(kameletName, "default")
 }
@@ -62,3 +83,52 @@ func createKameletInNamespace(kameletName string, namespace string) *camelkv1alp
 		},
 	}
 }
+
+func createKameletBinding(bindingName string, kameletName string) *camelkv1alpha1.KameletBinding {
+	return createKameletBindingInNamespace(bindingName, kameletName, "default")
+}
+
+func createKameletBindingInNamespace(bindingName string, kameletName string, namespace string) *camelkv1alpha1.KameletBinding {
+	return &camelkv1alpha1.KameletBinding{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: camelkv1alpha1.SchemeGroupVersion.String(),
+			Kind:       camelkv1alpha1.KameletBindingKind,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              bindingName,
+			CreationTimestamp: v1.Now(),
+		},
+		Spec: camelkv1alpha1.KameletBindingSpec{
+			Source: camelkv1alpha1.Endpoint{
+				Ref: &corev1.ObjectReference{
+					APIVersion: camelkv1alpha1.SchemeGroupVersion.String(),
+					Kind:       camelkv1alpha1.KameletKind,
+					Namespace:  namespace,
+					Name:       kameletName,
+				},
+			},
+			Sink: camelkv1alpha1.Endpoint{
+				Ref: &corev1.ObjectReference{
+					APIVersion: "eventing.knative.dev/v1",
+					Kind:       "Broker",
+					Namespace:  namespace,
+					Name:       "default",
+				},
+			},
+		},
+		Status: camelkv1alpha1.KameletBindingStatus{
+			Phase: camelkv1alpha1.KameletBindingPhaseReady,
+			Conditions: []camelkv1alpha1.KameletBindingCondition{
+				{
+					Type:   camelkv1alpha1.KameletBindingConditionReady,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+}
+
+func notFound(name string) error {
+	return apierrors.NewNotFound(schema.GroupResource{Group: "camel.apache.org", Resource: "kameletbindings"}, name)
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+)
+
+// Verbosity levels of the --verbose flag
+const (
+	// verbosityRequests logs method, URL, status and duration of each API request
+	verbosityRequests = 1
+	// verbosityHTTP additionally logs the headers and bodies of requests and responses
+	verbosityHTTP = 2
+)
+
+// redacted replaces sensitive header values and Secret data in logged HTTP traffic
+const redacted = "********"
+
+// sensitiveHeaders are the request and response headers redacted when logging HTTP traffic
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "WWW-Authenticate"}
+
+// AddLoggingFlags adds the flags enabling the logging of the requests against the Kubernetes API to given flag set
+func (params *KameletPluginParams) AddLoggingFlags(flags *pflag.FlagSet) {
+	flags.IntVarP(&params.Verbosity, "verbose", "v", 0, "Log the requests against the Kubernetes API to stderr, 1 logs method, URL, status and duration, 2 also logs headers and bodies with credentials and Secret data redacted. 'describe-type' uses --verbose for more output instead.")
+	flags.Lookup("verbose").NoOptDefVal = "1"
+	flags.BoolVar(&params.LogHTTP, "log-http", false, "Log the HTTP traffic with the Kubernetes API, same as --verbose=2.")
+}
+
+// wrapLoggingTransport wraps the transport of the REST config to log the API requests with given verbosity
+func (params *KameletPluginParams) wrapLoggingTransport(config *rest.Config, verbosity int) {
+	out := params.LogOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	config.Wrap(func(transport http.RoundTripper) http.RoundTripper {
+		return &loggingTransport{transport: transport, out: out, verbosity: verbosity}
+	})
+}
+
+// loggingTransport logs the requests against the Kubernetes API and their responses
+type loggingTransport struct {
+	transport http.RoundTripper
+	out       io.Writer
+	verbosity int
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// watches stream their response until closed, so their bodies are never logged
+	watch := req.URL.Query().Get("watch") == "true"
+
+	if t.verbosity >= verbosityHTTP {
+		dump, err := dumpRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(t.out, "===== REQUEST =====\n%s\n", dump)
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.out, "%s %s failed after %s: %v\n", req.Method, req.URL, duration, err)
+		return resp, err
+	}
+
+	fmt.Fprintf(t.out, "%s %s %s in %s\n", req.Method, req.URL, resp.Status, duration)
+	if t.verbosity >= verbosityHTTP {
+		dump, err := dumpResponse(resp, !watch)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(t.out, "===== RESPONSE =====\n%s\n", dump)
+	}
+	return resp, nil
+}
+
+// dumpRequest returns the request including its body with credentials and Secret data redacted
+func dumpRequest(req *http.Request) ([]byte, error) {
+	clone := req.Clone(req.Context())
+	redactHeaders(clone.Header)
+	if req.Body == nil || req.Body == http.NoBody {
+		return httputil.DumpRequestOut(clone, false)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	body = redactBody(body, req.Header.Get("Content-Type"))
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	return httputil.DumpRequestOut(clone, true)
+}
+
+// dumpResponse returns the response with credentials and Secret data redacted, the body is only included if requested
+func dumpResponse(resp *http.Response, includeBody bool) ([]byte, error) {
+	clone := *resp
+	clone.Header = resp.Header.Clone()
+	redactHeaders(clone.Header)
+	if !includeBody || resp.Body == nil {
+		return httputil.DumpResponse(&clone, false)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	body = redactBody(body, resp.Header.Get("Content-Type"))
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	clone.TransferEncoding = nil
+	return httputil.DumpResponse(&clone, true)
+}
+
+// redactBody returns the JSON body with Secret data redacted, bodies of other content types such as protobuf
+// can not be redacted and are replaced by a note
+func redactBody(body []byte, contentType string) []byte {
+	if len(body) == 0 || strings.Contains(contentType, "json") {
+		return redactSecretData(body)
+	}
+	return []byte(fmt.Sprintf("<%d bytes of %s omitted>", len(body), contentType))
+}
+
+func redactHeaders(header http.Header) {
+	for _, name := range sensitiveHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, redacted)
+		}
+	}
+}
+
+// redactSecretData replaces the values of Secrets and Secret lists in the JSON body,
+// any other body is returned unchanged
+func redactSecretData(body []byte) []byte {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+
+	switch obj["kind"] {
+	case "Secret":
+		redactSecret(obj)
+	case "SecretList":
+		items, _ := obj["items"].([]interface{})
+		for _, item := range items {
+			if secret, ok := item.(map[string]interface{}); ok {
+				redactSecret(secret)
+			}
+		}
+	default:
+		return body
+	}
+
+	redactedBody, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return redactedBody
+}
+
+func redactSecret(secret map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := secret[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = redacted
+		}
+	}
+	// the last applied configuration annotation holds a copy of the Secret data
+	if metadata, ok := secret["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+				annotations["kubectl.kubernetes.io/last-applied-configuration"] = redacted
+			}
+		}
+	}
+}
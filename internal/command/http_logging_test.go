@@ -0,0 +1,127 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+
+	"gotest.tools/v3/assert"
+)
+
+const secretJSON = `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"creds","namespace":"default"},"data":{"password":"c3VwZXJzZWNyZXQ="}}`
+
+func TestLogRequests(t *testing.T) {
+	p, log := loggingParams(t)
+	p.Verbosity = verbosityRequests
+
+	getSecret(t, p)
+	assert.Check(t, util.ContainsAll(log.String(), "GET", "/api/v1/namespaces/default/secrets/creds", "200 OK"))
+	assert.Check(t, util.ContainsNone(log.String(), "===== REQUEST =====", "c3VwZXJzZWNyZXQ="))
+}
+
+func TestLogHTTP(t *testing.T) {
+	for _, configure := range []func(p *KameletPluginParams){
+		func(p *KameletPluginParams) { p.Verbosity = verbosityHTTP },
+		func(p *KameletPluginParams) { p.LogHTTP = true },
+	} {
+		p, log := loggingParams(t)
+		configure(p)
+
+		getSecret(t, p)
+		assert.Check(t, util.ContainsAll(log.String(), "===== REQUEST =====", "===== RESPONSE =====", "200 OK", `"password":"********"`, "Authorization: ********"))
+		assert.Check(t, util.ContainsNone(log.String(), "c3VwZXJzZWNyZXQ=", "s3cr3t-token"))
+	}
+}
+
+func TestNoLogging(t *testing.T) {
+	p, log := loggingParams(t)
+
+	getSecret(t, p)
+	assert.Equal(t, log.String(), "")
+}
+
+func TestRedactBody(t *testing.T) {
+	list := fmt.Sprintf(`{"kind":"SecretList","items":[%s]}`, secretJSON)
+	assert.Check(t, util.ContainsNone(string(redactBody([]byte(list), "application/json")), "c3VwZXJzZWNyZXQ="))
+
+	kamelet := `{"kind":"Kamelet","metadata":{"name":"timer-source"}}`
+	assert.Equal(t, string(redactBody([]byte(kamelet), "application/json")), kamelet)
+
+	assert.Equal(t, string(redactBody([]byte("k8s\x00secret"), "application/vnd.kubernetes.protobuf")), "<10 bytes of application/vnd.kubernetes.protobuf omitted>")
+}
+
+func getSecret(t *testing.T, p *KameletPluginParams) {
+	client, err := p.newDynamicClient()
+	assert.NilError(t, err)
+	secret, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}).Namespace("default").Get(p.Context, "creds", v1.GetOptions{})
+	assert.NilError(t, err)
+	// the logging does not change the response seen by the client
+	assert.Equal(t, secret.Object["data"].(map[string]interface{})["password"], "c3VwZXJzZWNyZXQ=")
+}
+
+func loggingParams(t *testing.T) (*KameletPluginParams, *bytes.Buffer) {
+	// credentials are only sent to TLS secured servers
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, secretJSON)
+	}))
+	t.Cleanup(server.Close)
+	return serverParams(t, server)
+}
+
+// serverParams returns params connecting to the test server with a token
+func serverParams(t *testing.T, server *httptest.Server) (*KameletPluginParams, *bytes.Buffer) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	assert.NilError(t, ioutil.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: c1
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: u1
+  user:
+    token: s3cr3t-token
+contexts:
+- name: ctx1
+  context:
+    cluster: c1
+    user: u1
+current-context: ctx1
+`, server.URL)), 0600))
+
+	log := &bytes.Buffer{}
+	p := &KameletPluginParams{
+		KnParams:  &commands.KnParams{KubeCfgPath: kubeconfig},
+		Context:   context.TODO(),
+		LogOutput: log,
+	}
+	return p, log
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// passwordDescriptor marks Kamelet properties holding sensitive values
+const passwordDescriptor = "urn:alm:descriptor:com.tectonic.ui:password"
+
+// prompter reads answers to questions from the command input
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// readSecret reads a value without echoing it, defaults to reading a plain line when input is not a terminal
+	readSecret func() (string, error)
+}
+
+// isTerminalInput checks if the command reads its input from a terminal, i.e. a user is able to answer prompts
+var isTerminalInput = func(cmd *cobra.Command) bool {
+	file, ok := cmd.InOrStdin().(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// newPrompter creates a prompter using the input and output streams of given command
+func newPrompter(cmd *cobra.Command) *prompter {
+	p := &prompter{
+		in:  bufio.NewReader(cmd.InOrStdin()),
+		out: cmd.OutOrStdout(),
+	}
+	p.readSecret = p.readLine
+	if file, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		p.readSecret = func() (string, error) {
+			secret, err := term.ReadPassword(int(file.Fd()))
+			fmt.Fprintln(p.out)
+			return string(secret), err
+		}
+	}
+	return p
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New("interactive input aborted")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// ask prompts for a value, an empty answer selects the default value if any and repeats the question otherwise
+func (p *prompter) ask(question string, defaultValue string, secret bool) (string, error) {
+	for {
+		if defaultValue != "" && !secret {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		read := p.readLine
+		if secret {
+			read = p.readSecret
+		}
+		answer, err := read()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = defaultValue
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// confirm prompts for a yes or no answer, any answer other than y or yes declines
+func (p *prompter) confirm(question string) (bool, error) {
+	fmt.Fprintf(p.out, "%s [y/N]: ", question)
+	answer, err := p.readLine()
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// choose prompts to select one of the given options either by its number or its value
+func (p *prompter) choose(question string, options []string, defaultValue string) (string, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.ask(question, defaultValue, false)
+		if err != nil {
+			return "", err
+		}
+		if index, err := strconv.Atoi(answer); err == nil && index > 0 && index <= len(options) {
+			return options[index-1], nil
+		}
+		for _, option := range options {
+			if option == answer {
+				return option, nil
+			}
+		}
+		fmt.Fprintf(p.out, "Invalid choice %q.\n", answer)
+	}
+}
+
+// runBindWizard asks for the Kamelet source when not given, all its missing required properties and the sink
+// of given sink types when no sink flag is set. Answers are added to the binding flags, returns the chosen Kamelet.
+func runBindWizard(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, namespace string, p *prompter, flags *bindingFlags, kameletName string, sinkTypes map[string]v1.TypeMeta) (string, error) {
+	if kameletName == "" {
+		kameletList, err := client.Kamelets(namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return "", knerrors.GetError(err)
+		}
+		var names []string
+		for i := range kameletList.Items {
+			if isEventSourceType(&kameletList.Items[i]) {
+				names = append(names, kameletList.Items[i].Name)
+			}
+		}
+		if len(names) == 0 {
+			return "", fmt.Errorf("no Kamelet sources found in namespace '%s'", namespace)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(p.out, "Available Kamelet sources:\n")
+		if kameletName, err = p.choose("Kamelet source", names, ""); err != nil {
+			return "", err
+		}
+	}
+
+	kamelet, err := client.Kamelets(namespace).Get(ctx, kameletName, v1.GetOptions{})
+	if err != nil {
+		return "", knerrors.GetError(err)
+	}
+	if !isEventSourceType(kamelet) {
+		return "", fmt.Errorf("Kamelet %s is not an event source", kamelet.Name)
+	}
+
+	given, err := flags.sourceProperties()
+	if err != nil {
+		return "", err
+	}
+	if kamelet.Spec.Definition != nil {
+		for _, name := range kamelet.Spec.Definition.Required {
+			if _, ok := given[name]; ok {
+				continue
+			}
+			property := kamelet.Spec.Definition.Properties[name]
+			if property.Description != "" {
+				fmt.Fprintf(p.out, "%s\n", property.Description)
+			}
+			question := name
+			if property.Title != "" {
+				question = fmt.Sprintf("%s (%s)", property.Title, name)
+			}
+			value, err := p.ask(question, kameletapi.JSONValue(property.Default), isPasswordProperty(property))
+			if err != nil {
+				return "", err
+			}
+			flags.SourceProperties = append(flags.SourceProperties, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+
+	if _, err := flags.sinkExpression(); err != nil {
+		fmt.Fprintf(p.out, "Sink types:\n")
+		sinkType, err := p.choose("Sink type", kameletapi.SupportedSinkTypes(sinkTypes), "broker")
+		if err != nil {
+			return "", err
+		}
+		defaultName := ""
+		if sinkType == "broker" {
+			defaultName = "default"
+		}
+		sinkName, err := p.ask("Sink name", defaultName, false)
+		if err != nil {
+			return "", err
+		}
+		flags.Sink = fmt.Sprintf("%s:%s", sinkType, sinkName)
+	}
+
+	return kamelet.Name, nil
+}
+
+// isPasswordProperty checks if the property holds a sensitive value that should not be echoed
+func isPasswordProperty(property v1alpha1.JSONSchemaProps) bool {
+	if property.Format == "password" {
+		return true
+	}
+	for _, descriptor := range property.XDescriptors {
+		if descriptor == passwordDescriptor {
+			return true
+		}
+	}
+	return false
+}
+
+// addNoPromptFlag adds the --no-prompt flag to given command
+func addNoPromptFlag(cmd *cobra.Command, noPrompt *bool) {
+	cmd.Flags().BoolVar(noPrompt, "no-prompt", false, "Fail on missing required password properties instead of asking for them, e.g. in CI pipelines. Prompts are only shown when the input is a terminal.")
+}
+
+// promptPasswords asks for the missing password properties of the binding unless prompting is disabled, the input is
+// not a terminal or the binding is only rendered on client dry-run
+func (params *KameletPluginParams) promptPasswords(cmd *cobra.Command, binding *v1alpha1.KameletBinding, flags *bindingFlags, dryRun string, noPrompt bool) error {
+	if noPrompt || dryRun == dryRunClient || !isTerminalInput(cmd) {
+		return nil
+	}
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return err
+	}
+	return promptMissingPasswords(params.Context, client, binding, newPrompter(cmd), flags)
+}
+
+// promptMissingPasswords asks with hidden input for the required password properties of the Kamelet source and a
+// Kamelet sink of the binding that are not given. The answers are added as secret properties, so they are stored in
+// the Secret of the binding instead of its spec.
+func promptMissingPasswords(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, p *prompter, flags *bindingFlags) error {
+	for _, endpoint := range []struct {
+		endpoint *v1alpha1.Endpoint
+		secrets  *[]string
+	}{
+		{&binding.Spec.Source, &flags.SourceSecretProperties},
+		{&binding.Spec.Sink, &flags.SinkSecretProperties},
+	} {
+		ref := endpoint.endpoint.Ref
+		if ref == nil || ref.Kind != v1alpha1.KameletKind {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = binding.Namespace
+		}
+		kamelet, err := client.Kamelets(namespace).Get(ctx, ref.Name, v1.GetOptions{})
+		if err != nil {
+			return knerrors.GetError(err)
+		}
+		if kamelet.Spec.Definition == nil {
+			continue
+		}
+
+		given, err := kameletapi.DecodeEndpointProperties(endpoint.endpoint.Properties)
+		if err != nil {
+			return err
+		}
+		for _, secret := range *endpoint.secrets {
+			given[strings.SplitN(secret, "=", 2)[0]] = ""
+		}
+		for _, name := range kamelet.Spec.Definition.Required {
+			property := kamelet.Spec.Definition.Properties[name]
+			if _, ok := given[name]; ok || !isPasswordProperty(property) {
+				continue
+			}
+			question := fmt.Sprintf("%s of Kamelet %s", name, kamelet.Name)
+			if property.Title != "" {
+				question = fmt.Sprintf("%s (%s) of Kamelet %s", property.Title, name, kamelet.Name)
+			}
+			value, err := p.ask(question, "", true)
+			if err != nil {
+				return err
+			}
+			*endpoint.secrets = append(*endpoint.secrets, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPrompterChoose(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := testPrompter("foo\n2\n", out)
+
+	choice, err := p.choose("Pick one", []string{"a", "b"}, "")
+	assert.NilError(t, err)
+	assert.Equal(t, choice, "b")
+	assert.Check(t, util.ContainsAll(out.String(), "1) a", "2) b", "Invalid choice \"foo\""))
+}
+
+func TestPrompterAskDefault(t *testing.T) {
+	p := testPrompter("\n", &bytes.Buffer{})
+
+	answer, err := p.ask("Period", "1000", false)
+	assert.NilError(t, err)
+	assert.Equal(t, answer, "1000")
+
+	_, err = p.ask("Message", "", false)
+	assert.Error(t, err, "interactive input aborted")
+}
+
+func TestBindInteractive(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	sink := createKamelet("log-sink")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	kamelet := createKamelet("k2")
+	kamelet.Spec.Definition.Required = []string{"message", "password", "period"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"message":  {Title: "Message", Description: "The message to generate", Type: "string"},
+		"password": {Title: "Password", Type: "string", Format: "password"},
+		"period":   {Title: "Period", Type: "integer", Default: &camelkapis.JSON{RawMessage: []byte("1000")}},
+	}
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1"), *sink, *kamelet}}, nil)
+	recorder.Get(kamelet, nil)
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k2-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, binding.Name, "k2-to-broker-default")
+		assert.Equal(t, binding.Spec.Source.Ref.Name, "k2")
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello","password":"secret","period":1000}`)
+		assert.Equal(t, binding.Spec.Sink.Ref.Kind, "Broker")
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+
+	output, err := runBindCmdWithInput(mockClient, "2\nHello\nsecret\n\n\n\n", "--interactive")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "1) k1", "2) k2", "The message to generate", "Period (period) [1000]", "Sink type [broker]", "created"))
+	assert.Check(t, util.ContainsNone(output, "log-sink", "[secret]"))
+	recorder.Validate()
+}
+
+func TestBindInteractiveGivenValues(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+
+	output, err := runBindCmdWithInput(mockClient, "", "k1", "--interactive", "--source-property", "message=Hi", "--service", "display", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletBinding", "message: Hi", "kind: Service"))
+	recorder.Validate()
+}
+
+func TestBindInteractiveOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+
+	_, err := runBindCmd(mockClient, "--interactive", "--offline")
+	assert.Error(t, err, "--interactive can not be combined with --offline")
+}
+
+func TestBindPromptMissingPasswords(t *testing.T) {
+	defer func(isTerminal func(*cobra.Command) bool) {
+		isTerminalInput = isTerminal
+	}(isTerminalInput)
+	isTerminalInput = func(*cobra.Command) bool {
+		return true
+	}
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	kubeClient := fake.NewSimpleClientset()
+	p := secretPropertyParams(mockClient, kubeClient)
+
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message", "password"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"message":  {Type: "string"},
+		"password": {Title: "Password", Type: "string", Format: "password"},
+	}
+	recorder.Get(kamelet, nil)
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage),
+			`{"message":"Hello","password":"{{secret:k1-to-broker-default/source.password}}"}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runBindCmdWithParams(p, "pa55\n", "k1", "--broker", "default", "--source-property", "message=Hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Password (password) of Kamelet k1: ", "Secret 'k1-to-broker-default' created"))
+	assert.Check(t, util.ContainsNone(output, "pa55"))
+
+	secret, err := kubeClient.CoreV1().Secrets("current").Get(context.TODO(), "k1-to-broker-default", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, secret.StringData["source.password"], "pa55")
+
+	// given passwords are not asked for
+	recorder.Get(kamelet, nil)
+	recorder.Get(kamelet, nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err = runBindCmdWithParams(p, "", "k1", "--broker", "default", "--source-property", "message=Hello", "--source-secret-property", "password=pa55")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsNone(output, "Password (password)"))
+
+	recorder.Get(kamelet, nil)
+	_, err = runBindCmdWithParams(p, "pa55\n", "k1", "--broker", "default", "--source-property", "message=Hello", "--no-prompt")
+	assert.Error(t, err, "binding is missing required property \"password\" for Kamelet \"k1\"")
+	recorder.Validate()
+}
+
+func testPrompter(input string, out *bytes.Buffer) *prompter {
+	p := &prompter{
+		in:  bufio.NewReader(strings.NewReader(input)),
+		out: out,
+	}
+	p.readSecret = p.readLine
+	return p
+}
+
+func runBindCmdWithParams(p *KameletPluginParams, input string, options ...string) (string, error) {
+	bindCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindCommand(p), p.KnParams)
+	bindCmd.SetArgs(append([]string{"bind"}, options...))
+	bindCmd.SetIn(strings.NewReader(input))
+	err := bindCmd.Execute()
+	return output.String(), err
+}
+
+func runBindCmdWithInput(c *kamelettesting.MockKameletClient, input string, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	bindCmd, _, output := commands.CreateSourcesTestKnCommand(NewBindCommand(&p), p.KnParams)
+
+	args := []string{"bind"}
+	args = append(args, options...)
+	bindCmd.SetArgs(args)
+	bindCmd.SetIn(strings.NewReader(input))
+	err := bindCmd.Execute()
+
+	return output.String(), err
+}
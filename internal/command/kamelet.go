@@ -0,0 +1,169 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// NewKameletCommand implements 'kn-source-kamelet kamelet' command
+func NewKameletCommand(p *KameletPluginParams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "kamelet",
+		Short:   "Manage Kamelets",
+		Aliases: []string{"kamelets"},
+	}
+	cmd.AddCommand(newKameletCreateCommand(p))
+	cmd.AddCommand(newKameletInstallCommand(p))
+	cmd.AddCommand(newKameletDeleteCommand(p))
+	return cmd
+}
+
+// schemaTypes lists the JSON schema types supported for Kamelet properties
+var schemaTypes = []string{"string", "integer", "number", "boolean", "object", "array"}
+
+// validateKamelet checks the Kamelet definition, i.e. the name, the type label, the property schemas and the
+// presence of a flow before the Kamelet is sent to the cluster
+func validateKamelet(kamelet *v1alpha1.Kamelet) error {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(kamelet.Name) {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), kamelet.Name, msg))
+	}
+
+	typePath := field.NewPath("metadata", "labels").Key(kameletapi.TypeLabel)
+	switch kameletType := kamelet.Labels[kameletapi.TypeLabel]; kameletType {
+	case "source", "sink", "action":
+	case "":
+		errs = append(errs, field.Required(typePath, "the Kamelet type is one of source, sink or action"))
+	default:
+		errs = append(errs, field.NotSupported(typePath, kameletType, []string{"source", "sink", "action"}))
+	}
+
+	specPath := field.NewPath("spec")
+	if kamelet.Spec.Flow == nil && len(kamelet.Spec.Sources) == 0 {
+		errs = append(errs, field.Required(specPath.Child("flow"), "a flow or sources implementing the Kamelet are required"))
+	}
+
+	definition := kamelet.Spec.Definition
+	if definition == nil {
+		errs = append(errs, field.Required(specPath.Child("definition"), ""))
+		return aggregateKameletErrors(kamelet, errs)
+	}
+
+	propertiesPath := specPath.Child("definition", "properties")
+	names := make([]string, 0, len(definition.Properties))
+	for name := range definition.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := definition.Properties[name]
+		path := propertiesPath.Key(name)
+		if !containsString(schemaTypes, schema.Type) {
+			errs = append(errs, field.NotSupported(path.Child("type"), schema.Type, schemaTypes))
+			continue
+		}
+		if schema.Pattern != "" {
+			if _, err := regexp.Compile(schema.Pattern); err != nil {
+				errs = append(errs, field.Invalid(path.Child("pattern"), schema.Pattern, err.Error()))
+			}
+		}
+		if value := kameletapi.JSONValue(schema.Default); value != "" && schema.Type != "object" && schema.Type != "array" {
+			if err := kameletapi.ValidateValue(schema, value); err != nil {
+				errs = append(errs, field.Invalid(path.Child("default"), value, err.Error()))
+			}
+		}
+	}
+	for i, name := range definition.Required {
+		if _, ok := definition.Properties[name]; !ok {
+			errs = append(errs, field.NotFound(specPath.Child("definition", "required").Index(i), name))
+		}
+	}
+	return aggregateKameletErrors(kamelet, errs)
+}
+
+func aggregateKameletErrors(kamelet *v1alpha1.Kamelet, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid Kamelet %s: %w", kamelet.Name, errs.ToAggregate())
+}
+
+// applyKamelet creates the Kamelet or updates the definition of the existing Kamelet with the same name
+func applyKamelet(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, kamelet *v1alpha1.Kamelet, serverDryRun bool, audit *auditLog, out io.Writer) error {
+	var dryRun []string
+	var dryRunSuffix string
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	existing, err := client.Kamelets(kamelet.Namespace).Get(ctx, kamelet.Name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err := client.Kamelets(kamelet.Namespace).Create(ctx, kamelet, v1.CreateOptions{DryRun: dryRun, FieldManager: fieldManager})
+		if auditErr := audit.record("create", v1alpha1.KameletKind, kamelet.Namespace, kamelet.Name, nil, err); auditErr != nil {
+			return auditErr
+		}
+		if err != nil {
+			return knerrors.GetError(err)
+		}
+		fmt.Fprintf(out, "Kamelet '%s' created in namespace '%s'%s.\n", kamelet.Name, kamelet.Namespace, dryRunSuffix)
+		return nil
+	} else if err != nil {
+		return knerrors.GetError(err)
+	}
+
+	desired := existing.DeepCopy()
+	desired.Spec = kamelet.Spec
+	desired.Labels = mergeMetadata(desired.Labels, kamelet.Labels, nil)
+	desired.Annotations = mergeMetadata(desired.Annotations, kamelet.Annotations, nil)
+	_, err = client.Kamelets(kamelet.Namespace).Update(ctx, desired, v1.UpdateOptions{DryRun: dryRun, FieldManager: fieldManager})
+	if auditErr := audit.record("update", v1alpha1.KameletKind, kamelet.Namespace, kamelet.Name, nil, err); auditErr != nil {
+		return auditErr
+	}
+	if err != nil {
+		return knerrors.GetError(err)
+	}
+	fmt.Fprintf(out, "Kamelet '%s' updated in namespace '%s'%s.\n", kamelet.Name, kamelet.Namespace, dryRunSuffix)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
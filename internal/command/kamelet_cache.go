@@ -0,0 +1,213 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/kn-plugin-source-kamelet/internal/catalog"
+)
+
+// kameletCacheTTL defines how long cached Kamelets are used for completion before the cluster is queried again,
+// offline commands use cached Kamelets of any age
+const kameletCacheTTL = 5 * time.Minute
+
+// kameletCacheEntry holds the Kamelets cached for a single namespace
+type kameletCacheEntry struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Kamelets  []v1alpha1.Kamelet `json:"kamelets"`
+}
+
+// AddCacheFlags adds the flag bypassing the local Kamelet cache to given flag set
+func (params *KameletPluginParams) AddCacheFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&params.Refresh, "refresh", false, "Read Kamelets from the cluster instead of the local cache and refresh the cache.")
+}
+
+// NewCacheCommand implements 'kn-source-kamelet cache' command
+func NewCacheCommand(p *KameletPluginParams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of Kamelet definitions",
+		Long: `Manage the local cache of Kamelet definitions.
+
+Kamelets are cached per kubeconfig context and namespace whenever they are listed with 'list-types'. The cache powers
+shell completion as well as 'describe-type --offline' and the property validation of 'bind --offline'. Kamelets
+that are not cached are looked up in the snapshot of the Kamelet catalog bundled with the plugin.`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached Kamelets and namespaces",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := p.clearCache(); err != nil {
+				return err
+			}
+			fmt.Fprintln(p.messageOutput(cmd.OutOrStdout()), "Cache cleared.")
+			return nil
+		},
+	})
+	return cmd
+}
+
+// cacheKey identifies the cluster of the current kubeconfig context in the cache files
+func (params *KameletPluginParams) cacheKey() string {
+	key, kubeContext := params.currentContext()
+	if kubeContext != nil {
+		// the cluster of the context may be overridden by flag
+		key += "@" + kubeContext.Cluster
+	}
+	return key
+}
+
+// readKameletCache returns the cached Kamelets by namespace of the current context, the cache is best effort
+// so an unreadable cache is empty
+func (params *KameletPluginParams) readKameletCache() (map[string]map[string]kameletCacheEntry, string) {
+	cache := map[string]map[string]kameletCacheEntry{}
+	cacheFile, err := params.cacheFile("kamelets.json")
+	if err != nil {
+		return cache, ""
+	}
+	if data, err := ioutil.ReadFile(cacheFile); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache, cacheFile
+}
+
+// storeKamelets replaces the cached Kamelets of the namespace, Kamelets of all namespaces are stored by their namespace
+func (params *KameletPluginParams) storeKamelets(namespace string, kamelets []v1alpha1.Kamelet) {
+	cache, cacheFile := params.readKameletCache()
+	if cacheFile == "" {
+		return
+	}
+
+	byNamespace := map[string][]v1alpha1.Kamelet{}
+	if namespace != "" {
+		byNamespace[namespace] = []v1alpha1.Kamelet{}
+	}
+	for _, kamelet := range kamelets {
+		byNamespace[kamelet.Namespace] = append(byNamespace[kamelet.Namespace], kamelet)
+	}
+
+	key := params.cacheKey()
+	if cache[key] == nil {
+		cache[key] = map[string]kameletCacheEntry{}
+	}
+	for ns, items := range byNamespace {
+		cache[key][ns] = kameletCacheEntry{Timestamp: time.Now(), Kamelets: items}
+	}
+	if data, err := json.Marshal(cache); err == nil {
+		_ = ioutil.WriteFile(cacheFile, data, 0600)
+	}
+}
+
+// cachedKamelets returns the cached Kamelets of the namespace if cached within given age, zero accepts any age
+func (params *KameletPluginParams) cachedKamelets(namespace string, maxAge time.Duration) ([]v1alpha1.Kamelet, bool) {
+	cache, _ := params.readKameletCache()
+	entry, ok := cache[params.cacheKey()][namespace]
+	if !ok || (maxAge > 0 && time.Since(entry.Timestamp) >= maxAge) {
+		return nil, false
+	}
+	return entry.Kamelets, true
+}
+
+// cachedKamelet returns the cached Kamelet of any age
+func (params *KameletPluginParams) cachedKamelet(namespace string, name string) (*v1alpha1.Kamelet, bool) {
+	kamelets, ok := params.cachedKamelets(namespace, 0)
+	if !ok {
+		return nil, false
+	}
+	for i := range kamelets {
+		if kamelets[i].Name == name {
+			return &kamelets[i], true
+		}
+	}
+	return nil, false
+}
+
+// offlineKamelet returns the cached Kamelet of any age and falls back to the Kamelet catalog bundled with the plugin
+func (params *KameletPluginParams) offlineKamelet(namespace string, name string) (*v1alpha1.Kamelet, bool) {
+	if kamelet, ok := params.cachedKamelet(namespace, name); ok {
+		return kamelet, true
+	}
+	kamelet, ok := catalog.Get(name)
+	if ok {
+		kamelet.Namespace = namespace
+	}
+	return kamelet, ok
+}
+
+// listKamelets returns the Kamelets of the namespace from the cache while it is fresh, otherwise the Kamelets
+// are listed from the cluster and cached
+func (params *KameletPluginParams) listKamelets(namespace string) ([]v1alpha1.Kamelet, error) {
+	if !params.Refresh {
+		if kamelets, ok := params.cachedKamelets(namespace, kameletCacheTTL); ok {
+			return kamelets, nil
+		}
+	}
+
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return nil, err
+	}
+	kameletList, err := client.Kamelets(namespace).List(params.Context, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	params.storeKamelets(namespace, kameletList.Items)
+	return kameletList.Items, nil
+}
+
+// getKamelet returns the Kamelet from the cache while it is fresh, otherwise it is read from the cluster
+func (params *KameletPluginParams) getKamelet(namespace string, name string) (*v1alpha1.Kamelet, error) {
+	if !params.Refresh {
+		if kamelets, ok := params.cachedKamelets(namespace, kameletCacheTTL); ok {
+			for i := range kamelets {
+				if kamelets[i].Name == name {
+					return &kamelets[i], nil
+				}
+			}
+		}
+	}
+
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Kamelets(namespace).Get(params.Context, name, v1.GetOptions{})
+}
+
+// clearCache removes the plugin cache directory
+func (params *KameletPluginParams) clearCache() error {
+	cacheFile, err := params.cacheFile("kamelets.json")
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Dir(cacheFile)); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKameletCacheOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+
+	_, err := runPipeCmd(p, NewDescribeTypeCommand(p), "describe-type", "k1", "--offline")
+	assert.ErrorContains(t, err, "Kamelet k1 is neither cached for namespace current nor part of the bundled catalog")
+
+	kamelet := createKamelet("k1")
+	kamelet.Namespace = "current"
+	kamelet.Spec.Definition.Required = []string{"message"}
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{"message": {Type: "string"}}
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet}}, nil)
+	_, err = runPipeCmd(p, NewListTypesCommand(p), "list-types")
+	assert.NilError(t, err)
+
+	// cached Kamelets are described and verified without accessing the cluster
+	output, err := runPipeCmd(p, NewDescribeTypeCommand(p), "describe-type", "k1", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "k1", "Kamelet k1", "message"))
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--offline", "-n", "current")
+	assert.ErrorContains(t, err, "message")
+
+	output, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--offline", "-n", "current", "--source-property", "message=hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "message: hello"))
+
+	// Kamelets that are neither cached nor bundled are not verified
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "k2", "--broker", "default", "--offline", "-n", "current")
+	assert.NilError(t, err)
+
+	p.Refresh = true
+	_, err = runPipeCmd(p, NewDescribeTypeCommand(p), "describe-type", "k1", "--offline")
+	assert.Error(t, err, "--offline can not be combined with --refresh")
+	p.Refresh = false
+
+	output, err = runPipeCmd(p, NewCacheCommand(p), "cache", "clear")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Cache cleared.\n")
+	_, err = runPipeCmd(p, NewDescribeTypeCommand(p), "describe-type", "k1", "--offline")
+	assert.ErrorContains(t, err, "is neither cached")
+
+	recorder.Validate()
+}
+
+func TestKameletCatalogOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	p := cacheParams(t, mockClient)
+
+	output, err := runPipeCmd(p, NewDescribeTypeCommand(p), "describe-type", "timer-source", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "timer-source", "Timer Source", "message"))
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "timer-source", "--broker", "default", "--offline")
+	assert.ErrorContains(t, err, "message")
+
+	output, err = runPipeCmd(p, NewBindCommand(p), "bind", "timer-source", "--sink", "kamelet:log-sink", "--offline", "--source-property", "message=hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: timer-source", "name: log-sink", "message: hello", "namespace: default"))
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "log-sink", "--broker", "default", "--offline")
+	assert.ErrorContains(t, err, "Kamelet log-sink is not an event source")
+
+	mockClient.Recorder().Validate()
+}
+
+func TestKameletCacheRefresh(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKameletInNamespace("k1", "test")}}, nil)
+	kamelets, err := p.listKamelets("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(kamelets), 1)
+
+	// served from the cache
+	kamelets, err = p.listKamelets("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(kamelets), 1)
+
+	p.Refresh = true
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKameletInNamespace("k1", "test"), *createKameletInNamespace("k2", "test")}}, nil)
+	kamelets, err = p.listKamelets("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(kamelets), 2)
+
+	p.Refresh = false
+	kamelets, err = p.listKamelets("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(kamelets), 2)
+
+	recorder.Validate()
+}
+
+func TestKameletCacheAllNamespaces(t *testing.T) {
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	p.storeKamelets("", []camelkapis.Kamelet{*createKameletInNamespace("k1", "ns1"), *createKameletInNamespace("k2", "ns2")})
+	_, ok := p.cachedKamelet("ns1", "k1")
+	assert.Check(t, ok)
+	_, ok = p.cachedKamelet("ns2", "k1")
+	assert.Check(t, !ok)
+	_, ok = p.cachedKamelet("ns2", "k2")
+	assert.Check(t, ok)
+}
+
+func cacheParams(t *testing.T, c *kamelettesting.MockKameletClient) *KameletPluginParams {
+	p := completionParams(t, nil)
+	p.NewKameletClient = func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+		return c, nil
+	}
+	return p
+}
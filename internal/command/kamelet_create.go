@@ -0,0 +1,171 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var kameletCreateExample = `
+  # Print the skeleton of a new Kamelet source
+  kn-source-kamelet kamelet create my-source --type source --scaffold
+
+  # Write the skeleton of a new Kamelet sink to given file and install it once implemented
+  kn-source-kamelet kamelet create my-sink --type sink --scaffold --file my-sink.kamelet.yaml
+  kn-source-kamelet kamelet install -f my-sink.kamelet.yaml`
+
+// kameletScaffold is the skeleton of new Kamelets, the flow is given by the Kamelet type
+const kameletScaffold = `apiVersion: camel.apache.org/v1alpha1
+kind: Kamelet
+metadata:
+  name: %[1]s
+  annotations:
+    camel.apache.org/kamelet.support.level: "Preview"
+    camel.apache.org/provider: "%[4]s"
+  labels:
+    camel.apache.org/kamelet.type: "%[2]s"
+spec:
+  definition:
+    title: "%[3]s"
+    description: |-
+      Describe what the Kamelet does.
+    # properties the users of the Kamelet have to set when binding it
+    required:
+      - message
+    properties:
+      message:
+        title: Message
+        description: The message to send
+        type: string
+        example: hello world
+      period:
+        title: Period
+        description: The interval between two messages in milliseconds
+        type: integer
+        default: 1000
+  # media types of the data produced (out) and consumed (in) by the Kamelet
+  types:
+    %[5]s:
+      mediaType: text/plain
+  # the Camel route implementing the Kamelet, properties are referenced as {{name}}
+  flow:
+%[6]s`
+
+// scaffoldFlows holds the placeholder routes of the Kamelet types
+var scaffoldFlows = map[string]struct {
+	mediaType string
+	flow      string
+}{
+	"source": {"out", `    from:
+      uri: timer:tick
+      parameters:
+        period: "{{period}}"
+      steps:
+        - set-body:
+            constant: "{{message}}"
+        - to: kamelet:sink
+`},
+	"sink": {"in", `    from:
+      uri: kamelet:source
+      steps:
+        - set-header:
+            name: message
+            constant: "{{message}}"
+        - to:
+            uri: log:info
+            parameters:
+              showHeaders: true
+`},
+	"action": {"in", `    from:
+      uri: kamelet:source
+      steps:
+        - set-body:
+            simple: "{{message}}: ${body}"
+        - to: kamelet:sink
+`},
+}
+
+// newKameletCreateCommand implements 'kn-source-kamelet kamelet create' command
+func newKameletCreateCommand(p *KameletPluginParams) *cobra.Command {
+	var kameletType string
+	var scaffold bool
+	var file string
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:     "create NAME --scaffold",
+		Short:   "Create the skeleton of a new Kamelet",
+		Long:    "Create the skeleton of a new Kamelet with metadata, a property definition and a placeholder route, ready to be implemented and installed with 'kn-source-kamelet kamelet install'.",
+		Example: kameletCreateExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'kn-source-kamelet kamelet create' requires the Kamelet name given as single argument")
+			}
+			if !scaffold {
+				return errors.New("'kn-source-kamelet kamelet create' only supports generating a skeleton, use --scaffold")
+			}
+			name := args[0]
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return fmt.Errorf("invalid Kamelet name %q: %s", name, strings.Join(errs, ", "))
+			}
+			template, ok := scaffoldFlows[kameletType]
+			if !ok {
+				return fmt.Errorf("invalid Kamelet type %q, expected one of: source|sink|action", kameletType)
+			}
+
+			var out io.Writer = cmd.OutOrStdout()
+			if file != "" {
+				f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+				if err != nil {
+					return fmt.Errorf("failed to create Kamelet file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if _, err := fmt.Fprintf(out, kameletScaffold, name, kameletType, kameletTitle(name), provider, template.mediaType, template.flow); err != nil {
+				return err
+			}
+			if file != "" {
+				fmt.Fprintf(p.messageOutput(cmd.OutOrStdout()), "Kamelet '%s' written to %s.\n", name, file)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kameletType, "type", "source", "Type of the Kamelet, one of: source|sink|action.")
+	cmd.Flags().BoolVar(&scaffold, "scaffold", false, "Generate the skeleton of the Kamelet.")
+	cmd.Flags().StringVar(&file, "file", "", "Write the Kamelet to given file instead of stdout, existing files are not overwritten.")
+	cmd.Flags().StringVar(&provider, "provider", "Custom", "Provider of the Kamelet.")
+	return cmd
+}
+
+// kameletTitle derives a title from the Kamelet name, e.g. 'My Source' from 'my-source'
+func kameletTitle(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '.'
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKameletCreateScaffold(t *testing.T) {
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	for _, kameletType := range []string{"source", "sink", "action"} {
+		output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "my-"+kameletType, "--type", kameletType, "--scaffold")
+		assert.NilError(t, err)
+
+		// the skeleton is a valid Kamelet
+		kamelets, err := decodeKamelets(strings.NewReader(output), "stdout")
+		assert.NilError(t, err)
+		assert.Equal(t, len(kamelets), 1)
+		assert.NilError(t, validateKamelet(kamelets[0]))
+		assert.Equal(t, kameletapi.TypeOf(kamelets[0]), kameletType)
+		assert.Equal(t, kamelets[0].Spec.Definition.Title, "My "+strings.ToUpper(kameletType[:1])+kameletType[1:])
+		assert.Equal(t, kamelets[0].Annotations[providerAnnotation], "Custom")
+	}
+}
+
+func TestKameletCreateScaffoldFile(t *testing.T) {
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+	file := filepath.Join(t.TempDir(), "my-source.kamelet.yaml")
+
+	output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "my-source", "--scaffold", "--file", file, "--provider", "ACME")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'my-source' written to "+file+".\n")
+	data, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Check(t, strings.Contains(string(data), `camel.apache.org/provider: "ACME"`))
+
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "my-source", "--scaffold", "--file", file)
+	assert.ErrorContains(t, err, "failed to create Kamelet file")
+}
+
+func TestKameletCreateErrors(t *testing.T) {
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	_, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "my-source")
+	assert.ErrorContains(t, err, "use --scaffold")
+
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "my-source", "--scaffold", "--type", "step")
+	assert.Error(t, err, "invalid Kamelet type \"step\", expected one of: source|sink|action")
+
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "create", "My_Source", "--scaffold")
+	assert.ErrorContains(t, err, "invalid Kamelet name \"My_Source\"")
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var kameletDeleteExample = `
+  # Delete given Kamelets unless they are referenced by bindings
+  kn-source-kamelet kamelet delete NAME...
+
+  # Delete given Kamelet even though bindings still reference it
+  kn-source-kamelet kamelet delete NAME --force`
+
+// newKameletDeleteCommand implements 'kn-source-kamelet kamelet delete' command
+func newKameletDeleteCommand(p *KameletPluginParams) *cobra.Command {
+	var force bool
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:     "delete NAME...",
+		Short:   "Delete Kamelets",
+		Long:    "Delete Kamelets. Kamelets referenced by the bindings of their namespace are only deleted with --force.",
+		Aliases: []string{"rm"},
+		Example: kameletDeleteExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return errors.New("'kn-source-kamelet kamelet delete' requires the Kamelet names given as arguments")
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			bindings, err := p.listBindings(namespace)
+			if err != nil {
+				if !force {
+					return fmt.Errorf("failed to check the bindings referencing the Kamelets, use --force to delete them anyway: %w", err)
+				}
+				fmt.Fprintf(p.messageOutput(cmd.ErrOrStderr()), "Warning: failed to check the bindings referencing the Kamelets: %v\n", err)
+			}
+			for _, name := range args {
+				referencing := kameletReferences(bindings, namespace, name)
+				if len(referencing) == 0 {
+					continue
+				}
+				if !force {
+					return fmt.Errorf("Kamelet %s is referenced by bindings %s, use --force to delete it anyway", name, strings.Join(referencing, ", "))
+				}
+				fmt.Fprintf(p.messageOutput(cmd.ErrOrStderr()), "Warning: Kamelet %s is still referenced by bindings %s.\n", name, strings.Join(referencing, ", "))
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+
+			audit := p.auditLog()
+			errs, err := runConcurrently(concurrency, len(args), func(i int) error {
+				err := client.Kamelets(namespace).Delete(p.Context, args[i], v1.DeleteOptions{})
+				if auditErr := audit.record("delete", v1alpha1.KameletKind, namespace, args[i], nil, err); auditErr != nil {
+					return auditErr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to delete Kamelet '%s' in namespace '%s': %w", args[i], namespace, knerrors.GetError(err))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			out := p.messageOutput(cmd.OutOrStdout())
+			for i, name := range args {
+				if errs[i] == nil {
+					fmt.Fprintf(out, "Kamelet '%s' deleted in namespace '%s'.\n", name, namespace)
+				}
+			}
+
+			return aggregateErrors(errs)
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().BoolVar(&force, "force", false, "Delete the Kamelets even if bindings reference them.")
+	addConcurrencyFlag(cmd.Flags(), &concurrency)
+	return cmd
+}
+
+// listBindings returns the bindings of the namespace, Pipes are converted to KameletBindings
+func (params *KameletPluginParams) listBindings(namespace string) ([]v1alpha1.KameletBinding, error) {
+	pipes, err := params.usePipes()
+	if err != nil {
+		return nil, err
+	}
+	if pipes {
+		client, err := params.NewDynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		pipeList, err := client.Resource(pipeResource).Namespace(namespace).List(params.Context, v1.ListOptions{})
+		if err != nil {
+			return nil, knerrors.GetError(err)
+		}
+		bindingList, err := fromPipeList(pipeList)
+		if err != nil {
+			return nil, err
+		}
+		return bindingList.Items, nil
+	}
+
+	client, err := params.NewKameletClient()
+	if err != nil {
+		return nil, err
+	}
+	bindingList, err := client.KameletBindings(namespace).List(params.Context, v1.ListOptions{})
+	if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	return bindingList.Items, nil
+}
+
+// kameletReferences returns the names of the bindings using the Kamelet as source or sink
+func kameletReferences(bindings []v1alpha1.KameletBinding, namespace string, name string) []string {
+	var names []string
+	for _, binding := range bindings {
+		for _, endpoint := range []v1alpha1.Endpoint{binding.Spec.Source, binding.Spec.Sink} {
+			ref := endpoint.Ref
+			if ref == nil || ref.Kind != v1alpha1.KameletKind || ref.Name != name {
+				continue
+			}
+			if refNamespace := ref.Namespace; refNamespace == "" || refNamespace == namespace {
+				names = append(names, binding.Name)
+				break
+			}
+		}
+	}
+	return names
+}
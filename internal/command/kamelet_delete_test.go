@@ -0,0 +1,77 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKameletDelete(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+	p.UseKameletBinding = true
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*createKameletBinding("b1", "k3")}}, nil)
+	recorder.Delete("k1", nil)
+	recorder.Delete("k2", nil)
+	output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k1", "k2", "-n", "default", "--concurrency", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'k1' deleted in namespace 'default'.\nKamelet 'k2' deleted in namespace 'default'.\n")
+
+	recorder.Validate()
+}
+
+func TestKameletDeleteReferenced(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+	p.UseKameletBinding = true
+	bindings := &camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{
+		*createKameletBinding("b1", "k1"),
+		*createKameletBinding("b2", "k1"),
+		*createKameletBindingInNamespace("b3", "k2", "other"),
+	}}
+
+	recorder.ListBindings(bindings, nil)
+	_, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k1", "-n", "default")
+	assert.Error(t, err, "Kamelet k1 is referenced by bindings b1, b2, use --force to delete it anyway")
+
+	// Kamelets of other namespaces with the same name are not affected
+	recorder.ListBindings(bindings, nil)
+	recorder.Delete("k2", nil)
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k2", "-n", "default")
+	assert.NilError(t, err)
+
+	recorder.ListBindings(bindings, nil)
+	recorder.Delete("k1", nil)
+	output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k1", "-n", "default", "--force")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'k1' deleted in namespace 'default'.\n")
+
+	recorder.ListBindings(nil, errors.New("forbidden"))
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "delete", "k1")
+	assert.ErrorContains(t, err, "failed to check the bindings referencing the Kamelets, use --force to delete them anyway")
+
+	recorder.Validate()
+}
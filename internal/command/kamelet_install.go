@@ -0,0 +1,129 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"knative.dev/client/pkg/kn/commands"
+)
+
+var kameletInstallExample = `
+  # Install the Kamelets of given manifest file
+  kn-source-kamelet kamelet install -f my-source.kamelet.yaml
+
+  # Install all Kamelets of given directory into namespace 'integrations'
+  kn-source-kamelet kamelet install -f kamelets/ -n integrations
+
+  # Install a Kamelet from the upstream catalog
+  kn-source-kamelet kamelet install -f https://raw.githubusercontent.com/apache/camel-kamelets/v0.3.0/timer-source.kamelet.yaml
+
+  # Install a Kamelet after verifying its signature created with 'cosign sign-blob --key cosign.key', the signature
+  # is read from the manifest URL with the .sig suffix
+  kn-source-kamelet kamelet install -f https://example.com/kamelets/timer-source.kamelet.yaml --verify-signature --key cosign.pub
+
+  # Validate the Kamelet on the API server without installing it
+  kn-source-kamelet kamelet install -f my-source.kamelet.yaml --dry-run server`
+
+// newKameletInstallCommand implements 'kn-source-kamelet kamelet install' command
+func newKameletInstallCommand(p *KameletPluginParams) *cobra.Command {
+	var filenames []string
+	var dryRun string
+	var signature signatureFlags
+	printFlags := genericclioptions.NewPrintFlags("")
+
+	cmd := &cobra.Command{
+		Use:     "install -f FILENAME|URL",
+		Short:   "Install Kamelets from manifest files or URLs",
+		Long:    "Install Kamelets from manifest files or URLs. Kamelets are validated before they are installed, existing Kamelets with the same name are updated.",
+		Example: kameletInstallExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(filenames) == 0 {
+				return errors.New("'kn-source-kamelet kamelet install' requires the Kamelet manifests given with --filename")
+			}
+			if err := verifyDryRun(dryRun); err != nil {
+				return err
+			}
+
+			verifier, err := signature.verifier()
+			if err != nil {
+				return err
+			}
+
+			kamelets, err := readKameletManifests(p.Context, &http.Client{Timeout: p.RequestTimeout}, filenames, cmd.InOrStdin(), verifier)
+			if err != nil {
+				return err
+			}
+			if len(kamelets) == 0 {
+				return errors.New("no Kamelet found in given manifests")
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+			for _, kamelet := range kamelets {
+				if kamelet.Namespace == "" || cmd.Flags().Changed("namespace") {
+					kamelet.Namespace = namespace
+				}
+				// manifests exported from a cluster must not carry over the identity of the original resource
+				kamelet.UID = ""
+				kamelet.ResourceVersion = ""
+				kamelet.Status = v1alpha1.KameletStatus{}
+				if err := validateKamelet(kamelet); err != nil {
+					return err
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if dryRun == dryRunClient {
+				manifests := make([]runtime.Object, 0, len(kamelets))
+				for _, kamelet := range kamelets {
+					manifest, err := sanitize(kamelet)
+					if err != nil {
+						return err
+					}
+					manifests = append(manifests, manifest)
+				}
+				return printBindingManifests(printFlags, out, manifests...)
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+			for _, kamelet := range kamelets {
+				if err := applyKamelet(p.Context, client, kamelet, dryRun == dryRunServer, p.auditLog(), p.messageOutput(out)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), false)
+	cmd.Flags().StringArrayVarP(&filenames, "filename", "f", nil, "Manifest file, directory or http(s) URL with the Kamelets to install, use - to read from stdin.")
+	cmd.Flags().StringVar(&dryRun, "dry-run", "", "Only render (client) or validate on the API server (server) the Kamelets without installing them. One of: client|server.")
+	signature.addFlags(cmd.Flags(), "Verify the signatures of the manifests before installing them, the signature of a manifest is read from its file or URL with the .sig suffix.")
+	printFlags.AddFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const installKamelet = `apiVersion: camel.apache.org/v1alpha1
+kind: Kamelet
+metadata:
+  name: my-source
+  labels:
+    camel.apache.org/kamelet.type: source
+spec:
+  definition:
+    title: My Source
+    required:
+      - message
+    properties:
+      message:
+        title: Message
+        type: string
+      period:
+        title: Period
+        type: integer
+        default: 1000
+  flow:
+    from:
+      uri: timer:tick
+      steps:
+        - to: kamelet:sink
+`
+
+func TestKameletInstall(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+	file := filepath.Join(t.TempDir(), "my-source.kamelet.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(installKamelet), 0600))
+
+	recorder.Get(nil, notFound("my-source"))
+	recorder.Create(func(t *testing.T, kamelet *camelkapis.Kamelet) {
+		assert.Equal(t, kamelet.Namespace, "current")
+		assert.Equal(t, kamelet.Spec.Definition.Title, "My Source")
+		assert.Assert(t, kamelet.Spec.Flow != nil)
+	}, nil)
+	output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "install", "-f", file)
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'my-source' created in namespace 'current'.\n")
+
+	existing := createKameletInNamespace("my-source", "test")
+	existing.Labels["team"] = "integration"
+	existing.ResourceVersion = "42"
+	recorder.Get(existing, nil)
+	recorder.Update(func(t *testing.T, kamelet *camelkapis.Kamelet) {
+		assert.Equal(t, kamelet.ResourceVersion, "42")
+		assert.Equal(t, kamelet.Labels["team"], "integration")
+		assert.DeepEqual(t, kamelet.Spec.Definition.Required, []string{"message"})
+	}, nil)
+	output, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "install", "-f", file, "-n", "test")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Kamelet 'my-source' updated in namespace 'test'.\n")
+
+	recorder.Validate()
+}
+
+func TestKameletInstallURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/my-source.kamelet.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, installKamelet)
+	}))
+	t.Cleanup(server.Close)
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	p := cacheParams(t, mockClient)
+
+	// client dry run renders the Kamelets without accessing the cluster
+	output, err := runPipeCmd(p, NewKameletCommand(p), "kamelet", "install", "-f", server.URL+"/my-source.kamelet.yaml", "--dry-run", "client")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: Kamelet", "name: my-source", "namespace: current", "uri: timer:tick"))
+
+	_, err = runPipeCmd(p, NewKameletCommand(p), "kamelet", "install", "-f", server.URL+"/missing.kamelet.yaml")
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	mockClient.Recorder().Validate()
+}
+
+func TestKameletInstallValidation(t *testing.T) {
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		expected []string
+	}{
+		{"missing type", strings.Replace(installKamelet, "camel.apache.org/kamelet.type: source", "team: integration", 1),
+			[]string{"metadata.labels[camel.apache.org/kamelet.type]: Required value"}},
+		{"unsupported type", strings.Replace(installKamelet, "kamelet.type: source", "kamelet.type: step", 1),
+			[]string{`Unsupported value: "step"`}},
+		{"missing flow", installKamelet[:strings.Index(installKamelet, "  flow:")],
+			[]string{"spec.flow: Required value"}},
+		{"unknown required property", strings.Replace(installKamelet, "- message", "- msg", 1),
+			[]string{`spec.definition.required[0]: Not found: "msg"`}},
+		{"invalid property type", strings.Replace(installKamelet, "type: integer", "type: int", 1),
+			[]string{`spec.definition.properties[period].type: Unsupported value: "int"`}},
+		{"invalid default", strings.Replace(installKamelet, "default: 1000", "default: soon", 1),
+			[]string{`spec.definition.properties[period].default: Invalid value: "soon": expected type integer`}},
+		{"invalid name", strings.Replace(installKamelet, "name: my-source", "name: My_Source", 1),
+			[]string{"metadata.name: Invalid value: \"My_Source\""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := runKameletInstallCmd(p, tc.manifest, "-f", "-", "--dry-run", "client")
+			assert.Assert(t, err != nil)
+			assert.Check(t, util.ContainsAll(err.Error(), append(tc.expected, "invalid Kamelet")...))
+		})
+	}
+
+	_, err := runKameletInstallCmd(p, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c1\n", "-f", "-")
+	assert.Error(t, err, "no Kamelet found in given manifests")
+}
+
+func runKameletInstallCmd(p *KameletPluginParams, input string, options ...string) (string, error) {
+	kameletCmd, _, output := commands.CreateSourcesTestKnCommand(NewKameletCommand(p), p.KnParams)
+	kameletCmd.SetArgs(append([]string{"kamelet", "install"}, options...))
+	kameletCmd.SetIn(strings.NewReader(input))
+	err := kameletCmd.Execute()
+	return output.String(), err
+}
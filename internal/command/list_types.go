@@ -17,15 +17,23 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"knative.dev/client/pkg/kn/commands"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
 
 	camelkv1alpha1 "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1client "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"knative.dev/client/pkg/kn/commands/flags"
 	hprinters "knative.dev/client/pkg/printers"
@@ -35,12 +43,46 @@ var listExample = `
   # List available Kamelets
   kn-source-kamelet list-types
 
+  # List available Kamelets in all namespaces
+  kn-source-kamelet list-types --all-namespaces
+
+  # List available Kamelets with given label
+  kn-source-kamelet list-types -l team=integration
+
+  # List available Kamelets of all types, i.e. sources, sinks and actions
+  kn-source-kamelet list-types --type all
+
+  # List stable Kamelets of given provider
+  kn-source-kamelet list-types --provider "Apache Software Foundation" --support-level stable
+
+  # List available Kamelets sorted by age, oldest first
+  kn-source-kamelet list-types --sort-by age
+
+  # List available Kamelets including their provider and description
+  kn-source-kamelet list-types -o wide
+
+  # List available Kamelets with their creation timestamp instead of their age
+  kn-source-kamelet list-types --show-timestamps
+
+  # List available Kamelets and watch for Kamelets being added, updated or deleted
+  kn-source-kamelet list-types --watch
+
   # List available Kamelets in YAML output format
-  kn-source-kamelet list-types -o yaml`
+  kn-source-kamelet list-types -o yaml
+
+  # List the names of available Kamelets, e.g. for scripting
+  kn-source-kamelet list-types -o name
+
+  # List available Kamelets with their provider in custom columns
+  kn-source-kamelet list-types -o custom-columns='NAME:.metadata.name,PROVIDER:.metadata.annotations.camel\.apache\.org/provider'`
 
 // NewListTypesCommand implements 'kn-source-kamelet list-types' command
 func NewListTypesCommand(p *KameletPluginParams) *cobra.Command {
 	kameletListFlags := flags.NewListPrintFlags(ListHandlers)
+	var selector, sortBy string
+	var watchChanges, showTimestamps bool
+	var chunkSize int64
+	var filters kameletFilters
 
 	cmd := &cobra.Command{
 		Use:     "list-types",
@@ -53,50 +95,298 @@ func NewListTypesCommand(p *KameletPluginParams) *cobra.Command {
 				return err
 			}
 
+			if err := filters.verify(); err != nil {
+				return err
+			}
+			wideHandlers := ListWideHandlers
+			if showTimestamps {
+				kameletListFlags.PrinterHandler = listTimestampHandlers
+				wideHandlers = listWideTimestampHandlers
+			}
+
+			listOptions, err := selectorListOptions(selector)
+			if err != nil {
+				return err
+			}
+			sortPath, err := parseSortBy(sortBy)
+			if err != nil {
+				return err
+			}
+
 			kameletClient, err := p.NewKameletClient()
 			if err != nil {
 				return err
 			}
 
-			kameletList, err := kameletClient.Kamelets(namespace).List(p.Context, v1.ListOptions{})
+			result, err := listInChunks(listOptions, chunkSize, func(opts v1.ListOptions) (runtime.Object, error) {
+				return kameletClient.Kamelets(namespace).List(p.Context, opts)
+			})
 			if err != nil {
 				return err
 			}
-			if len(kameletList.Items) == 0 {
-				fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
-				return nil
+			kameletList := result.(*camelkv1alpha1.KameletList)
+			// only complete lists refresh the Kamelet cache
+			if selector == "" {
+				p.storeKamelets(namespace, kameletList.Items)
+			}
+			kameletList.Items = filters.filter(kameletList.Items)
+			setKameletListKind(kameletList)
+			if err := sortList(kameletList, sortPath); err != nil {
+				return err
 			}
-
 			// empty namespace indicates all-namespaces flag is specified
 			if namespace == "" {
 				kameletListFlags.EnsureWithNamespace()
 			}
 
-			err = kameletListFlags.Print(kameletList, cmd.OutOrStdout())
-			if err != nil {
-				return err
+			if watchChanges {
+				listOptions.ResourceVersion = kameletList.ResourceVersion
+				return watchKamelets(p.Context, kameletClient.Kamelets(namespace), listOptions, kameletList, &filters, showTimestamps, kameletListFlags, cmd.OutOrStdout())
 			}
-			return nil
+
+			if len(kameletList.Items) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No resources found.\n")
+				return nil
+			}
+
+			// wide output is the table with additional columns
+			if *kameletListFlags.GenericPrintFlags.OutputFormat == "wide" {
+				printer, err := kameletListFlags.HumanReadableFlags.ToPrinter(wideHandlers)
+				if err != nil {
+					return err
+				}
+				return p.printColoredTable(cmd.OutOrStdout(), func(out io.Writer) error {
+					return printer.PrintObj(kameletList, out)
+				})
+			}
+
+			return p.printColoredTable(cmd.OutOrStdout(), func(out io.Writer) error {
+				return printList(kameletListFlags, kameletList, out)
+			})
 		},
 	}
 	commands.AddNamespaceFlags(cmd.Flags(), true)
-	kameletListFlags.AddFlags(cmd)
+	addSelectorFlag(cmd.Flags(), &selector)
+	addSortByFlag(cmd.Flags(), &sortBy)
+	addWatchFlag(cmd.Flags(), &watchChanges)
+	addShowTimestampsFlag(cmd.Flags(), &showTimestamps)
+	addChunkSizeFlag(cmd.Flags(), &chunkSize)
+	filters.addFlags(cmd.Flags())
+	addListPrintFlags(cmd, kameletListFlags, "wide")
 	return cmd
 }
 
+// watchKamelets prints the listed Kamelets as added and then streams the changes of Kamelets matching the filters
+func watchKamelets(ctx context.Context, client camelkv1alpha1client.KameletInterface, listOptions v1.ListOptions, kameletList *camelkv1alpha1.KameletList,
+	filters *kameletFilters, showTimestamps bool, listFlags *flags.ListPrintFlags, out io.Writer) error {
+	columns := kameletColumnDefinitions()
+	printRows := func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return printKamelet(obj.(*camelkv1alpha1.Kamelet), options)
+	}
+	if showTimestamps {
+		columns, printRows = timestampColumns(columns), timestampRowPrinter(columns, printRows)
+	}
+	printer, err := newEventPrinter(listFlags, columns, printRows, out)
+	if err != nil {
+		return err
+	}
+
+	if err := printer.printHeader(); err != nil {
+		return err
+	}
+	for i := range kameletList.Items {
+		if err := printer.printEvent(watch.Added, &kameletList.Items[i]); err != nil {
+			return err
+		}
+	}
+
+	lw := selectorListWatch(listOptions, func(opts v1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, opts)
+	}, func(opts v1.ListOptions) (watch.Interface, error) {
+		return client.Watch(ctx, opts)
+	})
+	return watchEvents(ctx, lw, kameletList, &camelkv1alpha1.Kamelet{}, func(eventType watch.EventType, obj runtime.Object) error {
+		kamelet, ok := obj.(*camelkv1alpha1.Kamelet)
+		if !ok || len(filters.filter([]camelkv1alpha1.Kamelet{*kamelet})) == 0 {
+			return nil
+		}
+		kamelet.SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletKind))
+		return printer.printEvent(eventType, kamelet)
+	})
+}
+
+// setKameletListKind sets the type meta of the list and its items, which the client drops when decoding lists
+// but machine-readable output formats require
+func setKameletListKind(kameletList *camelkv1alpha1.KameletList) {
+	kameletList.SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletKind + "List"))
+	for i := range kameletList.Items {
+		kameletList.Items[i].SetGroupVersionKind(camelkv1alpha1.SchemeGroupVersion.WithKind(camelkv1alpha1.KameletKind))
+	}
+}
+
+// kameletFilters filter the listed Kamelets by their metadata, the values of each filter are alternatives
+type kameletFilters struct {
+	Type          string
+	Providers     []string
+	SupportLevels []string
+}
+
+// kameletTypes lists the supported values of the --type flag
+var kameletTypes = []string{"source", "sink", "action", "all"}
+
+func (f *kameletFilters) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Type, "type", "source", "Only list Kamelets of given type, one of: "+strings.Join(kameletTypes, "|")+".")
+	flags.StringSliceVar(&f.Providers, "provider", nil, "Only list Kamelets of given provider, "+
+		"the value is matched case-insensitive against the '"+providerAnnotation+"' annotation. "+
+		"This flag can be given multiple times.")
+	flags.StringSliceVar(&f.SupportLevels, "support-level", nil, "Only list Kamelets of given support level, e.g. stable, preview or experimental, "+
+		"the value is matched case-insensitive against the '"+supportLevelAnnotation+"' annotation. "+
+		"This flag can be given multiple times.")
+}
+
+func (f *kameletFilters) verify() error {
+	for _, kameletType := range kameletTypes {
+		if f.Type == kameletType {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Kamelet type %q, expected one of: %s", f.Type, strings.Join(kameletTypes, "|"))
+}
+
+// filter returns the Kamelets matching all filters
+func (f *kameletFilters) filter(kamelets []camelkv1alpha1.Kamelet) []camelkv1alpha1.Kamelet {
+	filtered := kamelets[:0]
+	for i := range kamelets {
+		kamelet := kamelets[i]
+		if (f.Type == "all" || kameletapi.TypeOf(&kamelet) == f.Type) &&
+			matchesAny(kamelet.Annotations[providerAnnotation], f.Providers) &&
+			matchesAny(kamelet.Annotations[supportLevelAnnotation], f.SupportLevels) {
+			filtered = append(filtered, kamelet)
+		}
+	}
+	return filtered
+}
+
+// matchesAny checks whether the value equals any of the alternatives ignoring case, no alternatives match any value
+func matchesAny(value string, alternatives []string) bool {
+	if len(alternatives) == 0 {
+		return true
+	}
+	for _, alternative := range alternatives {
+		if strings.EqualFold(value, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+// addSelectorFlag adds the label selector flag of list commands
+func addSelectorFlag(flags *pflag.FlagSet, selector *string) {
+	flags.StringVarP(selector, "selector", "l", "", "Label selector to filter on, supports '=', '==', '!=', 'in', 'notin' and existence (e.g. -l key1=value1,key2!=value2).")
+}
+
+// selectorListOptions returns the list options for given label selector, the selector is parsed upfront
+// to fail with a meaningful message before any request is sent to the cluster
+func selectorListOptions(selector string) (v1.ListOptions, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return v1.ListOptions{}, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+	return v1.ListOptions{LabelSelector: selector}, nil
+}
+
 // ListHandlers handles printing human readable table for `kn-source-kamelet list-types` command's output
 func ListHandlers(h hprinters.PrintHandler) {
-	kameletColumnDefinitions := []metav1beta1.TableColumnDefinition{
+	columns := kameletColumnDefinitions()
+	h.TableHandler(columns, printKamelet)
+	h.TableHandler(columns, printKameletList)
+}
+
+// kameletColumnDefinitions returns the columns of the Kamelet table
+func kameletColumnDefinitions() []metav1beta1.TableColumnDefinition {
+	return []metav1beta1.TableColumnDefinition{
 		{Name: "Namespace", Type: "string", Description: "Namespace of the Kamelet instance", Priority: 0},
 		{Name: "Name", Type: "string", Description: "Name of the Kamelet instance", Priority: 1},
+		{Name: "Type", Type: "string", Description: "Type of the Kamelet instance, e.g. source, sink or action", Priority: 1},
 		{Name: "Phase", Type: "string", Description: "Phase of the Kamelet instance", Priority: 1},
 		{Name: "Age", Type: "string", Description: "Age of the Kamelet instance", Priority: 1},
 		{Name: "Conditions", Type: "string", Description: "Ready state conditions", Priority: 1},
 		{Name: "Ready", Type: "string", Description: "Ready state of the Kamelet instance", Priority: 1},
 		{Name: "Reason", Type: "string", Description: "Reason if state is not Ready", Priority: 1},
 	}
-	h.TableHandler(kameletColumnDefinitions, printKamelet)
-	h.TableHandler(kameletColumnDefinitions, printKameletList)
+}
+
+// listTimestampHandlers handles printing the table of `kn-source-kamelet list-types --show-timestamps`, which shows
+// the creation timestamp instead of the age
+func listTimestampHandlers(h hprinters.PrintHandler) {
+	columns := kameletColumnDefinitions()
+	h.TableHandler(timestampColumns(columns), func(kamelet *camelkv1alpha1.Kamelet, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printKamelet(kamelet, options))
+	})
+	h.TableHandler(timestampColumns(columns), func(kameletList *camelkv1alpha1.KameletList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printKameletList(kameletList, options))
+	})
+}
+
+// kameletWideColumnDefinitions returns the columns of the wide Kamelet table
+func kameletWideColumnDefinitions() []metav1beta1.TableColumnDefinition {
+	return append(kameletColumnDefinitions(),
+		metav1beta1.TableColumnDefinition{Name: "Provider", Type: "string", Description: "Provider of the Kamelet", Priority: 1},
+		metav1beta1.TableColumnDefinition{Name: "Description", Type: "string", Description: "Summary of the Kamelet definition", Priority: 1},
+	)
+}
+
+// ListWideHandlers handles printing the wide table for `kn-source-kamelet list-types -o wide` command's output
+func ListWideHandlers(h hprinters.PrintHandler) {
+	columns := kameletWideColumnDefinitions()
+	h.TableHandler(columns, printKameletWide)
+	h.TableHandler(columns, printKameletListWide)
+}
+
+// listWideTimestampHandlers handles printing the wide table with creation timestamps instead of the age
+func listWideTimestampHandlers(h hprinters.PrintHandler) {
+	columns := kameletWideColumnDefinitions()
+	h.TableHandler(timestampColumns(columns), func(kamelet *camelkv1alpha1.Kamelet, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printKameletWide(kamelet, options))
+	})
+	h.TableHandler(timestampColumns(columns), func(kameletList *camelkv1alpha1.KameletList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printKameletListWide(kameletList, options))
+	})
+}
+
+// printKameletListWide populates the wide Kamelet list table rows
+func printKameletListWide(kameletList *camelkv1alpha1.KameletList, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	rows := make([]metav1beta1.TableRow, 0, len(kameletList.Items))
+
+	for i := range kameletList.Items {
+		r, err := printKameletWide(&kameletList.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+// printKameletWide populates the Kamelet table rows including provider and description
+func printKameletWide(kamelet *camelkv1alpha1.Kamelet, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	rows, err := printKamelet(kamelet, options)
+	if err != nil {
+		return nil, err
+	}
+	rows[0].Cells = append(rows[0].Cells, kamelet.Annotations[providerAnnotation], kameletSummary(kamelet))
+	return rows, nil
+}
+
+// kameletSummary returns the first line of the Kamelet description, or its title when there is no description
+func kameletSummary(kamelet *camelkv1alpha1.Kamelet) string {
+	definition := kamelet.Spec.Definition
+	if definition == nil {
+		return ""
+	}
+	if description := strings.TrimSpace(definition.Description); description != "" {
+		return strings.TrimSpace(strings.SplitN(description, "\n", 2)[0])
+	}
+	return definition.Title
 }
 
 // printKameletList populates the Kamelet list table rows
@@ -133,6 +423,7 @@ func printKamelet(kamelet *camelkv1alpha1.Kamelet, options hprinters.PrintOption
 
 	row.Cells = append(row.Cells,
 		name,
+		kameletapi.TypeOf(kamelet),
 		phase,
 		age,
 		conditions,
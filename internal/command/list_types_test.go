@@ -18,14 +18,18 @@ package command
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
 	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"knative.dev/client/pkg/kn/commands"
 	"knative.dev/client/pkg/util"
-	"knative.dev/kn-plugin-source-kamelet/internal/client"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
 
 	"gotest.tools/v3/assert"
 )
@@ -42,7 +46,7 @@ func TestListTypesSetup(t *testing.T) {
 }
 
 func TestListTypesOutput(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet1 := createKamelet("k1")
@@ -65,7 +69,7 @@ func TestListTypesOutput(t *testing.T) {
 }
 
 func TestListTypesEmpty(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	recorder.List(&camelkapis.KameletList{}, nil)
@@ -78,7 +82,7 @@ func TestListTypesEmpty(t *testing.T) {
 }
 
 func TestListTypesNoReadyReasonOutput(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet1 := createKamelet("k1")
@@ -107,7 +111,7 @@ func TestListTypesNoReadyReasonOutput(t *testing.T) {
 }
 
 func TestListTypesAllNamespace(t *testing.T) {
-	mockClient := client.NewMockKameletClient(t)
+	mockClient := kamelettesting.NewMockKameletClient(t)
 	recorder := mockClient.Recorder()
 
 	kamelet1 := createKameletInNamespace("k1", "default1")
@@ -128,16 +132,266 @@ func TestListTypesAllNamespace(t *testing.T) {
 	recorder.Validate()
 }
 
-func runListTypesCmd(c *client.MockKameletClient, options ...string) (string, error) {
-	p := KameletPluginParams{
-		KnParams: &commands.KnParams{},
-		Context:  context.TODO(),
-		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
-			return c, nil
-		},
+func TestListTypesSelector(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1")}}, nil)
+	output, err := runListTypesCmd(mockClient, "-l", "camel.apache.org/kamelet.type=source")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "k1"))
+
+	_, err = runListTypesCmd(mockClient, "--selector", "team in (a")
+	assert.ErrorContains(t, err, "invalid label selector \"team in (a\"")
+
+	recorder.Validate()
+}
+
+func TestListTypesProviderAndSupportLevel(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet1 := createKamelet("k1")
+	kamelet1.Annotations = map[string]string{providerAnnotation: "Apache Software Foundation", supportLevelAnnotation: "Stable"}
+	kamelet2 := createKamelet("k2")
+	kamelet2.Annotations = map[string]string{providerAnnotation: "Apache Software Foundation", supportLevelAnnotation: "Preview"}
+	kamelet3 := createKamelet("k3")
+	kamelet3.Annotations = map[string]string{providerAnnotation: "Acme", supportLevelAnnotation: "Stable"}
+	kameletList := func() *camelkapis.KameletList {
+		return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet1, *kamelet2, *kamelet3}}
+	}
+
+	recorder.List(kameletList(), nil)
+	output, err := runListTypesCmd(mockClient, "--provider", "apache software foundation", "--support-level", "stable")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "k1"))
+	assert.Check(t, util.ContainsNone(output, "k2", "k3"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "--support-level", "stable,preview", "--provider", "Acme")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "k3"))
+	assert.Check(t, util.ContainsNone(output, "k1", "k2"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "--support-level", "experimental")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "No resources found"))
+
+	recorder.Validate()
+}
+
+func TestListTypesType(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	source := createKamelet("k1")
+	sink := createKamelet("k2")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	action := createKamelet("k3")
+	action.Labels["camel.apache.org/kamelet.type"] = "action"
+	kameletList := func() *camelkapis.KameletList {
+		return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*source, *sink, *action}}
+	}
+
+	recorder.List(kameletList(), nil)
+	output, err := runListTypesCmd(mockClient)
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "TYPE", "PHASE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "source"))
+	assert.Check(t, util.ContainsNone(output, "k2", "k3"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "--type", "sink")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "k2", "sink"))
+	assert.Check(t, util.ContainsNone(output, "k1", "k3"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "--type", "all")
+	assert.NilError(t, err)
+	outputLines = strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "source"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "k2", "sink"))
+	assert.Check(t, util.ContainsAll(outputLines[3], "k3", "action"))
+
+	_, err = runListTypesCmd(mockClient, "--type", "step")
+	assert.Error(t, err, "invalid Kamelet type \"step\", expected one of: source|sink|action|all")
+
+	recorder.Validate()
+}
+
+func TestListTypesMachineReadableOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kameletList := func() *camelkapis.KameletList {
+		kamelet := createKamelet("k1")
+		// the client drops the type meta when decoding lists
+		kamelet.TypeMeta = v1.TypeMeta{}
+		return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet}}
+	}
+
+	recorder.List(kameletList(), nil)
+	output, err := runListTypesCmd(mockClient, "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: KameletList", "kind: Kamelet\n", "name: k1"))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "-o", "json")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "\"kind\": \"KameletList\"", "\"name\": \"k1\""))
+
+	recorder.List(kameletList(), nil)
+	output, err = runListTypesCmd(mockClient, "-o", "name")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kamelet.camel.apache.org/k1\n")
+
+	recorder.Validate()
+}
+
+func TestListTypesJSONPath(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1"), *createKamelet("k2")}}, nil)
+	output, err := runListTypesCmd(mockClient, "-o", "jsonpath={.items[*].metadata.name}")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "k1 k2")
+
+	recorder.Validate()
+}
+
+func TestListTypesNoHeaders(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1"), *createKamelet("k2")}}, nil)
+	output, err := runListTypesCmd(mockClient, "--no-headers")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsNone(output, "NAME", "PHASE"))
+	assert.Check(t, strings.HasPrefix(outputLines[0], "k1 "))
+	assert.Check(t, strings.HasPrefix(outputLines[1], "k2 "))
+
+	recorder.Validate()
+}
+
+func TestListTypesWideOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet1 := createKamelet("k1")
+	kamelet1.Annotations = map[string]string{providerAnnotation: "Acme"}
+	kamelet1.Spec.Definition.Description = "Produces periodic events.\n\nThe message is configurable."
+	kamelet2 := createKamelet("k2")
+	kamelet2.Spec.Definition.Description = ""
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet1, *kamelet2}}, nil)
+
+	output, err := runListTypesCmd(mockClient, "-o", "wide")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "TYPE", "PHASE", "PROVIDER", "DESCRIPTION"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "source", "Acme", "Produces periodic events."))
+	assert.Check(t, util.ContainsNone(outputLines[1], "configurable"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "k2", "Kamelet k2"))
+
+	recorder.Validate()
+}
+
+func TestListTypesTimestamps(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := createKamelet("k1")
+	kamelet.CreationTimestamp = v1.NewTime(time.Date(2021, 5, 4, 10, 0, 0, 0, time.UTC))
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet}}, nil)
+	output, err := runListTypesCmd(mockClient, "--show-timestamps")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "PHASE", "CREATED", "CONDITIONS"))
+	assert.Check(t, util.ContainsNone(outputLines[0], "AGE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "source", "2021-05-04T10:00:00Z"))
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet}}, nil)
+	output, err = runListTypesCmd(mockClient, "--show-timestamps", "-o", "wide")
+	assert.NilError(t, err)
+
+	outputLines = strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "CREATED", "PROVIDER"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "k1", "2021-05-04T10:00:00Z"))
+
+	recorder.Validate()
+}
+
+func TestListTypesWatch(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(&camelkapis.KameletList{Items: []camelkapis.Kamelet{*createKamelet("k1")}}, nil)
+
+	watcher := watch.NewFakeWithChanSize(4, false)
+	updated := createKamelet("k1")
+	updated.Status.Phase = camelkapis.KameletPhaseError
+	sink := createKamelet("k3")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+	watcher.Add(createKamelet("k2"))
+	watcher.Add(sink)
+	watcher.Modify(updated)
+	watcher.Delete(createKamelet("k2"))
+	recorder.Watch(watcher, nil)
+
+	p := listTypesParams(mockClient)
+	output, err := runWatchCmd(p, NewListTypesCommand(p), "DELETED", "list-types", "--watch")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "EVENT", "NAME", "TYPE", "PHASE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "ADDED", "k1", "Ready"))
+	// changes are only ordered per Kamelet
+	var events []string
+	for _, line := range outputLines[2:5] {
+		fields := strings.Fields(line)
+		events = append(events, strings.Join(fields[:2], " "))
+		if fields[0] == "MODIFIED" {
+			assert.Check(t, util.ContainsAll(line, "Error"))
+		}
 	}
+	sort.Strings(events)
+	assert.DeepEqual(t, events, []string{"ADDED k2", "DELETED k2", "MODIFIED k1"})
+	assert.Check(t, util.ContainsNone(output, "k3"))
+
+	recorder.Validate()
+}
 
-	listCmd, _, output := commands.CreateSourcesTestKnCommand(NewListTypesCommand(&p), p.KnParams)
+func TestListTypesWatchOutput(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(&camelkapis.KameletList{}, nil)
+	watcher := watch.NewFakeWithChanSize(1, false)
+	watcher.Add(createKamelet("k1"))
+	recorder.Watch(watcher, nil)
+
+	p := listTypesParams(mockClient)
+	output, err := runWatchCmd(p, NewListTypesCommand(p), "kamelet.camel.apache.org/k1", "list-types", "--watch", "-o", "name")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "kamelet.camel.apache.org/k1\n")
+
+	recorder.List(&camelkapis.KameletList{}, nil)
+	_, err = runListTypesCmd(mockClient, "--watch", "-o", "wide")
+	assert.Error(t, err, "--watch does not support output format \"wide\"")
+
+	recorder.Validate()
+}
+
+func runListTypesCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := listTypesParams(c)
+	listCmd, _, output := commands.CreateSourcesTestKnCommand(NewListTypesCommand(p), p.KnParams)
 
 	args := []string{"list-types"}
 	args = append(args, options...)
@@ -146,3 +400,13 @@ func runListTypesCmd(c *client.MockKameletClient, options ...string) (string, er
 
 	return output.String(), err
 }
+
+func listTypesParams(c *kamelettesting.MockKameletClient) *KameletPluginParams {
+	return &KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+}
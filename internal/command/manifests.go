@@ -0,0 +1,268 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// manifestExtensions lists the file extensions read when a directory is given
+var manifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// readBindingManifests reads all KameletBindings and Pipes from given files or directories, "-" reads from stdin.
+// Documents of other kinds are rejected.
+func readBindingManifests(paths []string, stdin io.Reader) ([]*v1alpha1.KameletBinding, error) {
+	var bindings []*v1alpha1.KameletBinding
+	for _, path := range paths {
+		if path == "-" {
+			read, err := decodeBindings(stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			bindings = append(bindings, read...)
+			continue
+		}
+
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			read, err := readBindingFile(file)
+			if err != nil {
+				return nil, err
+			}
+			bindings = append(bindings, read...)
+		}
+	}
+	return bindings, nil
+}
+
+// manifestFiles resolves the given path to the list of manifest files, directories are not traversed recursively
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && manifestExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func readBindingFile(file string) ([]*v1alpha1.KameletBinding, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeBindings(f, file)
+}
+
+// decodeBindings decodes all KameletBinding and Pipe documents of a YAML or JSON stream, Pipes are converted to
+// bindings. Documents of other kinds are rejected, so that no resource given to apply or delete is silently ignored.
+func decodeBindings(reader io.Reader, source string) ([]*v1alpha1.KameletBinding, error) {
+	var bindings []*v1alpha1.KameletBinding
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return bindings, nil
+			}
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		if trimmed := bytes.TrimSpace(document); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			// empty documents, e.g. after a trailing document separator
+			continue
+		}
+		binding, err := decodeBinding(document, source)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, binding)
+	}
+}
+
+// decodeBinding decodes the KameletBinding or Pipe document, Pipes are converted to bindings that are rendered as
+// Pipes again on clusters serving the Pipe API
+func decodeBinding(document []byte, source string) (*v1alpha1.KameletBinding, error) {
+	var typeMeta v1.TypeMeta
+	if err := json.Unmarshal(document, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+	}
+
+	binding := &v1alpha1.KameletBinding{}
+	switch typeMeta.Kind {
+	case v1alpha1.KameletBindingKind:
+		if err := json.Unmarshal(document, binding); err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+	case pipeKind:
+		pipe := &unstructured.Unstructured{}
+		if err := pipe.UnmarshalJSON(document); err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		converted, err := fromPipe(pipe)
+		if err != nil {
+			return nil, err
+		}
+		binding = converted
+		binding.APIVersion = v1alpha1.SchemeGroupVersion.String()
+		binding.Kind = v1alpha1.KameletBindingKind
+	default:
+		return nil, fmt.Errorf("unsupported kind %q in manifest %s, only KameletBindings and Pipes are supported", typeMeta.Kind, source)
+	}
+	if binding.Name == "" {
+		return nil, fmt.Errorf("%s in manifest %s is missing a name", typeMeta.Kind, source)
+	}
+	return binding, nil
+}
+
+// readKameletManifests reads all Kamelets from given files, directories or http(s) URLs, "-" reads from stdin.
+// Documents of other kinds are skipped. When a verifier is given, the manifests must be signed and their signatures
+// are read from the file or URL of the manifest with the .sig suffix.
+func readKameletManifests(ctx context.Context, client *http.Client, paths []string, stdin io.Reader, verifier *signatureVerifier) ([]*v1alpha1.Kamelet, error) {
+	var kamelets []*v1alpha1.Kamelet
+	for _, path := range paths {
+		switch {
+		case path == "-":
+			if verifier != nil {
+				return nil, errors.New("signatures of manifests read from stdin can not be verified")
+			}
+			read, err := decodeKamelets(stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			kamelets = append(kamelets, read...)
+		case strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://"):
+			manifest, err := downloadManifest(ctx, client, path)
+			if err != nil {
+				return nil, err
+			}
+			if verifier != nil {
+				signature, err := downloadManifest(ctx, client, path+signatureSuffix)
+				if err != nil {
+					return nil, err
+				}
+				if err := verifier.verify(manifest, signature, path); err != nil {
+					return nil, err
+				}
+			}
+			read, err := decodeKamelets(bytes.NewReader(manifest), path)
+			if err != nil {
+				return nil, err
+			}
+			kamelets = append(kamelets, read...)
+		default:
+			files, err := manifestFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				manifest, err := ioutil.ReadFile(file)
+				if err != nil {
+					return nil, err
+				}
+				if verifier != nil {
+					signature, err := ioutil.ReadFile(file + signatureSuffix)
+					if err != nil {
+						return nil, fmt.Errorf("failed to read signature of manifest %s: %w", file, err)
+					}
+					if err := verifier.verify(manifest, signature, file); err != nil {
+						return nil, err
+					}
+				}
+				read, err := decodeKamelets(bytes.NewReader(manifest), file)
+				if err != nil {
+					return nil, err
+				}
+				kamelets = append(kamelets, read...)
+			}
+		}
+	}
+	return kamelets, nil
+}
+
+// downloadManifest downloads the manifest or signature from given URL
+func downloadManifest(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download manifest %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download manifest %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// decodeKamelets decodes all Kamelet documents of a YAML or JSON stream
+func decodeKamelets(reader io.Reader, source string) ([]*v1alpha1.Kamelet, error) {
+	var kamelets []*v1alpha1.Kamelet
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		kamelet := &v1alpha1.Kamelet{}
+		if err := decoder.Decode(kamelet); err != nil {
+			if errors.Is(err, io.EOF) {
+				return kamelets, nil
+			}
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		if kamelet.Kind != v1alpha1.KameletKind {
+			continue
+		}
+		if kamelet.Name == "" {
+			return nil, fmt.Errorf("Kamelet in manifest %s is missing a name", source)
+		}
+		kamelets = append(kamelets, kamelet)
+	}
+}
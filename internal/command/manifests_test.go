@@ -0,0 +1,81 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReadBindingManifestsFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "a.yaml"), []byte(bindingManifests), 0600))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"apiVersion": "camel.apache.org/v1alpha1", "kind": "KameletBinding", "metadata": {"name": "b3"}}`), 0600))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0600))
+
+	bindings, err := readBindingManifests([]string{dir}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(bindings), 3)
+	assert.Equal(t, bindings[0].Name, "b1")
+	assert.Equal(t, bindings[0].Spec.Source.Ref.Name, "k1")
+	assert.Equal(t, bindings[1].Name, "b2")
+	assert.Equal(t, bindings[1].Namespace, "other")
+	assert.Equal(t, bindings[2].Name, "b3")
+}
+
+func TestReadBindingManifestsFromStdin(t *testing.T) {
+	bindings, err := readBindingManifests([]string{"-"}, strings.NewReader(bindingManifests))
+	assert.NilError(t, err)
+	assert.Equal(t, len(bindings), 2)
+}
+
+func TestReadBindingManifestsPipes(t *testing.T) {
+	pipes := `apiVersion: camel.apache.org/v1
+kind: Pipe
+metadata:
+  name: p1
+spec:
+  replicas: 2
+  source:
+    ref:
+      kind: Kamelet
+      name: k1
+---
+`
+	bindings, err := readBindingManifests([]string{"-"}, strings.NewReader(pipes))
+	assert.NilError(t, err)
+	assert.Equal(t, len(bindings), 1)
+	assert.Equal(t, bindings[0].Kind, "KameletBinding")
+	assert.Equal(t, bindings[0].Name, "p1")
+	assert.Equal(t, bindings[0].Spec.Source.Ref.Name, "k1")
+	assert.Equal(t, *bindings[0].Spec.Integration.Replicas, int32(2))
+}
+
+func TestReadBindingManifestsErrors(t *testing.T) {
+	_, err := readBindingManifests([]string{"-"}, strings.NewReader("kind: KameletBinding\nmetadata: {}\n"))
+	assert.Error(t, err, "KameletBinding in manifest stdin is missing a name")
+
+	_, err = readBindingManifests([]string{"-"}, strings.NewReader(bindingManifests+"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c1\n"))
+	assert.Error(t, err, "unsupported kind \"ConfigMap\" in manifest stdin, only KameletBindings and Pipes are supported")
+
+	_, err = readBindingManifests([]string{filepath.Join(t.TempDir(), "missing.yaml")}, nil)
+	assert.ErrorContains(t, err, "no such file or directory")
+}
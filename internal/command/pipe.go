@@ -0,0 +1,281 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// Pipe is the successor of the v1alpha1 KameletBinding in the Camel K v1 API
+const (
+	pipeAPIVersion = "camel.apache.org/v1"
+	pipeKind       = "Pipe"
+)
+
+var pipeResource = schema.GroupVersionResource{Group: "camel.apache.org", Version: "v1", Resource: "pipes"}
+
+// AddBindingAPIFlags adds the flags overriding the discovery of the binding API to given flag set
+func (params *KameletPluginParams) AddBindingAPIFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&params.UsePipe, "use-pipe", false, "Manage bindings as Camel K v1 Pipes, by default Pipes are used when served by the cluster.")
+	flags.BoolVar(&params.UseKameletBinding, "use-kameletbinding", false, "Manage bindings as v1alpha1 KameletBindings even if the cluster serves Pipes.")
+}
+
+// usePipes decides whether bindings are managed as Pipes, either forced by flag or discovered from the cluster
+func (params *KameletPluginParams) usePipes() (bool, error) {
+	if params.UsePipe && params.UseKameletBinding {
+		return false, errors.New("--use-pipe can not be combined with --use-kameletbinding")
+	}
+	if params.UsePipe || params.UseKameletBinding {
+		return params.UsePipe, nil
+	}
+
+	if params.pipesServed == nil {
+		served := params.discoverPipes()
+		params.pipesServed = &served
+	}
+	return *params.pipesServed, nil
+}
+
+// discoverPipes checks whether the cluster serves the Pipe API, any discovery failure falls back to KameletBindings
+func (params *KameletPluginParams) discoverPipes() bool {
+	if params.NewKubeClient == nil {
+		return false
+	}
+	client, err := params.NewKubeClient()
+	if err != nil {
+		return false
+	}
+
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(pipeAPIVersion)
+	if err != nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == pipeKind {
+			return true
+		}
+	}
+	return false
+}
+
+// toPipe converts the binding to a Pipe, the replicas of the binding integration are moved to the Pipe spec
+func toPipe(binding *v1alpha1.KameletBinding) (*unstructured.Unstructured, error) {
+	pipe, err := sanitize(binding)
+	if err != nil {
+		return nil, err
+	}
+	pipe.SetAPIVersion(pipeAPIVersion)
+	pipe.SetKind(pipeKind)
+
+	if replicas, found, _ := unstructured.NestedFieldNoCopy(pipe.Object, "spec", "integration", "replicas"); found {
+		if err := unstructured.SetNestedField(pipe.Object, replicas, "spec", "replicas"); err != nil {
+			return nil, err
+		}
+		unstructured.RemoveNestedField(pipe.Object, "spec", "integration", "replicas")
+		if integration, _, _ := unstructured.NestedMap(pipe.Object, "spec", "integration"); len(integration) == 0 {
+			unstructured.RemoveNestedField(pipe.Object, "spec", "integration")
+		}
+	}
+	return pipe, nil
+}
+
+// fromPipe converts the Pipe to a binding so Pipes are verified and printed the same way as KameletBindings
+func fromPipe(pipe *unstructured.Unstructured) (*v1alpha1.KameletBinding, error) {
+	obj := pipe.DeepCopy()
+	if replicas, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); found {
+		if err := unstructured.SetNestedField(obj.Object, replicas, "spec", "integration", "replicas"); err != nil {
+			return nil, err
+		}
+		unstructured.RemoveNestedField(obj.Object, "spec", "replicas")
+	}
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	binding := &v1alpha1.KameletBinding{}
+	if err := json.Unmarshal(data, binding); err != nil {
+		return nil, fmt.Errorf("failed to read Pipe '%s': %w", pipe.GetName(), err)
+	}
+	return binding, nil
+}
+
+// fromPipeList converts the Pipes to a binding list
+func fromPipeList(pipes *unstructured.UnstructuredList) (*v1alpha1.KameletBindingList, error) {
+	bindingList := &v1alpha1.KameletBindingList{}
+	for i := range pipes.Items {
+		binding, err := fromPipe(&pipes.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		bindingList.Items = append(bindingList.Items, *binding)
+	}
+	return bindingList, nil
+}
+
+// applyPipe creates the binding as Pipe or updates the spec of the existing Pipe with the same name
+func applyPipe(ctx context.Context, client dynamic.Interface, binding *v1alpha1.KameletBinding, steps []v1alpha1.Endpoint, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*unstructured.Unstructured, error) {
+	pipe, err := toPipe(binding)
+	if err != nil {
+		return nil, err
+	}
+	if err := setSteps(pipe, steps); err != nil {
+		return nil, err
+	}
+	return applyManifest(ctx, client.Resource(pipeResource).Namespace(binding.Namespace), pipe, binding, update, serverDryRun, audit, out)
+}
+
+// applyManifest creates the unstructured binding or Pipe manifest or updates the spec of the existing resource with
+// the same name, the binding the manifest is rendered from names the resource
+func applyManifest(ctx context.Context, resources dynamic.ResourceInterface, manifest *unstructured.Unstructured, binding *v1alpha1.KameletBinding, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*unstructured.Unstructured, error) {
+	var dryRun []string
+	var dryRunSuffix string
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		// nothing is persisted so there is nothing to audit
+		audit = nil
+	}
+
+	kind := manifest.GetKind()
+
+	// without overwrite the resource is created right away so the API server rejects existing resources with
+	// AlreadyExists, resources with a generated name are always created
+	create := update.NoOverwrite || binding.Name == ""
+	if update.ServerSide && !create {
+		return serverSideApplyManifest(ctx, resources, manifest, binding, update, serverDryRun, audit, out)
+	}
+	var existing *unstructured.Unstructured
+	var err error
+	if !create {
+		existing, err = resources.Get(ctx, binding.Name, v1.GetOptions{})
+	}
+	if create || apierrors.IsNotFound(err) {
+		applied, err := withLastApplied(manifest)
+		if err != nil {
+			return nil, err
+		}
+		created, err := resources.Create(ctx, applied.(*unstructured.Unstructured), v1.CreateOptions{DryRun: dryRun, FieldManager: fieldManager})
+		name := createdName(binding, created, err)
+		if auditErr := audit.record("create", kind, binding.Namespace, name, nil, err); auditErr != nil {
+			return nil, auditErr
+		}
+		if err != nil {
+			return nil, knerrors.GetError(err)
+		}
+		fmt.Fprintf(out, "%s '%s' created in namespace '%s'%s.\n", kind, name, binding.Namespace, dryRunSuffix)
+		return created, nil
+	} else if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+
+	existingBinding, err := fromPipe(existing)
+	if err != nil {
+		return nil, err
+	}
+	if err := update.verifyUpdate(existingBinding, binding, kind, out); err != nil {
+		return nil, err
+	}
+
+	desired, err := update.applyManifest(existing, manifest)
+	if err != nil {
+		return nil, err
+	}
+	desiredBinding, err := fromPipe(desired)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeUpdateDiff(existingBinding, desiredBinding, kind == pipeKind, update.redactor, out); err != nil {
+		return nil, err
+	}
+	changes := bindingChanges(existingBinding, desiredBinding)
+	if unchanged(existingBinding, desiredBinding, changes) {
+		fmt.Fprintf(out, "%s '%s' unchanged in namespace '%s'%s.\n", kind, binding.Name, binding.Namespace, dryRunSuffix)
+		return existing, nil
+	}
+
+	updated, err := resources.Update(ctx, desired, v1.UpdateOptions{DryRun: dryRun, FieldManager: fieldManager})
+	if auditErr := audit.record(update.action(), kind, binding.Namespace, binding.Name, changes, err); auditErr != nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, knerrors.GetError(err)
+	}
+	fmt.Fprintf(out, "%s '%s' %sd in namespace '%s'%s.\n", kind, binding.Name, update.action(), binding.Namespace, dryRunSuffix)
+	return updated, nil
+}
+
+// applyManifest returns the existing resource updated with given manifest like apply does for bindings
+func (o updateOptions) applyManifest(existing *unstructured.Unstructured, manifest *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if !o.Force {
+		return threeWayMergeManifest(existing, manifest)
+	}
+	applied, err := withLastApplied(manifest)
+	if err != nil {
+		return nil, err
+	}
+	desired := existing.DeepCopy()
+	desired.Object["spec"] = manifest.Object["spec"]
+	desired.SetLabels(manifest.GetLabels())
+	desired.SetAnnotations(applied.(*unstructured.Unstructured).GetAnnotations())
+	return desired, nil
+}
+
+// updatePipe returns a copy of the existing Pipe with the metadata and the spec fields known to bindings taken from
+// the updated binding, spec fields the binding can not represent such as steps are kept
+func updatePipe(existing *unstructured.Unstructured, binding *v1alpha1.KameletBinding) (*unstructured.Unstructured, error) {
+	pipe, err := toPipe(binding)
+	if err != nil {
+		return nil, err
+	}
+	updated := existing.DeepCopy()
+	for _, field := range []string{"source", "sink", "integration", "replicas"} {
+		if value, found, _ := unstructured.NestedFieldNoCopy(pipe.Object, "spec", field); found {
+			if err := unstructured.SetNestedField(updated.Object, value, "spec", field); err != nil {
+				return nil, err
+			}
+		} else {
+			unstructured.RemoveNestedField(updated.Object, "spec", field)
+		}
+	}
+	updated.SetLabels(binding.Labels)
+	updated.SetAnnotations(binding.Annotations)
+	return updated, nil
+}
+
+// getPipe returns the Pipe converted to a binding
+func getPipe(ctx context.Context, client dynamic.Interface, namespace string, name string) (*v1alpha1.KameletBinding, error) {
+	pipe, err := client.Resource(pipeResource).Namespace(namespace).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromPipe(pipe)
+}
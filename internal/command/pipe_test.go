@@ -0,0 +1,442 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+	"sigs.k8s.io/yaml"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestUsePipes(t *testing.T) {
+	p := &KameletPluginParams{UsePipe: true, UseKameletBinding: true}
+	_, err := p.usePipes()
+	assert.Error(t, err, "--use-pipe can not be combined with --use-kameletbinding")
+
+	p = pipeParams(nil, nil)
+	p.UseKameletBinding = true
+	pipes, err := p.usePipes()
+	assert.NilError(t, err)
+	assert.Assert(t, !pipes)
+
+	p = pipeParams(nil, nil)
+	pipes, err = p.usePipes()
+	assert.NilError(t, err)
+	assert.Assert(t, pipes)
+
+	p = pipeParams(nil, nil)
+	p.NewKubeClient = func() (kubernetes.Interface, error) {
+		return fake.NewSimpleClientset(), nil
+	}
+	pipes, err = p.usePipes()
+	assert.NilError(t, err)
+	assert.Assert(t, !pipes)
+}
+
+func TestToPipe(t *testing.T) {
+	replicas := int32(2)
+	binding, err := kameletapi.NewBinding(&kameletapi.BindingOptions{Namespace: "default", Kamelet: "k1", Sink: "broker:default", Replicas: &replicas})
+	assert.NilError(t, err)
+
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+	assert.Equal(t, pipe.GetAPIVersion(), "camel.apache.org/v1")
+	assert.Equal(t, pipe.GetKind(), "Pipe")
+	assert.Equal(t, pipe.GetName(), "k1-to-broker-default")
+	value, _, _ := unstructured.NestedFieldNoCopy(pipe.Object, "spec", "replicas")
+	assert.Equal(t, value, int64(2))
+	_, found, _ := unstructured.NestedFieldNoCopy(pipe.Object, "spec", "integration")
+	assert.Assert(t, !found)
+
+	converted, err := fromPipe(pipe)
+	assert.NilError(t, err)
+	assert.Equal(t, *converted.Spec.Integration.Replicas, int32(2))
+	assert.DeepEqual(t, converted.Spec.Sink, binding.Spec.Sink)
+}
+
+func TestBindPipe(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.Get(createKamelet("k1"), nil)
+
+	dynamicClient := applyClient(pipeScheme())
+	p := pipeParams(mockClient, dynamicClient)
+
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--source-property", "message=Hello")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe", "k1-to-broker-default", "created", "current"))
+
+	pipe, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "k1-to-broker-default", v1.GetOptions{})
+	assert.NilError(t, err)
+	message, _, _ := unstructured.NestedString(pipe.Object, "spec", "source", "properties", "message")
+	assert.Equal(t, message, "Hello")
+	recorder.Validate()
+}
+
+func TestBindPipeOffline(t *testing.T) {
+	p := &KameletPluginParams{KnParams: &commands.KnParams{}, Context: context.TODO(), UsePipe: true}
+
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--offline")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "apiVersion: camel.apache.org/v1", "kind: Pipe", "name: k1-to-broker-default"))
+}
+
+func TestBindPipeRequiresForce(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	pipe, err := toPipe(createKameletBindingInNamespace("b1", "k1", "current"))
+	assert.NilError(t, err)
+	dynamicClient := applyClient(pipeScheme(), pipe)
+	p := pipeParams(mockClient, dynamicClient)
+
+	recorder.Get(createKamelet("k1"), nil)
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--name", "b1", "--channel", "events")
+	assert.ErrorContains(t, err, "Pipe 'b1' already exists in namespace 'current', use --force to update it")
+	assert.Check(t, util.ContainsAll(output, "-      kind: Broker\n", "+      kind: Channel\n"))
+	existing, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	kind, _, _ := unstructured.NestedString(existing.Object, "spec", "sink", "ref", "kind")
+	assert.Equal(t, kind, "Broker")
+
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--name", "b1", "--channel", "events", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe 'b1' updated in namespace 'current'."))
+	updated, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	kind, _, _ = unstructured.NestedString(updated.Object, "spec", "sink", "ref", "kind")
+	assert.Equal(t, kind, "Channel")
+	recorder.Validate()
+}
+
+func TestBindingExportApplyPipes(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	pipe, err := toPipe(createKameletBindingInNamespace("b1", "k1", "current"))
+	assert.NilError(t, err)
+	dynamicClient := applyClient(pipeScheme(), pipe)
+	p := pipeParams(mockClient, dynamicClient)
+
+	dir := t.TempDir()
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "export", "--all", "--dir", dir)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "b1.yaml"))
+	exported, err := ioutil.ReadFile(filepath.Join(dir, "b1.yaml"))
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(string(exported), "kind: Pipe", "name: b1"))
+
+	// the exported Pipes are applied again instead of being skipped and pruned
+	recorder.Get(createKamelet("k1"), nil)
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "apply", "-f", dir, "--prune")
+	assert.NilError(t, err)
+	assert.Equal(t, output, "Pipe 'b1' updated in namespace 'current'.\n")
+
+	applied, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	source, _, _ := unstructured.NestedString(applied.Object, "spec", "source", "ref", "name")
+	assert.Equal(t, source, "k1")
+	recorder.Validate()
+}
+
+func TestBindingListPipes(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Namespace = "current"
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe))
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "list")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "NAME", "SOURCE", "SINK", "b1", "kamelet:k1"))
+
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "list", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: Pipe", "name: b1"))
+}
+
+func TestBindingListPipesAllNamespaces(t *testing.T) {
+	var objects []runtime.Object
+	for _, namespace := range []string{"default1", "default2"} {
+		binding := createKameletBinding("b-"+namespace, "k1")
+		binding.Namespace = namespace
+		pipe, err := toPipe(binding)
+		assert.NilError(t, err)
+		objects = append(objects, pipe)
+	}
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicfake.NewSimpleDynamicClient(pipeScheme(), objects...))
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "list", "--all-namespaces")
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAMESPACE", "NAME", "SOURCE", "SINK"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "default1", "b-default1"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "default2", "b-default2"))
+}
+
+func TestBindingListPipesSelector(t *testing.T) {
+	var objects []runtime.Object
+	for _, team := range []string{"a", "b"} {
+		binding := createKameletBinding("b-"+team, "k1")
+		binding.Namespace = "current"
+		binding.Labels = map[string]string{"team": team}
+		pipe, err := toPipe(binding)
+		assert.NilError(t, err)
+		objects = append(objects, pipe)
+	}
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicfake.NewSimpleDynamicClient(pipeScheme(), objects...))
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "list", "-l", "team=b")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "b-b"))
+	assert.Check(t, util.ContainsNone(output, "b-a"))
+
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "list", "-l", "team=c")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "No resources found"))
+}
+
+func TestBindingListPipesWatch(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Namespace = "current"
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe)
+	updated := pipe.DeepCopy()
+	assert.NilError(t, unstructured.SetNestedField(updated.Object, "Ready", "status", "phase"))
+	watcher := watch.NewFakeWithChanSize(1, false)
+	watcher.Modify(updated)
+	dynamicClient.PrependWatchReactor("pipes", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicClient)
+	output, err := runWatchCmd(p, NewBindingCommand(p), "MODIFIED", "binding", "list", "--watch")
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "EVENT", "NAME", "SOURCE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "ADDED", "b1", "kamelet:k1"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "MODIFIED", "b1", "Ready"))
+}
+
+func TestBindingDescribePipeWatch(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Namespace = "current"
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe)
+	updated := pipe.DeepCopy()
+	updated.SetResourceVersion("2")
+	assert.NilError(t, unstructured.SetNestedField(updated.Object, "Ready", "status", "phase"))
+	watcher := watch.NewFakeWithChanSize(1, false)
+	watcher.Modify(updated)
+	dynamicClient.PrependWatchReactor("pipes", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicClient)
+	output, err := runWatchCmd(p, NewBindingCommand(p), "Ready", "binding", "describe", "b1", "--watch", "--show-secrets")
+	assert.NilError(t, err)
+	descriptions := strings.Split(output, "Name:")
+	assert.Equal(t, len(descriptions), 3)
+	assert.Check(t, util.ContainsAll(descriptions[2], "b1", "Phase:", "Ready"))
+}
+
+func TestBindingUpdatePipe(t *testing.T) {
+	binding := createKameletBinding("b1", "k1")
+	binding.Namespace = "current"
+	pipe, err := toPipe(binding)
+	assert.NilError(t, err)
+	steps := []interface{}{map[string]interface{}{"ref": map[string]interface{}{"kind": "Kamelet", "name": "filter"}}}
+	assert.NilError(t, unstructured.SetNestedSlice(pipe.Object, steps, "spec", "steps"))
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.Get(createKamelet("k1"), nil)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe)
+	p := pipeParams(mockClient, dynamicClient)
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "update", "b1", "--source-property", "message=Bye")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe", "b1", "updated", "current"))
+
+	updated, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	message, _, _ := unstructured.NestedString(updated.Object, "spec", "source", "properties", "message")
+	assert.Equal(t, message, "Bye")
+	updatedSteps, _, _ := unstructured.NestedSlice(updated.Object, "spec", "steps")
+	assert.DeepEqual(t, updatedSteps, steps)
+	recorder.Validate()
+}
+
+func TestBindingDeletePipes(t *testing.T) {
+	var objects []runtime.Object
+	for _, name := range []string{"b1", "b2", "b3"} {
+		binding := createKameletBinding(name, "k1")
+		binding.Namespace = "current"
+		binding.Labels = map[string]string{"app": "payments"}
+		if name == "b3" {
+			binding.Labels = nil
+		}
+		pipe, err := toPipe(binding)
+		assert.NilError(t, err)
+		objects = append(objects, pipe)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), objects...)
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicClient)
+
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "delete", "b3")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe", "b3", "deleted", "current"))
+
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "delete", "-l", "app=payments", "--yes")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe 'b1' deleted", "Pipe 'b2' deleted"))
+
+	pipes, err := dynamicClient.Resource(pipeResource).Namespace("current").List(context.TODO(), v1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(pipes.Items), 0)
+
+	_, err = runPipeCmd(p, NewBindingCommand(p), "binding", "delete", "b1")
+	assert.ErrorContains(t, err, "failed to delete Pipe 'b1' in namespace 'current'")
+}
+
+func TestBindingDeletePipesFromFile(t *testing.T) {
+	pipe, err := toPipe(createKameletBindingInNamespace("b1", "k1", "current"))
+	assert.NilError(t, err)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(pipeScheme(), pipe)
+	p := pipeParams(kamelettesting.NewMockKameletClient(t), dynamicClient)
+
+	manifest, err := yaml.Marshal(pipe.Object)
+	assert.NilError(t, err)
+	file := filepath.Join(t.TempDir(), "b1.yaml")
+
+	// documents that are neither bindings nor Pipes fail the command before anything is deleted
+	assert.NilError(t, ioutil.WriteFile(file, append(manifest, []byte("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c1\n")...), 0600))
+	_, err = runPipeCmd(p, NewBindingCommand(p), "binding", "delete", "-f", file)
+	assert.ErrorContains(t, err, "unsupported kind \"ConfigMap\"")
+	_, err = dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+
+	assert.NilError(t, ioutil.WriteFile(file, manifest, 0600))
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "delete", "-f", file)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe 'b1' deleted in namespace 'current'"))
+	_, err = dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.Check(t, apierrors.IsNotFound(err))
+}
+
+// pipeParams returns params of a cluster serving the Pipe API
+func pipeParams(kameletClient camelkv1alpha1.CamelV1alpha1Interface, dynamicClient dynamic.Interface) *KameletPluginParams {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "camel.apache.org/v1",
+			APIResources: []v1.APIResource{{Name: "pipes", Kind: "Pipe", Namespaced: true}},
+		},
+	}
+
+	return &KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return kameletClient, nil
+		},
+		NewKubeClient: func() (kubernetes.Interface, error) {
+			return clientset, nil
+		},
+		NewDynamicClient: func() (dynamic.Interface, error) {
+			return dynamicClient, nil
+		},
+	}
+}
+
+func pipeScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	gv := schema.GroupVersion{Group: "camel.apache.org", Version: "v1"}
+	scheme.AddKnownTypeWithName(gv.WithKind("Pipe"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gv.WithKind("PipeList"), &unstructured.UnstructuredList{})
+	return scheme
+}
+
+// applyClient returns a fake dynamic client of given objects that also serves server-side apply, which the object
+// tracker of the fake does not support
+func applyClient(scheme *runtime.Scheme, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClient(scheme)
+	tracker := k8stesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	client.ReactionChain = nil
+	client.WatchReactionChain = nil
+	client.AddReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		if _, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName()); apierrors.IsNotFound(err) {
+			return true, obj, tracker.Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, tracker.Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+	client.AddReactor("*", "*", k8stesting.ObjectReaction(tracker))
+	client.AddWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher, err := tracker.Watch(action.GetResource(), action.GetNamespace())
+		return err == nil, watcher, err
+	})
+	return client
+}
+
+func runPipeCmd(p *KameletPluginParams, cmd *cobra.Command, args ...string) (string, error) {
+	pipeCmd, _, output := commands.CreateSourcesTestKnCommand(cmd, p.KnParams)
+	pipeCmd.SetArgs(args)
+	err := pipeCmd.Execute()
+	return output.String(), err
+}
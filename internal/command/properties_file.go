@@ -0,0 +1,89 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readPropertiesFile reads key/value pairs from a .properties or .env style file. Keys and values are separated
+// by '=' or ':', lines starting with '#' or '!' are comments, a leading "export" is ignored, surrounding quotes
+// are removed from values and a trailing backslash continues the value on the next line.
+func readPropertiesFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	var logical string
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if logical == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			logical += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		logical += line
+
+		key, value, err := parsePropertyLine(logical)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+		}
+		properties[key] = value
+		logical = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical != "" {
+		key, value, err := parsePropertyLine(logical)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+		}
+		properties[key] = value
+	}
+	return properties, nil
+}
+
+func parsePropertyLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+	separator := strings.IndexAny(line, "=:")
+	if separator < 0 {
+		return "", "", fmt.Errorf("invalid property %q, expected <key>=<value>", line)
+	}
+
+	key := strings.TrimSpace(line[:separator])
+	if key == "" {
+		return "", "", fmt.Errorf("invalid property %q, missing key", line)
+	}
+
+	value := strings.TrimSpace(line[separator+1:])
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, nil
+}
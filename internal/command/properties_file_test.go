@@ -0,0 +1,79 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReadPropertiesFile(t *testing.T) {
+	file := writePropertiesFile(t, `# comment
+! another comment
+
+message=Hello World
+period: 1000
+export TOKEN="my token"
+quoted='single'
+url=http://localhost:8080/path?a=b
+multi=first \
+  second
+`)
+
+	properties, err := readPropertiesFile(file)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, properties, map[string]string{
+		"message": "Hello World",
+		"period":  "1000",
+		"TOKEN":   "my token",
+		"quoted":  "single",
+		"url":     "http://localhost:8080/path?a=b",
+		"multi":   "first second",
+	})
+}
+
+func TestReadPropertiesFileErrors(t *testing.T) {
+	_, err := readPropertiesFile(writePropertiesFile(t, "message=Hello\ninvalid\n"))
+	assert.ErrorContains(t, err, "line 2: invalid property \"invalid\", expected <key>=<value>")
+
+	_, err = readPropertiesFile(writePropertiesFile(t, "=value\n"))
+	assert.ErrorContains(t, err, "line 1: invalid property \"=value\", missing key")
+
+	_, err = readPropertiesFile(filepath.Join(t.TempDir(), "missing.properties"))
+	assert.ErrorContains(t, err, "no such file or directory")
+}
+
+func TestMergedProperties(t *testing.T) {
+	file := writePropertiesFile(t, "message=Hello\nperiod=1000\n")
+
+	properties, err := mergedProperties(file, []string{"message=Bye"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, properties, map[string]string{"message": "Bye", "period": "1000"})
+
+	properties, err = mergedProperties("", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(properties), 0)
+}
+
+func writePropertiesFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "test.properties")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
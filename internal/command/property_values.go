@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	envValuePrefix  = "@env:"
+	fileValuePrefix = "@file:"
+	stdinValue      = "@stdin"
+)
+
+// expandPropertyValues replaces the "@env:<variable>", "@file:<path>" and "@stdin" values of the property flags
+// with the value read from the environment variable, the file or the input of the command, so that secrets do not
+// show up in the shell history. A single trailing newline of file and input values is removed.
+func (f *bindingFlags) expandPropertyValues(in io.Reader) error {
+	var stdinUsed bool
+	for _, values := range [][]string{f.SourceProperties, f.SourcePropertiesJSON, f.SourceSecretProperties, f.SinkProperties, f.SinkPropertiesJSON, f.SinkSecretProperties} {
+		for i, value := range values {
+			expanded, usesStdin, err := expandPropertyValue(value, in)
+			if err != nil {
+				return err
+			}
+			if usesStdin {
+				if stdinUsed {
+					return errors.New("@stdin can only be used for a single property value")
+				}
+				stdinUsed = true
+			}
+			values[i] = expanded
+		}
+	}
+	return nil
+}
+
+// readsStdin reports whether a property value is read from the input of the command
+func (f *bindingFlags) readsStdin() bool {
+	for _, values := range [][]string{f.SourceProperties, f.SourcePropertiesJSON, f.SourceSecretProperties, f.SinkProperties, f.SinkPropertiesJSON, f.SinkSecretProperties} {
+		for _, value := range values {
+			if strings.HasSuffix(value, "="+stdinValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandPropertyValue expands the value of a "<key>=<value>" pair and reports whether the value was read from input
+func expandPropertyValue(pair string, in io.Reader) (string, bool, error) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "@") {
+		return pair, false, nil
+	}
+	key, value := parts[0], parts[1]
+
+	switch {
+	case strings.HasPrefix(value, envValuePrefix):
+		variable := strings.TrimPrefix(value, envValuePrefix)
+		resolved, ok := os.LookupEnv(variable)
+		if !ok {
+			return "", false, fmt.Errorf("environment variable %q of property %q is not set", variable, key)
+		}
+		return key + "=" + resolved, false, nil
+	case strings.HasPrefix(value, fileValuePrefix):
+		path := strings.TrimPrefix(value, fileValuePrefix)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read value of property %q: %w", key, err)
+		}
+		return key + "=" + trimNewline(string(data)), false, nil
+	case value == stdinValue:
+		data, err := ioutil.ReadAll(in)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read value of property %q from stdin: %w", key, err)
+		}
+		return key + "=" + trimNewline(string(data)), true, nil
+	}
+	return pair, false, nil
+}
+
+// trimNewline removes a single trailing newline as written by most editors and by echo
+func trimNewline(value string) string {
+	value = strings.TrimSuffix(value, "\n")
+	return strings.TrimSuffix(value, "\r")
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExpandPropertyValues(t *testing.T) {
+	assert.NilError(t, os.Setenv("KN_SOURCE_KAMELET_TEST_TOKEN", "s3cr3t"))
+	defer os.Unsetenv("KN_SOURCE_KAMELET_TEST_TOKEN")
+	file := filepath.Join(t.TempDir(), "query.sql")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("SELECT *\nFROM events\n"), 0600))
+
+	flags := bindingFlags{
+		SourceProperties:       []string{"message=Hello", "query=@file:" + file, "mail=user@example.com", "period-"},
+		SourceSecretProperties: []string{"token=@env:KN_SOURCE_KAMELET_TEST_TOKEN"},
+		SinkSecretProperties:   []string{"password=@stdin"},
+	}
+	assert.NilError(t, flags.expandPropertyValues(strings.NewReader("pa55\n")))
+	assert.DeepEqual(t, flags.SourceProperties, []string{"message=Hello", "query=SELECT *\nFROM events", "mail=user@example.com", "period-"})
+	assert.DeepEqual(t, flags.SourceSecretProperties, []string{"token=s3cr3t"})
+	assert.DeepEqual(t, flags.SinkSecretProperties, []string{"password=pa55"})
+}
+
+func TestExpandPropertyValuesErrors(t *testing.T) {
+	flags := bindingFlags{SourceProperties: []string{"token=@env:KN_SOURCE_KAMELET_TEST_UNSET"}}
+	assert.Error(t, flags.expandPropertyValues(strings.NewReader("")), "environment variable \"KN_SOURCE_KAMELET_TEST_UNSET\" of property \"token\" is not set")
+
+	flags = bindingFlags{SourceProperties: []string{"query=@file:" + filepath.Join(t.TempDir(), "missing.sql")}}
+	assert.ErrorContains(t, flags.expandPropertyValues(strings.NewReader("")), "failed to read value of property \"query\": open ")
+
+	flags = bindingFlags{SourceProperties: []string{"token=@stdin"}, SinkProperties: []string{"password=@stdin"}}
+	assert.Error(t, flags.expandPropertyValues(strings.NewReader("")), "@stdin can only be used for a single property value")
+}
+
+func TestBindPropertyValueFromStdin(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmdWithInput(mockClient, "Hello from stdin\n", "k1", "--broker", "default", "--source-property", "message=@stdin", "--offline", "-n", "test")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "message: Hello from stdin"))
+	assert.Check(t, util.ContainsNone(output, "@stdin"))
+
+	_, err = runBindCmdWithInput(mockClient, "", "--interactive", "--source-property", "message=@stdin")
+	assert.Error(t, err, "--interactive can not be combined with property values read from @stdin")
+	recorder.Validate()
+}
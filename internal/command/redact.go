@@ -0,0 +1,258 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/pflag"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+)
+
+// redactedValue replaces the values of sensitive properties in the output of the commands
+const redactedValue = "******"
+
+// redactor masks the values of sensitive binding properties, i.e. properties marked as password by the Kamelet
+// definition or, when the Kamelet is unknown, properties whose name suggests a credential. Placeholders resolved at
+// runtime such as {{secret:name/key}} are shown as they are. A nil redactor leaves the bindings untouched.
+type redactor struct {
+	params *KameletPluginParams
+	// offline restricts the Kamelet lookup to the local cache and the bundled catalog
+	offline bool
+	// lock guards the looked up Kamelets as bindings may be redacted by concurrent workers
+	lock     sync.Mutex
+	kamelets map[string]*v1alpha1.Kamelet
+}
+
+// newRedactor returns the redactor of the command output, nil when the secrets should be shown
+func (params *KameletPluginParams) newRedactor(showSecrets bool) *redactor {
+	if showSecrets {
+		return nil
+	}
+	return &redactor{params: params, kamelets: map[string]*v1alpha1.Kamelet{}}
+}
+
+// newOfflineRedactor returns the redactor of manifests rendered without accessing the cluster, nil when the secrets
+// should be shown
+func (params *KameletPluginParams) newOfflineRedactor(showSecrets bool) *redactor {
+	r := params.newRedactor(showSecrets)
+	if r != nil {
+		r.offline = true
+	}
+	return r
+}
+
+// addShowSecretsFlag adds the --show-secrets flag to given flag set
+func addShowSecretsFlag(flags *pflag.FlagSet, showSecrets *bool) {
+	flags.BoolVar(showSecrets, "show-secrets", false, "Show the values of sensitive properties instead of masking them with "+redactedValue+".")
+}
+
+// redactBinding returns a copy of the binding with the values of sensitive properties masked, including the copy of
+// the properties in the last applied configuration
+func (r *redactor) redactBinding(binding *v1alpha1.KameletBinding) (*v1alpha1.KameletBinding, error) {
+	if r == nil || binding == nil {
+		return binding, nil
+	}
+	// the endpoint properties are raw JSON which the unstructured converter does not support
+	data, err := json.Marshal(binding)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &unstructured.Unstructured{}
+	if err := utiljson.Unmarshal(data, &manifest.Object); err != nil {
+		return nil, err
+	}
+	if err := r.redactManifest(manifest); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(manifest.Object); err != nil {
+		return nil, err
+	}
+	redacted := &v1alpha1.KameletBinding{}
+	if err := json.Unmarshal(data, redacted); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+// redactDiff masks the sensitive properties of both versions of a binding for printing their differences, values
+// changed by the update are masked distinctly so the diff still shows the change
+func (r *redactor) redactDiff(from *v1alpha1.KameletBinding, to *v1alpha1.KameletBinding) (*v1alpha1.KameletBinding, *v1alpha1.KameletBinding, error) {
+	if r == nil {
+		return from, to, nil
+	}
+	redactedFrom, err := r.redactBinding(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	redactedTo, err := r.redactBinding(to)
+	if err != nil || from == nil || to == nil {
+		return redactedFrom, redactedTo, err
+	}
+
+	for _, endpoint := range []struct {
+		from     *v1alpha1.Endpoint
+		to       *v1alpha1.Endpoint
+		redacted *v1alpha1.Endpoint
+	}{
+		{&from.Spec.Source, &to.Spec.Source, &redactedTo.Spec.Source},
+		{&from.Spec.Sink, &to.Spec.Sink, &redactedTo.Spec.Sink},
+	} {
+		redacted, err := kameletapi.RawEndpointProperties(endpoint.redacted.Properties)
+		if err != nil || len(redacted) == 0 {
+			continue
+		}
+		fromProperties, err := kameletapi.RawEndpointProperties(endpoint.from.Properties)
+		if err != nil {
+			continue
+		}
+		toProperties, err := kameletapi.RawEndpointProperties(endpoint.to.Properties)
+		if err != nil {
+			continue
+		}
+
+		changed := false
+		for name, value := range redacted {
+			previous, ok := fromProperties[name]
+			if value == redactedValue && ok && !reflect.DeepEqual(previous, toProperties[name]) {
+				redacted[name] = redactedValue + " (changed)"
+				changed = true
+			}
+		}
+		if changed {
+			data, err := json.Marshal(redacted)
+			if err != nil {
+				return nil, nil, err
+			}
+			endpoint.redacted.Properties = &v1alpha1.EndpointProperties{RawMessage: data}
+		}
+	}
+	return redactedFrom, redactedTo, nil
+}
+
+// redactObject masks the sensitive properties of a KameletBinding or a Pipe given as unstructured object, other
+// objects are returned as they are
+func (r *redactor) redactObject(obj runtime.Object) (runtime.Object, error) {
+	if r == nil {
+		return obj, nil
+	}
+	switch binding := obj.(type) {
+	case *v1alpha1.KameletBinding:
+		return r.redactBinding(binding)
+	case *unstructured.Unstructured:
+		manifest := binding.DeepCopy()
+		if err := r.redactManifest(manifest); err != nil {
+			return nil, err
+		}
+		return manifest, nil
+	}
+	return obj, nil
+}
+
+// redactManifest masks the sensitive properties of the source and sink of the binding manifest in place
+func (r *redactor) redactManifest(manifest *unstructured.Unstructured) error {
+	if r == nil {
+		return nil
+	}
+	r.redactEndpoints(manifest.Object, manifest.GetNamespace())
+
+	annotations := manifest.GetAnnotations()
+	if lastApplied, ok := annotations[lastAppliedAnnotation]; ok {
+		applied := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+			// mask the annotation completely rather than leaking values of a configuration that can not be read
+			annotations[lastAppliedAnnotation] = redactedValue
+		} else {
+			r.redactEndpoints(applied, manifest.GetNamespace())
+			data, err := json.Marshal(applied)
+			if err != nil {
+				return err
+			}
+			annotations[lastAppliedAnnotation] = string(data)
+		}
+		manifest.SetAnnotations(annotations)
+	}
+	return nil
+}
+
+// redactEndpoints masks the sensitive properties of the source and sink of the binding object
+func (r *redactor) redactEndpoints(object map[string]interface{}, namespace string) {
+	for _, endpoint := range []string{"source", "sink"} {
+		properties, ok, _ := unstructured.NestedMap(object, "spec", endpoint, "properties")
+		if !ok || len(properties) == 0 {
+			continue
+		}
+
+		var kamelet *v1alpha1.Kamelet
+		ref, _, _ := unstructured.NestedStringMap(object, "spec", endpoint, "ref")
+		if ref["kind"] == v1alpha1.KameletKind {
+			refNamespace := ref["namespace"]
+			if refNamespace == "" {
+				refNamespace = namespace
+			}
+			kamelet = r.kamelet(refNamespace, ref["name"])
+		}
+
+		redacted := false
+		for name, value := range properties {
+			if s, ok := value.(string); ok && kameletapi.IsPropertyReference(s) {
+				continue
+			}
+			if isCredentialProperty(kamelet, name) {
+				properties[name] = redactedValue
+				redacted = true
+			}
+		}
+		if redacted {
+			_ = unstructured.SetNestedMap(object, properties, "spec", endpoint, "properties")
+		}
+	}
+}
+
+// kamelet looks up the Kamelet on the cluster, falling back to the local cache and the bundled catalog, nil when the
+// Kamelet is unknown
+func (r *redactor) kamelet(namespace string, name string) *v1alpha1.Kamelet {
+	key := namespace + "/" + name
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if kamelet, ok := r.kamelets[key]; ok {
+		return kamelet
+	}
+
+	var kamelet *v1alpha1.Kamelet
+	if !r.offline && r.params.NewKameletClient != nil {
+		if client, err := r.params.NewKameletClient(); err == nil {
+			if found, err := client.Kamelets(namespace).Get(r.params.Context, name, v1.GetOptions{}); err == nil {
+				kamelet = found
+			}
+		}
+	}
+	if kamelet == nil {
+		if found, ok := r.params.offlineKamelet(namespace, name); ok {
+			kamelet = found
+		}
+	}
+	r.kamelets[key] = kamelet
+	return kamelet
+}
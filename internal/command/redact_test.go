@@ -0,0 +1,174 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func passwordKamelet() *camelkapis.Kamelet {
+	kamelet := createKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"user":   {Type: "string"},
+		"secret": {Type: "string", XDescriptors: []string{passwordDescriptor}},
+		"apiKey": {Type: "string"},
+	}
+	return kamelet
+}
+
+func passwordBinding() *camelkapis.KameletBinding {
+	binding := createKameletBinding("b1", "k1")
+	binding.Spec.Source.Properties = &camelkapis.EndpointProperties{
+		RawMessage: camelv1.RawMessage(`{"apiKey":"visible","secret":"s3cr3t","user":"admin"}`),
+	}
+	binding.Spec.Sink.Properties = &camelkapis.EndpointProperties{
+		RawMessage: camelv1.RawMessage(`{"password":"pa55","token":"{{secret:b1/sink.token}}"}`),
+	}
+	binding.Annotations = map[string]string{lastAppliedAnnotation: `{"spec":{"source":{"ref":{"kind":"Kamelet","name":"k1"},"properties":{"secret":"s3cr3t"}}}}`}
+	return binding
+}
+
+func TestRedactBinding(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := redactParams(mockClient)
+
+	recorder.Get(passwordKamelet(), nil)
+	redacted, err := p.newRedactor(false).redactBinding(passwordBinding())
+	assert.NilError(t, err)
+	// the source properties are masked by the Kamelet definition, the broker sink by property name
+	assert.Equal(t, string(redacted.Spec.Source.Properties.RawMessage), `{"apiKey":"visible","secret":"******","user":"admin"}`)
+	assert.Equal(t, string(redacted.Spec.Sink.Properties.RawMessage), `{"password":"******","token":"{{secret:b1/sink.token}}"}`)
+	assert.Equal(t, redacted.Annotations[lastAppliedAnnotation], `{"spec":{"source":{"properties":{"secret":"******"},"ref":{"kind":"Kamelet","name":"k1"}}}}`)
+
+	binding := passwordBinding()
+	redacted, err = p.newRedactor(true).redactBinding(binding)
+	assert.NilError(t, err)
+	assert.Equal(t, redacted, binding)
+	recorder.Validate()
+}
+
+func TestRedactDiff(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := redactParams(mockClient)
+
+	recorder.Get(passwordKamelet(), nil)
+	existing := passwordBinding()
+	desired := passwordBinding()
+	desired.Spec.Source.Properties.RawMessage = camelv1.RawMessage(`{"apiKey":"visible","secret":"changed","user":"admin"}`)
+	from, to, err := p.newRedactor(false).redactDiff(existing, desired)
+	assert.NilError(t, err)
+	assert.Equal(t, string(from.Spec.Source.Properties.RawMessage), `{"apiKey":"visible","secret":"******","user":"admin"}`)
+	assert.Equal(t, string(to.Spec.Source.Properties.RawMessage), `{"apiKey":"visible","secret":"****** (changed)","user":"admin"}`)
+	assert.Equal(t, string(to.Spec.Sink.Properties.RawMessage), `{"password":"******","token":"{{secret:b1/sink.token}}"}`)
+	recorder.Validate()
+}
+
+func TestBindingDescribeShowSecrets(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := redactParams(mockClient)
+
+	recorder.GetBinding(passwordBinding(), nil)
+	recorder.Get(passwordKamelet(), nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "secret:", "******", "user:", "admin"))
+	assert.Check(t, util.ContainsNone(output, "s3cr3t", "pa55"))
+
+	recorder.GetBinding(passwordBinding(), nil)
+	recorder.Get(passwordKamelet(), nil)
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default", "-o", "yaml")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "secret: '******'"))
+	assert.Check(t, util.ContainsNone(output, "s3cr3t", "pa55"))
+
+	recorder.GetBinding(passwordBinding(), nil)
+	output, err = runPipeCmd(p, NewBindingCommand(p), "binding", "describe", "b1", "-n", "default", "--show-secrets")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "s3cr3t", "pa55"))
+	recorder.Validate()
+}
+
+func TestBindingListRedactsSecrets(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := redactParams(mockClient)
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{*passwordBinding()}}, nil)
+	recorder.Get(passwordKamelet(), nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "list", "-o", "json")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, `\"secret\":\"******\"`, `"password": "******"`))
+	assert.Check(t, util.ContainsNone(output, "s3cr3t", "pa55"))
+	recorder.Validate()
+}
+
+func TestBindingDiffRedactsSecrets(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	manifest := strings.Replace(diffBindingManifest, "      namespace: default\n", "      namespace: default\n    properties:\n      secret: changed\n", 1)
+	existing := passwordBinding()
+	existing.Annotations = nil
+	recorder.GetBinding(existing, nil)
+	recorder.Get(passwordKamelet(), nil)
+	output, err := runBindingDiffCmd(mockClient, manifest, "b1", "-n", "default", "-f", "-", "--force")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "+      secret: '****** (changed)'\n"))
+	assert.Check(t, util.ContainsNone(output, "s3cr3t", "changed\n"))
+	recorder.Validate()
+}
+
+func redactParams(c *kamelettesting.MockKameletClient) *KameletPluginParams {
+	return &KameletPluginParams{
+		KnParams:          &commands.KnParams{},
+		Context:           context.TODO(),
+		UseKameletBinding: true,
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+}
+
+func TestBindOfflineRedactsSecrets(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	// the Kamelet is not looked up on the cluster, the property is masked for its name
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--source-property", "password=pa55", "--source-property", "user=admin")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "password: '******'", "user: admin"))
+	assert.Check(t, util.ContainsNone(output, "pa55"))
+
+	output, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--source-property", "password=pa55", "--show-secrets")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "password: pa55"))
+	recorder.Validate()
+}
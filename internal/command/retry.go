@@ -0,0 +1,146 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+)
+
+// defaultRetryInterval is the delay before the first retry, each further retry doubles the delay
+const defaultRetryInterval = time.Second
+
+// backoff returns the exponential backoff of the retries given by flag
+func (params *KameletPluginParams) backoff() wait.Backoff {
+	interval := params.RetryInterval
+	if interval == 0 {
+		interval = defaultRetryInterval
+	}
+	return wait.Backoff{Duration: interval, Factor: 2, Jitter: 0.1, Steps: params.Retries}
+}
+
+// wrapRetryTransport wraps the transport of the REST config to retry requests failing with transient errors, each
+// retry is logged when the API requests are logged
+func (params *KameletPluginParams) wrapRetryTransport(config *rest.Config, verbosity int) {
+	out := ioutil.Discard
+	if verbosity >= verbosityRequests {
+		out = params.LogOutput
+		if out == nil {
+			out = os.Stderr
+		}
+	}
+	config.Wrap(func(transport http.RoundTripper) http.RoundTripper {
+		return &retryTransport{transport: transport, backoff: params.backoff(), out: out}
+	})
+}
+
+// retryOnConflict runs the update again with the backoff of the retries given by flag as long as it fails with a
+// conflict, i.e. the resource was modified since it was read
+func (params *KameletPluginParams) retryOnConflict(update func() error) error {
+	if params.Retries == 0 {
+		return update()
+	}
+	backoff := params.backoff()
+	// the steps of the backoff include the first attempt
+	backoff.Steps++
+	return retry.RetryOnConflict(backoff, update)
+}
+
+// retryTransport retries requests answered with 429 Too Many Requests, idempotent requests answered with a 5xx server
+// error and GET requests failing to reach the API server, watches are never retried
+type retryTransport struct {
+	transport http.RoundTripper
+	backoff   wait.Backoff
+	out       io.Writer
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.backoff
+	retries := backoff.Steps
+	for attempt := 1; ; attempt++ {
+		resp, err := t.transport.RoundTrip(req)
+		if attempt > retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		retry := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			retry = req.Clone(req.Context())
+			retry.Body = body
+		}
+
+		reason := fmt.Sprint(err)
+		if resp != nil {
+			reason = resp.Status
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		delay := backoff.Step()
+		fmt.Fprintf(t.out, "%s %s failed with %s, retrying in %s (retry %d of %d)\n", req.Method, req.URL, reason, delay.Round(time.Millisecond), attempt, retries)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+		req = retry
+	}
+}
+
+// retryable checks whether the request failed with a transient error. A server error may be returned after the
+// request has been processed, so server errors of requests that are not idempotent such as the POST creating a
+// resource are only retried when the server asks for it with a 503 Service Unavailable carrying Retry-After.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.URL.Query().Get("watch") == "true" || req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return req.Method == http.MethodGet
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "":
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return idempotent(req.Method)
+	}
+	return false
+}
+
+// idempotent checks whether sending the request again has the same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	}
+	return false
+}
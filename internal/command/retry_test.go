@@ -0,0 +1,184 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/client/pkg/util"
+
+	"gotest.tools/v3/assert"
+)
+
+var secretsResource = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// flakyServer answers the first requests with given status before answering with the Secret, the bodies of all
+// requests are collected
+func flakyServer(t *testing.T, failures int, status int) (*httptest.Server, *[]string) {
+	var bodies []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		if len(bodies) <= failures {
+			w.WriteHeader(status)
+			reasons := map[int]v1.StatusReason{
+				http.StatusNotFound:            v1.StatusReasonNotFound,
+				http.StatusTooManyRequests:     v1.StatusReasonTooManyRequests,
+				http.StatusInternalServerError: v1.StatusReasonInternalError,
+				http.StatusServiceUnavailable:  v1.StatusReasonServiceUnavailable,
+			}
+			fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":%q,"code":%d}`, reasons[status], status)
+			return
+		}
+		fmt.Fprint(w, secretJSON)
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestRetryTransient(t *testing.T) {
+	server, bodies := flakyServer(t, 2, http.StatusServiceUnavailable)
+	p, log := serverParams(t, server)
+	p.Retries = 3
+	p.RetryInterval = time.Millisecond
+	p.Verbosity = verbosityRequests
+
+	getSecret(t, p)
+	assert.Equal(t, len(*bodies), 3)
+	assert.Check(t, util.ContainsAll(log.String(), "failed with 503 Service Unavailable, retrying in ", "(retry 1 of 3)", "(retry 2 of 3)", "200 OK"))
+}
+
+func TestRetryRequestBody(t *testing.T) {
+	server, bodies := flakyServer(t, 1, http.StatusTooManyRequests)
+	p, _ := serverParams(t, server)
+	p.Retries = 1
+	p.RetryInterval = time.Millisecond
+
+	client, err := p.newDynamicClient()
+	assert.NilError(t, err)
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "creds"},
+	}}
+	_, err = client.Resource(secretsResource).Namespace("default").Create(p.Context, secret, v1.CreateOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(*bodies), 2)
+	assert.Check(t, util.ContainsAll((*bodies)[1], `"name":"creds"`))
+	assert.Equal(t, (*bodies)[0], (*bodies)[1])
+}
+
+func TestRetryExhausted(t *testing.T) {
+	server, bodies := flakyServer(t, 5, http.StatusInternalServerError)
+	p, _ := serverParams(t, server)
+	p.Retries = 1
+	p.RetryInterval = time.Millisecond
+
+	client, err := p.newDynamicClient()
+	assert.NilError(t, err)
+	_, err = client.Resource(secretsResource).Namespace("default").Get(p.Context, "creds", v1.GetOptions{})
+	assert.Check(t, apierrors.IsInternalError(err))
+	assert.Equal(t, len(*bodies), 2)
+
+	// permanent errors are not retried
+	server, bodies = flakyServer(t, 5, http.StatusNotFound)
+	p, _ = serverParams(t, server)
+	p.Retries = 3
+	client, err = p.newDynamicClient()
+	assert.NilError(t, err)
+	_, err = client.Resource(secretsResource).Namespace("default").Get(p.Context, "creds", v1.GetOptions{})
+	assert.Check(t, apierrors.IsNotFound(err))
+	assert.Equal(t, len(*bodies), 1)
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	// the Secret may have been created before the server error, so the POST is not sent again
+	server, bodies := flakyServer(t, 1, http.StatusInternalServerError)
+	p, _ := serverParams(t, server)
+	p.Retries = 3
+	p.RetryInterval = time.Millisecond
+
+	client, err := p.newDynamicClient()
+	assert.NilError(t, err)
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "creds"},
+	}}
+	_, err = client.Resource(secretsResource).Namespace("default").Create(p.Context, secret, v1.CreateOptions{})
+	assert.Check(t, apierrors.IsInternalError(err))
+	assert.Equal(t, len(*bodies), 1)
+}
+
+func TestRetryable(t *testing.T) {
+	retryAfter := http.Header{"Retry-After": []string{"1"}}
+	for _, tc := range []struct {
+		method string
+		status int
+		header http.Header
+		retry  bool
+	}{
+		{method: http.MethodGet, status: http.StatusInternalServerError, retry: true},
+		{method: http.MethodPut, status: http.StatusBadGateway, retry: true},
+		{method: http.MethodDelete, status: http.StatusServiceUnavailable, retry: true},
+		{method: http.MethodPatch, status: http.StatusGatewayTimeout, retry: true},
+		{method: http.MethodPost, status: http.StatusTooManyRequests, retry: true},
+		{method: http.MethodPost, status: http.StatusServiceUnavailable, header: retryAfter, retry: true},
+		{method: http.MethodPost, status: http.StatusServiceUnavailable, retry: false},
+		{method: http.MethodPost, status: http.StatusInternalServerError, header: retryAfter, retry: false},
+		{method: http.MethodGet, status: http.StatusNotFound, retry: false},
+	} {
+		req := httptest.NewRequest(tc.method, "https://api/api/v1/namespaces/default/secrets", nil)
+		resp := &http.Response{StatusCode: tc.status, Header: tc.header}
+		assert.Check(t, retryable(req, resp, nil) == tc.retry, "%s answered with %d", tc.method, tc.status)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	p := &KameletPluginParams{RetryInterval: time.Millisecond}
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "kameletbindings"}, "b1", fmt.Errorf("the object has been modified"))
+
+	attempts := 0
+	err := p.retryOnConflict(func() error {
+		attempts++
+		return conflict
+	})
+	assert.Check(t, apierrors.IsConflict(err))
+	assert.Equal(t, attempts, 1)
+
+	p.Retries = 2
+	attempts = 0
+	err = p.retryOnConflict(func() error {
+		attempts++
+		if attempts < 3 {
+			return conflict
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, attempts, 3)
+}
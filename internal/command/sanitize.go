@@ -0,0 +1,62 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// serverGeneratedFields lists metadata fields set by the API server that must not be re-applied
+var serverGeneratedFields = []string{
+	"uid",
+	"resourceVersion",
+	"creationTimestamp",
+	"generation",
+	"managedFields",
+	"selfLink",
+}
+
+// addCleanFlag adds the --clean flag to commands printing resources in YAML/JSON format
+func addCleanFlag(flags *pflag.FlagSet, clean *bool) {
+	flags.BoolVar(clean, "clean", false, "Remove status and server generated fields from YAML/JSON output so it can be re-applied.")
+}
+
+// sanitize converts the given object to its unstructured representation
+// stripping status and all server generated metadata fields.
+func sanitize(obj runtime.Object) (*unstructured.Unstructured, error) {
+	// JSON round trip honors the custom marshalling of raw message fields used in Camel K types
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{}
+	// integers are decoded as int64 instead of float64 so large values keep their precision
+	if err := utiljson.Unmarshal(data, &u.Object); err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	for _, field := range serverGeneratedFields {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	return u, nil
+}
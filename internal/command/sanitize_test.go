@@ -0,0 +1,53 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	kamelet := createKamelet("k1")
+	kamelet.UID = "3d5f3c2a-1b0e-4c58-9f4e-6c1e0d3d9a11"
+	kamelet.ResourceVersion = "1234"
+	kamelet.Generation = 2
+	kamelet.ManagedFields = []v1.ManagedFieldsEntry{{Manager: "kubectl"}}
+
+	u, err := sanitize(kamelet)
+	assert.NilError(t, err)
+
+	assert.Equal(t, u.GetName(), "k1")
+	assert.Equal(t, u.GetNamespace(), "default")
+	assert.Equal(t, u.GetKind(), "Kamelet")
+	assert.DeepEqual(t, u.GetLabels(), map[string]string{"camel.apache.org/kamelet.type": "source"})
+
+	metadata, _, _ := unstructured.NestedMap(u.Object, "metadata")
+	for _, field := range serverGeneratedFields {
+		_, found := metadata[field]
+		assert.Assert(t, !found, "unexpected metadata field %s", field)
+	}
+	_, found := u.Object["status"]
+	assert.Assert(t, !found)
+
+	description, _, _ := unstructured.NestedString(u.Object, "spec", "definition", "description")
+	assert.Equal(t, description, "Sample Kamelet source")
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/printers"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+var searchExample = `
+  # Search Kamelet sources matching given keyword in their name, title or description
+  kn-source-kamelet search kafka
+
+  # Search Kamelets of all types matching all given keywords
+  kn-source-kamelet search aws s3 --type all
+
+  # Search Kamelets in all namespaces
+  kn-source-kamelet search timer --all-namespaces`
+
+// Scores of the different kinds of keyword matches, results are ranked by the sum of their keyword scores
+const (
+	scoreNameExact        = 100
+	scoreNamePrefix       = 80
+	scoreNameContains     = 60
+	scoreTitleContains    = 40
+	scoreDescriptionMatch = 20
+	scoreNameFuzzy        = 10
+	scoreTitleFuzzy       = 5
+)
+
+// searchResult is a Kamelet matching the search keywords
+type searchResult struct {
+	kamelet *v1alpha1.Kamelet
+	score   int
+}
+
+// NewSearchCommand implements 'kn-source-kamelet search' command
+func NewSearchCommand(p *KameletPluginParams) *cobra.Command {
+	var filters kameletFilters
+
+	cmd := &cobra.Command{
+		Use:   "search KEYWORD...",
+		Short: "Search Kamelets by keyword",
+		Long: `Search Kamelets by keyword.
+
+Keywords are matched case-insensitive against the name, title and description of the Kamelets.
+Names and titles also match when a word starts with the letters of the keyword in order, e.g. 'tmr' matches 'timer-source'.
+Kamelets must match all given keywords and are listed with the best matches first.`,
+		Example: searchExample,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				return errors.New("'kn-source-kamelet search' requires at least one keyword")
+			}
+			if err := filters.verify(); err != nil {
+				return err
+			}
+
+			namespace, err := p.GetNamespace(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := p.NewKameletClient()
+			if err != nil {
+				return err
+			}
+
+			kameletList, err := client.Kamelets(namespace).List(p.Context, v1.ListOptions{})
+			if err != nil {
+				return knerrors.GetError(err)
+			}
+
+			results := searchKamelets(filters.filter(kameletList.Items), args)
+			out := cmd.OutOrStdout()
+			if len(results) == 0 {
+				fmt.Fprintf(out, "No Kamelets found matching %q.\n", strings.Join(args, " "))
+				return nil
+			}
+
+			dw := printers.NewPrefixWriter(out)
+			// empty namespace indicates all-namespaces flag is specified
+			if namespace == "" {
+				dw.WriteColsLn("NAMESPACE", "NAME", "TYPE", "TITLE")
+			} else {
+				dw.WriteColsLn("NAME", "TYPE", "TITLE")
+			}
+			for _, result := range results {
+				kamelet := result.kamelet
+				var title string
+				if kamelet.Spec.Definition != nil {
+					title = kamelet.Spec.Definition.Title
+				}
+				if namespace == "" {
+					dw.WriteColsLn(kamelet.Namespace, kamelet.Name, kameletapi.TypeOf(kamelet), title)
+				} else {
+					dw.WriteColsLn(kamelet.Name, kameletapi.TypeOf(kamelet), title)
+				}
+			}
+			return dw.Flush()
+		},
+	}
+	commands.AddNamespaceFlags(cmd.Flags(), true)
+	filters.addFlags(cmd.Flags())
+	return cmd
+}
+
+// searchKamelets returns the Kamelets matching all keywords ranked by score and name
+func searchKamelets(kamelets []v1alpha1.Kamelet, keywords []string) []searchResult {
+	var results []searchResult
+	for i := range kamelets {
+		kamelet := &kamelets[i]
+		score := 0
+		for _, keyword := range keywords {
+			keywordScore := matchKamelet(kamelet, strings.ToLower(keyword))
+			if keywordScore == 0 {
+				score = 0
+				break
+			}
+			score += keywordScore
+		}
+		if score > 0 {
+			results = append(results, searchResult{kamelet: kamelet, score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].kamelet.Name < results[j].kamelet.Name
+	})
+	return results
+}
+
+// matchKamelet returns the score of the best match of the lower case keyword, 0 if the Kamelet does not match
+func matchKamelet(kamelet *v1alpha1.Kamelet, keyword string) int {
+	name := strings.ToLower(kamelet.Name)
+	var title, description string
+	if kamelet.Spec.Definition != nil {
+		title = strings.ToLower(kamelet.Spec.Definition.Title)
+		description = strings.ToLower(kamelet.Spec.Definition.Description)
+	}
+
+	switch {
+	case name == keyword:
+		return scoreNameExact
+	case strings.HasPrefix(name, keyword):
+		return scoreNamePrefix
+	case strings.Contains(name, keyword):
+		return scoreNameContains
+	case strings.Contains(title, keyword):
+		return scoreTitleContains
+	case strings.Contains(description, keyword):
+		return scoreDescriptionMatch
+	case fuzzyMatch(name, keyword):
+		return scoreNameFuzzy
+	case fuzzyMatch(title, keyword):
+		return scoreTitleFuzzy
+	}
+	return 0
+}
+
+// fuzzyMatch checks whether the value contains all characters of the keyword in the same order
+// starting at the beginning of a word, e.g. 'tmr' matches 'timer-source' but not 'streams-source'
+func fuzzyMatch(value string, keyword string) bool {
+	runes := []rune(value)
+	for start := range runes {
+		if start > 0 && runes[start-1] != '-' && runes[start-1] != ' ' {
+			continue
+		}
+		if isSubsequence(runes[start:], []rune(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubsequence checks whether the value contains all characters of the keyword in the same order,
+// the first character of the keyword has to match the first character of the value
+func isSubsequence(value []rune, keyword []rune) bool {
+	if len(keyword) == 0 || len(value) == 0 || value[0] != keyword[0] {
+		return false
+	}
+	remaining := keyword[1:]
+	for _, c := range value[1:] {
+		if len(remaining) == 0 {
+			break
+		}
+		if c == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRanking(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.List(searchKameletList(), nil)
+
+	output, err := runSearchCmd(mockClient, "KAFKA")
+	assert.NilError(t, err)
+
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[0], "NAME", "TYPE", "TITLE"))
+	assert.Check(t, util.ContainsAll(outputLines[1], "kafka-source", "source", "Kafka Source"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "my-kafka-source"))
+	assert.Check(t, util.ContainsAll(outputLines[3], "streams-source"))
+	assert.Check(t, util.ContainsAll(outputLines[4], "events-source"))
+	assert.Check(t, util.ContainsNone(output, "timer-source", "kafka-sink"))
+
+	recorder.Validate()
+}
+
+func TestSearchFuzzyAndKeywords(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.List(searchKameletList(), nil)
+	output, err := runSearchCmd(mockClient, "tmr")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "timer-source"))
+	assert.Check(t, util.ContainsNone(output, "kafka"))
+
+	recorder.List(searchKameletList(), nil)
+	output, err = runSearchCmd(mockClient, "kafka", "sink", "--type", "all")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kafka-sink"))
+	assert.Check(t, util.ContainsNone(output, "my-kafka-source"))
+
+	recorder.List(searchKameletList(), nil)
+	output, err = runSearchCmd(mockClient, "ftp")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "No Kamelets found matching \"ftp\""))
+
+	_, err = runSearchCmd(mockClient)
+	assert.Error(t, err, "'kn-source-kamelet search' requires at least one keyword")
+
+	recorder.Validate()
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	assert.Assert(t, fuzzyMatch("timer-source", "tmr"))
+	assert.Assert(t, fuzzyMatch("timer-source", "timersrc"))
+	assert.Assert(t, !fuzzyMatch("timer-source", "rmt"))
+	assert.Assert(t, fuzzyMatch("kafka-source", "src"))
+	assert.Assert(t, !fuzzyMatch("streams-source", "tmr"))
+	assert.Assert(t, !fuzzyMatch("", "t"))
+}
+
+func searchKameletList() *camelkapis.KameletList {
+	kafka := createKamelet("kafka-source")
+	kafka.Spec.Definition.Title = "Kafka Source"
+	myKafka := createKamelet("my-kafka-source")
+	streams := createKamelet("streams-source")
+	streams.Spec.Definition.Title = "Kafka Streams"
+	events := createKamelet("events-source")
+	events.Spec.Definition.Description = "Receive events from a Kafka topic"
+	timer := createKamelet("timer-source")
+	sink := createKamelet("kafka-sink")
+	sink.Labels["camel.apache.org/kamelet.type"] = "sink"
+
+	return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*events, *timer, *streams, *myKafka, *kafka, *sink}}
+}
+
+func runSearchCmd(c *kamelettesting.MockKameletClient, options ...string) (string, error) {
+	p := KameletPluginParams{
+		KnParams: &commands.KnParams{},
+		Context:  context.TODO(),
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+	}
+
+	searchCmd, _, output := commands.CreateSourcesTestKnCommand(NewSearchCommand(&p), p.KnParams)
+
+	args := []string{"search"}
+	args = append(args, options...)
+	searchCmd.SetArgs(args)
+	err := searchCmd.Execute()
+
+	return output.String(), err
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+
+	knerrors "knative.dev/client/pkg/errors"
+)
+
+// hasSecretProperties returns true when sensitive property values are given that are stored in a Secret
+func (f *bindingFlags) hasSecretProperties() bool {
+	return len(f.SourceSecretProperties) > 0 || len(f.SinkSecretProperties) > 0
+}
+
+// propertySecret moves the values of the secret property flags into a Secret named after the binding and sets the
+// binding properties to placeholders resolved from the Secret at runtime, so the values never land in the binding
+// spec. The Secret keys are prefixed with the endpoint, i.e. source.<key> and sink.<key>. Returns nil when no secret
+// property is given.
+func (f *bindingFlags) propertySecret(binding *v1alpha1.KameletBinding) (*corev1.Secret, error) {
+	if !f.hasSecretProperties() {
+		return nil, nil
+	}
+	if binding.Name == "" {
+		return nil, errors.New("secret properties require the binding name, they can not be combined with --generate-name")
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      binding.Name,
+			Namespace: binding.Namespace,
+		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: map[string]string{},
+	}
+	for _, endpoint := range []struct {
+		name       string
+		values     []string
+		properties **v1alpha1.EndpointProperties
+	}{
+		{"source", f.SourceSecretProperties, &binding.Spec.Source.Properties},
+		{"sink", f.SinkSecretProperties, &binding.Spec.Sink.Properties},
+	} {
+		if len(endpoint.values) == 0 {
+			continue
+		}
+		properties, err := kameletapi.RawEndpointProperties(*endpoint.properties)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range endpoint.values {
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("invalid %s secret property %q, expected <key>=<value>", endpoint.name, value)
+			}
+			key := endpoint.name + "." + parts[0]
+			secret.StringData[key] = parts[1]
+			properties[parts[0]] = fmt.Sprintf("{{secret:%s/%s}}", secret.Name, key)
+		}
+		data, err := json.Marshal(properties)
+		if err != nil {
+			return nil, err
+		}
+		*endpoint.properties = &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}
+	}
+	return secret, nil
+}
+
+// applyPropertySecret creates the Secret holding the secret properties of a binding or adds the values to the
+// existing Secret, values of other keys are preserved
+func (params *KameletPluginParams) applyPropertySecret(secret *corev1.Secret, serverDryRun bool, out io.Writer) error {
+	var dryRun []string
+	var dryRunSuffix string
+	audit := params.auditLog()
+	if serverDryRun {
+		dryRun = []string{v1.DryRunAll}
+		dryRunSuffix = " (server dry run)"
+		audit = nil
+	}
+
+	keys := make([]string, 0, len(secret.StringData))
+	for key := range secret.StringData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	client, err := params.NewKubeClient()
+	if err != nil {
+		return err
+	}
+	secrets := client.CoreV1().Secrets(secret.Namespace)
+	existing, err := secrets.Get(params.Context, secret.Name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(params.Context, secret, v1.CreateOptions{DryRun: dryRun, FieldManager: fieldManager})
+		if auditErr := audit.record("create", secret.Kind, secret.Namespace, secret.Name, keys, err); auditErr != nil {
+			return auditErr
+		}
+		if err != nil {
+			return knerrors.GetError(err)
+		}
+		fmt.Fprintf(out, "Secret '%s' created in namespace '%s'%s.\n", secret.Name, secret.Namespace, dryRunSuffix)
+		return nil
+	} else if err != nil {
+		return knerrors.GetError(err)
+	}
+
+	updated := existing.DeepCopy()
+	if updated.StringData == nil {
+		updated.StringData = map[string]string{}
+	}
+	for key, value := range secret.StringData {
+		updated.StringData[key] = value
+	}
+	_, err = secrets.Update(params.Context, updated, v1.UpdateOptions{DryRun: dryRun, FieldManager: fieldManager})
+	if auditErr := audit.record("update", secret.Kind, secret.Namespace, secret.Name, keys, err); auditErr != nil {
+		return auditErr
+	}
+	if err != nil {
+		return knerrors.GetError(err)
+	}
+	fmt.Fprintf(out, "Secret '%s' updated in namespace '%s'%s.\n", secret.Name, secret.Namespace, dryRunSuffix)
+	return nil
+}
+
+// preparePropertySecret returns the Secret storing the secret properties of the binding, it is applied by
+// submitBindings once the binding is verified. On client dry-run nothing is rendered, the values must never end up in
+// manifests.
+func preparePropertySecret(flags *bindingFlags, binding *v1alpha1.KameletBinding, dryRun string) (*corev1.Secret, error) {
+	secret, err := flags.propertySecret(binding)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	if dryRun == dryRunClient {
+		return nil, errors.New("secret properties are stored in a Secret on the cluster, they can not be combined with --offline or --dry-run client")
+	}
+	return secret, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindSecretProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	kubeClient := fake.NewSimpleClientset()
+	p := secretPropertyParams(mockClient, kubeClient)
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(&camelkapis.KameletBinding{}, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage),
+			`{"message":"Hello","token":"{{secret:k1-to-broker-default/source.token}}"}`)
+		assert.Equal(t, string(binding.Spec.Sink.Properties.RawMessage),
+			`{"password":"{{secret:k1-to-broker-default/sink.password}}"}`)
+	}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--source-property", "message=Hello",
+		"--source-secret-property", "token=s3cr3t", "--sink-secret-property", "password=pa55")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Secret 'k1-to-broker-default' created in namespace 'current'.", "KameletBinding 'k1-to-broker-default' created"))
+	assert.Check(t, util.ContainsNone(output, "s3cr3t", "pa55"))
+
+	secret, err := kubeClient.CoreV1().Secrets("current").Get(context.TODO(), "k1-to-broker-default", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, secret.StringData, map[string]string{"source.token": "s3cr3t", "sink.password": "pa55"})
+	recorder.Validate()
+}
+
+func TestBindingUpdateSecretProperty(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "b1", Namespace: "default"},
+		StringData: map[string]string{"source.token": "old", "source.user": "admin"},
+	})
+	p := secretPropertyParams(mockClient, kubeClient)
+
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.UpdateBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {
+		assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"token":"{{secret:b1/source.token}}"}`)
+	}, nil)
+	output, err := runPipeCmd(p, NewBindingCommand(p), "binding", "update", "b1", "-n", "default", "--source-secret-property", "token=new")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Secret 'b1' updated in namespace 'default'.", "KameletBinding 'b1' updated"))
+
+	secret, err := kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "b1", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, secret.StringData, map[string]string{"source.token": "new", "source.user": "admin"})
+	recorder.Validate()
+}
+
+func TestBindSecretPropertyVerificationFailed(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	kubeClient := fake.NewSimpleClientset()
+	p := secretPropertyParams(mockClient, kubeClient)
+
+	// the Secret is only created once the binding is verified
+	recorder.Get(nil, notFound("k1"))
+	_, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--source-secret-property", "token=s3cr3t")
+	assert.ErrorContains(t, err, "k1")
+
+	secrets, err := kubeClient.CoreV1().Secrets("current").List(context.TODO(), v1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(secrets.Items), 0)
+	recorder.Validate()
+}
+
+func TestBindSecretPropertyErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := secretPropertyParams(mockClient, fake.NewSimpleClientset())
+
+	_, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--source-secret-property", "token=s3cr3t", "--offline")
+	assert.Error(t, err, "secret properties are stored in a Secret on the cluster, they can not be combined with --offline or --dry-run client")
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--source-secret-property", "token=s3cr3t", "--generate-name")
+	assert.Error(t, err, "secret properties require the binding name, they can not be combined with --generate-name")
+
+	_, err = runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--sink-secret-property", "password")
+	assert.Error(t, err, "invalid sink secret property \"password\", expected <key>=<value>")
+	recorder.Validate()
+}
+
+func secretPropertyParams(c *kamelettesting.MockKameletClient, kubeClient kubernetes.Interface) *KameletPluginParams {
+	return &KameletPluginParams{
+		KnParams:          &commands.KnParams{},
+		Context:           context.TODO(),
+		UseKameletBinding: true,
+		NewKameletClient: func() (camelkv1alpha1.CamelV1alpha1Interface, error) {
+			return c, nil
+		},
+		NewKubeClient: func() (kubernetes.Interface, error) {
+			return kubeClient, nil
+		},
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// signatureSuffix is appended to the file or URL of a manifest to locate its signature
+const signatureSuffix = ".sig"
+
+// signatureFlags holds the flags verifying the signatures of manifests and catalog releases
+type signatureFlags struct {
+	verify              bool
+	keyFile             string
+	certificateIdentity string
+}
+
+// addFlags adds the signature flags, usage describes where the signatures are read from
+func (f *signatureFlags) addFlags(flags *pflag.FlagSet, usage string) {
+	flags.BoolVar(&f.verify, "verify-signature", false, usage)
+	flags.StringVar(&f.keyFile, "key", "", "PEM encoded public key the signatures are verified with, e.g. cosign.pub written by 'cosign generate-key-pair'.")
+	flags.StringVar(&f.certificateIdentity, "certificate-identity", "", "Identity of the certificate of keyless signatures. Keyless verification is not supported, sign with a key pair and verify with --key instead.")
+}
+
+// verifier returns the verifier of the public key given with --key, nil when signatures are not verified. Keyless
+// signatures require the Fulcio certificate chain and a Rekor transparency log lookup, which are not supported.
+func (f *signatureFlags) verifier() (*signatureVerifier, error) {
+	if f.certificateIdentity != "" {
+		return nil, errors.New("keyless signature verification with --certificate-identity is not supported, verify the signatures with the public key given with --key")
+	}
+	if !f.verify {
+		if f.keyFile != "" {
+			return nil, errors.New("--key is only used with --verify-signature")
+		}
+		return nil, nil
+	}
+	if f.keyFile == "" {
+		return nil, errors.New("--verify-signature requires the public key given with --key")
+	}
+	return newSignatureVerifier(f.keyFile)
+}
+
+// signatureVerifier verifies the signatures of manifests created with 'cosign sign-blob --key', i.e. base64 encoded
+// ECDSA, RSA or Ed25519 signatures of the manifest
+type signatureVerifier struct {
+	key crypto.PublicKey
+}
+
+// newSignatureVerifier loads the PEM encoded public key of given file
+func newSignatureVerifier(keyFile string) (*signatureVerifier, error) {
+	data, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded public key found in %s", keyFile)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %w", keyFile, err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return &signatureVerifier{key: key}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T in %s", key, keyFile)
+}
+
+// verify checks the base64 encoded signature of the manifest read from given source
+func (v *signatureVerifier) verify(manifest []byte, signature []byte, source string) error {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid signature of manifest %s: %w", source, err)
+	}
+
+	digest := sha256.Sum256(manifest)
+	var valid bool
+	switch key := v.key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], decoded)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], decoded) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, manifest, decoded)
+	}
+	if !valid {
+		return fmt.Errorf("signature verification of manifest %s failed", source)
+	}
+	return nil
+}
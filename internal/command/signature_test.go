@@ -0,0 +1,197 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKameletInstallVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	dir := t.TempDir()
+	keyFile := writePublicKey(t, dir, key.Public())
+	signature := signManifest(t, key, []byte(installKamelet))
+
+	file := filepath.Join(dir, "my-source.kamelet.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(installKamelet), 0600))
+	assert.NilError(t, ioutil.WriteFile(file+".sig", signature, 0600))
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	output, err := runKameletInstallCmd(p, "", "-f", file, "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "kind: Kamelet", "name: my-source"))
+
+	tampered := filepath.Join(dir, "tampered.kamelet.yaml")
+	assert.NilError(t, ioutil.WriteFile(tampered, []byte(installKamelet+"    # tampered\n"), 0600))
+	assert.NilError(t, ioutil.WriteFile(tampered+".sig", signature, 0600))
+	_, err = runKameletInstallCmd(p, "", "-f", tampered, "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.Error(t, err, fmt.Sprintf("signature verification of manifest %s failed", tampered))
+
+	unsigned := filepath.Join(t.TempDir(), "unsigned.kamelet.yaml")
+	assert.NilError(t, ioutil.WriteFile(unsigned, []byte(installKamelet), 0600))
+	_, err = runKameletInstallCmd(p, "", "-f", unsigned, "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.ErrorContains(t, err, fmt.Sprintf("failed to read signature of manifest %s", unsigned))
+
+	_, err = runKameletInstallCmd(p, installKamelet, "-f", "-", "--verify-signature", "--key", keyFile)
+	assert.Error(t, err, "signatures of manifests read from stdin can not be verified")
+
+	_, err = runKameletInstallCmd(p, "", "-f", file, "--verify-signature")
+	assert.Error(t, err, "--verify-signature requires the public key given with --key")
+
+	_, err = runKameletInstallCmd(p, "", "-f", file, "--key", keyFile)
+	assert.Error(t, err, "--key is only used with --verify-signature")
+
+	_, err = runKameletInstallCmd(p, "", "-f", file, "--verify-signature", "--certificate-identity", "release@example.com")
+	assert.ErrorContains(t, err, "keyless signature verification with --certificate-identity is not supported")
+
+	_, err = runKameletInstallCmd(p, "", "-f", file, "--verify-signature", "--key", file)
+	assert.Error(t, err, fmt.Sprintf("no PEM encoded public key found in %s", file))
+}
+
+func TestKameletInstallVerifySignatureURL(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	keyFile := writePublicKey(t, t.TempDir(), key.Public())
+	signature := signManifest(t, key, []byte(installKamelet))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/my-source.kamelet.yaml", "/unsigned.kamelet.yaml":
+			fmt.Fprint(w, installKamelet)
+		case "/my-source.kamelet.yaml.sig":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	p := cacheParams(t, kamelettesting.NewMockKameletClient(t))
+
+	output, err := runKameletInstallCmd(p, "", "-f", server.URL+"/my-source.kamelet.yaml", "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "name: my-source"))
+
+	_, err = runKameletInstallCmd(p, "", "-f", server.URL+"/unsigned.kamelet.yaml", "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.Error(t, err, fmt.Sprintf("failed to download manifest %s/unsigned.kamelet.yaml.sig: 404 Not Found", server.URL))
+}
+
+func TestCatalogUpgradeVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	keyFile := writePublicKey(t, t.TempDir(), key.Public())
+	archive := catalogArchive(t, map[string]string{
+		"camel-kamelets-0.4.0/kafka-sink.kamelet.yaml": fmt.Sprintf(catalogKamelet, "kafka-sink", "sink", "Kafka Sink", "Kafka Sink"),
+	})
+	signature := signManifest(t, key, archive)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apache/camel-kamelets/archive/v0.4.0.tar.gz", "/apache/camel-kamelets/archive/v0.5.0.tar.gz":
+			w.Write(archive)
+		case "/apache/camel-kamelets/archive/v0.4.0.tar.gz.sig":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	p := cacheParams(t, mockClient)
+	p.Config = &PluginConfig{CatalogRepository: server.URL + "/apache/camel-kamelets"}
+
+	recorder.List(&camelkapis.KameletList{}, nil)
+	output, err := runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.4.0", "--dry-run", "client", "--verify-signature", "--key", keyFile)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Kamelet 'kafka-sink' would be added."))
+
+	// the cached release is downloaded again to verify its signature
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherKeyFile := writePublicKey(t, t.TempDir(), otherKey.Public())
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.4.0", "--verify-signature", "--key", otherKeyFile)
+	assert.Error(t, err, fmt.Sprintf("signature verification of manifest %s/apache/camel-kamelets/archive/v0.4.0.tar.gz failed", server.URL))
+
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.5.0", "--verify-signature", "--key", keyFile)
+	assert.Error(t, err, "failed to download signature of Kamelet catalog v0.5.0: 404 Not Found")
+
+	_, err = runPipeCmd(p, NewCatalogCommand(p), "catalog", "upgrade", "--catalog-version", "v0.4.0", "--verify-signature", "--certificate-identity", "release@example.com")
+	assert.ErrorContains(t, err, "keyless signature verification with --certificate-identity is not supported")
+	recorder.Validate()
+}
+
+func TestSignatureVerifierKeyTypes(t *testing.T) {
+	manifest := []byte(installKamelet)
+	digest := sha256.Sum256(manifest)
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	assert.NilError(t, err)
+	verifier, err := newSignatureVerifier(writePublicKey(t, dir, rsaKey.Public()))
+	assert.NilError(t, err)
+	assert.NilError(t, verifier.verify(manifest, []byte(base64.StdEncoding.EncodeToString(rsaSignature)), "rsa"))
+	assert.Error(t, verifier.verify(manifest, []byte("not base64!"), "rsa"), "invalid signature of manifest rsa: illegal base64 data at input byte 3")
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	verifier, err = newSignatureVerifier(writePublicKey(t, dir, public))
+	assert.NilError(t, err)
+	assert.NilError(t, verifier.verify(manifest, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, manifest))), "ed25519"))
+	assert.Error(t, verifier.verify(manifest, []byte(base64.StdEncoding.EncodeToString(rsaSignature)), "ed25519"), "signature verification of manifest ed25519 failed")
+}
+
+// writePublicKey writes the PEM encoded public key to the directory and returns its file
+func writePublicKey(t *testing.T, dir string, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NilError(t, err)
+	file := filepath.Join(dir, "cosign.pub")
+	assert.NilError(t, ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return file
+}
+
+// signManifest returns the signature of the manifest as written by 'cosign sign-blob'
+func signManifest(t *testing.T, key *ecdsa.PrivateKey, manifest []byte) []byte {
+	digest := sha256.Sum256(manifest)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	assert.NilError(t, err)
+	return []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// sortByFields maps the short sort keys of the --sort-by flag to their JSONPath expression
+var sortByFields = map[string]string{
+	"name":  ".metadata.name",
+	"age":   ".metadata.creationTimestamp",
+	"phase": ".status.phase",
+}
+
+// addSortByFlag adds the flag sorting the items of list commands
+func addSortByFlag(flags *pflag.FlagSet, sortBy *string) {
+	flags.StringVar(sortBy, "sort-by", "", "Sort the listed resources by name, age (oldest first), phase or given JSONPath expression, e.g. '.metadata.namespace'.")
+}
+
+// parseSortBy returns the JSONPath expression of the sort key, nil if no sort key is given
+func parseSortBy(sortBy string) (*jsonpath.JSONPath, error) {
+	if sortBy == "" {
+		return nil, nil
+	}
+
+	expression := sortBy
+	if field, ok := sortByFields[sortBy]; ok {
+		expression = field
+	} else if !strings.HasPrefix(sortBy, ".") && !strings.HasPrefix(sortBy, "{") {
+		return nil, fmt.Errorf("invalid sort key %q, expected one of name|age|phase or a JSONPath expression", sortBy)
+	}
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	path := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := path.Parse(expression); err != nil {
+		return nil, fmt.Errorf("invalid sort key %q: %w", sortBy, err)
+	}
+	return path, nil
+}
+
+// sortList sorts the items of the list by the value of the JSONPath expression, numbers are compared numerically
+// and items without value are sorted first. The order of items with the same value is kept.
+func sortList(list runtime.Object, path *jsonpath.JSONPath) error {
+	if path == nil {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	type sortItem struct {
+		obj runtime.Object
+		key string
+	}
+	sorted := make([]sortItem, 0, len(items))
+	for _, item := range items {
+		key, err := sortKey(item, path)
+		if err != nil {
+			return err
+		}
+		sorted = append(sorted, sortItem{obj: item, key: key})
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lessSortKey(sorted[i].key, sorted[j].key)
+	})
+	for i := range sorted {
+		items[i] = sorted[i].obj
+	}
+	return meta.SetList(list, items)
+}
+
+// sortKey returns the value of the JSONPath expression on given item
+func sortKey(item runtime.Object, path *jsonpath.JSONPath) (string, error) {
+	// JSON round trip honors the custom marshalling of raw message fields used in Camel K types
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", err
+	}
+
+	results, err := path.FindResults(obj)
+	if err != nil {
+		return "", err
+	}
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, fmt.Sprintf("%v", value.Interface()))
+		}
+	}
+	return strings.Join(values, ","), nil
+}
+
+func lessSortKey(a string, b string) bool {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return x < y
+		}
+	}
+	return a < b
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestListTypesSortBy(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	now := time.Now()
+	kamelet1 := createKamelet("k1")
+	kamelet1.CreationTimestamp = v1.NewTime(now.Add(-time.Minute))
+	kamelet2 := createKamelet("k2")
+	kamelet2.CreationTimestamp = v1.NewTime(now.Add(-time.Hour))
+	kamelet2.Status.Phase = camelkapis.KameletPhaseError
+	kamelet3 := createKamelet("k3")
+	kamelet3.CreationTimestamp = v1.NewTime(now.Add(-24 * time.Hour))
+	kameletList := func() *camelkapis.KameletList {
+		return &camelkapis.KameletList{Items: []camelkapis.Kamelet{*kamelet2, *kamelet3, *kamelet1}}
+	}
+
+	for _, tc := range []struct {
+		sortBy string
+		names  []string
+	}{
+		{"name", []string{"k1", "k2", "k3"}},
+		{"age", []string{"k3", "k2", "k1"}},
+		{"phase", []string{"k2", "k3", "k1"}},
+		{"{.spec.definition.title}", []string{"k1", "k2", "k3"}},
+	} {
+		recorder.List(kameletList(), nil)
+		output, err := runListTypesCmd(mockClient, "--sort-by", tc.sortBy, "--no-headers")
+		assert.NilError(t, err)
+		outputLines := strings.Split(output, "\n")
+		for i, name := range tc.names {
+			assert.Check(t, strings.HasPrefix(outputLines[i], name+" "), "sort by %s: line %d is %q", tc.sortBy, i, outputLines[i])
+		}
+	}
+
+	_, err := runListTypesCmd(mockClient, "--sort-by", "size")
+	assert.Error(t, err, "invalid sort key \"size\", expected one of name|age|phase or a JSONPath expression")
+
+	recorder.Validate()
+}
+
+func TestBindingListSortBy(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.ListBindings(&camelkapis.KameletBindingList{Items: []camelkapis.KameletBinding{
+		*createKameletBindingInNamespace("b1", "k1", "ns2"),
+		*createKameletBindingInNamespace("b2", "k2", "ns1"),
+	}}, nil)
+
+	output, err := runBindingListCmd(mockClient, "--all-namespaces", "--sort-by", ".metadata.namespace")
+	assert.NilError(t, err)
+	outputLines := strings.Split(output, "\n")
+	assert.Check(t, util.ContainsAll(outputLines[1], "ns1", "b2"))
+	assert.Check(t, util.ContainsAll(outputLines[2], "ns2", "b1"))
+
+	recorder.Validate()
+}
+
+func TestSortListNumeric(t *testing.T) {
+	path, err := parseSortBy(".spec.integration.replicas")
+	assert.NilError(t, err)
+
+	bindings := &camelkapis.KameletBindingList{}
+	for _, replicas := range []int32{10, 2, 1} {
+		count := replicas
+		binding := createKameletBinding("b", "k1")
+		binding.Spec.Integration = &camelv1.IntegrationSpec{Replicas: &count}
+		bindings.Items = append(bindings.Items, *binding)
+	}
+
+	assert.NilError(t, sortList(bindings, path))
+	var sorted []int32
+	for _, binding := range bindings.Items {
+		sorted = append(sorted, *binding.Spec.Integration.Replicas)
+	}
+	assert.DeepEqual(t, sorted, []int32{1, 2, 10})
+
+	_, err = parseSortBy("{.metadata.name")
+	assert.ErrorContains(t, err, "invalid sort key \"{.metadata.name\"")
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
+	kameletapi "knative.dev/kn-plugin-source-kamelet/pkg/kamelet"
+)
+
+// kameletBindingResource is used to manage KameletBindings with steps, the typed v1alpha1 client does not know
+// spec.steps of newer Camel K versions and would drop them
+var kameletBindingResource = v1alpha1.SchemeGroupVersion.WithResource("kameletbindings")
+
+// stepFlags holds the action Kamelets the events pass between source and sink
+type stepFlags struct {
+	Steps          []string
+	StepProperties []string
+}
+
+// addFlags adds the --step and --step-property flags to given flag set
+func (f *stepFlags) addFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&f.Steps, "step", nil, "Add an action Kamelet the events pass between source and sink, repeat the flag to add multiple steps in the given order.")
+	flags.StringArrayVar(&f.StepProperties, "step-property", nil, "Add a property of a step in the form of \"<step>:<key>=<value>\", where <step> is the name of the action Kamelet given by --step.")
+}
+
+// endpoints returns the steps as Kamelet endpoints in given namespace, the step properties are added to the step of
+// the same name
+func (f *stepFlags) endpoints(namespace string) ([]v1alpha1.Endpoint, error) {
+	properties := map[string]map[string]string{}
+	for _, step := range f.Steps {
+		if _, ok := properties[step]; ok {
+			return nil, fmt.Errorf("step %s is given more than once", step)
+		}
+		properties[step] = map[string]string{}
+	}
+	for _, property := range f.StepProperties {
+		parts := strings.SplitN(property, ":", 2)
+		var keyValue []string
+		if len(parts) == 2 {
+			keyValue = strings.SplitN(parts[1], "=", 2)
+		}
+		if len(keyValue) != 2 || parts[0] == "" || keyValue[0] == "" {
+			return nil, fmt.Errorf("invalid step property %q, expected <step>:<key>=<value>", property)
+		}
+		stepProperties, ok := properties[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("step property %q refers to step %s which is not given by --step", property, parts[0])
+		}
+		stepProperties[keyValue[0]] = keyValue[1]
+	}
+
+	endpoints := make([]v1alpha1.Endpoint, 0, len(f.Steps))
+	for _, step := range f.Steps {
+		stepProperties, err := kameletapi.ToEndpointProperties(properties[step])
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, v1alpha1.Endpoint{
+			Ref: &corev1.ObjectReference{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       v1alpha1.KameletKind,
+				Namespace:  namespace,
+				Name:       step,
+			},
+			Properties: stepProperties,
+		})
+	}
+	return endpoints, nil
+}
+
+// verifySteps checks that the Kamelets referenced as steps are actions and verifies their properties
+func verifySteps(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, binding *v1alpha1.KameletBinding, steps []v1alpha1.Endpoint, options verifyOptions, out io.Writer) error {
+	for i := range steps {
+		if err := verifyKameletEndpoint(ctx, client, binding, &steps[i], "action", options, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyOfflineSteps verifies the steps against the Kamelets of the local cache or the bundled catalog without
+// accessing the cluster, steps referencing Kamelets that are not cached are not verified
+func (params *KameletPluginParams) verifyOfflineSteps(steps []v1alpha1.Endpoint, options verifyOptions, out io.Writer) error {
+	for i := range steps {
+		if kamelet, ok := params.offlineKamelet(steps[i].Ref.Namespace, steps[i].Ref.Name); ok {
+			if err := verifyKameletProperties(kamelet, &steps[i], "action", options, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setSteps sets the steps on the unstructured binding or Pipe manifest
+func setSteps(manifest *unstructured.Unstructured, steps []v1alpha1.Endpoint) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+	var values []interface{}
+	if err := utiljson.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	return unstructured.SetNestedSlice(manifest.Object, values, "spec", "steps")
+}
+
+// applyBindingWithSteps creates or updates the KameletBinding including its steps using the dynamic client
+func applyBindingWithSteps(ctx context.Context, client dynamic.Interface, binding *v1alpha1.KameletBinding, steps []v1alpha1.Endpoint, update updateOptions, serverDryRun bool, audit *auditLog, out io.Writer) (*unstructured.Unstructured, error) {
+	obj, err := bindingManifest(binding, false)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := sanitize(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := setSteps(manifest, steps); err != nil {
+		return nil, err
+	}
+	return applyManifest(ctx, client.Resource(kameletBindingResource).Namespace(binding.Namespace), manifest, binding, update, serverDryRun, audit, out)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func createActionKamelet(name string) *camelkapis.Kamelet {
+	kamelet := createKamelet(name)
+	kamelet.Labels["camel.apache.org/kamelet.type"] = "action"
+	return kamelet
+}
+
+func TestBindSteps(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.Get(createActionKamelet("json-deserialize-action"), nil)
+	recorder.Get(createActionKamelet("predicate-filter-action"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(camelkapis.SchemeGroupVersion.WithKind("KameletBinding"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(camelkapis.SchemeGroupVersion.WithKind("KameletBindingList"), &unstructured.UnstructuredList{})
+	dynamicClient := applyClient(scheme)
+	p := pipeParams(mockClient, dynamicClient)
+	p.UseKameletBinding = true
+
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default",
+		"--step", "json-deserialize-action", "--step", "predicate-filter-action", "--step-property", "predicate-filter-action:expression=@.foo =~ /.*bar.*/")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "KameletBinding 'k1-to-broker-default' created in namespace 'current'"))
+
+	binding, err := dynamicClient.Resource(kameletBindingResource).Namespace("current").Get(context.TODO(), "k1-to-broker-default", v1.GetOptions{})
+	assert.NilError(t, err)
+	steps, _, _ := unstructured.NestedSlice(binding.Object, "spec", "steps")
+	assert.Equal(t, len(steps), 2)
+	first, _, _ := unstructured.NestedString(steps[0].(map[string]interface{}), "ref", "name")
+	assert.Equal(t, first, "json-deserialize-action")
+	expression, _, _ := unstructured.NestedString(steps[1].(map[string]interface{}), "properties", "expression")
+	assert.Equal(t, expression, "@.foo =~ /.*bar.*/")
+	source, _, _ := unstructured.NestedString(binding.Object, "spec", "source", "ref", "name")
+	assert.Equal(t, source, "k1")
+	recorder.Validate()
+}
+
+func TestBindStepsPipe(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+	recorder.Get(createActionKamelet("a1"), nil)
+	recorder.Get(createKamelet("k1"), nil)
+
+	dynamicClient := applyClient(pipeScheme())
+	p := pipeParams(mockClient, dynamicClient)
+
+	output, err := runPipeCmd(p, NewBindCommand(p), "bind", "k1", "--broker", "default", "--step", "a1")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "Pipe 'k1-to-broker-default' created"))
+
+	pipe, err := dynamicClient.Resource(pipeResource).Namespace("current").Get(context.TODO(), "k1-to-broker-default", v1.GetOptions{})
+	assert.NilError(t, err)
+	steps, _, _ := unstructured.NestedSlice(pipe.Object, "spec", "steps")
+	assert.Equal(t, len(steps), 1)
+	recorder.Validate()
+}
+
+func TestBindStepsOffline(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "-n", "test",
+		"--step", "a1", "--step", "a2", "--step-property", "a2:limit=10")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, `  steps:
+  - ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: a1
+      namespace: test
+  - properties:
+      limit: "10"
+    ref:
+      apiVersion: camel.apache.org/v1alpha1
+      kind: Kamelet
+      name: a2
+      namespace: test
+`))
+	recorder.Validate()
+}
+
+func TestBindStepsErrors(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	_, err := runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--step", "a1", "--step-property", "a1=limit")
+	assert.Error(t, err, "invalid step property \"a1=limit\", expected <step>:<key>=<value>")
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--step", "a1", "--step-property", "a2:limit=10")
+	assert.Error(t, err, "step property \"a2:limit=10\" refers to step a2 which is not given by --step")
+
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--offline", "--step", "a1", "--step", "a1")
+	assert.Error(t, err, "step a1 is given more than once")
+
+	recorder.Get(createKamelet("k2"), nil)
+	_, err = runBindCmd(mockClient, "k1", "--broker", "default", "--step", "k2")
+	assert.Error(t, err, "Kamelet k2 is not an action")
+	recorder.Validate()
+}
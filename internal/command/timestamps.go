@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	hprinters "knative.dev/client/pkg/printers"
+)
+
+// timestampFormat is the format of absolute timestamps in tables
+const timestampFormat = "2006-01-02T15:04:05Z"
+
+// addShowTimestampsFlag adds the flag replacing the age column of list commands by the creation timestamp
+func addShowTimestampsFlag(flags *pflag.FlagSet, showTimestamps *bool) {
+	flags.BoolVar(showTimestamps, "show-timestamps", false, "Show the creation timestamp in UTC instead of the age of the listed resources.")
+}
+
+// formatTimestamp returns the timestamp in UTC, zero timestamps are unknown
+func formatTimestamp(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return timestamp.UTC().Format(timestampFormat)
+}
+
+// timestampColumns returns the columns with the age column replaced by the creation timestamp column
+func timestampColumns(columns []metav1beta1.TableColumnDefinition) []metav1beta1.TableColumnDefinition {
+	replaced := make([]metav1beta1.TableColumnDefinition, 0, len(columns))
+	for _, column := range columns {
+		if column.Name == "Age" {
+			column = metav1beta1.TableColumnDefinition{Name: "Created", Type: "string", Description: "Creation timestamp in UTC", Priority: column.Priority}
+		}
+		replaced = append(replaced, column)
+	}
+	return replaced
+}
+
+// showTimestamps returns a function replacing the age cell of printed rows by the creation timestamp of the row
+// object, the cell is located by the position of the age column in given columns
+func showTimestamps(columns []metav1beta1.TableColumnDefinition, options hprinters.PrintOptions) func([]metav1beta1.TableRow, error) ([]metav1beta1.TableRow, error) {
+	index, cell := -1, 0
+	for _, column := range columns {
+		// the namespace column is only printed for all namespaces
+		if !options.AllNamespaces && column.Priority == 0 {
+			continue
+		}
+		if column.Name == "Age" {
+			index = cell
+			break
+		}
+		cell++
+	}
+
+	return func(rows []metav1beta1.TableRow, err error) ([]metav1beta1.TableRow, error) {
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			if index < 0 || index >= len(rows[i].Cells) {
+				continue
+			}
+			obj, err := meta.Accessor(rows[i].Object.Object)
+			if err != nil {
+				return nil, err
+			}
+			rows[i].Cells[index] = formatTimestamp(obj.GetCreationTimestamp())
+		}
+		return rows, nil
+	}
+}
+
+// timestampRowPrinter wraps the row printer of watch events to show the creation timestamp instead of the age
+func timestampRowPrinter(columns []metav1beta1.TableColumnDefinition, printRows func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error)) func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+	return func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error) {
+		return showTimestamps(columns, options)(printRows(obj, options))
+	}
+}
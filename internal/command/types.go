@@ -18,9 +18,19 @@ package command
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"time"
 
 	camelk "github.com/apache/camel-k/pkg/client/camel/clientset/versioned"
 	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"knative.dev/client/pkg/kn/commands"
 )
 
@@ -30,6 +40,50 @@ type KameletPluginParams struct {
 	Context          context.Context
 	ContextCancel    context.CancelFunc
 	NewKameletClient func() (camelkv1alpha1.CamelV1alpha1Interface, error)
+	NewKubeClient    func() (kubernetes.Interface, error)
+	NewDynamicClient func() (dynamic.Interface, error)
+
+	// Quiet suppresses informational messages such as progress and warnings, errors and requested output such as
+	// printed resources are still written
+	Quiet bool
+
+	// NoColor disables colored phase and ready state values in tables and descriptions
+	NoColor bool
+
+	// AuditLogFile enables the audit log of all mutations when set
+	AuditLogFile string
+	audit        *auditLog
+
+	// Verbosity enables logging of the API requests, LogOutput defaults to stderr
+	Verbosity int
+	LogOutput io.Writer
+
+	// ImpersonateUser and ImpersonateGroups are the user and groups the API requests are performed as
+	ImpersonateUser   string
+	ImpersonateGroups []string
+
+	// RequestTimeout, QPS and Burst tune the API requests, zero values keep the client defaults
+	RequestTimeout time.Duration
+	QPS            float32
+	Burst          int
+
+	// Retries is the number of retries of API requests failing with transient errors, RetryInterval the delay of the
+	// first retry that doubles with each further retry
+	Retries       int
+	RetryInterval time.Duration
+
+	// ConfigFile overrides the location of the plugin config file, Config holds the loaded config
+	ConfigFile string
+	Config     *PluginConfig
+
+	// CacheDir overrides the default plugin cache directory, Refresh bypasses the cached Kamelets
+	CacheDir string
+	Refresh  bool
+
+	// UsePipe and UseKameletBinding override the discovery of the API used to manage bindings
+	UsePipe           bool
+	UseKameletBinding bool
+	pipesServed       *bool
 }
 
 func (params *KameletPluginParams) Initialize() {
@@ -41,6 +95,106 @@ func (params *KameletPluginParams) Initialize() {
 	if params.NewKameletClient == nil {
 		params.NewKameletClient = params.newKameletClient
 	}
+
+	if params.NewKubeClient == nil {
+		params.NewKubeClient = params.newKubeClient
+	}
+
+	if params.NewDynamicClient == nil {
+		params.NewDynamicClient = params.newDynamicClient
+	}
+}
+
+// AddQuietFlag adds the flag suppressing informational messages to given flag set
+func (params *KameletPluginParams) AddQuietFlag(flags *pflag.FlagSet) {
+	flags.BoolVarP(&params.Quiet, "quiet", "q", false, "Only print errors and requested output such as resources printed with --output, suppressing progress messages and warnings.")
+}
+
+// messageOutput returns the writer of informational messages, messages are discarded with --quiet
+func (params *KameletPluginParams) messageOutput(out io.Writer) io.Writer {
+	if params.Quiet {
+		return ioutil.Discard
+	}
+	return out
+}
+
+// AddKubeconfigFlags adds the flags selecting the kubeconfig file, context and cluster the plugin connects to
+func (params *KameletPluginParams) AddKubeconfigFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&params.KubeCfgPath, "kubeconfig", "", "Path to the kubeconfig file (default: $KUBECONFIG or ~/.kube/config).")
+	flags.StringVar(&params.KubeContext, "context", "", "Name of the kubeconfig context to use instead of the current context.")
+	flags.StringVar(&params.KubeCluster, "cluster", "", "Name of the kubeconfig cluster to use instead of the cluster of the context.")
+}
+
+// AddImpersonationFlags adds the flags impersonating a user or service account for all API requests
+func (params *KameletPluginParams) AddImpersonationFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&params.ImpersonateUser, "as", "", "Username to impersonate for the operation, e.g. system:serviceaccount:<namespace>:<name>.")
+	flags.StringArrayVar(&params.ImpersonateGroups, "as-group", nil, "Group to impersonate for the operation, can be repeated to specify multiple groups.")
+}
+
+// RestConfig returns the REST config of the cluster with the timeout, rate limits and impersonation given by flag,
+// the transport is wrapped to log the API requests according to the configured verbosity and to retry requests
+// failing with transient errors
+func (params *KameletPluginParams) RestConfig() (*rest.Config, error) {
+	// the logging transport of the knative client does not redact response bodies, so it is replaced
+	logHTTP := params.LogHTTP
+	params.LogHTTP = false
+	defer func() {
+		params.LogHTTP = logHTTP
+	}()
+
+	config, err := params.KnParams.RestConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if params.RequestTimeout < 0 || params.QPS < 0 || params.Burst < 0 {
+		return nil, errors.New("--request-timeout, --qps and --burst must not be negative")
+	}
+	if params.RequestTimeout > 0 {
+		config.Timeout = params.RequestTimeout
+	}
+	if params.QPS > 0 {
+		config.QPS = params.QPS
+	}
+	if params.Burst > 0 {
+		config.Burst = params.Burst
+	}
+
+	if params.Retries < 0 || params.RetryInterval < 0 {
+		return nil, errors.New("--retries and --retry-interval must not be negative")
+	}
+
+	if params.ImpersonateUser != "" || len(params.ImpersonateGroups) > 0 {
+		if params.ImpersonateUser == "" {
+			return nil, errors.New("--as-group requires --as to specify the user to impersonate")
+		}
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: params.ImpersonateUser,
+			Groups:   params.ImpersonateGroups,
+		}
+	}
+
+	verbosity := params.Verbosity
+	if logHTTP && verbosity < verbosityHTTP {
+		verbosity = verbosityHTTP
+	}
+	if verbosity >= verbosityRequests {
+		params.wrapLoggingTransport(config, verbosity)
+	}
+	// each retry is logged as separate request
+	if params.Retries > 0 {
+		params.wrapRetryTransport(config, verbosity)
+	}
+	return config, nil
+}
+
+// AddClientFlags adds the flags tuning the timeout, rate limiting and retries of the API requests
+func (params *KameletPluginParams) AddClientFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&params.RequestTimeout, "request-timeout", 0, "Timeout of a single API request, e.g. 30s or 2m, zero means no timeout. Also ends --watch streams when expired.")
+	flags.Float32Var(&params.QPS, "qps", 0, "Maximum queries per second sent to the API server (default: 5).")
+	flags.IntVar(&params.Burst, "burst", 0, "Maximum burst of queries sent to the API server above the --qps rate (default: 10).")
+	flags.IntVar(&params.Retries, "retries", 0, "Number of retries of API requests failing with 429 Too Many Requests, a server error of an idempotent request or a connection error, and of updates failing with a conflict. Zero disables retries.")
+	flags.DurationVar(&params.RetryInterval, "retry-interval", defaultRetryInterval, "Delay before the first retry, each further retry doubles the delay.")
 }
 
 func (params *KameletPluginParams) newKameletClient() (camelkv1alpha1.CamelV1alpha1Interface, error) {
@@ -49,6 +203,9 @@ func (params *KameletPluginParams) newKameletClient() (camelkv1alpha1.CamelV1alp
 		return nil, err
 	}
 
+	// Kamelet custom resources are only served as JSON
+	useJSONContentType(restConfig)
+
 	client, err := camelk.NewForConfig(restConfig)
 	if err != nil {
 		return nil, err
@@ -56,3 +213,68 @@ func (params *KameletPluginParams) newKameletClient() (camelkv1alpha1.CamelV1alp
 
 	return client.CamelV1alpha1(), nil
 }
+
+func (params *KameletPluginParams) newKubeClient() (kubernetes.Interface, error) {
+	restConfig, err := params.RestConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	useProtobufContentType(restConfig)
+
+	return kubernetes.NewForConfig(restConfig)
+}
+
+func (params *KameletPluginParams) newDynamicClient() (dynamic.Interface, error) {
+	restConfig, err := params.RestConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// arbitrary resources are not guaranteed to support protobuf
+	useJSONContentType(restConfig)
+
+	return dynamic.NewForConfig(restConfig)
+}
+
+// currentContext returns the name and settings of the current kubeconfig context, best effort
+// as the context is only used for informational purpose and caching.
+func (params *KameletPluginParams) currentContext() (string, *clientcmdapi.Context) {
+	clientConfig := params.ClientConfig
+	if clientConfig == nil {
+		clientConfig, _ = params.GetClientConfig()
+	}
+	if clientConfig == nil {
+		return "", nil
+	}
+
+	config, err := clientConfig.RawConfig()
+	if err != nil {
+		return "", nil
+	}
+
+	// the raw config does not apply the context and cluster given by flag
+	name := config.CurrentContext
+	if params.KubeContext != "" {
+		name = params.KubeContext
+	}
+	kubeContext := config.Contexts[name]
+	if kubeContext != nil && params.KubeCluster != "" {
+		kubeContext = kubeContext.DeepCopy()
+		kubeContext.Cluster = params.KubeCluster
+	}
+	return name, kubeContext
+}
+
+// useProtobufContentType negotiates protobuf with the API server for built-in types
+// and falls back to JSON for resources that do not support it.
+func useProtobufContentType(config *rest.Config) {
+	config.ContentType = runtime.ContentTypeProtobuf
+	config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+}
+
+// useJSONContentType forces JSON, which is required for custom resources.
+func useJSONContentType(config *rest.Config) {
+	config.ContentType = runtime.ContentTypeJSON
+	config.AcceptContentTypes = runtime.ContentTypeJSON
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	"knative.dev/client/pkg/kn/commands"
+	"knative.dev/client/pkg/util"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProtobufContentType(t *testing.T) {
+	config := &rest.Config{}
+	useProtobufContentType(config)
+
+	assert.Equal(t, config.ContentType, "application/vnd.kubernetes.protobuf")
+	assert.Equal(t, config.AcceptContentTypes, "application/vnd.kubernetes.protobuf,application/json")
+}
+
+func TestJSONContentType(t *testing.T) {
+	config := &rest.Config{}
+	useProtobufContentType(config)
+	useJSONContentType(config)
+
+	assert.Equal(t, config.ContentType, "application/json")
+	assert.Equal(t, config.AcceptContentTypes, "application/json")
+}
+
+func TestKubeconfigFlags(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	assert.NilError(t, ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    namespace: dev-ns
+- name: prod
+  context:
+    cluster: prod
+    namespace: prod-ns
+current-context: dev
+`), 0600))
+
+	p := &KameletPluginParams{KnParams: &commands.KnParams{}}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.AddKubeconfigFlags(flags)
+	assert.NilError(t, flags.Parse([]string{"--kubeconfig", kubeconfig, "--context", "prod", "--cluster", "dev"}))
+
+	namespace, err := p.CurrentNamespace()
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "prod-ns")
+
+	config, err := p.RestConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Host, "https://dev.example.com:6443")
+
+	name, kubeContext := p.currentContext()
+	assert.Equal(t, name, "prod")
+	assert.Equal(t, kubeContext.Cluster, "dev")
+}
+
+func TestImpersonationFlags(t *testing.T) {
+	p, log := loggingParams(t)
+	p.Verbosity = verbosityHTTP
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.AddImpersonationFlags(flags)
+	assert.NilError(t, flags.Parse([]string{"--as", "system:serviceaccount:test:deployer", "--as-group", "ci", "--as-group", "deployers"}))
+
+	getSecret(t, p)
+	assert.Check(t, util.ContainsAll(log.String(), "Impersonate-User: system:serviceaccount:test:deployer", "Impersonate-Group: ci", "Impersonate-Group: deployers"))
+
+	p.ImpersonateUser = ""
+	_, err := p.RestConfig()
+	assert.Error(t, err, "--as-group requires --as to specify the user to impersonate")
+}
+
+func TestClientFlags(t *testing.T) {
+	p, _ := loggingParams(t)
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.AddClientFlags(flags)
+
+	config, err := p.RestConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Timeout, time.Duration(0))
+	assert.Equal(t, config.QPS, float32(0))
+
+	assert.NilError(t, flags.Parse([]string{"--request-timeout", "30s", "--qps", "50", "--burst", "100"}))
+	config, err = p.RestConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Timeout, 30*time.Second)
+	assert.Equal(t, config.QPS, float32(50))
+	assert.Equal(t, config.Burst, 100)
+
+	assert.NilError(t, flags.Parse([]string{"--qps", "-1"}))
+	_, err = p.RestConfig()
+	assert.Error(t, err, "--request-timeout, --qps and --burst must not be negative")
+
+	assert.NilError(t, flags.Parse([]string{"--qps", "50", "--retries", "-1"}))
+	_, err = p.RestConfig()
+	assert.Error(t, err, "--retries and --retry-interval must not be negative")
+}
@@ -16,7 +16,10 @@ package command
 
 import (
 	"fmt"
+	"runtime/debug"
+	"strings"
 
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
 	"github.com/spf13/cobra"
 )
 
@@ -24,17 +27,61 @@ var Version string
 var BuildDate string
 var GitRevision string
 
+// knativeClientModule is the module path of the knative client library the plugin is built with
+const knativeClientModule = "knative.dev/client"
+
+// VersionInfo holds the build metadata of the plugin
+type VersionInfo struct {
+	Version              string   `json:"version"`
+	BuildDate            string   `json:"buildDate"`
+	GitRevision          string   `json:"gitRevision"`
+	CamelKAPIVersions    []string `json:"camelKAPIVersions"`
+	KnativeClientVersion string   `json:"knativeClientVersion"`
+}
+
+// GetVersionInfo returns the build metadata of the plugin
+func GetVersionInfo() VersionInfo {
+	return VersionInfo{
+		Version:              Version,
+		BuildDate:            BuildDate,
+		GitRevision:          GitRevision,
+		CamelKAPIVersions:    []string{v1alpha1.SchemeGroupVersion.String(), pipeAPIVersion},
+		KnativeClientVersion: moduleVersion(knativeClientModule),
+	}
+}
+
 // NewVersionCommand implements 'kn-source-kamelet version' command
 func NewVersionCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Prints the plugin version",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			info := GetVersionInfo()
 			out := cmd.OutOrStdout()
-			fmt.Fprintf(out, "Version:      %s\n", Version)
-			fmt.Fprintf(out, "Build Date:   %s\n", BuildDate)
-			fmt.Fprintf(out, "Git Revision: %s\n", GitRevision)
+			fmt.Fprintf(out, "Version:        %s\n", info.Version)
+			fmt.Fprintf(out, "Build Date:     %s\n", info.BuildDate)
+			fmt.Fprintf(out, "Git Revision:   %s\n", info.GitRevision)
+			fmt.Fprintf(out, "Camel K APIs:   %s\n", strings.Join(info.CamelKAPIVersions, ", "))
+			fmt.Fprintf(out, "Knative Client: %s\n", info.KnativeClientVersion)
 			return nil
 		},
 	}
 }
+
+// moduleVersion returns the version of given dependency recorded in the binary, honoring replacements
+func moduleVersion(path string) string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
@@ -22,9 +22,11 @@ import (
 	"gotest.tools/v3/assert"
 )
 
-var versionOutputTemplate = `Version:      %s
-Build Date:   %s
-Git Revision: %s
+var versionOutputTemplate = `Version:        %s
+Build Date:     %s
+Git Revision:   %s
+Camel K APIs:   camel.apache.org/v1alpha1, camel.apache.org/v1
+Knative Client: %s
 `
 
 const (
@@ -44,13 +46,21 @@ func TestVersionOutput(t *testing.T) {
 	Version = fakeVersion
 	BuildDate = fakeBuildDate
 	GitRevision = fakeGitRevision
-	expectedOutput := fmt.Sprintf(versionOutputTemplate, fakeVersion, fakeBuildDate, fakeGitRevision)
+	expectedOutput := fmt.Sprintf(versionOutputTemplate, fakeVersion, fakeBuildDate, fakeGitRevision, moduleVersion(knativeClientModule))
 
 	out, err := runVersionCmd()
 	assert.NilError(t, err)
 	assert.Equal(t, out, expectedOutput)
 }
 
+func TestVersionInfo(t *testing.T) {
+	Version = fakeVersion
+	info := GetVersionInfo()
+	assert.Equal(t, info.Version, fakeVersion)
+	assert.DeepEqual(t, info.CamelKAPIVersions, []string{"camel.apache.org/v1alpha1", "camel.apache.org/v1"})
+	assert.Assert(t, info.KnativeClientVersion != "")
+}
+
 func runVersionCmd() (string, error) {
 	versionCmd := NewVersionCommand()
 
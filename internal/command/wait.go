@@ -0,0 +1,183 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/client/pkg/kn/commands"
+
+	knerrors "knative.dev/client/pkg/errors"
+	knflags "knative.dev/client/pkg/kn/flags"
+)
+
+// waitPollInterval defines how often the binding status is polled while waiting
+var waitPollInterval = 2 * time.Second
+
+// addWaitFlags adds the --wait, --no-wait and --wait-timeout flags to given command, waiting is disabled by default
+func addWaitFlags(cmd *cobra.Command, waitFlags *commands.WaitFlags) {
+	knflags.AddBothBoolFlagsUnhidden(cmd.Flags(), &waitFlags.Wait, "wait", "", false, "Wait for the KameletBinding to become ready.")
+	cmd.Flags().IntVar(&waitFlags.TimeoutInSeconds, "wait-timeout", commands.WaitDefaultTimeout, "Seconds to wait before giving up on waiting for the KameletBinding to be ready.")
+}
+
+// waitForBindingReady polls the binding status until the Ready condition is True. Returns an error
+// when the binding reports the Error phase or the timeout is reached.
+func waitForBindingReady(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, namespace string, name string, timeout time.Duration, out io.Writer) error {
+	get := func(ctx context.Context) (*v1alpha1.KameletBinding, error) {
+		return client.KameletBindings(namespace).Get(ctx, name, v1.GetOptions{})
+	}
+	return waitForReady(ctx, v1alpha1.KameletBindingKind, namespace, name, timeout, get, out)
+}
+
+// waitForPipeReady polls the Pipe status until the Ready condition is True
+func waitForPipeReady(ctx context.Context, client dynamic.Interface, namespace string, name string, timeout time.Duration, out io.Writer) error {
+	get := func(ctx context.Context) (*v1alpha1.KameletBinding, error) {
+		return getPipe(ctx, client, namespace, name)
+	}
+	return waitForReady(ctx, pipeKind, namespace, name, timeout, get, out)
+}
+
+// waitForReady polls the status of the binding of given kind until it is ready, fails or the timeout is reached
+func waitForReady(ctx context.Context, kind string, namespace string, name string, timeout time.Duration, get func(ctx context.Context) (*v1alpha1.KameletBinding, error), out io.Writer) error {
+	fmt.Fprintf(out, "Waiting for %s '%s' to become ready ...\n", kind, name)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	progress := newWaitProgress(out, start)
+	defer progress.done()
+	for {
+		binding, err := get(ctx)
+		if err != nil && ctx.Err() == nil {
+			return knerrors.GetError(err)
+		}
+
+		if err == nil {
+			progress.update(binding)
+			if binding.Status.Phase == v1alpha1.KameletBindingPhaseError {
+				if reason := bindingNonReadyReason(binding.Status.Conditions); reason != "" {
+					return fmt.Errorf("%s '%s' in namespace '%s' failed: %s", kind, name, namespace, reason)
+				}
+				return fmt.Errorf("%s '%s' in namespace '%s' failed", kind, name, namespace)
+			}
+			if bindingReadyCondition(binding.Status.Conditions) == string(corev1.ConditionTrue) {
+				progress.done()
+				fmt.Fprintf(out, "%s '%s' in namespace '%s' is ready after %s.\n", kind, name, namespace, time.Since(start).Round(time.Second))
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return withExitCode(ExitCodeTimeout, fmt.Errorf("timeout: %s '%s' in namespace '%s' not ready after %s", kind, name, namespace, timeout))
+		case <-ticker.C:
+		}
+	}
+}
+
+// bindingNonReadyReason returns formatted string of reason and message of the Ready condition
+func bindingNonReadyReason(conditions []v1alpha1.KameletBindingCondition) string {
+	for _, condition := range conditions {
+		if condition.Type == v1alpha1.KameletBindingConditionReady && condition.Status != corev1.ConditionTrue {
+			if condition.Message != "" {
+				return fmt.Sprintf("%s : %s", condition.Reason, condition.Message)
+			}
+			return condition.Reason
+		}
+	}
+	return ""
+}
+
+// waitProgress reports the phase and the latest condition message of the binding while waiting. On a terminal the
+// progress line is rewritten in place together with the elapsed time, otherwise a line is printed on every change.
+type waitProgress struct {
+	out      io.Writer
+	start    time.Time
+	terminal bool
+	last     string
+	pending  bool
+}
+
+func newWaitProgress(out io.Writer, start time.Time) *waitProgress {
+	terminal := out
+	if writer, ok := out.(*syncWriter); ok {
+		// the output shared by concurrent workers is a terminal when the output it serializes is
+		terminal = writer.out
+	}
+	return &waitProgress{out: out, start: start, terminal: isTerminalOutput(terminal)}
+}
+
+// update reports the current state of the binding
+func (w *waitProgress) update(binding *v1alpha1.KameletBinding) {
+	state := string(binding.Status.Phase)
+	if state == "" {
+		state = "Pending"
+	}
+	if message := latestConditionMessage(binding.Status.Conditions); message != "" {
+		state = state + ": " + message
+	}
+
+	if w.terminal {
+		// carriage return and erase the line to replace the previous progress
+		fmt.Fprintf(w.out, "\r\033[K  %s (%s)", state, time.Since(w.start).Round(time.Second))
+		w.pending = true
+		return
+	}
+	if state != w.last {
+		fmt.Fprintf(w.out, "  %s\n", state)
+	}
+	w.last = state
+}
+
+// done terminates the progress line on a terminal
+func (w *waitProgress) done() {
+	if w.pending {
+		fmt.Fprintln(w.out)
+		w.pending = false
+	}
+}
+
+// latestConditionMessage returns the message of the most recently transitioned condition that has a message
+func latestConditionMessage(conditions []v1alpha1.KameletBindingCondition) string {
+	var latest *v1alpha1.KameletBindingCondition
+	for i := range conditions {
+		condition := &conditions[i]
+		if condition.Message == "" {
+			continue
+		}
+		if latest == nil || latest.LastTransitionTime.Before(&condition.LastTransitionTime) {
+			latest = condition
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Message
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/client/pkg/util"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWaitForBindingReady(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.GetBinding(creatingBinding("b1"), nil)
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+
+	out := &bytes.Buffer{}
+	err := waitForBindingReady(context.TODO(), mockClient, "default", "b1", time.Second, out)
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(out.String(), "Waiting", "b1", "is ready"))
+	recorder.Validate()
+}
+
+func TestWaitForBindingProgress(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	creating := creatingBinding("b1")
+	creating.Status.Conditions[0].Message = "Integration b1 is building"
+	recorder.GetBinding(creating, nil)
+	recorder.GetBinding(creating, nil)
+	starting := creatingBinding("b1")
+	starting.Status.Conditions[0].Message = "0/1 pods ready"
+	recorder.GetBinding(starting, nil)
+	recorder.GetBinding(createKameletBinding("b1", "k1"), nil)
+
+	out := &bytes.Buffer{}
+	err := waitForBindingReady(context.TODO(), mockClient, "default", "b1", time.Second, out)
+	assert.NilError(t, err)
+	assert.Equal(t, out.String(), `Waiting for KameletBinding 'b1' to become ready ...
+  Creating: Integration b1 is building
+  Creating: 0/1 pods ready
+  Ready
+KameletBinding 'b1' in namespace 'default' is ready after 0s.
+`)
+	recorder.Validate()
+}
+
+func TestBindWaitProgressOnTerminal(t *testing.T) {
+	defer fastPolling()()
+	defer func(isTerminal func(io.Writer) bool) {
+		isTerminalOutput = isTerminal
+	}(isTerminalOutput)
+	// only the command output is a terminal, the writers wrapping it are not
+	isTerminalOutput = func(out io.Writer) bool {
+		_, ok := out.(*bytes.Buffer)
+		return ok
+	}
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	recorder.Get(createKamelet("k1"), nil)
+	recorder.GetBinding(nil, notFound("k1-to-broker-default"))
+	recorder.ApplyBinding(func(t *testing.T, binding *camelkapis.KameletBinding) {}, func(t *testing.T, opts v1.PatchOptions) {}, nil)
+	recorder.GetBinding(creatingBinding("k1-to-broker-default"), nil)
+	recorder.GetBinding(createKameletBinding("k1-to-broker-default", "k1"), nil)
+
+	output, err := runBindCmd(mockClient, "k1", "--broker", "default", "--wait")
+	assert.NilError(t, err)
+	assert.Check(t, util.ContainsAll(output, "\r\033[K  Creating (0s)", "\r\033[K  Ready (0s)\n", "is ready"))
+	recorder.Validate()
+}
+
+func TestWaitForBindingErrorPhase(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	binding := createKameletBinding("b1", "k1")
+	binding.Status.Phase = camelkapis.KameletBindingPhaseError
+	binding.Status.Conditions[0].Status = corev1.ConditionFalse
+	binding.Status.Conditions[0].Reason = "IntegrationError"
+	binding.Status.Conditions[0].Message = "Kamelet not found"
+	recorder.GetBinding(binding, nil)
+
+	err := waitForBindingReady(context.TODO(), mockClient, "default", "b1", time.Second, &bytes.Buffer{})
+	assert.Error(t, err, "KameletBinding 'b1' in namespace 'default' failed: IntegrationError : Kamelet not found")
+	recorder.Validate()
+}
+
+func TestWaitForBindingTimeout(t *testing.T) {
+	defer fastPolling()()
+
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	for i := 0; i < 10; i++ {
+		recorder.GetBinding(creatingBinding("b1"), nil)
+	}
+
+	err := waitForBindingReady(context.TODO(), mockClient, "default", "b1", 25*time.Millisecond, &bytes.Buffer{})
+	assert.Error(t, err, "timeout: KameletBinding 'b1' in namespace 'default' not ready after 25ms")
+	assert.Equal(t, ExitCode(err), ExitCodeTimeout)
+}
+
+func creatingBinding(name string) *camelkapis.KameletBinding {
+	binding := createKameletBinding(name, "k1")
+	binding.Status.Phase = camelkapis.KameletBindingPhaseCreating
+	binding.Status.Conditions[0].Status = corev1.ConditionUnknown
+	return binding
+}
+
+// fastPolling reduces the wait poll interval and returns a function restoring the default
+func fastPolling() func() {
+	interval := waitPollInterval
+	waitPollInterval = 10 * time.Millisecond
+	return func() {
+		waitPollInterval = interval
+	}
+}
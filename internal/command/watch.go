@@ -0,0 +1,213 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/client/pkg/kn/commands/flags"
+	hprinters "knative.dev/client/pkg/printers"
+)
+
+// addWatchFlag adds the flag streaming the changes of listed resources
+func addWatchFlag(flags *pflag.FlagSet, watchChanges *bool) {
+	flags.BoolVarP(watchChanges, "watch", "w", false, "After listing the resources, watch for changes and print them as they occur until interrupted.")
+}
+
+// eventPrinter prints the resources of watch events, tables are prefixed with the event type
+// while any other output format prints the changed resources
+type eventPrinter struct {
+	out       io.Writer
+	printer   hprinters.ResourcePrinter
+	columns   []metav1beta1.TableColumnDefinition
+	printRows func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error)
+	options   hprinters.PrintOptions
+}
+
+// newEventPrinter returns the event printer for the output format given by the list print flags,
+// custom columns and other formats handled by the commands themselves are not supported
+func newEventPrinter(listFlags *flags.ListPrintFlags, columns []metav1beta1.TableColumnDefinition,
+	printRows func(obj runtime.Object, options hprinters.PrintOptions) ([]metav1beta1.TableRow, error), out io.Writer) (*eventPrinter, error) {
+	if listFlags.GenericPrintFlags.OutputFlagSpecified() {
+		output := *listFlags.GenericPrintFlags.OutputFormat
+		if strings.HasPrefix(output, customColumnsPrefix) || output == "wide" {
+			return nil, fmt.Errorf("--watch does not support output format %q", output)
+		}
+		printer, err := listFlags.GenericPrintFlags.ToPrinter()
+		if err != nil {
+			return nil, err
+		}
+		return &eventPrinter{out: out, printer: printer}, nil
+	}
+
+	return &eventPrinter{
+		out:       out,
+		columns:   columns,
+		printRows: printRows,
+		options: hprinters.PrintOptions{
+			AllNamespaces: listFlags.HumanReadableFlags.WithNamespace,
+			NoHeaders:     listFlags.HumanReadableFlags.NoHeaders,
+		},
+	}, nil
+}
+
+// printHeader prints the table header including the event type column
+func (p *eventPrinter) printHeader() error {
+	if p.printer != nil || p.options.NoHeaders {
+		return nil
+	}
+	headers := []string{"EVENT"}
+	for _, column := range p.columns {
+		// the namespace column is only printed for all namespaces
+		if !p.options.AllNamespaces && column.Priority == 0 {
+			continue
+		}
+		headers = append(headers, strings.ToUpper(column.Name))
+	}
+	dw := hprinters.NewPrefixWriter(p.out)
+	dw.WriteColsLn(headers...)
+	return dw.Flush()
+}
+
+// printEvent prints the resource of the event
+func (p *eventPrinter) printEvent(eventType watch.EventType, obj runtime.Object) error {
+	if p.printer != nil {
+		return p.printer.PrintObj(obj, p.out)
+	}
+
+	rows, err := p.printRows(obj, p.options)
+	if err != nil {
+		return err
+	}
+	dw := hprinters.NewPrefixWriter(p.out)
+	for _, row := range rows {
+		cells := []string{string(eventType)}
+		for _, cell := range row.Cells {
+			cells = append(cells, fmt.Sprint(cell))
+		}
+		dw.WriteColsLn(cells...)
+	}
+	return dw.Flush()
+}
+
+// watchResyncPeriod is the period the informers of watches resync their cache
+var watchResyncPeriod = 10 * time.Minute
+
+// watchEvents handles the changes of the resources of given list and watch functions until the context is canceled
+// or handling a change fails. The changes are observed by an informer, so the watch resumes from bookmarks and is
+// reestablished when it expires or the API server restarts. The informer starts from the initial list, its items are
+// expected to be handled already so only their later changes are reported.
+func watchEvents(ctx context.Context, lw *cache.ListWatch, initial runtime.Object, objType runtime.Object, handle func(eventType watch.EventType, obj runtime.Object) error) error {
+	items, err := meta.ExtractList(initial)
+	if err != nil {
+		return err
+	}
+	listed := make(map[runtime.Object]bool, len(items))
+	for _, item := range items {
+		listed[item] = true
+	}
+
+	// the first list of the informer is served from the initial list
+	list := lw.ListFunc
+	initialList := true
+	lw.ListFunc = func(options v1.ListOptions) (runtime.Object, error) {
+		if initialList {
+			initialList = false
+			return initial, nil
+		}
+		return list(options)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var handleErr error
+	notify := func(eventType watch.EventType, obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		object, ok := obj.(runtime.Object)
+		if !ok || handleErr != nil {
+			return
+		}
+		if err := handle(eventType, object); err != nil {
+			handleErr = err
+			cancel()
+		}
+	}
+
+	informer := cache.NewSharedIndexInformer(lw, objType, watchResyncPeriod, cache.Indexers{})
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if object, ok := obj.(runtime.Object); ok && listed[object] {
+				delete(listed, object)
+				return
+			}
+			notify(watch.Added, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// resyncs and relists report unchanged resources as updated
+			if oldObj == newObj || sameResourceVersion(oldObj, newObj) {
+				return
+			}
+			notify(watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			notify(watch.Deleted, obj)
+		},
+	})
+	informer.Run(ctx.Done())
+	return handleErr
+}
+
+// selectorListWatch returns the list and watch functions of an informer, restricted to the label and field selectors
+// of given list options
+func selectorListWatch(selectors v1.ListOptions, list func(opts v1.ListOptions) (runtime.Object, error), watchFunc func(opts v1.ListOptions) (watch.Interface, error)) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector, options.FieldSelector = selectors.LabelSelector, selectors.FieldSelector
+			return list(options)
+		},
+		WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector, options.FieldSelector = selectors.LabelSelector, selectors.FieldSelector
+			return watchFunc(options)
+		},
+	}
+}
+
+// sameResourceVersion checks if both resources have the same resource version
+func sameResourceVersion(oldObj, newObj interface{}) bool {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldMeta.GetResourceVersion() != "" && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
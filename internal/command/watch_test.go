@@ -0,0 +1,116 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/client/pkg/kn/commands"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWatchEventsReconnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, modified := createKamelet("k1"), createKamelet("k1")
+	created.ResourceVersion, modified.ResourceVersion = "1", "2"
+	initial := &camelkapis.KameletList{ListMeta: v1.ListMeta{ResourceVersion: "1"}, Items: []camelkapis.Kamelet{*created}}
+
+	// the first watch closes, the informer relists and reports the changes missed in between
+	first := watch.NewFakeWithChanSize(1, false)
+	first.Stop()
+	second := watch.NewFakeWithChanSize(1, false)
+	second.Add(createKamelet("k3"))
+	watches := []watch.Interface{first, second}
+	var lists int
+	lw := &cache.ListWatch{
+		ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+			lists++
+			return &camelkapis.KameletList{ListMeta: v1.ListMeta{ResourceVersion: "2"}, Items: []camelkapis.Kamelet{*modified, *createKamelet("k2")}}, nil
+		},
+		WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+			w := watches[0]
+			if len(watches) > 1 {
+				watches = watches[1:]
+			}
+			return w, nil
+		},
+	}
+
+	var events []string
+	err := watchEvents(ctx, lw, initial, &camelkapis.Kamelet{}, func(eventType watch.EventType, obj runtime.Object) error {
+		events = append(events, string(eventType)+" "+obj.(*camelkapis.Kamelet).Name)
+		if len(events) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, lists, 1)
+	assert.DeepEqual(t, events, []string{"MODIFIED k1", "ADDED k2", "ADDED k3"})
+}
+
+// watchOutput collects the output of a watch command and cancels the watch once the output contains the expected text
+type watchOutput struct {
+	lock   sync.Mutex
+	buf    bytes.Buffer
+	until  string
+	cancel func()
+}
+
+func (w *watchOutput) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	n, err := w.buf.Write(p)
+	if strings.Contains(w.buf.String(), w.until) {
+		w.cancel()
+	}
+	return n, err
+}
+
+func (w *watchOutput) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.String()
+}
+
+// runWatchCmd runs the watch command until its output contains given text, the watch is canceled after a timeout
+func runWatchCmd(p *KameletPluginParams, cmd *cobra.Command, until string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	p.Context = ctx
+
+	watchCmd, _, _ := commands.CreateSourcesTestKnCommand(cmd, p.KnParams)
+	output := &watchOutput{until: until, cancel: cancel}
+	watchCmd.SetOut(output)
+	watchCmd.SetArgs(args)
+	err := watchCmd.Execute()
+	return output.String(), err
+}
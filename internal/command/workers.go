@@ -0,0 +1,103 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/pflag"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// defaultConcurrency is the number of workers used by bulk operations
+const defaultConcurrency = 10
+
+// addConcurrencyFlag adds the --concurrency flag to commands operating on many resources
+func addConcurrencyFlag(flags *pflag.FlagSet, concurrency *int) {
+	flags.IntVar(concurrency, "concurrency", defaultConcurrency, "Maximum number of resources processed in parallel.")
+}
+
+// runConcurrently calls fn for each index in [0, count) using at most concurrency workers.
+// The returned slice holds the error of each item at its index (nil on success).
+func runConcurrently(concurrency int, count int, fn func(i int) error) ([]error, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("invalid concurrency %d, must be greater than zero", concurrency)
+	}
+	if concurrency > count {
+		concurrency = count
+	}
+
+	errs := make([]error, count)
+	items := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+
+	return errs, nil
+}
+
+// aggregateErrors combines the per item errors returned by runConcurrently, a single failure is returned unchanged so
+// that its exit code is kept and nested aggregates are flattened
+func aggregateErrors(errs []error) error {
+	failed := nonNilErrors(errs)
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	}
+	return utilerrors.Flatten(utilerrors.NewAggregate(failed))
+}
+
+// nonNilErrors filters the per item errors returned by runConcurrently
+func nonNilErrors(errs []error) []error {
+	var result []error
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// syncWriter serializes the writes of concurrent workers to a shared output
+type syncWriter struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.out.Write(p)
+}
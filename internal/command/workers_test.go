@@ -0,0 +1,68 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	var running, maxRunning, processed int32
+
+	errs, err := runConcurrently(3, 20, func(i int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		atomic.AddInt32(&processed, 1)
+		if i%5 == 0 {
+			return fmt.Errorf("item %d failed", i)
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, processed, int32(20))
+	assert.Assert(t, maxRunning <= 3)
+	assert.Equal(t, len(errs), 20)
+	assert.Error(t, errs[5], "item 5 failed")
+	assert.NilError(t, errs[6])
+	assert.Equal(t, len(nonNilErrors(errs)), 4)
+}
+
+func TestRunConcurrentlyNoItems(t *testing.T) {
+	errs, err := runConcurrently(5, 0, func(i int) error {
+		return fmt.Errorf("should not be called")
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(errs), 0)
+}
+
+func TestRunConcurrentlyInvalidConcurrency(t *testing.T) {
+	_, err := runConcurrently(0, 5, func(i int) error {
+		return nil
+	})
+	assert.Error(t, err, "invalid concurrency 0, must be greater than zero")
+}
@@ -16,16 +16,49 @@ package root
 
 import (
 	"context"
+	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
+	"knative.dev/client/pkg/kn/commands/options"
+	"knative.dev/client/pkg/templates"
 	"knative.dev/kn-plugin-source-kamelet/internal/command"
 )
 
+// standaloneName is the name of the plugin binary, usage and examples of the commands refer to it
+const standaloneName = "kn-source-kamelet"
+
 // NewSourceKameletCommand represents the plugin's entrypoint
 func NewSourceKameletCommand() *cobra.Command {
+	return newSourceKameletCommand(standaloneName)
+}
+
+// NewInlineCommand represents the plugin's entrypoint when compiled into kn. The returned command is nested into
+// parent commands of given kn root name and command parts, e.g. 'kn source kamelet', so that usage, help and examples
+// refer to the command path used with kn. Executing the returned command executes the root of the command tree,
+// arguments must therefore include the command parts.
+func NewInlineCommand(knName string, commandParts ...string) *cobra.Command {
+	if len(commandParts) == 0 {
+		return newSourceKameletCommand(knName)
+	}
+
+	parent := &cobra.Command{Use: knName}
+	for _, part := range commandParts[:len(commandParts)-1] {
+		cmd := &cobra.Command{Use: part}
+		parent.AddCommand(cmd)
+		parent = cmd
+	}
+	rootCmd := newSourceKameletCommand(commandParts[len(commandParts)-1])
+	parent.AddCommand(rootCmd)
+
+	renameCommandPath(rootCmd, standaloneName, rootCmd.CommandPath())
+	return rootCmd
+}
+
+func newSourceKameletCommand(name string) *cobra.Command {
 
 	var rootCmd = &cobra.Command{
-		Use:   "kn-source-kamelet",
+		Use:   name,
 		Short: "Knative eventing Kamelet source plugin",
 		Long:  `Plugin manages Kamelets and KameletBindings as Knative eventing sources.`,
 	}
@@ -38,9 +71,64 @@ func NewSourceKameletCommand() *cobra.Command {
 	}
 	p.Initialize()
 
-	rootCmd.AddCommand(command.NewListTypesCommand(p))
-	rootCmd.AddCommand(command.NewDescribeTypeCommand(p))
-	rootCmd.AddCommand(command.NewVersionCommand())
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return p.ApplyConfig(cmd)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&p.AuditLogFile, "audit-log", "", "Append a structured record of every create, update and delete performed by the plugin to given file.")
+	p.AddKubeconfigFlags(rootCmd.PersistentFlags())
+	p.AddImpersonationFlags(rootCmd.PersistentFlags())
+	p.AddClientFlags(rootCmd.PersistentFlags())
+	p.AddCacheFlags(rootCmd.PersistentFlags())
+	p.AddBindingAPIFlags(rootCmd.PersistentFlags())
+	p.AddLoggingFlags(rootCmd.PersistentFlags())
+	p.AddQuietFlag(rootCmd.PersistentFlags())
+	p.AddNoColorFlag(rootCmd.PersistentFlags())
+
+	groups := templates.CommandGroups{
+		{
+			Header: "Kamelet Commands:",
+			Commands: []*cobra.Command{
+				command.NewListTypesCommand(p),
+				command.NewDescribeTypeCommand(p),
+				command.NewSearchCommand(p),
+				command.NewCatalogCommand(p),
+				command.NewKameletCommand(p),
+			},
+		},
+		{
+			Header: "Binding Commands:",
+			Commands: []*cobra.Command{
+				command.NewBindCommand(p),
+				command.NewBindingCommand(p),
+			},
+		},
+		{
+			Header: "Other Commands:",
+			Commands: []*cobra.Command{
+				command.NewCacheCommand(p),
+				options.NewOptionsCommand(),
+				command.NewVersionCommand(),
+			},
+		},
+	}
+	groups.AddTo(rootCmd)
+	groups.SetRootUsage(rootCmd, &template.FuncMap{
+		// plugins of kn are not available to the plugin commands
+		"listPlugins": func(*cobra.Command) string { return "" },
+	})
+
+	command.RegisterNamespaceCompletion(rootCmd, p)
 
 	return rootCmd
 }
+
+// renameCommandPath replaces the standalone command name in examples and help texts of given command and its
+// sub-commands with the command path used when the plugin is compiled into kn
+func renameCommandPath(cmd *cobra.Command, from string, to string) {
+	cmd.Example = strings.ReplaceAll(cmd.Example, from+" ", to+" ")
+	cmd.Long = strings.ReplaceAll(cmd.Long, from+" ", to+" ")
+	for _, sub := range cmd.Commands() {
+		renameCommandPath(sub, from, to)
+	}
+}
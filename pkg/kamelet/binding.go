@@ -0,0 +1,218 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kamelet provides the logic of the plugin to render, validate and create KameletBindings, e.g. for operators
+// and tools managing bindings without shelling out to the CLI.
+package kamelet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BindingOptions holds all settings needed to render a KameletBinding
+type BindingOptions struct {
+	Name                 string
+	Namespace            string
+	Kamelet              string
+	Sink                 string
+	SinkTypes            map[string]v1.TypeMeta
+	SinkNamespace        string
+	SourceProperties     map[string]string
+	SinkProperties       map[string]string
+	SourceJSONProperties map[string]json.RawMessage
+	SinkJSONProperties   map[string]json.RawMessage
+	RuntimeLogLevel      string
+	RuntimeLoggers       map[string]string
+	Labels               map[string]string
+	Annotations          map[string]string
+	Replicas             *int32
+}
+
+// NewBinding renders the KameletBinding for given options without accessing the cluster
+func NewBinding(options *BindingOptions) (*v1alpha1.KameletBinding, error) {
+	sink, err := DecodeSink(options.Sink, options.SinkTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetSinkNamespace(sink, options.SinkNamespace, options.Namespace); err != nil {
+		return nil, err
+	}
+
+	name := options.Name
+	if name == "" {
+		name = BindingName(options.Kamelet, sink)
+	}
+
+	sourceProperties, err := ToEndpointProperties(options.SourceProperties)
+	if err != nil {
+		return nil, err
+	}
+	if sourceProperties, err = SetRawProperties(sourceProperties, options.SourceJSONProperties); err != nil {
+		return nil, err
+	}
+
+	sinkProperties, err := ToEndpointProperties(options.SinkProperties)
+	if err != nil {
+		return nil, err
+	}
+	if sinkProperties, err = SetRawProperties(sinkProperties, options.SinkJSONProperties); err != nil {
+		return nil, err
+	}
+
+	integration, err := RuntimeLogging(options.RuntimeLogLevel, options.RuntimeLoggers)
+	if err != nil {
+		return nil, err
+	}
+	if options.Replicas != nil {
+		if integration == nil {
+			integration = &camelv1.IntegrationSpec{}
+		}
+		integration.Replicas = options.Replicas
+	}
+
+	return &v1alpha1.KameletBinding{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       v1alpha1.KameletBindingKind,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        name,
+			Namespace:   options.Namespace,
+			Labels:      options.Labels,
+			Annotations: options.Annotations,
+		},
+		Spec: v1alpha1.KameletBindingSpec{
+			Source: v1alpha1.Endpoint{
+				Ref: &corev1.ObjectReference{
+					APIVersion: v1alpha1.SchemeGroupVersion.String(),
+					Kind:       v1alpha1.KameletKind,
+					Namespace:  options.Namespace,
+					Name:       options.Kamelet,
+				},
+				Properties: sourceProperties,
+			},
+			Sink: v1alpha1.Endpoint{
+				Ref:        sink.Ref,
+				URI:        sink.URI,
+				Properties: sinkProperties,
+			},
+			Integration: integration,
+		},
+	}, nil
+}
+
+// CreateBinding renders the KameletBinding for given options, verifies the Kamelets referenced as source and sink
+// and their properties and creates the binding
+func CreateBinding(ctx context.Context, client camelkv1alpha1.CamelV1alpha1Interface, options *BindingOptions) (*v1alpha1.KameletBinding, error) {
+	binding, err := NewBinding(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range []struct {
+		kameletType string
+		endpoint    v1alpha1.Endpoint
+	}{{"source", binding.Spec.Source}, {"sink", binding.Spec.Sink}} {
+		ref := endpoint.endpoint.Ref
+		if ref == nil || ref.Kind != v1alpha1.KameletKind {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = binding.Namespace
+		}
+		kamelet, err := client.Kamelets(namespace).Get(ctx, ref.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyEndpoint(kamelet, endpoint.endpoint, endpoint.kameletType); err != nil {
+			return nil, err
+		}
+	}
+	return client.KameletBindings(binding.Namespace).Create(ctx, binding, v1.CreateOptions{})
+}
+
+// VerifyEndpoint checks that the Kamelet is of given type, i.e. source or sink, and validates the endpoint properties
+// against the Kamelet definition
+func VerifyEndpoint(kamelet *v1alpha1.Kamelet, endpoint v1alpha1.Endpoint, kameletType string) error {
+	if TypeOf(kamelet) != kameletType {
+		return fmt.Errorf("Kamelet %s is not an event %s", kamelet.Name, kameletType)
+	}
+	properties, err := DecodeEndpointProperties(endpoint.Properties)
+	if err != nil {
+		return err
+	}
+	if err := ValidateProperties(kamelet, properties); err != nil {
+		return err
+	}
+	return ValidateStructuredProperties(kamelet, endpoint.Properties)
+}
+
+// RuntimeLogLevels lists the log levels supported by the integration runtime
+var RuntimeLogLevels = []string{"trace", "debug", "info", "warn", "error"}
+
+// VerifyRuntimeLogLevel checks that the log level is supported by the integration runtime
+func VerifyRuntimeLogLevel(level string) error {
+	for _, supported := range RuntimeLogLevels {
+		if strings.ToLower(level) == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported runtime log level %q, expected one of: %s", level, strings.Join(RuntimeLogLevels, "|"))
+}
+
+// RuntimeLogging maps the root log level to the logging trait and each logger override
+// to a runtime property of the binding integration, returns nil when nothing is set.
+func RuntimeLogging(level string, loggers map[string]string) (*camelv1.IntegrationSpec, error) {
+	if level == "" && len(loggers) == 0 {
+		return nil, nil
+	}
+
+	integration := &camelv1.IntegrationSpec{}
+	if level != "" {
+		configuration, err := json.Marshal(map[string]string{"level": strings.ToUpper(level)})
+		if err != nil {
+			return nil, err
+		}
+		integration.Traits = map[string]camelv1.TraitSpec{
+			"logging": {
+				Configuration: camelv1.TraitConfiguration{RawMessage: configuration},
+			},
+		}
+	}
+
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		integration.Configuration = append(integration.Configuration, camelv1.ConfigurationSpec{
+			Type:  "property",
+			Value: fmt.Sprintf("quarkus.log.category.\"%s\".level=%s", name, strings.ToUpper(loggers[name])),
+		})
+	}
+	return integration, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kamelettesting "knative.dev/kn-plugin-source-kamelet/pkg/testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func newKamelet(name string) *v1alpha1.Kamelet {
+	return &v1alpha1.Kamelet{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{TypeLabel: "source"},
+		},
+		Spec: v1alpha1.KameletSpec{
+			Definition: &v1alpha1.JSONSchemaProps{},
+		},
+	}
+}
+
+func TestNewBinding(t *testing.T) {
+	binding, err := NewBinding(&BindingOptions{
+		Namespace:        "test",
+		Kamelet:          "k1",
+		Sink:             "service:display",
+		SourceProperties: map[string]string{"message": "Hello", "period": "1000"},
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, binding.Name, "k1-to-service-display")
+	assert.Equal(t, binding.Namespace, "test")
+	assert.Equal(t, binding.Kind, "KameletBinding")
+	assert.Equal(t, binding.Spec.Source.Ref.Kind, "Kamelet")
+	assert.Equal(t, binding.Spec.Source.Ref.Namespace, "test")
+	assert.Equal(t, string(binding.Spec.Source.Properties.RawMessage), `{"message":"Hello","period":"1000"}`)
+	assert.Equal(t, binding.Spec.Sink.Ref.Namespace, "test")
+
+	binding, err = NewBinding(&BindingOptions{
+		Name:      "my-binding",
+		Namespace: "test",
+		Kamelet:   "k1",
+		Sink:      "broker:default",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, binding.Name, "my-binding")
+	assert.Assert(t, binding.Spec.Source.Properties == nil)
+
+	binding, err = NewBinding(&BindingOptions{
+		Namespace:     "test",
+		Kamelet:       "k1",
+		Sink:          "broker:default",
+		SinkNamespace: "events",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, binding.Namespace, "test")
+	assert.Equal(t, binding.Spec.Source.Ref.Namespace, "test")
+	assert.Equal(t, binding.Spec.Sink.Ref.Namespace, "events")
+}
+
+func TestRuntimeLogging(t *testing.T) {
+	integration, err := RuntimeLogging("", nil)
+	assert.NilError(t, err)
+	assert.Assert(t, integration == nil)
+
+	integration, err = RuntimeLogging("debug", map[string]string{"org.apache.camel": "trace", "io.quarkus": "warn"})
+	assert.NilError(t, err)
+	assert.Equal(t, string(integration.Traits["logging"].Configuration.RawMessage), `{"level":"DEBUG"}`)
+	assert.Equal(t, len(integration.Configuration), 2)
+	assert.Equal(t, integration.Configuration[0].Type, "property")
+	assert.Equal(t, integration.Configuration[0].Value, `quarkus.log.category."io.quarkus".level=WARN`)
+	assert.Equal(t, integration.Configuration[1].Value, `quarkus.log.category."org.apache.camel".level=TRACE`)
+
+	integration, err = RuntimeLogging("", map[string]string{"org.apache.camel": "debug"})
+	assert.NilError(t, err)
+	assert.Assert(t, integration.Traits == nil)
+	assert.Equal(t, len(integration.Configuration), 1)
+}
+
+func TestCreateBinding(t *testing.T) {
+	mockClient := kamelettesting.NewMockKameletClient(t)
+	recorder := mockClient.Recorder()
+
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"message"}
+	recorder.Get(kamelet, nil)
+	recorder.CreateBinding(func(t *testing.T, binding *v1alpha1.KameletBinding) {
+		assert.Equal(t, binding.Name, "k1-to-broker-default")
+		assert.Equal(t, binding.Spec.Source.Ref.Name, "k1")
+	}, nil)
+	binding, err := CreateBinding(context.TODO(), mockClient, &BindingOptions{
+		Namespace:        "default",
+		Kamelet:          "k1",
+		Sink:             "broker:default",
+		SourceProperties: map[string]string{"message": "Hello"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, binding.Namespace, "default")
+
+	recorder.Get(kamelet, nil)
+	_, err = CreateBinding(context.TODO(), mockClient, &BindingOptions{Namespace: "default", Kamelet: "k1", Sink: "broker:default"})
+	assert.Error(t, err, "binding is missing required property \"message\" for Kamelet \"k1\"")
+
+	kamelet.Labels[TypeLabel] = "sink"
+	recorder.Get(kamelet, nil)
+	_, err = CreateBinding(context.TODO(), mockClient, &BindingOptions{Namespace: "default", Kamelet: "k1", Sink: "broker:default"})
+	assert.Error(t, err, "Kamelet k1 is not an event source")
+
+	recorder.Validate()
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	camelv1 "github.com/apache/camel-k/pkg/apis/camel/v1"
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+)
+
+// ValidateProperties checks that all required properties of the Kamelet are given and that the values
+// match the property definitions of the Kamelet
+func ValidateProperties(kamelet *v1alpha1.Kamelet, properties map[string]string) error {
+	if kamelet.Spec.Definition == nil {
+		return nil
+	}
+
+	for _, required := range kamelet.Spec.Definition.Required {
+		if _, ok := properties[required]; !ok {
+			return fmt.Errorf("binding is missing required property %q for Kamelet %q", required, kamelet.Name)
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema, ok := kamelet.Spec.Definition.Properties[name]
+		if !ok {
+			continue
+		}
+		if err := ValidatePropertyValue(kamelet, name, schema, properties[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyPropertyDefaults sets the default value defined by the Kamelet for all properties not given,
+// returns the updated properties and the sorted names of the defaulted properties
+func ApplyPropertyDefaults(kamelet *v1alpha1.Kamelet, existing *v1alpha1.EndpointProperties) (*v1alpha1.EndpointProperties, []string, error) {
+	if kamelet.Spec.Definition == nil {
+		return existing, nil, nil
+	}
+
+	properties, err := RawEndpointProperties(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var defaulted []string
+	for name, property := range kamelet.Spec.Definition.Properties {
+		if _, ok := properties[name]; ok || property.Default == nil || len(property.Default.RawMessage) == 0 {
+			continue
+		}
+		var value interface{}
+		if err := decodeJSON(property.Default.RawMessage, &value); err != nil {
+			return nil, nil, fmt.Errorf("invalid default value of property %q for Kamelet %q: %w", name, kamelet.Name, err)
+		}
+		properties[name] = value
+		defaulted = append(defaulted, name)
+	}
+	if len(defaulted) == 0 {
+		return existing, nil, nil
+	}
+	sort.Strings(defaulted)
+
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}, defaulted, nil
+}
+
+// EncodeTypedProperties encodes the string values of integer, number and boolean properties as native JSON types
+// according to the property definitions of the Kamelet, as some Kamelets fail on quoted numeric values. The items of
+// array properties are encoded according to the item definition. Values that do
+// not match the property type and property placeholders are kept as strings. Returns the unchanged properties if no
+// value is encoded.
+func EncodeTypedProperties(kamelet *v1alpha1.Kamelet, existing *v1alpha1.EndpointProperties) (*v1alpha1.EndpointProperties, error) {
+	if kamelet.Spec.Definition == nil {
+		return existing, nil
+	}
+
+	properties, err := RawEndpointProperties(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := false
+	for name, value := range properties {
+		schema := kamelet.Spec.Definition.Properties[name]
+		switch v := value.(type) {
+		case string:
+			if typed, ok := typedValue(schema.Type, v); ok && !IsPropertyReference(v) {
+				properties[name] = typed
+				encoded = true
+			}
+		case []interface{}:
+			if schema.Type != "array" || schema.Items == nil {
+				continue
+			}
+			for i, item := range v {
+				if s, ok := item.(string); ok && !IsPropertyReference(s) {
+					if typed, ok := typedValue(schema.Items.Type, s); ok {
+						v[i] = typed
+						encoded = true
+					}
+				}
+			}
+		}
+	}
+	if !encoded {
+		return existing, nil
+	}
+
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}, nil
+}
+
+// typedValue converts the value to the JSON type of the property, numbers keep their given representation
+func typedValue(propertyType string, value string) (interface{}, bool) {
+	switch propertyType {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.Number(value), true
+		}
+	case "number":
+		var number float64
+		if err := json.Unmarshal([]byte(value), &number); err == nil {
+			return json.Number(value), true
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// ToEndpointProperties marshals the properties to the raw JSON representation used by endpoints
+func ToEndpointProperties(properties map[string]string) (*v1alpha1.EndpointProperties, error) {
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.EndpointProperties{
+		RawMessage: camelv1.RawMessage(data),
+	}, nil
+}
+
+// SetRawProperties sets the properties to given JSON values, e.g. objects or arrays, preserving the other properties
+func SetRawProperties(existing *v1alpha1.EndpointProperties, values map[string]json.RawMessage) (*v1alpha1.EndpointProperties, error) {
+	if len(values) == 0 {
+		return existing, nil
+	}
+
+	properties, err := RawEndpointProperties(existing)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range values {
+		if !json.Valid(value) {
+			return nil, fmt.Errorf("invalid JSON value of property %q", name)
+		}
+		properties[name] = value
+	}
+
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.EndpointProperties{RawMessage: camelv1.RawMessage(data)}, nil
+}
+
+// RawEndpointProperties reads the raw JSON endpoint properties preserving the value types
+func RawEndpointProperties(properties *v1alpha1.EndpointProperties) (map[string]interface{}, error) {
+	decoded := map[string]interface{}{}
+	if properties == nil || len(properties.RawMessage) == 0 {
+		return decoded, nil
+	}
+
+	if err := decodeJSON(properties.RawMessage, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to read endpoint properties: %w", err)
+	}
+	return decoded, nil
+}
+
+// decodeJSON unmarshals the JSON data keeping numbers as json.Number, so integers beyond the float64 precision and
+// large integers survive a round trip without being formatted in exponent notation
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// DecodeEndpointProperties reads the raw JSON endpoint properties, values are kept in their string representation,
+// objects and arrays in their JSON representation
+func DecodeEndpointProperties(properties *v1alpha1.EndpointProperties) (map[string]string, error) {
+	decoded, err := RawEndpointProperties(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for key, value := range decoded {
+		switch v := value.(type) {
+		case string:
+			result[key] = v
+		case json.Number:
+			result[key] = v.String()
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = string(data)
+		default:
+			result[key] = fmt.Sprint(value)
+		}
+	}
+	return result, nil
+}
+
+// ValidateStructuredProperties checks that object and array values are only given for properties the Kamelet defines
+// as object or array, properties not defined by the Kamelet are not checked
+func ValidateStructuredProperties(kamelet *v1alpha1.Kamelet, existing *v1alpha1.EndpointProperties) error {
+	if kamelet.Spec.Definition == nil {
+		return nil
+	}
+
+	properties, err := RawEndpointProperties(existing)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var valueType string
+		switch properties[name].(type) {
+		case map[string]interface{}:
+			valueType = "object"
+		case []interface{}:
+			valueType = "array"
+		default:
+			continue
+		}
+		schema, ok := kamelet.Spec.Definition.Properties[name]
+		if ok && schema.Type != "" && schema.Type != valueType {
+			return fmt.Errorf("invalid %s value for property %q of Kamelet %q: expected type %s", valueType, name, kamelet.Name, schema.Type)
+		}
+	}
+	return nil
+}
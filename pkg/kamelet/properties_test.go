@@ -0,0 +1,147 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateProperties(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Required = []string{"period", "message"}
+
+	assert.NilError(t, ValidateProperties(kamelet, map[string]string{"period": "1000", "message": "Hello"}))
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"period": "1000"}), "binding is missing required property \"message\" for Kamelet \"k1\"")
+	assert.Error(t, ValidateProperties(kamelet, nil), "binding is missing required property \"period\" for Kamelet \"k1\"")
+
+	kamelet.Spec.Definition = nil
+	assert.NilError(t, ValidateProperties(kamelet, nil))
+}
+
+func TestEncodeTypedProperties(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]v1alpha1.JSONSchemaProps{
+		"period":  {Type: "integer"},
+		"ratio":   {Type: "number"},
+		"enabled": {Type: "boolean"},
+		"count":   {Type: "integer"},
+		"limit":   {Type: "integer"},
+		"message": {Type: "string"},
+	}
+
+	properties, err := ToEndpointProperties(map[string]string{
+		"period":  "1000",
+		"ratio":   "0.5e1",
+		"enabled": "true",
+		"count":   "{{secret:counts/count}}",
+		"limit":   "many",
+		"message": "42",
+		"unknown": "1",
+	})
+	assert.NilError(t, err)
+	encoded, err := EncodeTypedProperties(kamelet, properties)
+	assert.NilError(t, err)
+	assert.Equal(t, string(encoded.RawMessage), `{"count":"{{secret:counts/count}}","enabled":true,"limit":"many","message":"42","period":1000,"ratio":0.5e1,"unknown":"1"}`)
+
+	properties, err = ToEndpointProperties(map[string]string{"message": "Hello", "ratio": "NaN"})
+	assert.NilError(t, err)
+	unchanged, err := EncodeTypedProperties(kamelet, properties)
+	assert.NilError(t, err)
+	assert.Equal(t, unchanged, properties)
+
+	kamelet.Spec.Definition.Properties["partitions"] = v1alpha1.JSONSchemaProps{Type: "array", Items: &v1alpha1.JSONSchemaProps{Type: "integer"}}
+	properties, err = SetRawProperties(nil, map[string]json.RawMessage{"partitions": json.RawMessage(`["1","{{partition}}",3]`)})
+	assert.NilError(t, err)
+	encoded, err = EncodeTypedProperties(kamelet, properties)
+	assert.NilError(t, err)
+	assert.Equal(t, string(encoded.RawMessage), `{"partitions":[1,"{{partition}}",3]}`)
+
+	kamelet.Spec.Definition = nil
+	unchanged, err = EncodeTypedProperties(kamelet, encoded)
+	assert.NilError(t, err)
+	assert.Equal(t, unchanged, encoded)
+}
+
+func TestTypedPropertiesRoundTrip(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]v1alpha1.JSONSchemaProps{
+		"period": {Type: "integer"},
+		"offset": {Type: "integer"},
+		"ratio":  {Type: "number"},
+		"limit":  {Type: "integer", Default: &v1alpha1.JSON{RawMessage: []byte("9007199254740993")}},
+	}
+
+	properties, err := ToEndpointProperties(map[string]string{"period": "1000000", "offset": "9007199254740993", "ratio": "0.5e1"})
+	assert.NilError(t, err)
+	encoded, err := EncodeTypedProperties(kamelet, properties)
+	assert.NilError(t, err)
+	defaulted, _, err := ApplyPropertyDefaults(kamelet, encoded)
+	assert.NilError(t, err)
+	assert.Equal(t, string(defaulted.RawMessage), `{"limit":9007199254740993,"offset":9007199254740993,"period":1000000,"ratio":0.5e1}`)
+
+	decoded, err := DecodeEndpointProperties(defaulted)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded, map[string]string{"period": "1000000", "offset": "9007199254740993", "ratio": "0.5e1", "limit": "9007199254740993"})
+
+	_, err = DecodeEndpointProperties(&v1alpha1.EndpointProperties{RawMessage: []byte(`{"period":1} {}`)})
+	assert.ErrorContains(t, err, "failed to read endpoint properties")
+}
+
+func TestSetRawProperties(t *testing.T) {
+	properties, err := ToEndpointProperties(map[string]string{"message": "Hello"})
+	assert.NilError(t, err)
+
+	updated, err := SetRawProperties(properties, map[string]json.RawMessage{"filters": json.RawMessage(`{"a": 1, "b": [2, 3]}`)})
+	assert.NilError(t, err)
+	assert.Equal(t, string(updated.RawMessage), `{"filters":{"a":1,"b":[2,3]},"message":"Hello"}`)
+
+	decoded, err := DecodeEndpointProperties(updated)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded, map[string]string{"filters": `{"a":1,"b":[2,3]}`, "message": "Hello"})
+
+	unchanged, err := SetRawProperties(properties, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, unchanged, properties)
+
+	_, err = SetRawProperties(nil, map[string]json.RawMessage{"filters": json.RawMessage(`{"a"`)})
+	assert.Error(t, err, "invalid JSON value of property \"filters\"")
+}
+
+func TestValidateStructuredProperties(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]v1alpha1.JSONSchemaProps{
+		"filters": {Type: "object"},
+		"topics":  {Type: "array"},
+		"message": {Type: "string"},
+	}
+
+	properties, err := SetRawProperties(nil, map[string]json.RawMessage{
+		"filters": json.RawMessage(`{"a":1}`),
+		"topics":  json.RawMessage(`["a","b"]`),
+		"unknown": json.RawMessage(`[1]`),
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, ValidateStructuredProperties(kamelet, properties))
+
+	properties, err = SetRawProperties(nil, map[string]json.RawMessage{"message": json.RawMessage(`["a"]`)})
+	assert.NilError(t, err)
+	assert.Error(t, ValidateStructuredProperties(kamelet, properties), "invalid array value for property \"message\" of Kamelet \"k1\": expected type string")
+}
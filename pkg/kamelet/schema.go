@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+)
+
+// TypeLabel holds the type of the Kamelet, e.g. source, sink or action
+const TypeLabel = "camel.apache.org/kamelet.type"
+
+// TypeOf returns the type of the Kamelet given by its type label, e.g. source, sink or action
+func TypeOf(kamelet *v1alpha1.Kamelet) string {
+	return kamelet.Labels[TypeLabel]
+}
+
+// sampleValues provides an example value for each JSON schema type when the Kamelet does not define one
+var sampleValues = map[string]string{
+	"integer": "1000",
+	"number":  "1.5",
+	"boolean": "true",
+	"string":  "text",
+}
+
+// ValidatePropertyValue checks the property value against the Kamelet JSON schema of the property. Values referencing
+// Secrets or ConfigMaps are resolved at runtime and therefore can not be validated.
+func ValidatePropertyValue(kamelet *v1alpha1.Kamelet, name string, schema v1alpha1.JSONSchemaProps, value string) error {
+	if IsPropertyReference(value) {
+		return nil
+	}
+
+	if err := ValidateValue(schema, value); err != nil {
+		message := fmt.Sprintf("invalid value %q for property %q of Kamelet %q: %s", value, name, kamelet.Name, err.Error())
+		if example := SchemaExample(schema); example != "" {
+			message += fmt.Sprintf(" (e.g. %s)", example)
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+// ValidateValue checks type, enum, pattern and format of given value
+func ValidateValue(schema v1alpha1.JSONSchemaProps, value string) error {
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("expected type %s", schema.Type)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("expected type %s", schema.Type)
+		}
+	case "boolean":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("expected type %s", schema.Type)
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, enum := range schema.Enum {
+			allowed = append(allowed, JSONValue(enum))
+		}
+		if !containsString(allowed, value) {
+			return fmt.Errorf("expected one of: %s", strings.Join(allowed, "|"))
+		}
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err == nil && !pattern.MatchString(value) {
+			return fmt.Errorf("expected value matching pattern %q", schema.Pattern)
+		}
+	}
+
+	return verifyFormat(schema.Format, value)
+}
+
+// verifyFormat checks the well known string formats, unknown formats such as password are accepted as is
+func verifyFormat(format string, value string) error {
+	var err error
+	switch format {
+	case "uri", "url":
+		var parsed *url.URL
+		parsed, err = url.Parse(value)
+		if err == nil && !parsed.IsAbs() {
+			err = fmt.Errorf("not absolute")
+		}
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "duration":
+		_, err = time.ParseDuration(value)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("expected format %s", format)
+	}
+	return nil
+}
+
+// SchemaExample returns the example value of the property or a sample value for its type
+func SchemaExample(schema v1alpha1.JSONSchemaProps) string {
+	if example := JSONValue(schema.Example); example != "" {
+		return example
+	}
+	if len(schema.Enum) > 0 {
+		return JSONValue(schema.Enum[0])
+	}
+	if schema.Format == "" && schema.Pattern == "" {
+		return sampleValues[schema.Type]
+	}
+	return ""
+}
+
+// IsPropertyReference checks whether the value is a placeholder resolved at runtime such as {{secret:name/key}}
+func IsPropertyReference(value string) bool {
+	return strings.HasPrefix(value, "{{") && strings.HasSuffix(value, "}}")
+}
+
+// JSONValue formats the raw JSON value for display, strings are printed without quotes
+func JSONValue(value *v1alpha1.JSON) string {
+	if value == nil || len(value.RawMessage) == 0 {
+		return ""
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(value.RawMessage, &decoded); err != nil {
+		return string(value.RawMessage)
+	}
+	if s, ok := decoded.(string); ok {
+		return s
+	}
+	return string(value.RawMessage)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
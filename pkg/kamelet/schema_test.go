@@ -0,0 +1,82 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidatePropertyTypes(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"period":  {Type: "integer", Example: &camelkapis.JSON{RawMessage: []byte("5000")}},
+		"ratio":   {Type: "number"},
+		"enabled": {Type: "boolean"},
+		"message": {Type: "string"},
+	}
+
+	assert.NilError(t, ValidateProperties(kamelet, map[string]string{"period": "1000", "ratio": "0.5", "enabled": "false", "message": "Hello"}))
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"period": "1s"}),
+		"invalid value \"1s\" for property \"period\" of Kamelet \"k1\": expected type integer (e.g. 5000)")
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"ratio": "half"}),
+		"invalid value \"half\" for property \"ratio\" of Kamelet \"k1\": expected type number (e.g. 1.5)")
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"enabled": "yes"}),
+		"invalid value \"yes\" for property \"enabled\" of Kamelet \"k1\": expected type boolean (e.g. true)")
+}
+
+func TestValidatePropertyConstraints(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"level": {Type: "string", Enum: []*camelkapis.JSON{
+			{RawMessage: []byte(`"info"`)},
+			{RawMessage: []byte(`"debug"`)},
+		}},
+		"topic": {Type: "string", Pattern: "^[a-z]+$"},
+		"url":   {Type: "string", Format: "uri"},
+		"mail":  {Type: "string", Format: "email"},
+		"token": {Type: "string", Format: "password"},
+	}
+
+	assert.NilError(t, ValidateProperties(kamelet, map[string]string{
+		"level": "debug",
+		"topic": "orders",
+		"url":   "https://example.com/events",
+		"mail":  "admin@example.com",
+		"token": "s3cr3t!",
+	}))
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"level": "warn"}),
+		"invalid value \"warn\" for property \"level\" of Kamelet \"k1\": expected one of: info|debug (e.g. info)")
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"topic": "Orders"}),
+		"invalid value \"Orders\" for property \"topic\" of Kamelet \"k1\": expected value matching pattern \"^[a-z]+$\"")
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"url": "example.com"}),
+		"invalid value \"example.com\" for property \"url\" of Kamelet \"k1\": expected format uri")
+	assert.Error(t, ValidateProperties(kamelet, map[string]string{"mail": "admin"}),
+		"invalid value \"admin\" for property \"mail\" of Kamelet \"k1\": expected format email")
+}
+
+func TestValidatePropertyReferences(t *testing.T) {
+	kamelet := newKamelet("k1")
+	kamelet.Spec.Definition.Properties = map[string]camelkapis.JSONSchemaProps{
+		"period": {Type: "integer"},
+	}
+
+	assert.NilError(t, ValidateProperties(kamelet, map[string]string{"period": "{{configmap:timer-config/period}}"}))
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SinkTypes maps the built-in sink types to their API version and kind
+var SinkTypes = map[string]v1.TypeMeta{
+	"broker": {
+		APIVersion: "eventing.knative.dev/v1",
+		Kind:       "Broker",
+	},
+	"channel": {
+		APIVersion: "messaging.knative.dev/v1",
+		Kind:       "Channel",
+	},
+	"service": {
+		APIVersion: "serving.knative.dev/v1",
+		Kind:       "Service",
+	},
+	"kamelet": {
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       v1alpha1.KameletKind,
+	},
+	"kafkatopic": {
+		APIVersion: "kafka.strimzi.io/v1beta2",
+		Kind:       "KafkaTopic",
+	},
+}
+
+// URISinkType is the sink type of Camel endpoint URIs given in the form of uri:<endpoint-uri>
+const URISinkType = "uri"
+
+// DecodeSink resolves the sink expression to the binding sink endpoint. Expressions of the uri type and
+// URLs such as https://example.com/webhook are set as endpoint URI, all others are resolved to an object reference
+// with given sink types, nil sink types default to the built-in SinkTypes.
+func DecodeSink(sink string, sinkTypes map[string]v1.TypeMeta) (*v1alpha1.Endpoint, error) {
+	uri := ""
+	if strings.HasPrefix(sink, URISinkType+":") {
+		uri = strings.TrimPrefix(sink, URISinkType+":")
+		if !strings.Contains(uri, ":") {
+			return nil, fmt.Errorf("invalid sink URI %q, expected <scheme>:<path>", uri)
+		}
+	} else if strings.Contains(sink, "://") {
+		uri = sink
+	}
+	if uri != "" {
+		return &v1alpha1.Endpoint{URI: &uri}, nil
+	}
+
+	ref, err := DecodeSinkReference(sink, sinkTypes)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.Endpoint{Ref: ref}, nil
+}
+
+// DecodeSinkReference resolves the sink expression in the form of <type>:[<namespace>/]<name> to an object reference.
+// Any other resource is given fully qualified in the form of <apiVersion>:<kind>:[<namespace>/]<name>. Nil sink types
+// default to the built-in SinkTypes.
+func DecodeSinkReference(sink string, sinkTypes map[string]v1.TypeMeta) (*corev1.ObjectReference, error) {
+	if strings.Count(sink, ":") == 2 {
+		return decodeResourceSink(sink)
+	}
+
+	parts := strings.SplitN(sink, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid sink expression %q, expected <type>:<name>", sink)
+	}
+
+	if sinkTypes == nil {
+		sinkTypes = SinkTypes
+	}
+	sinkType, ok := sinkTypes[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sink type %q, supported types are: %s, use --uri for Camel endpoint URIs", parts[0], strings.Join(SupportedSinkTypes(sinkTypes), ", "))
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: sinkType.APIVersion,
+		Kind:       sinkType.Kind,
+		Name:       parts[1],
+	}
+	if names := strings.SplitN(parts[1], "/", 2); len(names) == 2 {
+		if names[0] == "" || names[1] == "" {
+			return nil, fmt.Errorf("invalid sink expression %q, expected <type>:[<namespace>/]<name>", sink)
+		}
+		ref.Namespace = names[0]
+		ref.Name = names[1]
+	}
+	return ref, nil
+}
+
+// SetSinkNamespace sets the namespace of the sink reference unless given by the sink expression. The namespace
+// defaults to the sink namespace if given, else to the binding namespace. A sink namespace is rejected for URI sinks
+// and when it conflicts with the namespace of the sink expression.
+func SetSinkNamespace(sink *v1alpha1.Endpoint, sinkNamespace string, namespace string) error {
+	if sink.Ref == nil {
+		if sinkNamespace != "" {
+			return fmt.Errorf("sink namespace %q is not supported for URI sinks", sinkNamespace)
+		}
+		return nil
+	}
+
+	switch {
+	case sink.Ref.Namespace != "" && sinkNamespace != "" && sink.Ref.Namespace != sinkNamespace:
+		return fmt.Errorf("sink namespace %q conflicts with namespace %q of the sink expression", sinkNamespace, sink.Ref.Namespace)
+	case sink.Ref.Namespace != "":
+	case sinkNamespace != "":
+		sink.Ref.Namespace = sinkNamespace
+	default:
+		sink.Ref.Namespace = namespace
+	}
+	return nil
+}
+
+// decodeResourceSink resolves the fully qualified sink expression <apiVersion>:<kind>:[<namespace>/]<name>
+func decodeResourceSink(sink string) (*corev1.ObjectReference, error) {
+	parts := strings.Split(sink, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid sink expression %q, expected <apiVersion>:<kind>:[<namespace>/]<name>", sink)
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: parts[0],
+		Kind:       parts[1],
+		Name:       parts[2],
+	}
+	if names := strings.SplitN(parts[2], "/", 2); len(names) == 2 {
+		if names[0] == "" || names[1] == "" {
+			return nil, fmt.Errorf("invalid sink expression %q, expected <apiVersion>:<kind>:[<namespace>/]<name>", sink)
+		}
+		ref.Namespace = names[0]
+		ref.Name = names[1]
+	}
+	return ref, nil
+}
+
+// SupportedSinkTypes returns the sorted list of given sink types, nil sink types default to the built-in SinkTypes
+func SupportedSinkTypes(sinkTypes map[string]v1.TypeMeta) []string {
+	if sinkTypes == nil {
+		sinkTypes = SinkTypes
+	}
+	types := make([]string, 0, len(sinkTypes))
+	for sinkType := range sinkTypes {
+		types = append(types, sinkType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// BindingName generates the default binding name in the form of <source>-to-<kind>-<name>, or <source>-to-<scheme>
+// for URI sinks
+func BindingName(source string, sink *v1alpha1.Endpoint) string {
+	if sink.Ref == nil && sink.URI != nil {
+		scheme := strings.SplitN(*sink.URI, ":", 2)[0]
+		return fmt.Sprintf("%s-to-%s", source, strings.ToLower(scheme))
+	}
+	return fmt.Sprintf("%s-to-%s-%s", source, strings.ToLower(sink.Ref.Kind), sink.Ref.Name)
+}
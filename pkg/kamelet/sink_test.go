@@ -0,0 +1,149 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kamelet
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDecodeSinkReference(t *testing.T) {
+	ref, err := DecodeSinkReference("broker:default", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "eventing.knative.dev/v1")
+	assert.Equal(t, ref.Kind, "Broker")
+	assert.Equal(t, ref.Name, "default")
+
+	ref, err = DecodeSinkReference("channel:events", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "messaging.knative.dev/v1")
+	assert.Equal(t, ref.Kind, "Channel")
+
+	ref, err = DecodeSinkReference("service:display", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "serving.knative.dev/v1")
+	assert.Equal(t, ref.Kind, "Service")
+
+	ref, err = DecodeSinkReference("kafkatopic:my-topic", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "kafka.strimzi.io/v1beta2")
+	assert.Equal(t, ref.Kind, "KafkaTopic")
+	assert.Equal(t, ref.Name, "my-topic")
+
+	ref, err = DecodeSinkReference("broker:events/default", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Kind, "Broker")
+	assert.Equal(t, ref.Namespace, "events")
+	assert.Equal(t, ref.Name, "default")
+
+	_, err = DecodeSinkReference("broker:events/", nil)
+	assert.Error(t, err, "invalid sink expression \"broker:events/\", expected <type>:[<namespace>/]<name>")
+}
+
+func TestSetSinkNamespace(t *testing.T) {
+	sink, _ := DecodeSink("broker:default", nil)
+	assert.NilError(t, SetSinkNamespace(sink, "", "test"))
+	assert.Equal(t, sink.Ref.Namespace, "test")
+
+	sink, _ = DecodeSink("broker:default", nil)
+	assert.NilError(t, SetSinkNamespace(sink, "events", "test"))
+	assert.Equal(t, sink.Ref.Namespace, "events")
+
+	sink, _ = DecodeSink("broker:events/default", nil)
+	assert.NilError(t, SetSinkNamespace(sink, "", "test"))
+	assert.Equal(t, sink.Ref.Namespace, "events")
+	assert.NilError(t, SetSinkNamespace(sink, "events", "test"))
+	assert.Error(t, SetSinkNamespace(sink, "other", "test"), "sink namespace \"other\" conflicts with namespace \"events\" of the sink expression")
+
+	sink, _ = DecodeSink("https://example.com/webhook", nil)
+	assert.NilError(t, SetSinkNamespace(sink, "", "test"))
+	assert.Error(t, SetSinkNamespace(sink, "events", "test"), "sink namespace \"events\" is not supported for URI sinks")
+}
+
+func TestDecodeSink(t *testing.T) {
+	sink, err := DecodeSink("broker:default", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, sink.Ref.Kind, "Broker")
+	assert.Assert(t, sink.URI == nil)
+
+	sink, err = DecodeSink("https://example.com/webhook", nil)
+	assert.NilError(t, err)
+	assert.Assert(t, sink.Ref == nil)
+	assert.Equal(t, *sink.URI, "https://example.com/webhook")
+
+	sink, err = DecodeSink("uri:kafka:topic", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, *sink.URI, "kafka:topic")
+
+	_, err = DecodeSink("uri:topic", nil)
+	assert.Error(t, err, "invalid sink URI \"topic\", expected <scheme>:<path>")
+
+	assert.Equal(t, BindingName("k1", sink), "k1-to-kafka")
+}
+
+func TestDecodeResourceSink(t *testing.T) {
+	ref, err := DecodeSinkReference("sources.example.com/v1:EventSink:events/display", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "sources.example.com/v1")
+	assert.Equal(t, ref.Kind, "EventSink")
+	assert.Equal(t, ref.Namespace, "events")
+	assert.Equal(t, ref.Name, "display")
+
+	ref, err = DecodeSinkReference("v1:Service:display", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Namespace, "")
+	assert.Equal(t, ref.Name, "display")
+
+	_, err = DecodeSinkReference("v1:Service:events/", nil)
+	assert.Error(t, err, "invalid sink expression \"v1:Service:events/\", expected <apiVersion>:<kind>:[<namespace>/]<name>")
+
+	binding, err := NewBinding(&BindingOptions{Namespace: "default", Kamelet: "k1", Sink: "sources.example.com/v1:EventSink:events/display"})
+	assert.NilError(t, err)
+	assert.Equal(t, binding.Spec.Sink.Ref.Namespace, "events")
+}
+
+func TestDecodeSinkErrors(t *testing.T) {
+	_, err := DecodeSinkReference("default", nil)
+	assert.Error(t, err, "invalid sink expression \"default\", expected <type>:<name>")
+
+	_, err = DecodeSinkReference("broker:", nil)
+	assert.Error(t, err, "invalid sink expression \"broker:\", expected <type>:<name>")
+
+	_, err = DecodeSinkReference("foo:bar", nil)
+	assert.Error(t, err, "unsupported sink type \"foo\", supported types are: broker, channel, kafkatopic, kamelet, service, use --uri for Camel endpoint URIs")
+}
+
+func TestDecodeSinkTypes(t *testing.T) {
+	sinkTypes := map[string]v1.TypeMeta{
+		"kafkachannel": {APIVersion: "messaging.knative.dev/v1beta1", Kind: "KafkaChannel"},
+	}
+	ref, err := DecodeSinkReference("kafkachannel:orders", sinkTypes)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.APIVersion, "messaging.knative.dev/v1beta1")
+	assert.Equal(t, ref.Kind, "KafkaChannel")
+	assert.Equal(t, ref.Name, "orders")
+
+	_, err = DecodeSinkReference("broker:default", sinkTypes)
+	assert.Error(t, err, "unsupported sink type \"broker\", supported types are: kafkachannel, use --uri for Camel endpoint URIs")
+
+	// the built-in sink types are left untouched
+	_, err = DecodeSinkReference("kafkachannel:orders", nil)
+	assert.ErrorContains(t, err, "unsupported sink type \"kafkachannel\"")
+	assert.DeepEqual(t, SupportedSinkTypes(sinkTypes), []string{"kafkachannel"})
+}
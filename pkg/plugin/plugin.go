@@ -15,8 +15,7 @@
 package plugin
 
 import (
-	"os"
-
+	"knative.dev/kn-plugin-source-kamelet/internal/command"
 	"knative.dev/kn-plugin-source-kamelet/internal/root"
 
 	knplugin "knative.dev/client/pkg/kn/plugin"
@@ -33,14 +32,10 @@ func (pl *plugin) Name() string {
 	return "kn-source-kamelet"
 }
 
-// Execute represents the plugin's entrypoint when called through kn
+// Execute represents the plugin's entrypoint when called through kn, the commands run as 'kn source kamelet ...'
 func (pl *plugin) Execute(args []string) error {
-	cmd := root.NewSourceKameletCommand()
-	oldArgs := os.Args
-	defer (func() {
-		os.Args = oldArgs
-	})()
-	os.Args = append([]string{"kn-source-kamelet"}, args...)
+	cmd := root.NewInlineCommand("kn", pl.CommandParts()...)
+	cmd.Root().SetArgs(append(pl.CommandParts(), args...))
 	return cmd.Execute()
 }
 
@@ -58,3 +53,25 @@ func (pl *plugin) CommandParts() []string {
 func (pl *plugin) Path() string {
 	return ""
 }
+
+// Manifest describes the plugin including its build metadata
+type Manifest struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	CommandParts []string            `json:"commandParts"`
+	Version      command.VersionInfo `json:"version"`
+}
+
+// Manifest returns the plugin manifest with the version metadata also printed by the version command
+func (pl *plugin) Manifest() (*Manifest, error) {
+	description, err := pl.Description()
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{
+		Name:         pl.Name(),
+		Description:  description,
+		CommandParts: pl.CommandParts(),
+		Version:      command.GetVersionInfo(),
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	knplugin "knative.dev/client/pkg/kn/plugin"
+	"knative.dev/client/pkg/util"
+	"knative.dev/kn-plugin-source-kamelet/internal/root"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPluginRegistration(t *testing.T) {
+	var registered knplugin.Plugin
+	for _, pl := range knplugin.InternalPlugins {
+		if pl.Name() == "kn-source-kamelet" {
+			registered = pl
+		}
+	}
+	assert.Assert(t, registered != nil)
+	assert.DeepEqual(t, registered.CommandParts(), []string{"source", "kamelet"})
+	assert.Equal(t, registered.Path(), "")
+}
+
+func TestPluginExecute(t *testing.T) {
+	pl := &plugin{}
+	assert.NilError(t, pl.Execute([]string{"version"}))
+	assert.ErrorContains(t, pl.Execute([]string{"version", "--unknown"}), "unknown flag: --unknown")
+}
+
+func TestInlineCommand(t *testing.T) {
+	cmd := root.NewInlineCommand("kn", "source", "kamelet")
+	assert.Equal(t, cmd.CommandPath(), "kn source kamelet")
+
+	bindingCmd, _, err := cmd.Find([]string{"binding", "list"})
+	assert.NilError(t, err)
+	assert.Equal(t, bindingCmd.CommandPath(), "kn source kamelet binding list")
+	assert.Check(t, util.ContainsAll(bindingCmd.Example, "kn source kamelet binding list --all-namespaces"))
+	assert.Check(t, util.ContainsNone(bindingCmd.Example, "kn-source-kamelet"))
+
+	assert.Check(t, util.ContainsAll(cmd.UsageString(), "Kamelet Commands:", "Binding Commands:", "Other Commands:",
+		"Use \"kn source kamelet <command> --help\""))
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	camelkapis "github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelkv1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"knative.dev/client/pkg/util/mock"
+)
+
+// MockKameletClient implements the Camel K v1alpha1 client by replaying the calls recorded on its KameletRecorder
+type MockKameletClient struct {
+	t        *testing.T
+	recorder *KameletRecorder
+}
+
+func (c *MockKameletClient) RESTClient() rest.Interface {
+	panic("should not be called")
+}
+
+// NewMockKameletClient returns a new mock instance which you need to record for, the optional namespace defaults to
+// "default"
+func NewMockKameletClient(t *testing.T, ns ...string) *MockKameletClient {
+	namespace := "default"
+	if len(ns) > 0 {
+		namespace = ns[0]
+	}
+	return &MockKameletClient{
+		t:        t,
+		recorder: &KameletRecorder{r: mock.NewRecorder(t, namespace)},
+	}
+}
+
+// Ensure that the interface is implemented
+var _ camelkv1alpha1.CamelV1alpha1Interface = &MockKameletClient{}
+var _ camelkv1alpha1.KameletInterface = &MockKameletClient{}
+
+// KameletRecorder records the expected Kamelet and KameletBinding calls of a MockKameletClient
+type KameletRecorder struct {
+	r    *mock.Recorder
+	lock sync.Mutex
+}
+
+// verifyCall shifts the next recorded call, guarded for clients used concurrently
+func (sr *KameletRecorder) verifyCall(name string, args ...interface{}) *mock.ApiMethodCall {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+	return sr.r.VerifyCall(name, args...)
+}
+
+func (c *MockKameletClient) CamelV1() camelkv1.CamelV1Interface {
+	panic("implement me")
+}
+
+func (c *MockKameletClient) CamelV1alpha1() *camelkv1alpha1.CamelV1alpha1Interface {
+	var i camelkv1alpha1.CamelV1alpha1Interface = c
+	return &i
+}
+
+func (c *MockKameletClient) GetScheme() *runtime.Scheme {
+	panic("implement me")
+}
+
+func (c *MockKameletClient) GetConfig() *rest.Config {
+	panic("implement me")
+}
+
+func (c *MockKameletClient) GetCurrentNamespace(kubeConfig string) (string, error) {
+	panic("implement me")
+}
+
+func (c *MockKameletClient) Kamelets(namespace string) camelkv1alpha1.KameletInterface {
+	var i camelkv1alpha1.KameletInterface = c
+	return i
+}
+
+func (c *MockKameletClient) KameletBindings(namespace string) camelkv1alpha1.KameletBindingInterface {
+	return &mockKameletBindingClient{recorder: c.recorder}
+}
+
+// Recorder returns the recorder for registering API calls
+func (c *MockKameletClient) Recorder() *KameletRecorder {
+	return c.recorder
+}
+
+// List records a call for ListKamelets with the expected result and error (nil if none)
+func (sr *KameletRecorder) List(kameletList *camelkapis.KameletList, err error) {
+	sr.r.Add("List", nil, []interface{}{kameletList, err})
+}
+
+// List performs a previously recorded action
+func (c *MockKameletClient) List(ctx context.Context, opts v1.ListOptions) (*camelkapis.KameletList, error) {
+	call := c.recorder.verifyCall("List")
+	return call.Result[0].(*camelkapis.KameletList), mock.ErrorOrNil(call.Result[1])
+}
+
+// Create records a call for CreateKamelet with the expected Kamelet (or an assertion function) and error (nil if none)
+func (sr *KameletRecorder) Create(kamelet interface{}, err error) {
+	sr.r.Add("Create", []interface{}{kamelet}, []interface{}{err})
+}
+
+// Create performs a previously recorded action
+func (c *MockKameletClient) Create(ctx context.Context, kamelet *camelkapis.Kamelet, opts v1.CreateOptions) (*camelkapis.Kamelet, error) {
+	call := c.recorder.verifyCall("Create", kamelet)
+	return kamelet, mock.ErrorOrNil(call.Result[0])
+}
+
+// Update records a call for UpdateKamelet with the expected Kamelet (or an assertion function) and error (nil if none)
+func (sr *KameletRecorder) Update(kamelet interface{}, err error) {
+	sr.r.Add("Update", []interface{}{kamelet}, []interface{}{err})
+}
+
+// Update performs a previously recorded action
+func (c *MockKameletClient) Update(ctx context.Context, kamelet *camelkapis.Kamelet, opts v1.UpdateOptions) (*camelkapis.Kamelet, error) {
+	call := c.recorder.verifyCall("Update", kamelet)
+	return kamelet, mock.ErrorOrNil(call.Result[0])
+}
+
+func (c *MockKameletClient) UpdateStatus(ctx context.Context, kamelet *camelkapis.Kamelet, opts v1.UpdateOptions) (*camelkapis.Kamelet, error) {
+	panic("implement me")
+}
+
+// Delete records a call for DeleteKamelet with the expected name and error (nil if none)
+func (sr *KameletRecorder) Delete(name string, err error) {
+	sr.r.Add("Delete", []interface{}{name}, []interface{}{err})
+}
+
+// Delete performs a previously recorded action
+func (c *MockKameletClient) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	call := c.recorder.verifyCall("Delete", name)
+	return mock.ErrorOrNil(call.Result[0])
+}
+
+func (c *MockKameletClient) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	panic("implement me")
+}
+
+// Get records a call for GetKamelet with the expected result and error (nil if none)
+func (sr *KameletRecorder) Get(kamelet *camelkapis.Kamelet, err error) {
+	sr.r.Add("Get", nil, []interface{}{kamelet, err})
+}
+
+// Get performs a previously recorded action
+func (c *MockKameletClient) Get(ctx context.Context, name string, opts v1.GetOptions) (*camelkapis.Kamelet, error) {
+	call := c.recorder.verifyCall("Get")
+	return call.Result[0].(*camelkapis.Kamelet), mock.ErrorOrNil(call.Result[1])
+}
+
+// Watch records a call for WatchKamelets with the expected watcher and error (nil if none)
+func (sr *KameletRecorder) Watch(watcher watch.Interface, err error) {
+	sr.r.Add("Watch", nil, []interface{}{watcher, err})
+}
+
+// Watch performs a previously recorded action
+func (c *MockKameletClient) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	call := c.recorder.verifyCall("Watch")
+	return call.Result[0].(watch.Interface), mock.ErrorOrNil(call.Result[1])
+}
+
+func (c *MockKameletClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *camelkapis.Kamelet, err error) {
+	panic("implement me")
+}
+
+// Validate validates whether every recorded action has been called
+func (sr *KameletRecorder) Validate() {
+	sr.r.CheckThatAllRecordedMethodsHaveBeenCalled()
+}
+
+// mockKameletBindingClient performs the recorded KameletBinding actions
+type mockKameletBindingClient struct {
+	recorder *KameletRecorder
+}
+
+// Ensure that the interface is implemented
+var _ camelkv1alpha1.KameletBindingInterface = &mockKameletBindingClient{}
+
+// ListBindings records a call for ListKameletBindings with the expected result and error (nil if none)
+func (sr *KameletRecorder) ListBindings(bindingList *camelkapis.KameletBindingList, err error) {
+	sr.r.Add("ListBindings", nil, []interface{}{bindingList, err})
+}
+
+// List performs a previously recorded action
+func (c *mockKameletBindingClient) List(ctx context.Context, opts v1.ListOptions) (*camelkapis.KameletBindingList, error) {
+	call := c.recorder.verifyCall("ListBindings")
+	return call.Result[0].(*camelkapis.KameletBindingList), mock.ErrorOrNil(call.Result[1])
+}
+
+// GetBinding records a call for GetKameletBinding with the expected result and error (nil if none)
+func (sr *KameletRecorder) GetBinding(binding *camelkapis.KameletBinding, err error) {
+	sr.r.Add("GetBinding", nil, []interface{}{binding, err})
+}
+
+// Get performs a previously recorded action
+func (c *mockKameletBindingClient) Get(ctx context.Context, name string, opts v1.GetOptions) (*camelkapis.KameletBinding, error) {
+	call := c.recorder.verifyCall("GetBinding")
+	return call.Result[0].(*camelkapis.KameletBinding), mock.ErrorOrNil(call.Result[1])
+}
+
+// CreateBinding records a call for CreateKameletBinding with the expected binding (or an assertion function) and error (nil if none)
+func (sr *KameletRecorder) CreateBinding(binding interface{}, err error) {
+	sr.r.Add("CreateBinding", []interface{}{binding}, []interface{}{err})
+}
+
+// Create performs a previously recorded action
+func (c *mockKameletBindingClient) Create(ctx context.Context, binding *camelkapis.KameletBinding, opts v1.CreateOptions) (*camelkapis.KameletBinding, error) {
+	call := c.recorder.verifyCall("CreateBinding", binding)
+	return binding, mock.ErrorOrNil(call.Result[0])
+}
+
+// UpdateBinding records a call for UpdateKameletBinding with the expected binding (or an assertion function) and error (nil if none)
+func (sr *KameletRecorder) UpdateBinding(binding interface{}, err error) {
+	sr.r.Add("UpdateBinding", []interface{}{binding}, []interface{}{err})
+}
+
+// Update performs a previously recorded action
+func (c *mockKameletBindingClient) Update(ctx context.Context, binding *camelkapis.KameletBinding, opts v1.UpdateOptions) (*camelkapis.KameletBinding, error) {
+	call := c.recorder.verifyCall("UpdateBinding", binding)
+	return binding, mock.ErrorOrNil(call.Result[0])
+}
+
+func (c *mockKameletBindingClient) UpdateStatus(ctx context.Context, binding *camelkapis.KameletBinding, opts v1.UpdateOptions) (*camelkapis.KameletBinding, error) {
+	panic("implement me")
+}
+
+// DeleteBinding records a call for DeleteKameletBinding with the expected name and error (nil if none)
+func (sr *KameletRecorder) DeleteBinding(name string, err error) {
+	sr.r.Add("DeleteBinding", []interface{}{name}, []interface{}{err})
+}
+
+// Delete performs a previously recorded action
+func (c *mockKameletBindingClient) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	call := c.recorder.verifyCall("DeleteBinding", name)
+	return mock.ErrorOrNil(call.Result[0])
+}
+
+func (c *mockKameletBindingClient) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	panic("implement me")
+}
+
+// WatchBindings records a call for WatchKameletBindings with the expected watcher and error (nil if none)
+func (sr *KameletRecorder) WatchBindings(watcher watch.Interface, err error) {
+	sr.r.Add("WatchBindings", nil, []interface{}{watcher, err})
+}
+
+// Watch performs a previously recorded action
+func (c *mockKameletBindingClient) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	call := c.recorder.verifyCall("WatchBindings")
+	return call.Result[0].(watch.Interface), mock.ErrorOrNil(call.Result[1])
+}
+
+// PatchBinding records a call for PatchKameletBinding with the expected name, patch type, data and options (or assertion
+// functions) and the resulting binding and error (nil if none)
+func (sr *KameletRecorder) PatchBinding(name string, patchType interface{}, data interface{}, opts interface{}, binding *camelkapis.KameletBinding, err error) {
+	sr.r.Add("PatchBinding", []interface{}{name, patchType, data, opts}, []interface{}{binding, err})
+}
+
+// ApplyBinding records a server-side apply call for PatchKameletBinding with the expected binding and options (or
+// assertion functions) and error (nil if none), the applied binding is returned like on create
+func (sr *KameletRecorder) ApplyBinding(binding interface{}, opts interface{}, err error) {
+	sr.r.Add("ApplyBinding", []interface{}{binding, opts}, []interface{}{err})
+}
+
+// Patch performs a previously recorded action, server-side apply calls are verified against the ApplyBinding
+// recordings
+func (c *mockKameletBindingClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *camelkapis.KameletBinding, err error) {
+	if pt == types.ApplyPatchType {
+		binding := &camelkapis.KameletBinding{}
+		if err := json.Unmarshal(data, binding); err != nil {
+			return nil, err
+		}
+		call := c.recorder.verifyCall("ApplyBinding", binding, opts)
+		return binding, mock.ErrorOrNil(call.Result[0])
+	}
+	call := c.recorder.verifyCall("PatchBinding", name, pt, data, opts)
+	return call.Result[0].(*camelkapis.KameletBinding), mock.ErrorOrNil(call.Result[1])
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright © 2021 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package testing provides a mock of the Camel K v1alpha1 client for unit tests of code working with Kamelets and
+// KameletBindings, e.g. other kn plugins composing with this plugin.
+//
+// The mock replays API calls recorded in advance. Each expectation is recorded on the KameletRecorder with the result
+// the mock returns, the calls have to happen in the order of recording. With the package imported as kamelettesting:
+//
+//	mockClient := kamelettesting.NewMockKameletClient(t)
+//	recorder := mockClient.Recorder()
+//	recorder.Get(kamelet, nil)
+//	recorder.CreateBinding(func(t *testing.T, binding *v1alpha1.KameletBinding) {
+//		assert.Equal(t, binding.Spec.Source.Ref.Name, kamelet.Name)
+//	}, nil)
+//
+//	// run the code under test with mockClient as its CamelV1alpha1Interface
+//
+//	recorder.Validate()
+//
+// Kamelet calls are recorded with List, Get, Create, Update, Delete and Watch, KameletBinding calls with ListBindings,
+// GetBinding, CreateBinding, UpdateBinding, PatchBinding, DeleteBinding and WatchBindings. Expected arguments are
+// given either as value compared for equality or as function of the form func(*testing.T, <argument type>) asserting
+// the actual argument. Validate fails the test when a recorded call has not been performed, an unexpected call fails
+// the test immediately.
+package testing
@@ -0,0 +1,40 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBindingReconciliation(t *testing.T) {
+	h := NewHarness(t)
+	h.InstallFixtureKamelets("timer-source")
+
+	h.RunOrFail("bind", "timer-source", "--name", "timer", "--source-property", "message=hello", "--broker", "default")
+	binding := h.Binding("timer")
+	assert.Equal(t, binding.Spec.Source.Ref.Name, "timer-source")
+	h.AssertBindingReady("timer")
+
+	result := h.RunOrFail("binding", "list")
+	assert.Assert(t, result.Stdout != "")
+
+	h.RunOrFail("binding", "delete", "timer")
+	h.AssertBindingDeleted("timer")
+}
@@ -0,0 +1,291 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package e2e provides a harness running the plugin commands against a real cluster or an envtest API server, so
+// that downstream distributions can run the conformance tests of the plugin against their Camel K version.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/camel-k/pkg/apis/camel/v1alpha1"
+	camelk "github.com/apache/camel-k/pkg/client/camel/clientset/versioned"
+	camelkv1alpha1 "github.com/apache/camel-k/pkg/client/camel/clientset/versioned/typed/camel/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"knative.dev/kn-plugin-source-kamelet/internal/catalog"
+	"knative.dev/kn-plugin-source-kamelet/internal/command"
+	"knative.dev/kn-plugin-source-kamelet/internal/root"
+)
+
+// DefaultTimeout is the time the harness waits for bindings to be reconciled unless given otherwise
+const DefaultTimeout = 2 * time.Minute
+
+// Harness runs the plugin commands in a namespace created for the test. The namespace is set as namespace of the
+// kubeconfig context the commands run with so that commands do not need the --namespace flag.
+type Harness struct {
+	t          *testing.T
+	ctx        context.Context
+	kubeconfig string
+	context    string
+	namespace  string
+	timeout    time.Duration
+
+	kubeClient    kubernetes.Interface
+	kameletClient camelkv1alpha1.CamelV1alpha1Interface
+}
+
+// Option customizes the harness
+type Option func(*Harness)
+
+// WithKubeconfig runs the commands against the cluster of given kubeconfig file, e.g. the kubeconfig written for an
+// envtest API server, instead of the default kubeconfig given by $KUBECONFIG or ~/.kube/config
+func WithKubeconfig(path string) Option {
+	return func(h *Harness) {
+		h.kubeconfig = path
+	}
+}
+
+// WithContext selects the kubeconfig context instead of the current context
+func WithContext(name string) Option {
+	return func(h *Harness) {
+		h.context = name
+	}
+}
+
+// WithNamespace uses given existing namespace instead of creating a namespace, the namespace is not deleted
+func WithNamespace(namespace string) Option {
+	return func(h *Harness) {
+		h.namespace = namespace
+	}
+}
+
+// WithTimeout sets the time the harness waits for bindings to be reconciled
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Harness) {
+		h.timeout = timeout
+	}
+}
+
+// NewHarness connects to the cluster and creates the namespace of the test, the namespace is deleted on test cleanup.
+// The test fails immediately when the cluster is not reachable.
+func NewHarness(t *testing.T, options ...Option) *Harness {
+	t.Helper()
+	h := &Harness{t: t, ctx: context.Background(), timeout: DefaultTimeout}
+	for _, option := range options {
+		option(h)
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if h.kubeconfig != "" {
+		loadingRules.ExplicitPath = h.kubeconfig
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: h.context})
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	if h.kubeClient, err = kubernetes.NewForConfig(restConfig); err != nil {
+		t.Fatalf("failed to create kubernetes client: %v", err)
+	}
+	camelkClient, err := camelk.NewForConfig(restConfig)
+	if err != nil {
+		t.Fatalf("failed to create Camel K client: %v", err)
+	}
+	h.kameletClient = camelkClient.CamelV1alpha1()
+
+	if h.namespace == "" {
+		namespace, err := h.kubeClient.CoreV1().Namespaces().Create(h.ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "kn-source-kamelet-e2e-"},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("failed to create test namespace: %v", err)
+		}
+		h.namespace = namespace.Name
+		t.Cleanup(func() {
+			err := h.kubeClient.CoreV1().Namespaces().Delete(h.ctx, h.namespace, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Errorf("failed to delete test namespace %s: %v", h.namespace, err)
+			}
+		})
+	}
+
+	// the commands run with a copy of the kubeconfig that defaults to the test namespace
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	if h.context != "" {
+		rawConfig.CurrentContext = h.context
+	}
+	kubeContext, ok := rawConfig.Contexts[rawConfig.CurrentContext]
+	if !ok {
+		t.Fatalf("kubeconfig context %q not found", rawConfig.CurrentContext)
+	}
+	testContext := kubeContext.DeepCopy()
+	testContext.Namespace = h.namespace
+	rawConfig.Contexts = map[string]*clientcmdapi.Context{rawConfig.CurrentContext: testContext}
+	h.kubeconfig = filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(rawConfig, h.kubeconfig); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return h
+}
+
+// Namespace returns the namespace the commands run in
+func (h *Harness) Namespace() string {
+	return h.namespace
+}
+
+// KameletClient returns the Camel K client of the cluster
+func (h *Harness) KameletClient() camelkv1alpha1.CamelV1alpha1Interface {
+	return h.kameletClient
+}
+
+// KubeClient returns the Kubernetes client of the cluster
+func (h *Harness) KubeClient() kubernetes.Interface {
+	return h.kubeClient
+}
+
+// Result holds the output of a command run
+type Result struct {
+	Args   []string
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// ExitCode returns the code the plugin binary exits with for the result of the command run
+func (r Result) ExitCode() int {
+	return command.ExitCode(r.Err)
+}
+
+// Run executes the plugin command of given arguments in-process, e.g. Run("bind", "timer-source", "--sink", "broker:default")
+func (h *Harness) Run(args ...string) Result {
+	h.t.Helper()
+	cmd := root.NewSourceKameletCommand()
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(append([]string{"--kubeconfig", h.kubeconfig}, args...))
+	err := cmd.Execute()
+	h.t.Logf("kn-source-kamelet %s\n%s%s", strings.Join(args, " "), stdout.String(), stderr.String())
+	return Result{Args: args, Stdout: stdout.String(), Stderr: stderr.String(), Err: err}
+}
+
+// RunOrFail executes the plugin command of given arguments and fails the test when the command fails
+func (h *Harness) RunOrFail(args ...string) Result {
+	h.t.Helper()
+	result := h.Run(args...)
+	if result.Err != nil {
+		h.t.Fatalf("kn-source-kamelet %s failed: %v", strings.Join(args, " "), result.Err)
+	}
+	return result
+}
+
+// InstallKamelet creates the Kamelet in the test namespace
+func (h *Harness) InstallKamelet(kamelet *v1alpha1.Kamelet) {
+	h.t.Helper()
+	kamelet = kamelet.DeepCopy()
+	kamelet.Namespace = h.namespace
+	kamelet.ResourceVersion = ""
+	if _, err := h.kameletClient.Kamelets(h.namespace).Create(h.ctx, kamelet, metav1.CreateOptions{}); err != nil {
+		h.t.Fatalf("failed to install Kamelet %s: %v", kamelet.Name, err)
+	}
+}
+
+// InstallFixtureKamelets creates the Kamelets of given names from the catalog bundled with the plugin in the test
+// namespace, e.g. InstallFixtureKamelets("timer-source", "log-sink")
+func (h *Harness) InstallFixtureKamelets(names ...string) {
+	h.t.Helper()
+	for _, name := range names {
+		kamelet, ok := catalog.Get(name)
+		if !ok {
+			h.t.Fatalf("Kamelet %s is not part of the bundled catalog", name)
+		}
+		h.InstallKamelet(kamelet)
+	}
+}
+
+// Binding returns the KameletBinding of given name in the test namespace
+func (h *Harness) Binding(name string) *v1alpha1.KameletBinding {
+	h.t.Helper()
+	binding, err := h.kameletClient.KameletBindings(h.namespace).Get(h.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		h.t.Fatalf("failed to get KameletBinding %s: %v", name, err)
+	}
+	return binding
+}
+
+// AssertBindingReady waits until the Ready condition of the binding is True and fails the test on timeout or when
+// the binding enters the error phase
+func (h *Harness) AssertBindingReady(name string) {
+	h.t.Helper()
+	var last *v1alpha1.KameletBinding
+	err := wait.PollImmediate(time.Second, h.timeout, func() (bool, error) {
+		binding, err := h.kameletClient.KameletBindings(h.namespace).Get(h.ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		last = binding
+		if binding.Status.Phase == v1alpha1.KameletBindingPhaseError {
+			return false, fmt.Errorf("binding is in phase %s", binding.Status.Phase)
+		}
+		condition := binding.Status.GetCondition(v1alpha1.KameletBindingConditionReady)
+		return condition != nil && condition.Status == corev1.ConditionTrue, nil
+	})
+	if err != nil {
+		h.t.Fatalf("KameletBinding %s did not become ready: %v%s", name, err, describeStatus(last))
+	}
+}
+
+// AssertBindingDeleted waits until the binding is gone and fails the test on timeout
+func (h *Harness) AssertBindingDeleted(name string) {
+	h.t.Helper()
+	err := wait.PollImmediate(time.Second, h.timeout, func() (bool, error) {
+		_, err := h.kameletClient.KameletBindings(h.namespace).Get(h.ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		h.t.Fatalf("KameletBinding %s was not deleted: %v", name, err)
+	}
+}
+
+// describeStatus summarizes the binding status for failure messages
+func describeStatus(binding *v1alpha1.KameletBinding) string {
+	if binding == nil {
+		return ", binding not found"
+	}
+	var conditions []string
+	for _, condition := range binding.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s (%s)", condition.Type, condition.Status, condition.Message))
+	}
+	return fmt.Sprintf(", phase %q, conditions [%s]", binding.Status.Phase, strings.Join(conditions, ", "))
+}